@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/awsutil/eventstest"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/emulator"
+)
+
+type headers []string
+
+func (h *headers) String() string {
+	return strings.Join(*h, ",")
+}
+
+func (h *headers) Set(value string) error {
+	*h = append(*h, value)
+	return nil
+}
+
+// local-runner starts function binary against emulated Lambda Runtime API and feeds it
+// with recorded (-event) or synthesized (-type/-method/-path) events, e.g.:
+//
+//	go build -o ./bin/service ./cmd/service
+//	go run ./cmd/local-runner -bin ./bin/service -type function-url -method GET -path /api/status
+//	go run ./cmd/local-runner -bin ./bin/service -event ./events/sqs.json
+func main() {
+	var (
+		bin       = flag.String("bin", "", "path to the function binary")
+		eventPath = flag.String("event", "", "path to JSON event file or directory with JSON event files")
+		eventType = flag.String("type", "function-url", "type of synthesized event: function-url, api-gateway, alb or sqs")
+		method    = flag.String("method", "GET", "http method of synthesized event")
+		path      = flag.String("path", "/api/status", "http path (with optional query) of synthesized event")
+		body      = flag.String("body", "", "body of synthesized event (message body for sqs)")
+		queue     = flag.String("queue", "local-queue", "queue name of synthesized sqs event")
+		timeout   = flag.Duration("timeout", 30*time.Second, "function timeout")
+		hdrs      headers
+	)
+	flag.Var(&hdrs, "header", "header of synthesized event in name=value format, can be repeated")
+	flag.Parse()
+
+	if err := run(*bin, *eventPath, *eventType, *method, *path, *body, *queue, *timeout, hdrs); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "local-runner: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(bin, eventPath, eventType, method, path, body, queue string, timeout time.Duration, hdrs headers) error {
+	if bin == "" {
+		return errors.Errorf("-bin is required")
+	}
+	events, err := loadEvents(eventPath, eventType, method, path, body, queue, hdrs)
+	if err != nil {
+		return err
+	}
+
+	emu := emulator.New(emulator.WithTimeout(timeout))
+	if err := emu.Start("127.0.0.1:0"); err != nil {
+		return err
+	}
+	defer func() { _ = emu.Close() }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cmd := exec.CommandContext(ctx, bin)
+	cmd.Env = append(os.Environ(),
+		emulator.RuntimeAPIEnv+"="+emu.Addr(),
+		"AWS_LAMBDA_FUNCTION_NAME=local",
+		fmt.Sprintf("AWS_LAMBDA_FUNCTION_TIMEOUT=%d", int(timeout.Seconds())),
+	)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return errors.Wrapf(err, "failed to start %s", bin)
+	}
+	defer func() { _ = cmd.Process.Kill() }()
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	for _, event := range events {
+		res, err := emu.Invoke(ctx, event)
+		if err != nil {
+			return err
+		}
+		if err := encoder.Encode(res); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func loadEvents(eventPath, eventType, method, path, body, queue string, hdrs headers) ([]any, error) {
+	if eventPath != "" {
+		files := []string{eventPath}
+		if info, err := os.Stat(eventPath); err != nil {
+			return nil, err
+		} else if info.IsDir() {
+			if files, err = filepath.Glob(filepath.Join(eventPath, "*.json")); err != nil {
+				return nil, err
+			}
+		}
+		var res []any
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to read event %s", file)
+			}
+			res = append(res, data)
+		}
+		return res, nil
+	}
+
+	var opts []eventstest.RequestOption
+	for _, h := range hdrs {
+		name, value, _ := strings.Cut(h, "=")
+		opts = append(opts, eventstest.WithHeader(name, value))
+	}
+	if body != "" {
+		opts = append(opts, eventstest.WithBody(body))
+	}
+	switch eventType {
+	case "function-url":
+		return []any{eventstest.LambdaFunctionURLRequest(method, path, opts...)}, nil
+	case "api-gateway":
+		return []any{eventstest.APIGatewayProxyRequest(method, path, opts...)}, nil
+	case "alb":
+		return []any{eventstest.ALBTargetGroupRequest(method, path, opts...)}, nil
+	case "sqs":
+		return []any{eventstest.SQSEvent(queue, body)}, nil
+	default:
+		return nil, errors.Errorf("unknown event type %q", eventType)
+	}
+}
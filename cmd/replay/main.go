@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+)
+
+type headers []string
+
+func (h *headers) String() string {
+	return strings.Join(*h, ",")
+}
+
+func (h *headers) Set(value string) error {
+	*h = append(*h, value)
+	return nil
+}
+
+type mismatch struct {
+	ID       string `json:"id"`
+	URI      string `json:"uri"`
+	Recorded int    `json:"recordedStatus"`
+	Replayed int    `json:"replayedStatus"`
+	BodyDiff bool   `json:"bodyDiff"`
+}
+
+// replay re-sends requests captured with service.WithRequestRecorder to the target and reports
+// status/body mismatches and latency compared to the recorded version, e.g.:
+//
+//	go run ./cmd/replay -dir ./recordings -target http://localhost:8080 -header "Authorization=Bearer $API_KEY"
+func main() {
+	var (
+		dir     = flag.String("dir", "", "directory with recorded exchanges")
+		target  = flag.String("target", "http://localhost:8080", "base url of the service to replay requests against")
+		timeout = flag.Duration("timeout", 30*time.Second, "request timeout")
+		hdrs    headers
+	)
+	flag.Var(&hdrs, "header", "header in name=value format to replace redacted values, can be repeated")
+	flag.Parse()
+
+	if err := run(*dir, *target, *timeout, hdrs); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "replay: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir, target string, timeout time.Duration, hdrs headers) error {
+	if dir == "" {
+		return errors.Errorf("-dir is required")
+	}
+	exchanges, err := service.LoadRecordedExchanges(dir)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: timeout}
+
+	var recorded, replayed []time.Duration
+	var mismatches []mismatch
+	var skipped []string
+	for _, exchange := range exchanges {
+		if exchange.Request.Truncated {
+			// request body is incomplete, replaying it would compare responses to a different request
+			skipped = append(skipped, exchange.ID)
+			continue
+		}
+		req, err := http.NewRequest(exchange.Request.Method, strings.TrimSuffix(target, "/")+exchange.Request.URI, bytes.NewBufferString(exchange.Request.Body))
+		if err != nil {
+			return errors.Wrapf(err, "failed to build request %s", exchange.ID)
+		}
+		for name, values := range exchange.Request.Headers {
+			for _, value := range values {
+				if value != service.RedactedValue {
+					req.Header.Add(name, value)
+				}
+			}
+		}
+		for _, h := range hdrs {
+			name, value, _ := strings.Cut(h, "=")
+			req.Header.Set(name, value)
+		}
+
+		startedAt := time.Now()
+		res, err := client.Do(req)
+		if err != nil {
+			return errors.Wrapf(err, "failed to replay %s", exchange.ID)
+		}
+		body, err := io.ReadAll(res.Body)
+		_ = res.Body.Close()
+		if err != nil {
+			return errors.Wrapf(err, "failed to read response of %s", exchange.ID)
+		}
+		recorded = append(recorded, exchange.Duration)
+		replayed = append(replayed, time.Since(startedAt))
+
+		bodyDiff := !exchange.Response.Truncated && !sameBody(exchange.Response.Body, string(body))
+		if res.StatusCode != exchange.Response.StatusCode || bodyDiff {
+			mismatches = append(mismatches, mismatch{
+				ID:       exchange.ID,
+				URI:      exchange.Request.URI,
+				Recorded: exchange.Response.StatusCode,
+				Replayed: res.StatusCode,
+				BodyDiff: bodyDiff,
+			})
+		}
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(map[string]any{
+		"total":      len(exchanges),
+		"skipped":    skipped,
+		"mismatches": mismatches,
+		"recorded":   percentiles(recorded),
+		"replayed":   percentiles(replayed),
+	})
+}
+
+// sameBody compares JSON bodies semantically and other bodies byte-by-byte
+func sameBody(a, b string) bool {
+	var aJSON, bJSON any
+	if json.Unmarshal([]byte(a), &aJSON) == nil && json.Unmarshal([]byte(b), &bJSON) == nil {
+		return reflect.DeepEqual(aJSON, bJSON)
+	}
+	return a == b
+}
+
+func percentiles(durations []time.Duration) map[string]string {
+	if len(durations) == 0 {
+		return nil
+	}
+	sorted := append([]time.Duration{}, durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	at := func(p float64) string {
+		return sorted[int(p*float64(len(sorted)-1))].String()
+	}
+	return map[string]string{"p50": at(0.5), "p90": at(0.9), "p99": at(0.99), "max": at(1)}
+}
@@ -0,0 +1,141 @@
+package analytics
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+)
+
+const defaultBatchSize = 100
+
+// Event is a product analytics event, it is kept separate from logs so that it can feed usage dashboards
+type Event struct {
+	Name       string         `json:"event" yaml:"event"`
+	UserID     string         `json:"userId,omitempty" yaml:"userId,omitempty"`
+	Properties map[string]any `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Timestamp  time.Time      `json:"timestamp" yaml:"timestamp"`
+	RequestUID string         `json:"requestUID,omitempty" yaml:"requestUID,omitempty"`
+}
+
+// Sink delivers batch of events, implementations split batch according to limits of the destination
+type Sink interface {
+	WriteBatch(ctx context.Context, events []Event) error
+}
+
+// Emitter buffers events and writes them to all sinks once batch is full or Flush is called,
+// lambda freezes background work between invocations so Flush must be called before the handler returns
+type Emitter interface {
+	Track(ctx context.Context, event Event) error
+	Flush(ctx context.Context) error
+}
+
+type (
+	Option func(*emitter)
+)
+
+type emitter struct {
+	sinks     []Sink
+	logger    logger.Logger
+	batchSize int
+
+	mu     sync.Mutex
+	buffer []Event
+}
+
+func WithSink(sink Sink) Option {
+	return func(e *emitter) {
+		e.sinks = append(e.sinks, sink)
+	}
+}
+
+func WithLogger(logger logger.Logger) Option {
+	return func(e *emitter) {
+		e.logger = logger
+	}
+}
+
+func WithBatchSize(size int) Option {
+	return func(e *emitter) {
+		e.batchSize = size
+	}
+}
+
+// New creates emitter, in local debug mode events are printed to console instead of configured sinks
+func New(opts ...Option) (Emitter, error) {
+	e := &emitter{
+		logger:    logger.NewLogger(),
+		batchSize: defaultBatchSize,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if os.Getenv("LOCAL_DEBUG") == "true" {
+		e.sinks = []Sink{ConsoleSink(os.Stdout)}
+	}
+	if len(e.sinks) == 0 {
+		return nil, errors.Errorf("at least one analytics sink must be configured")
+	}
+	if e.batchSize < 1 {
+		e.batchSize = 1
+	}
+	return e, nil
+}
+
+func (e *emitter) Track(ctx context.Context, event Event) error {
+	if event.Name == "" {
+		return errors.Errorf("event name must be set")
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now().UTC()
+	}
+	if requestUID, ok := e.logger.GetValue(ctx, service.RequestUIDKey).(string); ok && event.RequestUID == "" {
+		event.RequestUID = requestUID
+	}
+	e.mu.Lock()
+	e.buffer = append(e.buffer, event)
+	full := len(e.buffer) >= e.batchSize
+	e.mu.Unlock()
+	if full {
+		return e.Flush(ctx)
+	}
+	return nil
+}
+
+func (e *emitter) Flush(ctx context.Context) error {
+	e.mu.Lock()
+	batch := e.buffer
+	e.buffer = nil
+	e.mu.Unlock()
+	if len(batch) == 0 {
+		return nil
+	}
+	var errs []error
+	for _, sink := range e.sinks {
+		if err := sink.WriteBatch(ctx, batch); err != nil {
+			e.logger.Errorf(ctx, "failed to write %d analytics events: %v", len(batch), err)
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Wrapf(errs[0], "failed to write analytics events to %d of %d sinks", len(errs), len(e.sinks))
+	}
+	return nil
+}
+
+// Middleware flushes events tracked during the request once the request is served
+func Middleware(e Emitter) service.HandlerMiddleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r)
+			// errors are already logged by the emitter
+			_ = e.Flush(context.WithoutCancel(r.Context()))
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package analytics
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSink struct {
+	mu      sync.Mutex
+	batches [][]Event
+}
+
+func (f *fakeSink) WriteBatch(_ context.Context, events []Event) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.batches = append(f.batches, events)
+	return nil
+}
+
+func TestEmitter(t *testing.T) {
+	t.Setenv("LOCAL_DEBUG", "")
+	tests := []struct {
+		name        string
+		batchSize   int
+		events      int
+		wantBatches []int
+	}{
+		{name: "flush on full batch and explicit flush", batchSize: 2, events: 5, wantBatches: []int{2, 2, 1}},
+		{name: "single flush", batchSize: 10, events: 3, wantBatches: []int{3}},
+		{name: "nothing to flush", batchSize: 10, events: 0, wantBatches: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &fakeSink{}
+			e, err := New(WithSink(sink), WithBatchSize(tt.batchSize))
+			assert.NoError(t, err)
+			ctx := context.Background()
+			for i := 0; i < tt.events; i++ {
+				assert.NoError(t, e.Track(ctx, Event{Name: "signed_up", UserID: "u1"}))
+			}
+			assert.NoError(t, e.Flush(ctx))
+
+			var sizes []int
+			for _, batch := range sink.batches {
+				sizes = append(sizes, len(batch))
+				for _, event := range batch {
+					assert.False(t, event.Timestamp.IsZero())
+				}
+			}
+			assert.Equal(t, tt.wantBatches, sizes)
+		})
+	}
+}
+
+func TestTrackRequiresName(t *testing.T) {
+	e, err := New(WithSink(&fakeSink{}))
+	assert.NoError(t, err)
+	assert.Error(t, e.Track(context.Background(), Event{UserID: "u1"}))
+}
@@ -0,0 +1,172 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/eventbridge"
+	"github.com/aws/aws-sdk-go/service/eventbridge/eventbridgeiface"
+	"github.com/aws/aws-sdk-go/service/firehose"
+	"github.com/aws/aws-sdk-go/service/firehose/firehoseiface"
+	"github.com/aws/aws-sdk-go/service/kinesis"
+	"github.com/aws/aws-sdk-go/service/kinesis/kinesisiface"
+)
+
+const (
+	kinesisMaxBatch     = 500
+	firehoseMaxBatch    = 500
+	eventBridgeMaxBatch = 10
+)
+
+type kinesisSink struct {
+	client     kinesisiface.KinesisAPI
+	streamName string
+}
+
+// KinesisSink writes events as JSON records partitioned by user id (event name when user is unknown)
+func KinesisSink(client kinesisiface.KinesisAPI, streamName string) Sink {
+	return &kinesisSink{
+		client:     client,
+		streamName: streamName,
+	}
+}
+
+func (k *kinesisSink) WriteBatch(ctx context.Context, events []Event) error {
+	for _, chunk := range lo.Chunk(events, kinesisMaxBatch) {
+		records := make([]*kinesis.PutRecordsRequestEntry, 0, len(chunk))
+		for _, event := range chunk {
+			data, err := json.Marshal(event)
+			if err != nil {
+				return errors.Wrapf(err, "failed to marshal event %q", event.Name)
+			}
+			records = append(records, &kinesis.PutRecordsRequestEntry{
+				Data:         data,
+				PartitionKey: aws.String(lo.CoalesceOrEmpty(event.UserID, event.Name)),
+			})
+		}
+		out, err := k.client.PutRecordsWithContext(ctx, &kinesis.PutRecordsInput{
+			StreamName: aws.String(k.streamName),
+			Records:    records,
+		})
+		if err != nil {
+			return err
+		}
+		if failed := aws.Int64Value(out.FailedRecordCount); failed > 0 {
+			return errors.Errorf("%d of %d records were not written to kinesis stream %s", failed, len(records), k.streamName)
+		}
+	}
+	return nil
+}
+
+type firehoseSink struct {
+	client     firehoseiface.FirehoseAPI
+	streamName string
+}
+
+// FirehoseSink writes events as new-line delimited JSON into the delivery stream
+func FirehoseSink(client firehoseiface.FirehoseAPI, streamName string) Sink {
+	return &firehoseSink{
+		client:     client,
+		streamName: streamName,
+	}
+}
+
+func (f *firehoseSink) WriteBatch(ctx context.Context, events []Event) error {
+	for _, chunk := range lo.Chunk(events, firehoseMaxBatch) {
+		records := make([]*firehose.Record, 0, len(chunk))
+		for _, event := range chunk {
+			data, err := json.Marshal(event)
+			if err != nil {
+				return errors.Wrapf(err, "failed to marshal event %q", event.Name)
+			}
+			records = append(records, &firehose.Record{Data: append(data, '\n')})
+		}
+		out, err := f.client.PutRecordBatchWithContext(ctx, &firehose.PutRecordBatchInput{
+			DeliveryStreamName: aws.String(f.streamName),
+			Records:            records,
+		})
+		if err != nil {
+			return err
+		}
+		if failed := aws.Int64Value(out.FailedPutCount); failed > 0 {
+			return errors.Errorf("%d of %d records were not written to delivery stream %s", failed, len(records), f.streamName)
+		}
+	}
+	return nil
+}
+
+type eventBridgeSink struct {
+	client  eventbridgeiface.EventBridgeAPI
+	busName string
+	source  string
+}
+
+// EventBridgeSink puts events to the bus using event name as detail type
+func EventBridgeSink(client eventbridgeiface.EventBridgeAPI, busName, source string) Sink {
+	return &eventBridgeSink{
+		client:  client,
+		busName: busName,
+		source:  source,
+	}
+}
+
+func (e *eventBridgeSink) WriteBatch(ctx context.Context, events []Event) error {
+	for _, chunk := range lo.Chunk(events, eventBridgeMaxBatch) {
+		entries := make([]*eventbridge.PutEventsRequestEntry, 0, len(chunk))
+		for _, event := range chunk {
+			data, err := json.Marshal(event)
+			if err != nil {
+				return errors.Wrapf(err, "failed to marshal event %q", event.Name)
+			}
+			entries = append(entries, &eventbridge.PutEventsRequestEntry{
+				EventBusName: aws.String(e.busName),
+				Source:       aws.String(e.source),
+				DetailType:   aws.String(event.Name),
+				Detail:       aws.String(string(data)),
+				Time:         aws.Time(event.Timestamp),
+			})
+		}
+		out, err := e.client.PutEventsWithContext(ctx, &eventbridge.PutEventsInput{
+			Entries: entries,
+		})
+		if err != nil {
+			return err
+		}
+		if failed := aws.Int64Value(out.FailedEntryCount); failed > 0 {
+			return errors.Errorf("%d of %d events were not put to event bus %s", failed, len(entries), e.busName)
+		}
+	}
+	return nil
+}
+
+type consoleSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// ConsoleSink prints events one per line, used in local debug mode
+func ConsoleSink(w io.Writer) Sink {
+	return &consoleSink{w: w}
+}
+
+func (c *consoleSink) WriteBatch(_ context.Context, events []Event) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return errors.Wrapf(err, "failed to marshal event %q", event.Name)
+		}
+		if _, err := fmt.Fprintf(c.w, "analytics event: %s\n", data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
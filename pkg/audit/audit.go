@@ -0,0 +1,174 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+)
+
+type Outcome string
+
+const (
+	Success Outcome = "success"
+	Failure Outcome = "failure"
+	Denied  Outcome = "denied"
+)
+
+// Record is a single audit entry, each record references hash of the previous one
+// so that removal or modification of any record breaks the chain
+type Record struct {
+	ID        string              `json:"id" yaml:"id"`
+	ChainID   string              `json:"chainID" yaml:"chainID"` // unique per Auditor instance (i.e. per Lambda container)
+	Sequence  uint64              `json:"sequence" yaml:"sequence"`
+	Timestamp time.Time           `json:"timestamp" yaml:"timestamp"`
+	Action    string              `json:"action" yaml:"action"`
+	Resource  string              `json:"resource" yaml:"resource"`
+	Outcome   Outcome             `json:"outcome" yaml:"outcome"`
+	Details   map[string]any      `json:"details,omitempty" yaml:"details,omitempty"`
+	Context   logger.ContextValue `json:"context,omitempty" yaml:"context,omitempty"` // logger values, e.g. requestUID
+	PrevHash  string              `json:"prevHash" yaml:"prevHash"`
+	Hash      string              `json:"hash" yaml:"hash"`
+}
+
+type Sink interface {
+	Write(ctx context.Context, record Record) error
+}
+
+type Auditor interface {
+	Audit(ctx context.Context, action, resource string, outcome Outcome, details map[string]any) error
+}
+
+type (
+	Option func(*auditor)
+)
+
+type auditor struct {
+	mu       sync.Mutex
+	sink     Sink
+	logger   logger.Logger
+	chainID  string
+	sequence uint64
+	lastHash string
+	now      func() time.Time
+}
+
+func WithSink(sink Sink) Option {
+	return func(a *auditor) {
+		a.sink = sink
+	}
+}
+
+func WithLogger(logger logger.Logger) Option {
+	return func(a *auditor) {
+		a.logger = logger
+	}
+}
+
+// WithChainID continues an existing chain, e.g. when state is persisted between invocations
+func WithChainID(chainID string, lastSequence uint64, lastHash string) Option {
+	return func(a *auditor) {
+		a.chainID = chainID
+		a.sequence = lastSequence
+		a.lastHash = lastHash
+	}
+}
+
+func New(opts ...Option) (Auditor, error) {
+	a := &auditor{
+		logger:  logger.NewLogger(),
+		chainID: uuid.NewString(),
+		now:     time.Now,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	if a.sink == nil {
+		return nil, errors.Errorf("audit sink is not set")
+	}
+	return a, nil
+}
+
+func (a *auditor) Audit(ctx context.Context, action, resource string, outcome Outcome, details map[string]any) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	record := Record{
+		ID:        uuid.NewString(),
+		ChainID:   a.chainID,
+		Sequence:  a.sequence + 1,
+		Timestamp: a.now().UTC(),
+		Action:    action,
+		Resource:  resource,
+		Outcome:   outcome,
+		Details:   details,
+		PrevHash:  a.lastHash,
+	}
+	if ctxValue := logger.GetValues(ctx); len(ctxValue) > 0 {
+		record.Context = ctxValue
+	}
+	hash, err := Hash(record)
+	if err != nil {
+		return errors.Wrapf(err, "failed to hash audit record")
+	}
+	record.Hash = hash
+
+	if err := a.sink.Write(ctx, record); err != nil {
+		a.logger.Errorf(a.logger.WithValues(ctx, map[string]any{
+			"auditAction":   action,
+			"auditResource": resource,
+			"error":         err.Error(),
+		}), "failed to write audit record")
+		return errors.Wrapf(err, "failed to write audit record")
+	}
+
+	// chain only advances when record is persisted
+	a.sequence = record.Sequence
+	a.lastHash = record.Hash
+	return nil
+}
+
+// Hash calculates hash of the record, Hash field itself is not included
+func Hash(record Record) (string, error) {
+	record.Hash = ""
+	data, err := json.Marshal(record)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Verify checks that records form a continuous chain and none of them was tampered with
+func Verify(records []Record) error {
+	for i, record := range records {
+		hash, err := Hash(record)
+		if err != nil {
+			return errors.Wrapf(err, "failed to hash record %d", record.Sequence)
+		}
+		if hash != record.Hash {
+			return errors.Errorf("record %d hash mismatch", record.Sequence)
+		}
+		if i == 0 {
+			continue
+		}
+		prev := records[i-1]
+		if record.ChainID != prev.ChainID {
+			return errors.Errorf("record %d belongs to a different chain", record.Sequence)
+		}
+		if record.Sequence != prev.Sequence+1 {
+			return errors.Errorf("record %d does not follow record %d", record.Sequence, prev.Sequence)
+		}
+		if record.PrevHash != prev.Hash {
+			return errors.Errorf("record %d does not reference hash of record %d", record.Sequence, prev.Sequence)
+		}
+	}
+	return nil
+}
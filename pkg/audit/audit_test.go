@@ -0,0 +1,43 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+)
+
+func TestAuditChain(t *testing.T) {
+	buf := &bytes.Buffer{}
+	a, err := New(WithSink(WriterSink(buf)))
+	assert.NoError(t, err)
+
+	log := logger.NewLogger()
+	ctx := log.WithValue(context.Background(), "requestUID", "uid")
+	assert.NoError(t, a.Audit(ctx, "user.create", "users/1", Success, map[string]any{"email": "john@example.com"}))
+	assert.NoError(t, a.Audit(ctx, "user.delete", "users/1", Denied, nil))
+	assert.NoError(t, a.Audit(ctx, "user.delete", "users/1", Success, nil))
+
+	var records []Record
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var record Record
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	assert.Len(t, records, 3)
+	assert.Equal(t, "uid", records[0].Context["requestUID"])
+	assert.Empty(t, records[0].PrevHash)
+	assert.NoError(t, Verify(records))
+
+	tampered := append([]Record{}, records...)
+	tampered[1].Outcome = Success
+	assert.EqualError(t, Verify(tampered), "record 2 hash mismatch")
+
+	assert.EqualError(t, Verify([]Record{records[0], records[2]}), "record 3 does not follow record 1")
+}
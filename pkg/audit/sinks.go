@@ -0,0 +1,147 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
+	"github.com/aws/aws-sdk-go/service/firehose"
+	"github.com/aws/aws-sdk-go/service/firehose/firehoseiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+type firehoseSink struct {
+	client     firehoseiface.FirehoseAPI
+	streamName string
+}
+
+// FirehoseSink writes each record as a new-line delimited JSON into the delivery stream
+func FirehoseSink(client firehoseiface.FirehoseAPI, streamName string) Sink {
+	return &firehoseSink{
+		client:     client,
+		streamName: streamName,
+	}
+}
+
+func (f *firehoseSink) Write(ctx context.Context, record Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	_, err = f.client.PutRecordWithContext(ctx, &firehose.PutRecordInput{
+		DeliveryStreamName: aws.String(f.streamName),
+		Record: &firehose.Record{
+			Data: append(data, '\n'),
+		},
+	})
+	return err
+}
+
+type s3Sink struct {
+	client s3iface.S3API
+	bucket string
+	prefix string
+}
+
+// S3Sink writes each record into a separate object under <prefix>/<chainID>/<sequence>.json
+func S3Sink(client s3iface.S3API, bucket, prefix string) Sink {
+	return &s3Sink{
+		client: client,
+		bucket: bucket,
+		prefix: prefix,
+	}
+}
+
+func (s *s3Sink) Write(ctx context.Context, record Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(fmt.Sprintf("%s/%s/%020d.json", s.prefix, record.ChainID, record.Sequence)),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String("application/json"),
+	})
+	return err
+}
+
+type cloudWatchSink struct {
+	mu            sync.Mutex
+	client        cloudwatchlogsiface.CloudWatchLogsAPI
+	group         string
+	streamCreated map[string]bool
+}
+
+// CloudWatchSink writes records into the log group, one log stream per chain
+func CloudWatchSink(client cloudwatchlogsiface.CloudWatchLogsAPI, group string) Sink {
+	return &cloudWatchSink{
+		client:        client,
+		group:         group,
+		streamCreated: make(map[string]bool),
+	}
+}
+
+func (c *cloudWatchSink) Write(ctx context.Context, record Record) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.streamCreated[record.ChainID] {
+		_, err := c.client.CreateLogStreamWithContext(ctx, &cloudwatchlogs.CreateLogStreamInput{
+			LogGroupName:  aws.String(c.group),
+			LogStreamName: aws.String(record.ChainID),
+		})
+		var awsErr awserr.Error
+		if err != nil && !(errors.As(err, &awsErr) && awsErr.Code() == cloudwatchlogs.ErrCodeResourceAlreadyExistsException) {
+			return errors.Wrapf(err, "failed to create log stream")
+		}
+		c.streamCreated[record.ChainID] = true
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	_, err = c.client.PutLogEventsWithContext(ctx, &cloudwatchlogs.PutLogEventsInput{
+		LogGroupName:  aws.String(c.group),
+		LogStreamName: aws.String(record.ChainID),
+		LogEvents: []*cloudwatchlogs.InputLogEvent{{
+			Message:   aws.String(string(data)),
+			Timestamp: aws.Int64(record.Timestamp.UnixMilli()),
+		}},
+	})
+	return err
+}
+
+type writerSink struct {
+	mu     sync.Mutex
+	writer io.Writer
+}
+
+// WriterSink writes records as new-line delimited JSON, useful for local debugging and tests
+func WriterSink(writer io.Writer) Sink {
+	return &writerSink{
+		writer: writer,
+	}
+}
+
+func (w *writerSink) Write(_ context.Context, record Record) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	_, err = w.writer.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,47 @@
+package awsutil
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+)
+
+// requestUIDKey is the logger context key of request UID, it matches service.RequestUIDKey
+const requestUIDKey = "requestUID"
+
+// WithRequestAnnotations returns copy of the session which annotates every AWS call made with request context:
+// request UID is appended to the user agent, so that throttles and errors in CloudTrail can be correlated
+// with requests, and latency of each call is logged with the request context values
+func WithRequestAnnotations(sess *session.Session, log logger.Logger, cfgs ...*aws.Config) *session.Session {
+	res := sess.Copy(cfgs...)
+	res.Handlers.Build.PushBackNamed(request.NamedHandler{
+		Name: "awsutil.RequestUIDUserAgent",
+		Fn: func(r *request.Request) {
+			if requestUID, ok := log.GetValue(r.Context(), requestUIDKey).(string); ok && requestUID != "" {
+				request.AddToUserAgent(r, requestUIDKey+"/"+requestUID)
+			}
+		},
+	})
+	res.Handlers.Complete.PushBackNamed(request.NamedHandler{
+		Name: "awsutil.LatencyLog",
+		Fn: func(r *request.Request) {
+			ctx := log.WithValues(r.Context(), map[string]any{
+				"awsService":   r.ClientInfo.ServiceName,
+				"awsOperation": r.Operation.Name,
+				"awsRequestId": r.RequestID,
+				"latencyMs":    time.Since(r.Time).Milliseconds(),
+				"retries":      r.RetryCount,
+			})
+			if r.Error != nil {
+				log.Warnf(ctx, "aws call %s.%s failed: %v", r.ClientInfo.ServiceName, r.Operation.Name, r.Error)
+				return
+			}
+			log.Infof(ctx, "aws call %s.%s completed", r.ClientInfo.ServiceName, r.Operation.Name)
+		},
+	})
+	return res
+}
@@ -0,0 +1,85 @@
+package awsutil
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+)
+
+type recordingLogger struct {
+	logger.Logger
+	mu      sync.Mutex
+	entries []string
+	values  []logger.ContextValue
+}
+
+func (l *recordingLogger) Infof(ctx context.Context, format string, args ...any) {
+	l.add(ctx, format, args)
+}
+
+func (l *recordingLogger) Warnf(ctx context.Context, format string, args ...any) {
+	l.add(ctx, format, args)
+}
+
+func (l *recordingLogger) add(ctx context.Context, format string, args []any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, fmt.Sprintf(format, args...))
+	l.values = append(l.values, logger.GetValues(ctx))
+}
+
+func TestWithRequestAnnotations(t *testing.T) {
+	var userAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		if strings.Contains(r.URL.Path, "forbidden") {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	sess, err := session.NewSession(&aws.Config{
+		Region:           aws.String("us-east-1"),
+		Endpoint:         aws.String(server.URL),
+		Credentials:      credentials.NewStaticCredentials("id", "secret", ""),
+		S3ForcePathStyle: aws.Bool(true),
+		MaxRetries:       aws.Int(0),
+	})
+	require.NoError(t, err)
+	log := &recordingLogger{Logger: logger.NewLogger()}
+	client := s3.New(WithRequestAnnotations(sess, log))
+
+	ctx := log.WithValue(context.Background(), requestUIDKey, "req-1")
+	_, err = client.HeadBucketWithContext(ctx, &s3.HeadBucketInput{Bucket: aws.String("bucket")})
+	require.NoError(t, err)
+	assert.Contains(t, userAgent, "requestUID/req-1")
+
+	_, err = client.HeadBucketWithContext(context.Background(), &s3.HeadBucketInput{Bucket: aws.String("forbidden")})
+	require.Error(t, err)
+	assert.NotContains(t, userAgent, "requestUID/")
+
+	require.Len(t, log.entries, 2)
+	assert.Equal(t, "aws call s3.HeadBucket completed", log.entries[0])
+	assert.Equal(t, "req-1", log.values[0][requestUIDKey])
+	assert.Equal(t, "HeadBucket", log.values[0]["awsOperation"])
+	assert.Contains(t, log.entries[1], "aws call s3.HeadBucket failed")
+
+	_, err = s3.New(sess).HeadBucketWithContext(ctx, &s3.HeadBucketInput{Bucket: aws.String("bucket")})
+	require.NoError(t, err)
+	assert.NotContains(t, userAgent, "requestUID/", "original session must not be annotated")
+	assert.Len(t, log.entries, 2)
+}
@@ -0,0 +1,32 @@
+package awsutil
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+type originalEventKeyType struct{}
+
+var originalEventKey originalEventKeyType = struct{}{}
+
+// WithOriginalEvent stores raw lambda event in context, so that handlers can access fields
+// which are lost when event is converted into http request
+func WithOriginalEvent(ctx context.Context, event any) context.Context {
+	return context.WithValue(ctx, originalEventKey, event)
+}
+
+// OriginalEvent returns raw lambda event which triggered the request, nil if not available
+func OriginalEvent(ctx context.Context) any {
+	return ctx.Value(originalEventKey)
+}
+
+func OriginalFunctionURLRequest(ctx context.Context) (events.LambdaFunctionURLRequest, bool) {
+	event, ok := OriginalEvent(ctx).(events.LambdaFunctionURLRequest)
+	return event, ok
+}
+
+func OriginalAPIGatewayRequest(ctx context.Context) (events.APIGatewayProxyRequest, bool) {
+	event, ok := OriginalEvent(ctx).(events.APIGatewayProxyRequest)
+	return event, ok
+}
@@ -0,0 +1,308 @@
+package eventstest
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const (
+	AccountID = "123456789012"
+	Region    = "us-east-1"
+	APIID     = "abcdef1234"
+	SourceIP  = "203.0.113.10"
+	UserAgent = "eventstest/1.0"
+	Stage     = "test" // stage of REST API events, HTTP APIs use $default
+)
+
+type request struct {
+	method         string
+	path           string
+	headers        map[string][]string
+	query          map[string][]string
+	pathParameters map[string]string
+	body           string
+	base64         bool
+	sourceIP       string
+	stage          string
+	authorizer     map[string]any
+	time           time.Time
+}
+
+type RequestOption func(*request)
+
+func WithHeader(name, value string) RequestOption {
+	return func(r *request) {
+		r.headers[strings.ToLower(name)] = append(r.headers[strings.ToLower(name)], value)
+	}
+}
+
+func WithQuery(name, value string) RequestOption {
+	return func(r *request) {
+		r.query[name] = append(r.query[name], value)
+	}
+}
+
+func WithPathParameter(name, value string) RequestOption {
+	return func(r *request) {
+		r.pathParameters[name] = value
+	}
+}
+
+func WithBody(body string) RequestOption {
+	return func(r *request) {
+		r.body = body
+	}
+}
+
+// WithJSONBody marshals value into the body and sets content-type header
+func WithJSONBody(value any) RequestOption {
+	return func(r *request) {
+		data, err := json.Marshal(value)
+		if err != nil {
+			panic(fmt.Sprintf("failed to marshal json body: %v", err))
+		}
+		r.body = string(data)
+		r.headers["content-type"] = []string{"application/json"}
+	}
+}
+
+// WithBinaryBody base64 encodes body and marks event as base64 encoded like AWS does for binary payloads
+func WithBinaryBody(body []byte, contentType string) RequestOption {
+	return func(r *request) {
+		r.body = base64.StdEncoding.EncodeToString(body)
+		r.base64 = true
+		r.headers["content-type"] = []string{contentType}
+	}
+}
+
+func WithSourceIP(ip string) RequestOption {
+	return func(r *request) {
+		r.sourceIP = ip
+	}
+}
+
+func WithStage(stage string) RequestOption {
+	return func(r *request) {
+		r.stage = stage
+	}
+}
+
+// WithAuthorizer sets request context authorizer values as passed by a Lambda authorizer
+func WithAuthorizer(values map[string]any) RequestOption {
+	return func(r *request) {
+		r.authorizer = values
+	}
+}
+
+func WithTime(t time.Time) RequestOption {
+	return func(r *request) {
+		r.time = t
+	}
+}
+
+func newRequest(method, path string, opts []RequestOption) *request {
+	r := &request{
+		method:         method,
+		path:           path,
+		headers:        map[string][]string{"user-agent": {UserAgent}},
+		query:          map[string][]string{},
+		pathParameters: map[string]string{},
+		sourceIP:       SourceIP,
+		stage:          Stage,
+		time:           time.Now().UTC(),
+	}
+	if p, q, found := strings.Cut(path, "?"); found {
+		r.path = p
+		values, _ := url.ParseQuery(q)
+		for k, v := range values {
+			r.query[k] = v
+		}
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	r.headers["x-forwarded-for"] = lo.Ternary(len(r.headers["x-forwarded-for"]) > 0, r.headers["x-forwarded-for"], []string{r.sourceIP})
+	return r
+}
+
+func (r *request) singleHeaders() map[string]string {
+	return lo.MapValues(r.headers, func(v []string, _ string) string {
+		return strings.Join(v, ",")
+	})
+}
+
+func (r *request) singleQuery() map[string]string {
+	return lo.MapValues(r.query, func(v []string, _ string) string {
+		return v[len(v)-1]
+	})
+}
+
+func (r *request) rawQuery() string {
+	values := url.Values{}
+	for k, v := range r.query {
+		values[k] = v
+	}
+	return values.Encode()
+}
+
+func (r *request) cookies() []string {
+	if cookie, ok := r.headers["cookie"]; ok {
+		return lo.FlatMap(cookie, func(c string, _ int) []string {
+			return lo.Map(strings.Split(c, ";"), func(s string, _ int) string { return strings.TrimSpace(s) })
+		})
+	}
+	return nil
+}
+
+func nilIfEmpty[T any](m map[string]T) map[string]T {
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
+
+// APIGatewayProxyRequest builds REST API (v1 payload) proxy integration event, path may contain query string
+func APIGatewayProxyRequest(method, path string, opts ...RequestOption) events.APIGatewayProxyRequest {
+	r := newRequest(method, path, opts)
+	return events.APIGatewayProxyRequest{
+		Resource:                        r.path,
+		Path:                            r.path,
+		HTTPMethod:                      r.method,
+		Headers:                         r.singleHeaders(),
+		MultiValueHeaders:               r.headers,
+		QueryStringParameters:           nilIfEmpty(r.singleQuery()),
+		MultiValueQueryStringParameters: nilIfEmpty(r.query),
+		PathParameters:                  nilIfEmpty(r.pathParameters),
+		RequestContext: events.APIGatewayProxyRequestContext{
+			AccountID:        AccountID,
+			ResourceID:       "resource",
+			Stage:            r.stage,
+			DomainName:       fmt.Sprintf("%s.execute-api.%s.amazonaws.com", APIID, Region),
+			DomainPrefix:     APIID,
+			RequestID:        uuid.NewString(),
+			Protocol:         "HTTP/1.1",
+			Identity:         events.APIGatewayRequestIdentity{SourceIP: r.sourceIP, UserAgent: UserAgent},
+			ResourcePath:     r.path,
+			Path:             fmt.Sprintf("/%s%s", r.stage, r.path),
+			Authorizer:       r.authorizer,
+			HTTPMethod:       r.method,
+			RequestTime:      r.time.Format("02/Jan/2006:15:04:05 -0700"),
+			RequestTimeEpoch: r.time.UnixMilli(),
+			APIID:            APIID,
+		},
+		Body:            r.body,
+		IsBase64Encoded: r.base64,
+	}
+}
+
+// LambdaFunctionURLRequest builds Function URL (v2 payload) event for buffered invoke mode
+func LambdaFunctionURLRequest(method, path string, opts ...RequestOption) events.LambdaFunctionURLRequest {
+	r := newRequest(method, path, opts)
+	var authorizer *events.LambdaFunctionURLRequestContextAuthorizerDescription
+	if r.authorizer != nil {
+		userARN, _ := r.authorizer["userArn"].(string)
+		authorizer = &events.LambdaFunctionURLRequestContextAuthorizerDescription{
+			IAM: &events.LambdaFunctionURLRequestContextAuthorizerIAMDescription{
+				AccountID: AccountID,
+				UserARN:   userARN,
+			},
+		}
+	}
+	return events.LambdaFunctionURLRequest{
+		Version:               "2.0",
+		RawPath:               r.path,
+		RawQueryString:        r.rawQuery(),
+		Cookies:               r.cookies(),
+		Headers:               lo.OmitByKeys(r.singleHeaders(), []string{"cookie"}), // function URLs pass cookies separately
+		QueryStringParameters: nilIfEmpty(lo.MapValues(r.query, func(v []string, _ string) string { return strings.Join(v, ",") })),
+		RequestContext: events.LambdaFunctionURLRequestContext{
+			AccountID:    AccountID,
+			RequestID:    uuid.NewString(),
+			Authorizer:   authorizer,
+			APIID:        APIID,
+			DomainName:   fmt.Sprintf("%s.lambda-url.%s.on.aws", APIID, Region),
+			DomainPrefix: APIID,
+			Time:         r.time.Format("02/Jan/2006:15:04:05 -0700"),
+			TimeEpoch:    r.time.UnixMilli(),
+			HTTP: events.LambdaFunctionURLRequestContextHTTPDescription{
+				Method:    r.method,
+				Path:      r.path,
+				Protocol:  "HTTP/1.1",
+				SourceIP:  r.sourceIP,
+				UserAgent: UserAgent,
+			},
+		},
+		Body:            r.body,
+		IsBase64Encoded: r.base64,
+	}
+}
+
+// LambdaFunctionURLStreamingRequest builds Function URL event for RESPONSE_STREAM invoke mode,
+// payload is identical to the buffered one, only response differs
+func LambdaFunctionURLStreamingRequest(method, path string, opts ...RequestOption) events.LambdaFunctionURLRequest {
+	return LambdaFunctionURLRequest(method, path, opts...)
+}
+
+// ALBTargetGroupRequest builds ALB event with multi-value headers enabled
+func ALBTargetGroupRequest(method, path string, opts ...RequestOption) events.ALBTargetGroupRequest {
+	r := newRequest(method, path, opts)
+	return events.ALBTargetGroupRequest{
+		HTTPMethod:                      r.method,
+		Path:                            r.path,
+		MultiValueQueryStringParameters: r.query,
+		MultiValueHeaders:               r.headers,
+		RequestContext: events.ALBTargetGroupRequestContext{
+			ELB: events.ELBContext{
+				TargetGroupArn: fmt.Sprintf("arn:aws:elasticloadbalancing:%s:%s:targetgroup/lambda/%s", Region, AccountID, APIID),
+			},
+		},
+		IsBase64Encoded: r.base64,
+		Body:            r.body,
+	}
+}
+
+// SQSEvent builds event with a message per provided body
+func SQSEvent(queueName string, bodies ...string) events.SQSEvent {
+	now := time.Now().UnixMilli()
+	return events.SQSEvent{
+		Records: lo.Map(bodies, func(body string, i int) events.SQSMessage {
+			return events.SQSMessage{
+				MessageId:     uuid.NewString(),
+				ReceiptHandle: base64.StdEncoding.EncodeToString([]byte(uuid.NewString())),
+				Body:          body,
+				Md5OfBody:     fmt.Sprintf("%x", md5.Sum([]byte(body))),
+				Attributes: map[string]string{
+					"ApproximateReceiveCount":          "1",
+					"SentTimestamp":                    fmt.Sprint(now),
+					"SenderId":                         AccountID,
+					"ApproximateFirstReceiveTimestamp": fmt.Sprint(now),
+				},
+				MessageAttributes: map[string]events.SQSMessageAttribute{},
+				EventSourceARN:    fmt.Sprintf("arn:aws:sqs:%s:%s:%s", Region, AccountID, queueName),
+				EventSource:       "aws:sqs",
+				AWSRegion:         Region,
+			}
+		}),
+	}
+}
+
+// SQSJSONEvent marshals each message as JSON body
+func SQSJSONEvent(queueName string, messages ...any) events.SQSEvent {
+	return SQSEvent(queueName, lo.Map(messages, func(m any, _ int) string {
+		data, err := json.Marshal(m)
+		if err != nil {
+			panic(fmt.Sprintf("failed to marshal sqs message: %v", err))
+		}
+		return string(data)
+	})...)
+}
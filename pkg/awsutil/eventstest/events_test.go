@@ -0,0 +1,79 @@
+package eventstest
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIGatewayProxyRequest(t *testing.T) {
+	req := APIGatewayProxyRequest("GET", "/api/items?tag=a&tag=b&limit=10",
+		WithHeader("X-Request", "1"),
+		WithPathParameter("id", "42"),
+		WithAuthorizer(map[string]any{"principalId": "user"}))
+
+	assert.Equal(t, "/api/items", req.Path)
+	assert.Equal(t, []string{"a", "b"}, req.MultiValueQueryStringParameters["tag"])
+	assert.Equal(t, "b", req.QueryStringParameters["tag"])
+	assert.Equal(t, "1", req.Headers["x-request"])
+	assert.Equal(t, []string{SourceIP}, req.MultiValueHeaders["x-forwarded-for"])
+	assert.Equal(t, "42", req.PathParameters["id"])
+	assert.Equal(t, Stage, req.RequestContext.Stage)
+	assert.Equal(t, "/test/api/items", req.RequestContext.Path)
+	assert.Equal(t, "user", req.RequestContext.Authorizer["principalId"])
+}
+
+func TestLambdaFunctionURLRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []RequestOption
+		wantUserARN *string
+		wantBody    string
+		wantBase64  bool
+	}{
+		{
+			name: "without authorizer",
+		},
+		{
+			name:        "authorizer without user arn",
+			opts:        []RequestOption{WithAuthorizer(map[string]any{"principalId": "user"})},
+			wantUserARN: lo.ToPtr(""),
+		},
+		{
+			name:        "iam authorizer",
+			opts:        []RequestOption{WithAuthorizer(map[string]any{"userArn": "arn:aws:iam::123456789012:user/test"})},
+			wantUserARN: lo.ToPtr("arn:aws:iam::123456789012:user/test"),
+		},
+		{
+			name:       "binary body",
+			opts:       []RequestOption{WithBinaryBody([]byte{0xff, 0x00}, "application/octet-stream")},
+			wantBody:   base64.StdEncoding.EncodeToString([]byte{0xff, 0x00}),
+			wantBase64: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := LambdaFunctionURLRequest("POST", "/api/items", append(tt.opts, WithHeader("Cookie", "a=1; b=2"))...)
+
+			assert.Equal(t, []string{"a=1", "b=2"}, req.Cookies)
+			assert.NotContains(t, req.Headers, "cookie")
+			assert.Equal(t, tt.wantBody, req.Body)
+			assert.Equal(t, tt.wantBase64, req.IsBase64Encoded)
+			if tt.wantUserARN == nil {
+				assert.Nil(t, req.RequestContext.Authorizer)
+			} else {
+				assert.Equal(t, *tt.wantUserARN, req.RequestContext.Authorizer.IAM.UserARN)
+			}
+		})
+	}
+}
+
+func TestSQSJSONEvent(t *testing.T) {
+	event := SQSJSONEvent("orders", map[string]int{"id": 1}, map[string]int{"id": 2})
+
+	assert.Len(t, event.Records, 2)
+	assert.Equal(t, `{"id":1}`, event.Records[0].Body)
+	assert.Equal(t, "arn:aws:sqs:us-east-1:123456789012:orders", event.Records[1].EventSourceARN)
+}
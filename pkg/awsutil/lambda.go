@@ -2,6 +2,8 @@ package awsutil
 
 import (
 	"encoding/base64"
+	"net/http"
+	"net/url"
 	"strings"
 
 	"github.com/samber/lo"
@@ -10,20 +12,76 @@ import (
 )
 
 func ToLambdaFunctionURLResponse(res events.APIGatewayProxyResponse) events.LambdaFunctionURLResponse {
+	headers := res.MultiValueHeaders
+	if len(headers) == 0 {
+		headers = lo.MapValues(res.Headers, func(value string, _ string) []string { return []string{value} })
+	}
+	var cookies []string
 	return events.LambdaFunctionURLResponse{
-		Headers: lo.MapValues(res.MultiValueHeaders, func(value []string, key string) string {
-			if len(value) == 1 {
-				return value[0]
-			} else if len(value) > 1 {
-				return strings.Join(value, "; ")
+		Headers: lo.OmitBy(lo.MapValues(headers, func(value []string, key string) string {
+			// cookies can not be joined, function URLs have a dedicated field for them
+			if http.CanonicalHeaderKey(key) == "Set-Cookie" {
+				cookies = append(cookies, value...)
+				return ""
 			}
-			return ""
+			return strings.Join(value, ",")
+		}), func(key string, _ string) bool {
+			return http.CanonicalHeaderKey(key) == "Set-Cookie"
 		}),
-		Body:       res.Body,
-		StatusCode: res.StatusCode,
+		Cookies:         cookies,
+		Body:            res.Body,
+		IsBase64Encoded: res.IsBase64Encoded,
+		StatusCode:      res.StatusCode,
 	}
 }
 
+// BinaryMediaTypes are base64-encoded in lambda responses by EncodeBinaryBody, types ending with /*
+// match any subtype
+var BinaryMediaTypes = []string{
+	"application/x-protobuf", "application/protobuf", "application/vnd.google.protobuf",
+	"application/octet-stream", "application/pdf", "application/zip", "application/gzip",
+	"application/msgpack", "multipart/byteranges", "image/*", "audio/*", "video/*", "font/*",
+}
+
+// EncodeBinaryBody base64-encodes body of binary media type, proxy adapters only encode bodies which are not
+// valid UTF-8, while binary payloads which happen to be valid UTF-8 must still be flagged as base64 for clients
+// to receive them intact
+func EncodeBinaryBody(res events.APIGatewayProxyResponse) events.APIGatewayProxyResponse {
+	if res.IsBase64Encoded || res.Body == "" {
+		return res
+	}
+	// headers of the events are not necessarily canonical
+	contentType := ""
+	for name, values := range res.MultiValueHeaders {
+		if strings.EqualFold(name, "Content-Type") && len(values) > 0 {
+			contentType = values[0]
+		}
+	}
+	for name, value := range res.Headers {
+		if strings.EqualFold(name, "Content-Type") && contentType == "" {
+			contentType = value
+		}
+	}
+	if !IsBinaryMediaType(contentType) {
+		return res
+	}
+	res.Body = base64.StdEncoding.EncodeToString([]byte(res.Body))
+	res.IsBase64Encoded = true
+	return res
+}
+
+// IsBinaryMediaType reports whether content type is one of BinaryMediaTypes, parameters are ignored
+func IsBinaryMediaType(contentType string) bool {
+	mediaType, _, _ := strings.Cut(strings.ToLower(contentType), ";")
+	mediaType = strings.TrimSpace(mediaType)
+	return lo.SomeBy(BinaryMediaTypes, func(binary string) bool {
+		if prefix, ok := strings.CutSuffix(binary, "/*"); ok {
+			return strings.HasPrefix(mediaType, prefix+"/")
+		}
+		return mediaType == binary
+	})
+}
+
 func ToAPIGatewayRequest(request events.LambdaFunctionURLRequest) events.APIGatewayProxyRequest {
 	body := request.Body
 	if request.IsBase64Encoded {
@@ -32,11 +90,16 @@ func ToAPIGatewayRequest(request events.LambdaFunctionURLRequest) events.APIGate
 			body = string(data)
 		}
 	}
+	headers := lo.Assign(request.Headers)
+	if len(request.Cookies) > 0 {
+		headers["cookie"] = strings.Join(request.Cookies, "; ")
+	}
 	return events.APIGatewayProxyRequest{
-		Path:                  request.RequestContext.HTTP.Path,
-		HTTPMethod:            request.RequestContext.HTTP.Method,
-		Headers:               request.Headers,
-		QueryStringParameters: request.QueryStringParameters,
+		Path:                            request.RequestContext.HTTP.Path,
+		HTTPMethod:                      request.RequestContext.HTTP.Method,
+		Headers:                         headers,
+		QueryStringParameters:           request.QueryStringParameters,
+		MultiValueQueryStringParameters: toMultiValueQuery(request),
 		RequestContext: events.APIGatewayProxyRequestContext{
 			AccountID:    request.RequestContext.AccountID,
 			DomainName:   request.RequestContext.DomainName,
@@ -54,7 +117,40 @@ func ToAPIGatewayRequest(request events.LambdaFunctionURLRequest) events.APIGate
 			RequestTime:      request.RequestContext.Time,
 			RequestTimeEpoch: request.RequestContext.TimeEpoch,
 			APIID:            request.RequestContext.APIID,
+			Authorizer:       ToAuthorizerMap(request.RequestContext.Authorizer),
 		},
 		Body: body,
 	}
 }
+
+// toMultiValueQuery restores repeated query parameters, function URLs join them with comma
+// in QueryStringParameters, but keep them intact in RawQueryString
+func toMultiValueQuery(request events.LambdaFunctionURLRequest) map[string][]string {
+	if request.RawQueryString != "" {
+		if values, err := url.ParseQuery(request.RawQueryString); err == nil {
+			return values
+		}
+	}
+	if len(request.QueryStringParameters) == 0 {
+		return nil
+	}
+	return lo.MapValues(request.QueryStringParameters, func(value string, _ string) []string {
+		return strings.Split(value, ",")
+	})
+}
+
+// ToAuthorizerMap converts function URL IAM authorizer into API Gateway authorizer representation
+func ToAuthorizerMap(authorizer *events.LambdaFunctionURLRequestContextAuthorizerDescription) map[string]any {
+	if authorizer == nil || authorizer.IAM == nil {
+		return nil
+	}
+	return map[string]any{
+		"iam": map[string]any{
+			"accessKey": authorizer.IAM.AccessKey,
+			"accountId": authorizer.IAM.AccountID,
+			"callerId":  authorizer.IAM.CallerID,
+			"userArn":   authorizer.IAM.UserARN,
+			"userId":    authorizer.IAM.UserID,
+		},
+	}
+}
@@ -0,0 +1,145 @@
+package awsutil
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/awsutil/eventstest"
+)
+
+func TestToAPIGatewayRequest(t *testing.T) {
+	tests := []struct {
+		name  string
+		event events.LambdaFunctionURLRequest
+		check func(t *testing.T, req events.APIGatewayProxyRequest)
+	}{
+		{
+			name:  "should keep method, path and source ip",
+			event: eventstest.LambdaFunctionURLRequest("POST", "/api/items", eventstest.WithSourceIP("198.51.100.1")),
+			check: func(t *testing.T, req events.APIGatewayProxyRequest) {
+				assert.Equal(t, "POST", req.HTTPMethod)
+				assert.Equal(t, "/api/items", req.Path)
+				assert.Equal(t, "198.51.100.1", req.RequestContext.Identity.SourceIP)
+				assert.Equal(t, eventstest.APIID, req.RequestContext.APIID)
+			},
+		},
+		{
+			name:  "should keep repeated query parameters",
+			event: eventstest.LambdaFunctionURLRequest("GET", "/api/items?tag=a&tag=b&limit=10"),
+			check: func(t *testing.T, req events.APIGatewayProxyRequest) {
+				assert.Equal(t, []string{"a", "b"}, req.MultiValueQueryStringParameters["tag"])
+				assert.Equal(t, []string{"10"}, req.MultiValueQueryStringParameters["limit"])
+			},
+		},
+		{
+			name:  "should decode base64 body",
+			event: eventstest.LambdaFunctionURLRequest("POST", "/api/upload", eventstest.WithBinaryBody([]byte("binary"), "application/octet-stream")),
+			check: func(t *testing.T, req events.APIGatewayProxyRequest) {
+				assert.Equal(t, "binary", req.Body)
+				assert.False(t, req.IsBase64Encoded)
+			},
+		},
+		{
+			name:  "should restore cookie header",
+			event: eventstest.LambdaFunctionURLRequest("GET", "/", eventstest.WithHeader("Cookie", "a=1; b=2")),
+			check: func(t *testing.T, req events.APIGatewayProxyRequest) {
+				assert.Equal(t, "a=1; b=2", req.Headers["cookie"])
+			},
+		},
+		{
+			name:  "should keep IAM authorizer",
+			event: eventstest.LambdaFunctionURLRequest("GET", "/", eventstest.WithAuthorizer(map[string]any{"userArn": "arn:aws:iam::123456789012:user/john"})),
+			check: func(t *testing.T, req events.APIGatewayProxyRequest) {
+				iam, ok := req.RequestContext.Authorizer["iam"].(map[string]any)
+				assert.True(t, ok)
+				assert.Equal(t, "arn:aws:iam::123456789012:user/john", iam["userArn"])
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.check(t, ToAPIGatewayRequest(tt.event))
+		})
+	}
+}
+
+func TestToLambdaFunctionURLResponse(t *testing.T) {
+	res := ToLambdaFunctionURLResponse(events.APIGatewayProxyResponse{
+		StatusCode: 201,
+		MultiValueHeaders: map[string][]string{
+			"Content-Type": {"application/json"},
+			"Vary":         {"Origin", "Accept-Encoding"},
+			"Set-Cookie":   {"a=1; Path=/", "b=2; HttpOnly"},
+		},
+		Body: `{"ok":true}`,
+	})
+	assert.Equal(t, 201, res.StatusCode)
+	assert.Equal(t, `{"ok":true}`, res.Body)
+	assert.Equal(t, map[string]string{
+		"Content-Type": "application/json",
+		"Vary":         "Origin,Accept-Encoding",
+	}, res.Headers)
+	assert.Equal(t, []string{"a=1; Path=/", "b=2; HttpOnly"}, res.Cookies)
+}
+
+func TestEncodeBinaryBody(t *testing.T) {
+	tests := []struct {
+		name       string
+		res        events.APIGatewayProxyResponse
+		wantBase64 bool
+	}{
+		{
+			name:       "protobuf",
+			res:        events.APIGatewayProxyResponse{MultiValueHeaders: map[string][]string{"Content-Type": {"application/x-protobuf"}}, Body: "\n\x02ok"},
+			wantBase64: true,
+		},
+		{
+			name:       "image from single value headers",
+			res:        events.APIGatewayProxyResponse{Headers: map[string]string{"content-type": "image/png"}, Body: "png"},
+			wantBase64: true,
+		},
+		{
+			name: "json",
+			res:  events.APIGatewayProxyResponse{MultiValueHeaders: map[string][]string{"Content-Type": {"application/json; charset=utf-8"}}, Body: `{}`},
+		},
+		{
+			name: "already encoded",
+			res: events.APIGatewayProxyResponse{
+				MultiValueHeaders: map[string][]string{"Content-Type": {"application/octet-stream"}},
+				Body:              base64.StdEncoding.EncodeToString([]byte{0xff}),
+				IsBase64Encoded:   true,
+			},
+			wantBase64: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := EncodeBinaryBody(tt.res)
+			assert.Equal(t, tt.wantBase64, res.IsBase64Encoded)
+			if tt.wantBase64 && !tt.res.IsBase64Encoded {
+				assert.Equal(t, base64.StdEncoding.EncodeToString([]byte(tt.res.Body)), res.Body)
+			} else {
+				assert.Equal(t, tt.res.Body, res.Body)
+			}
+			assert.Equal(t, res.IsBase64Encoded, ToLambdaFunctionURLResponse(res).IsBase64Encoded)
+		})
+	}
+}
+
+func TestOriginalEvent(t *testing.T) {
+	event := eventstest.LambdaFunctionURLRequest("GET", "/", eventstest.WithBody(base64.StdEncoding.EncodeToString([]byte("x"))))
+	ctx := WithOriginalEvent(context.Background(), event)
+
+	original, ok := OriginalFunctionURLRequest(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, event, original)
+
+	_, ok = OriginalAPIGatewayRequest(ctx)
+	assert.False(t, ok)
+	assert.Nil(t, OriginalEvent(context.Background()))
+}
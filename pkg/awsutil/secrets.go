@@ -1,22 +1,176 @@
 package awsutil
 
 import (
+	"context"
 	"os"
 	"strings"
+	"sync"
 
+	"github.com/pkg/errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
 	"github.com/aws/aws-secretsmanager-caching-go/secretcache"
 )
 
-var secretCache, _ = secretcache.New()
+const (
+	secretsManagerPrefix = "arn:aws:secretsmanager"
+	ssmARNPrefix         = "arn:aws:ssm"
+	ssmPrefix            = "ssm:"
+	filePrefix           = "file://"
+)
 
-func GetEnvOrSecret(envName string) (string, error) {
-	envValue := os.Getenv(envName)
-	var err error
-	if strings.HasPrefix(envValue, "arn:aws:secretsmanager") {
-		envValue, err = secretCache.GetSecretString(envValue)
+// SecretsProvider resolves secrets by name, ok is false when the provider does not know the secret
+type SecretsProvider interface {
+	Secret(ctx context.Context, name string) (value string, ok bool, err error)
+}
+
+// SecretsProviderFunc adapts function to SecretsProvider, e.g. to inject fakes in tests
+type SecretsProviderFunc func(ctx context.Context, name string) (string, bool, error)
+
+func (f SecretsProviderFunc) Secret(ctx context.Context, name string) (string, bool, error) {
+	return f(ctx, name)
+}
+
+type secretsChain []SecretsProvider
+
+// SecretsChain returns the secret of the first provider which knows it, the value is then passed to the
+// following providers once, so that e.g. env variable holding Secrets Manager ARN resolves to the secret
+func SecretsChain(providers ...SecretsProvider) SecretsProvider {
+	return secretsChain(providers)
+}
+
+func (c secretsChain) Secret(ctx context.Context, name string) (string, bool, error) {
+	for i, provider := range c {
+		value, ok, err := provider.Secret(ctx, name)
 		if err != nil {
-			return envValue, err
+			return "", false, errors.Wrapf(err, "failed to resolve secret %s", name)
 		}
+		if !ok {
+			continue
+		}
+		for _, next := range c[i+1:] {
+			resolved, ok, err := next.Secret(ctx, value)
+			if err != nil {
+				return "", false, errors.Wrapf(err, "failed to resolve secret %s", name)
+			}
+			if ok {
+				return resolved, true, nil
+			}
+		}
+		return value, true, nil
+	}
+	return "", false, nil
+}
+
+// EnvSecrets looks secrets up in environment variables, os.Getenv is used when getenv is nil
+func EnvSecrets(getenv func(string) string) SecretsProvider {
+	if getenv == nil {
+		getenv = os.Getenv
 	}
-	return envValue, err
+	return SecretsProviderFunc(func(_ context.Context, name string) (string, bool, error) {
+		value := getenv(name)
+		return value, value != "", nil
+	})
+}
+
+// SecretsManagerSecrets resolves Secrets Manager ARNs, the cache is created on first use when nil
+func SecretsManagerSecrets(cache *secretcache.Cache) SecretsProvider {
+	var once sync.Once
+	var cacheErr error
+	return SecretsProviderFunc(func(ctx context.Context, name string) (string, bool, error) {
+		if !strings.HasPrefix(name, secretsManagerPrefix) {
+			return "", false, nil
+		}
+		once.Do(func() {
+			if cache == nil {
+				cache, cacheErr = secretcache.New()
+			}
+		})
+		if cacheErr != nil {
+			return "", false, errors.Wrapf(cacheErr, "failed to init secrets cache")
+		}
+		value, err := cache.GetSecretStringWithContext(ctx, name)
+		if err != nil {
+			return "", false, err
+		}
+		return value, true, nil
+	})
+}
+
+// SSMSecrets resolves SSM parameter ARNs and ssm:<parameter name> references, secure strings are decrypted;
+// the client is created on first use when nil
+func SSMSecrets(client ssmiface.SSMAPI) SecretsProvider {
+	var once sync.Once
+	var clientErr error
+	return SecretsProviderFunc(func(ctx context.Context, name string) (string, bool, error) {
+		parameter, ok := strings.CutPrefix(name, ssmPrefix)
+		if !ok && !strings.HasPrefix(name, ssmARNPrefix) {
+			return "", false, nil
+		}
+		once.Do(func() {
+			if client == nil {
+				var sess *session.Session
+				sess, clientErr = session.NewSession()
+				if clientErr == nil {
+					client = ssm.New(sess)
+				}
+			}
+		})
+		if clientErr != nil {
+			return "", false, errors.Wrapf(clientErr, "failed to init ssm client")
+		}
+		out, err := client.GetParameterWithContext(ctx, &ssm.GetParameterInput{
+			Name:           aws.String(parameter),
+			WithDecryption: aws.Bool(true),
+		})
+		if err != nil {
+			return "", false, err
+		}
+		return aws.StringValue(out.Parameter.Value), true, nil
+	})
+}
+
+// FileSecrets resolves file://<path> references, surrounding whitespace of the file content is trimmed
+func FileSecrets() SecretsProvider {
+	return SecretsProviderFunc(func(_ context.Context, name string) (string, bool, error) {
+		path, ok := strings.CutPrefix(name, filePrefix)
+		if !ok {
+			return "", false, nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", false, err
+		}
+		return strings.TrimSpace(string(data)), true, nil
+	})
+}
+
+// secret caches and clients are shared by all default providers
+var (
+	sharedSecretsManagerSecrets = SecretsManagerSecrets(nil)
+	sharedSSMSecrets            = SSMSecrets(nil)
+)
+
+// DefaultSecretsProvider looks secrets up in env and resolves values referencing Secrets Manager, SSM or files;
+// AWS clients are only created when a reference needs them, so plain values work offline
+func DefaultSecretsProvider(getenv func(string) string) SecretsProvider {
+	return SecretsChain(EnvSecrets(getenv), sharedSecretsManagerSecrets, sharedSSMSecrets, FileSecrets())
+}
+
+func GetEnvOrSecret(envName string) (string, error) {
+	value, _, err := DefaultSecretsProvider(os.Getenv).Secret(context.Background(), envName)
+	return value, err
+}
+
+// ResolveSecret returns value of the secret when value is a reference (Secrets Manager or SSM ARN,
+// ssm:<name> or file://<path>) and value itself otherwise
+func ResolveSecret(value string) (string, error) {
+	literal := SecretsProviderFunc(func(_ context.Context, name string) (string, bool, error) {
+		return name, true, nil
+	})
+	resolved, _, err := SecretsChain(literal, sharedSecretsManagerSecrets, sharedSSMSecrets, FileSecrets()).Secret(context.Background(), value)
+	return resolved, err
 }
@@ -0,0 +1,83 @@
+package awsutil
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+)
+
+type fakeSSM struct {
+	ssmiface.SSMAPI
+	parameters map[string]string
+}
+
+func (f *fakeSSM) GetParameterWithContext(_ aws.Context, in *ssm.GetParameterInput, _ ...request.Option) (*ssm.GetParameterOutput, error) {
+	value, ok := f.parameters[aws.StringValue(in.Name)]
+	if !ok || !aws.BoolValue(in.WithDecryption) {
+		return nil, errors.Errorf("parameter %s not found", aws.StringValue(in.Name))
+	}
+	return &ssm.GetParameterOutput{Parameter: &ssm.Parameter{Value: aws.String(value)}}, nil
+}
+
+func TestSecretsChain(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, os.WriteFile(file, []byte("from-file\n"), 0o600))
+	env := map[string]string{
+		"PLAIN":   "plain",
+		"SSM":     "ssm:/app/key",
+		"FILE":    "file://" + file,
+		"MISSING": "ssm:/app/missing",
+	}
+	provider := SecretsChain(
+		EnvSecrets(func(name string) string { return env[name] }),
+		SSMSecrets(&fakeSSM{parameters: map[string]string{"/app/key": "from-ssm"}}),
+		FileSecrets(),
+	)
+	tests := []struct {
+		name    string
+		want    string
+		wantOK  bool
+		wantErr string
+	}{
+		{name: "PLAIN", want: "plain", wantOK: true},
+		{name: "SSM", want: "from-ssm", wantOK: true},
+		{name: "FILE", want: "from-file", wantOK: true},
+		{name: "UNKNOWN"},
+		{name: "MISSING", wantErr: "failed to resolve secret MISSING: parameter /app/missing not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, ok, err := provider.Secret(context.Background(), tt.name)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, value)
+		})
+	}
+}
+
+func TestResolveSecret(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, os.WriteFile(file, []byte("ssm:/not/resolved/again"), 0o600))
+
+	value, err := ResolveSecret("plain")
+	require.NoError(t, err)
+	assert.Equal(t, "plain", value)
+
+	value, err = ResolveSecret("file://" + file)
+	require.NoError(t, err)
+	assert.Equal(t, "ssm:/not/resolved/again", value, "resolved value must not be resolved again")
+}
@@ -0,0 +1,79 @@
+package awsutil
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+)
+
+type cloudWatchSecuritySink struct {
+	client cloudwatchlogsiface.CloudWatchLogsAPI
+	group  string
+	stream string
+
+	once      sync.Once
+	clientErr error
+}
+
+// CloudWatchSecuritySink writes security events as JSON to the stream of the existing log group, the stream is
+// created on the first event; the client is created on first use when nil. When the stream can not be created
+// all events fail and the logger prints them instead
+func CloudWatchSecuritySink(client cloudwatchlogsiface.CloudWatchLogsAPI, group, stream string) logger.SecuritySink {
+	return &cloudWatchSecuritySink{
+		client: client,
+		group:  group,
+		stream: stream,
+	}
+}
+
+func (c *cloudWatchSecuritySink) WriteSecurityEvent(ctx context.Context, msg logger.Message) error {
+	c.once.Do(func() {
+		c.clientErr = c.init(ctx)
+	})
+	if c.clientErr != nil {
+		return c.clientErr
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal security event")
+	}
+	_, err = c.client.PutLogEventsWithContext(ctx, &cloudwatchlogs.PutLogEventsInput{
+		LogGroupName:  aws.String(c.group),
+		LogStreamName: aws.String(c.stream),
+		LogEvents: []*cloudwatchlogs.InputLogEvent{{
+			Message:   aws.String(string(data)),
+			Timestamp: aws.Int64(time.Now().UnixMilli()),
+		}},
+	})
+	return errors.Wrapf(err, "failed to put security event to %s/%s", c.group, c.stream)
+}
+
+func (c *cloudWatchSecuritySink) init(ctx context.Context) error {
+	if c.client == nil {
+		sess, err := session.NewSession()
+		if err != nil {
+			return errors.Wrapf(err, "failed to init cloudwatch logs client")
+		}
+		c.client = cloudwatchlogs.New(sess)
+	}
+	_, err := c.client.CreateLogStreamWithContext(ctx, &cloudwatchlogs.CreateLogStreamInput{
+		LogGroupName:  aws.String(c.group),
+		LogStreamName: aws.String(c.stream),
+	})
+	var awsErr awserr.Error
+	if err != nil && !(errors.As(err, &awsErr) && awsErr.Code() == cloudwatchlogs.ErrCodeResourceAlreadyExistsException) {
+		return errors.Wrapf(err, "failed to create log stream %s/%s", c.group, c.stream)
+	}
+	return nil
+}
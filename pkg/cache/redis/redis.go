@@ -0,0 +1,317 @@
+package redis
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/util"
+)
+
+const (
+	defaultCommandTimeout   = time.Second
+	defaultSlowThreshold    = 50 * time.Millisecond
+	defaultFailureThreshold = 5
+	defaultCooldown         = 10 * time.Second
+	// time left to the handler to react on a failed command before lambda deadline
+	deadlineMargin = 100 * time.Millisecond
+)
+
+var ErrCircuitOpen = errors.New("redis circuit breaker is open")
+
+// Doer executes raw redis command, e.g. go-redis client adapted with
+//
+//	redis.DoerFunc(func(ctx context.Context, args ...any) (any, error) { return rdb.Do(ctx, args...).Result() })
+type Doer interface {
+	Do(ctx context.Context, args ...any) (any, error)
+}
+
+type DoerFunc func(ctx context.Context, args ...any) (any, error)
+
+func (f DoerFunc) Do(ctx context.Context, args ...any) (any, error) {
+	return f(ctx, args...)
+}
+
+// Config of ElastiCache endpoint, Addrs contain configuration endpoint in cluster mode
+// (Connect is responsible for creating cluster client then)
+type Config struct {
+	Addrs      []string `json:"addrs" yaml:"addrs"`
+	TLS        bool     `json:"tls" yaml:"tls"`
+	ServerName string   `json:"serverName,omitempty" yaml:"serverName,omitempty"` // defaults to the host of the first address
+	Username   string   `json:"username,omitempty" yaml:"username,omitempty"`
+	Password   string   `json:"-" yaml:"-"`
+}
+
+// TLSConfig returns config for in-transit encryption, nil when TLS is disabled
+func (c Config) TLSConfig() *tls.Config {
+	if !c.TLS {
+		return nil
+	}
+	serverName := c.ServerName
+	if serverName == "" && len(c.Addrs) > 0 {
+		serverName = strings.Split(c.Addrs[0], ":")[0]
+	}
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+	}
+}
+
+// key identifies connection, password is hashed so that it is not kept in memory in plain text
+func (c Config) key() string {
+	password := sha256.Sum256([]byte(c.Password))
+	return fmt.Sprintf("%s|%t|%s|%s|%x", strings.Join(c.Addrs, ","), c.TLS, c.ServerName, c.Username, password[:8])
+}
+
+// Connect creates redis client for config, e.g. with go-redis NewUniversalClient
+type Connect func(cfg Config) (Doer, error)
+
+type (
+	Option func(*Client)
+)
+
+type Client struct {
+	doer             Doer
+	name             string
+	logger           logger.Logger
+	commandTimeout   time.Duration
+	slowThreshold    time.Duration
+	failureThreshold int
+	cooldown         time.Duration
+	ignoredErrors    []error
+	clock            util.Clock
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+// WithName names client returned by Shared, clients are shared by name and by options other than logger
+// and ignored errors, so that clients which differ in those must be named differently
+func WithName(name string) Option {
+	return func(c *Client) {
+		c.name = name
+	}
+}
+
+func WithLogger(logger logger.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithCommandTimeout limits duration of a single command, it is shortened further to fit lambda deadline
+func WithCommandTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.commandTimeout = timeout
+	}
+}
+
+// WithSlowThreshold sets duration after which commands are logged as slow
+func WithSlowThreshold(threshold time.Duration) Option {
+	return func(c *Client) {
+		c.slowThreshold = threshold
+	}
+}
+
+// WithClock sets clock of circuit breaker cooldowns and slow command detection
+func WithClock(clock util.Clock) Option {
+	return func(c *Client) {
+		c.clock = clock
+	}
+}
+
+// WithCircuitBreaker makes commands fail fast with ErrCircuitOpen for cooldown after failureThreshold
+// consecutive failures, a single command is let through afterwards to probe the connection
+func WithCircuitBreaker(failureThreshold int, cooldown time.Duration) Option {
+	return func(c *Client) {
+		c.failureThreshold = failureThreshold
+		c.cooldown = cooldown
+	}
+}
+
+// WithIgnoredErrors sets errors that are not failures of the connection, e.g. go-redis redis.Nil
+func WithIgnoredErrors(errs ...error) Option {
+	return func(c *Client) {
+		c.ignoredErrors = append(c.ignoredErrors, errs...)
+	}
+}
+
+func New(doer Doer, opts ...Option) *Client {
+	c := &Client{
+		doer:             doer,
+		logger:           logger.NewLogger(),
+		commandTimeout:   defaultCommandTimeout,
+		slowThreshold:    defaultSlowThreshold,
+		failureThreshold: defaultFailureThreshold,
+		cooldown:         defaultCooldown,
+		clock:            util.SystemClock(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// sharedKey identifies shared client by connection, name and comparable settings
+type sharedKey struct {
+	conn             string
+	name             string
+	commandTimeout   time.Duration
+	slowThreshold    time.Duration
+	failureThreshold int
+	cooldown         time.Duration
+	clock            util.Clock
+}
+
+var (
+	sharedMu    sync.Mutex
+	sharedDoers = map[string]Doer{}
+	shared      = map[sharedKey]*Client{}
+)
+
+// Shared returns client for config created once per lambda instance so that connections
+// are reused across invocations, clients with different options share the connection (see WithName)
+func Shared(cfg Config, connect Connect, opts ...Option) (*Client, error) {
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+	candidate := New(nil, opts...)
+	key := sharedKey{
+		conn:             cfg.key(),
+		name:             candidate.name,
+		commandTimeout:   candidate.commandTimeout,
+		slowThreshold:    candidate.slowThreshold,
+		failureThreshold: candidate.failureThreshold,
+		cooldown:         candidate.cooldown,
+		clock:            candidate.clock,
+	}
+	if c, ok := shared[key]; ok {
+		return c, nil
+	}
+	doer, ok := sharedDoers[cfg.key()]
+	if !ok {
+		var err error
+		if doer, err = connect(cfg); err != nil {
+			return nil, errors.Wrapf(err, "failed to connect to redis %s", strings.Join(cfg.Addrs, ","))
+		}
+		sharedDoers[cfg.key()] = doer
+	}
+	candidate.doer = doer
+	shared[key] = candidate
+	return candidate, nil
+}
+
+// Do executes command with deadline of ctx limited by command timeout
+func (c *Client) Do(ctx context.Context, args ...any) (any, error) {
+	command := "unknown"
+	if len(args) > 0 {
+		command = strings.ToUpper(fmt.Sprint(args[0]))
+	}
+	if !c.allow() {
+		return nil, errors.Wrapf(ErrCircuitOpen, "redis %s", command)
+	}
+
+	cmdCtx, limitedByCaller, cancel := c.withDeadline(ctx)
+	defer cancel()
+	startedAt := c.clock.Now()
+	res, err := c.doer.Do(cmdCtx, args...)
+	duration := c.clock.Since(startedAt)
+
+	// commands cancelled by the caller or cut by its deadline tell nothing about the connection
+	callerDone := ctx.Err() != nil || limitedByCaller && errors.Is(err, context.DeadlineExceeded)
+	if !callerDone {
+		c.record(err != nil && !lo.ContainsBy(c.ignoredErrors, func(ignored error) bool { return errors.Is(err, ignored) }))
+	}
+	if duration >= c.slowThreshold {
+		logCtx := c.logger.WithValue(ctx, "redisCommand", command)
+		logCtx = c.logger.WithValue(logCtx, "redisDurationMs", duration.Milliseconds())
+		c.logger.Warnf(logCtx, "slow redis command %s took %s", command, duration)
+	}
+	if err != nil {
+		return res, errors.Wrapf(err, "redis %s", command)
+	}
+	return res, nil
+}
+
+// Get returns false when key does not exist, ignored errors are treated as a miss
+func (c *Client) Get(ctx context.Context, key string) (string, bool, error) {
+	res, err := c.Do(ctx, "GET", key)
+	if err != nil && lo.ContainsBy(c.ignoredErrors, func(ignored error) bool { return errors.Is(err, ignored) }) {
+		return "", false, nil
+	} else if err != nil || res == nil {
+		return "", false, err
+	}
+	switch v := res.(type) {
+	case string:
+		return v, true, nil
+	case []byte:
+		return string(v), true, nil
+	default:
+		return fmt.Sprint(v), true, nil
+	}
+}
+
+// Set stores value, key never expires when ttl is zero
+func (c *Client) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	args := []any{"SET", key, value}
+	if ttl > 0 {
+		args = append(args, "PX", ttl.Milliseconds())
+	}
+	_, err := c.Do(ctx, args...)
+	return err
+}
+
+func (c *Client) Del(ctx context.Context, keys ...string) error {
+	_, err := c.Do(ctx, append([]any{"DEL"}, lo.ToAnySlice(keys)...)...)
+	return err
+}
+
+// withDeadline also reports whether command timeout was shortened to fit deadline of the caller
+func (c *Client) withDeadline(ctx context.Context) (context.Context, bool, context.CancelFunc) {
+	timeout, limitedByCaller := c.commandTimeout, false
+	if deadline, ok := ctx.Deadline(); ok {
+		if left := time.Until(deadline) - deadlineMargin; left < timeout {
+			timeout, limitedByCaller = left, true
+		}
+	}
+	cmdCtx, cancel := context.WithTimeout(ctx, max(timeout, 0))
+	return cmdCtx, limitedByCaller, cancel
+}
+
+func (c *Client) allow() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.failures < c.failureThreshold {
+		return true
+	}
+	if c.clock.Now().Before(c.openUntil) {
+		return false
+	}
+	// half-open: let one command through and re-open circuit until it completes
+	c.openUntil = c.clock.Now().Add(c.cooldown)
+	return true
+}
+
+func (c *Client) record(failed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !failed {
+		c.failures = 0
+		return
+	}
+	c.failures++
+	if c.failures == c.failureThreshold {
+		c.logger.Warnf(context.Background(), "redis circuit breaker is open for %s after %d failures", c.cooldown, c.failures)
+	}
+	if c.failures >= c.failureThreshold {
+		c.openUntil = c.clock.Now().Add(c.cooldown)
+	}
+}
@@ -0,0 +1,145 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/util/clocktest"
+)
+
+var errNil = errors.New("redis: nil")
+
+func TestCircuitBreaker(t *testing.T) {
+	var calls int
+	var fail bool
+	clock := clocktest.New(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	c := New(DoerFunc(func(ctx context.Context, args ...any) (any, error) {
+		calls++
+		if fail {
+			return nil, errors.New("connection refused")
+		}
+		if args[0] == "GET" {
+			return nil, errNil
+		}
+		return "OK", nil
+	}), WithCircuitBreaker(2, 20*time.Millisecond), WithIgnoredErrors(errNil), WithClock(clock))
+
+	_, found, err := c.Get(context.Background(), "key")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	fail = true
+	for i := 0; i < 2; i++ {
+		_, err := c.Do(context.Background(), "get", "key")
+		assert.EqualError(t, err, "redis GET: connection refused")
+	}
+	_, err = c.Do(context.Background(), "get", "key")
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, 3, calls)
+
+	clock.Advance(19 * time.Millisecond)
+	_, err = c.Do(context.Background(), "get", "key")
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+
+	clock.Advance(time.Millisecond)
+	fail = false
+	require.NoError(t, c.Set(context.Background(), "key", "value", time.Minute))
+	assert.Equal(t, 4, calls)
+}
+
+func TestDeadline(t *testing.T) {
+	tests := []struct {
+		name         string
+		ctxTimeout   time.Duration
+		wantMaxAfter time.Duration
+	}{
+		{name: "command timeout", ctxTimeout: time.Minute, wantMaxAfter: time.Second},
+		{name: "lambda deadline", ctxTimeout: 300 * time.Millisecond, wantMaxAfter: 200 * time.Millisecond},
+		{name: "deadline exceeded", ctxTimeout: 50 * time.Millisecond, wantMaxAfter: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), tt.ctxTimeout)
+			defer cancel()
+			c := New(DoerFunc(func(ctx context.Context, args ...any) (any, error) {
+				deadline, ok := ctx.Deadline()
+				require.True(t, ok)
+				assert.LessOrEqual(t, time.Until(deadline), tt.wantMaxAfter)
+				return "OK", nil
+			}))
+			_, err := c.Do(ctx, "PING")
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCallerErrorsDoNotOpenCircuit(t *testing.T) {
+	c := New(DoerFunc(func(ctx context.Context, args ...any) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}), WithCircuitBreaker(1, time.Minute))
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := c.Do(cancelled, "GET", "key")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// deadline of the caller shortens the command timeout
+	short, cancelShort := context.WithTimeout(context.Background(), deadlineMargin+10*time.Millisecond)
+	defer cancelShort()
+	_, err = c.Do(short, "GET", "key")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	_, err = c.Do(context.Background(), "GET", "key")
+	assert.NotErrorIs(t, err, ErrCircuitOpen, "caller errors must not open circuit")
+	_, err = c.Do(context.Background(), "GET", "key")
+	assert.ErrorIs(t, err, ErrCircuitOpen, "command timeout is a failure of the connection")
+}
+
+func TestShared(t *testing.T) {
+	var connects int
+	connect := func(cfg Config) (Doer, error) {
+		connects++
+		return DoerFunc(func(ctx context.Context, args ...any) (any, error) { return "OK", nil }), nil
+	}
+	cfg := Config{Addrs: []string{"shared-test:6379"}, TLS: true, Password: "one"}
+
+	first, err := Shared(cfg, connect)
+	require.NoError(t, err)
+	again, err := Shared(cfg, connect)
+	require.NoError(t, err)
+	assert.Same(t, first, again)
+	assert.Equal(t, 1, connects)
+
+	withOpts, err := Shared(cfg, connect, WithCommandTimeout(time.Minute))
+	require.NoError(t, err)
+	assert.NotSame(t, first, withOpts, "options must not be ignored")
+	assert.Equal(t, time.Minute, withOpts.commandTimeout)
+	withClock, err := Shared(cfg, connect, WithClock(clocktest.New(time.Now())))
+	require.NoError(t, err)
+	assert.NotSame(t, first, withClock)
+	assert.Equal(t, 1, connects, "connection is shared by clients with different options")
+
+	named, err := Shared(cfg, connect, WithName("sessions"), WithIgnoredErrors(errNil))
+	require.NoError(t, err)
+	assert.NotSame(t, first, named)
+	namedAgain, err := Shared(cfg, connect, WithName("sessions"))
+	require.NoError(t, err)
+	assert.Same(t, named, namedAgain, "clients are shared by name")
+	assert.Equal(t, 1, connects)
+
+	for _, other := range []Config{
+		{Addrs: cfg.Addrs, TLS: true, Password: "two"},
+		{Addrs: cfg.Addrs, TLS: true, Password: "one", ServerName: "other"},
+	} {
+		c, err := Shared(other, connect)
+		require.NoError(t, err)
+		assert.NotSame(t, first, c)
+	}
+	assert.Equal(t, 3, connects)
+}
@@ -0,0 +1,256 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/rds/rdsutils"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+)
+
+const (
+	// a lambda instance serves one request at a time, so bigger pools only exhaust database connections
+	defaultMaxOpenConns = 2
+	// IAM auth tokens are valid for 15 minutes, connections are recycled earlier so that
+	// reconnects always happen with a fresh token
+	defaultConnMaxLifetime = 10 * time.Minute
+)
+
+// Endpoint of the database, Proxy is set when connecting through RDS Proxy which requires TLS
+type Endpoint struct {
+	Host     string `json:"host" yaml:"host"`
+	Port     int    `json:"port" yaml:"port"`
+	User     string `json:"user" yaml:"user"`
+	Database string `json:"database" yaml:"database"`
+	Proxy    bool   `json:"proxy" yaml:"proxy"`
+}
+
+func (e Endpoint) Address() string {
+	return fmt.Sprintf("%s:%d", e.Host, e.Port)
+}
+
+// DSNFunc builds driver specific data source name, it is called for every new connection
+// so that rotated passwords and IAM auth tokens are picked up
+type DSNFunc func(endpoint Endpoint, password string) string
+
+type (
+	Option func(*DB)
+)
+
+// DB lazily opens database/sql pool on first use and keeps it across invocations
+type DB struct {
+	driverName      string
+	dsn             string
+	endpoint        Endpoint
+	dsnFunc         DSNFunc
+	password        string
+	iamAuth         bool
+	region          string
+	credentials     *credentials.Credentials
+	maxOpenConns    int
+	connMaxLifetime time.Duration
+	logger          logger.Logger
+	proxyHost       string
+
+	mu sync.Mutex
+	db *sql.DB
+}
+
+// WithDSN sets static data source name
+func WithDSN(dsn string) Option {
+	return func(d *DB) {
+		d.dsn = dsn
+	}
+}
+
+// WithEndpoint makes DB build data source name with dsnFunc for every new connection
+func WithEndpoint(endpoint Endpoint, dsnFunc DSNFunc) Option {
+	return func(d *DB) {
+		d.endpoint = endpoint
+		d.dsnFunc = dsnFunc
+	}
+}
+
+func WithPassword(password string) Option {
+	return func(d *DB) {
+		d.password = password
+	}
+}
+
+// WithIAMAuth uses RDS IAM auth tokens instead of password, region defaults to AWS_REGION
+// and credentials default to the ones of the lambda execution role
+func WithIAMAuth(region string, creds *credentials.Credentials) Option {
+	return func(d *DB) {
+		d.iamAuth = true
+		d.region = region
+		d.credentials = creds
+	}
+}
+
+// WithRDSProxy connects to the RDS Proxy endpoint host instead of the database host, it takes
+// precedence over the host of WithEndpoint regardless of the order of options
+func WithRDSProxy(host string) Option {
+	return func(d *DB) {
+		d.proxyHost = host
+	}
+}
+
+func WithMaxOpenConns(n int) Option {
+	return func(d *DB) {
+		d.maxOpenConns = n
+	}
+}
+
+func WithConnMaxLifetime(lifetime time.Duration) Option {
+	return func(d *DB) {
+		d.connMaxLifetime = lifetime
+	}
+}
+
+func WithLogger(logger logger.Logger) Option {
+	return func(d *DB) {
+		d.logger = logger
+	}
+}
+
+// New configures DB for the registered database/sql driver, connection is not opened until Get is called
+func New(driverName string, opts ...Option) (*DB, error) {
+	d := &DB{
+		driverName:      driverName,
+		maxOpenConns:    defaultMaxOpenConns,
+		connMaxLifetime: defaultConnMaxLifetime,
+		region:          os.Getenv("AWS_REGION"),
+		logger:          logger.NewLogger(),
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	if d.proxyHost != "" {
+		d.endpoint.Host = d.proxyHost
+		d.endpoint.Proxy = true
+	}
+	if IsProxyHost(d.endpoint.Host) {
+		d.endpoint.Proxy = true
+	}
+	if d.dsn == "" && d.dsnFunc == nil {
+		return nil, errors.Errorf("either dsn or endpoint must be set")
+	}
+	if d.iamAuth && d.dsnFunc == nil {
+		return nil, errors.Errorf("IAM auth requires endpoint")
+	}
+	if d.iamAuth && d.credentials == nil {
+		sess, err := session.NewSession()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to init aws session")
+		}
+		d.credentials = sess.Config.Credentials
+	}
+	return d, nil
+}
+
+// Get returns connection pool opening it on the first call
+func (d *DB) Get(ctx context.Context) (*sql.DB, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.db != nil {
+		return d.db, nil
+	}
+	db, err := d.open()
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(d.maxOpenConns)
+	db.SetMaxIdleConns(d.maxOpenConns)
+	db.SetConnMaxLifetime(d.connMaxLifetime)
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, errors.Wrapf(err, "failed to connect to database")
+	}
+	d.logger.Infof(ctx, "opened %s connection pool (max %d connections)", d.driverName, d.maxOpenConns)
+	d.db = db
+	return db, nil
+}
+
+func (d *DB) open() (*sql.DB, error) {
+	if d.dsnFunc == nil {
+		db, err := sql.Open(d.driverName, d.dsn)
+		return db, errors.Wrapf(err, "failed to open %s database", d.driverName)
+	}
+	// sql.Open does not connect, it is only used to look up registered driver
+	lookup, err := sql.Open(d.driverName, "")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open %s database", d.driverName)
+	}
+	drv := lookup.Driver()
+	_ = lookup.Close()
+	return sql.OpenDB(&connector{db: d, driver: drv}), nil
+}
+
+// Close closes connection pool if it was opened, it can be passed to service.WithShutdownHook
+func (d *DB) Close(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.db == nil {
+		return nil
+	}
+	err := d.db.Close()
+	d.db = nil
+	d.logger.Infof(ctx, "closed %s connection pool", d.driverName)
+	return errors.Wrapf(err, "failed to close %s database", d.driverName)
+}
+
+var buildAuthToken = rdsutils.BuildAuthToken
+
+func (d *DB) connectionDSN() (string, error) {
+	password := d.password
+	if d.iamAuth {
+		token, err := buildAuthToken(d.endpoint.Address(), d.region, d.endpoint.User, d.credentials)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to build IAM auth token for %s", d.endpoint.Address())
+		}
+		password = token
+	}
+	return d.dsnFunc(d.endpoint, password), nil
+}
+
+// connector builds data source name for every new connection so that credentials can rotate
+type connector struct {
+	db     *DB
+	driver driver.Driver
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	dsn, err := c.db.connectionDSN()
+	if err != nil {
+		return nil, err
+	}
+	if dc, ok := c.driver.(driver.DriverContext); ok {
+		conn, err := dc.OpenConnector(dsn)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to connect to %s", c.db.endpoint.Address())
+		}
+		return conn.Connect(ctx)
+	}
+	conn, err := c.driver.Open(dsn)
+	return conn, errors.Wrapf(err, "failed to connect to %s", c.db.endpoint.Address())
+}
+
+func (c *connector) Driver() driver.Driver {
+	return c.driver
+}
+
+// IsProxyHost reports whether host is RDS Proxy endpoint, e.g. my-proxy.proxy-abc.us-east-1.rds.amazonaws.com
+func IsProxyHost(host string) bool {
+	return strings.Contains(host, ".proxy-")
+}
@@ -0,0 +1,115 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+)
+
+type fakeDriver struct {
+	mu   sync.Mutex
+	dsns []string
+}
+
+func (d *fakeDriver) Open(dsn string) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.dsns = append(d.dsns, dsn)
+	return &fakeConn{}, nil
+}
+
+type fakeConn struct{}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, fmt.Errorf("not supported") }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return nil, fmt.Errorf("not supported") }
+
+var testDriver = &fakeDriver{}
+
+func init() {
+	sql.Register("fake", testDriver)
+}
+
+func TestDB(t *testing.T) {
+	original := buildAuthToken
+	buildAuthToken = func(endpoint, region, user string, _ *credentials.Credentials) (string, error) {
+		return fmt.Sprintf("token(%s,%s,%s)", endpoint, region, user), nil
+	}
+	defer func() { buildAuthToken = original }()
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		wantDSN  string
+		wantErr  string
+		wantPool bool
+	}{
+		{
+			name:    "static dsn",
+			opts:    []Option{WithDSN("user:pass@db")},
+			wantDSN: "user:pass@db",
+		},
+		{
+			name: "iam auth through rds proxy",
+			opts: []Option{
+				WithEndpoint(Endpoint{Host: "db.cluster-abc.eu-west-1.rds.amazonaws.com", Port: 5432, User: "app", Database: "main"}, func(e Endpoint, password string) string {
+					return fmt.Sprintf("%s:%s@%s/%s?tls=%t", e.User, password, e.Address(), e.Database, e.Proxy)
+				}),
+				WithRDSProxy("app.proxy-abc.eu-west-1.rds.amazonaws.com"),
+				WithIAMAuth("eu-west-1", credentials.AnonymousCredentials),
+			},
+			wantDSN: "app:token(app.proxy-abc.eu-west-1.rds.amazonaws.com:5432,eu-west-1,app)@app.proxy-abc.eu-west-1.rds.amazonaws.com:5432/main?tls=true",
+		},
+		{
+			name: "rds proxy before endpoint",
+			opts: []Option{
+				WithRDSProxy("app.proxy-abc.eu-west-1.rds.amazonaws.com"),
+				WithEndpoint(Endpoint{Host: "db.cluster-abc.eu-west-1.rds.amazonaws.com", Port: 5432, User: "app", Database: "main"}, func(e Endpoint, password string) string {
+					return fmt.Sprintf("%s:%s@%s/%s?tls=%t", e.User, password, e.Address(), e.Database, e.Proxy)
+				}),
+				WithPassword("secret"),
+			},
+			wantDSN: "app:secret@app.proxy-abc.eu-west-1.rds.amazonaws.com:5432/main?tls=true",
+		},
+		{
+			name:    "iam auth without endpoint",
+			opts:    []Option{WithDSN("dsn"), WithIAMAuth("eu-west-1", credentials.AnonymousCredentials)},
+			wantErr: "IAM auth requires endpoint",
+		},
+		{
+			name:    "nothing to connect to",
+			wantErr: "either dsn or endpoint must be set",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testDriver.dsns = nil
+			d, err := New("fake", tt.opts...)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Empty(t, testDriver.dsns, "connection must be opened lazily")
+
+			db, err := d.Get(context.Background())
+			require.NoError(t, err)
+			again, err := d.Get(context.Background())
+			require.NoError(t, err)
+			assert.Same(t, db, again)
+			assert.Equal(t, []string{tt.wantDSN}, testDriver.dsns)
+			assert.Equal(t, defaultMaxOpenConns, db.Stats().MaxOpenConnections)
+
+			require.NoError(t, d.Close(context.Background()))
+			require.NoError(t, d.Close(context.Background()))
+		})
+	}
+}
@@ -0,0 +1,267 @@
+package emulator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+)
+
+const (
+	apiPrefix = "/2018-06-01/runtime"
+
+	// RuntimeAPIEnv must be set for the emulated function process, lambda.Start connects to it
+	RuntimeAPIEnv = "AWS_LAMBDA_RUNTIME_API"
+
+	streamingContentType = "application/vnd.awslambda.http-integration-response"
+
+	// respondedWindow is how many recently responded invocations are remembered to reject duplicate responses
+	respondedWindow = 1024
+)
+
+// Result of a single invocation, for streaming responses Prelude contains
+// status code and headers, while Payload contains the streamed body
+type Result struct {
+	RequestID string          `json:"requestID"`
+	Payload   json.RawMessage `json:"payload,omitempty"`
+	Prelude   json.RawMessage `json:"prelude,omitempty"`
+	Body      string          `json:"body,omitempty"`
+	Error     *InvokeError    `json:"error,omitempty"`
+	Duration  time.Duration   `json:"duration"`
+}
+
+type InvokeError struct {
+	Message string `json:"errorMessage"`
+	Type    string `json:"errorType"`
+}
+
+func (e *InvokeError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Type, e.Message)
+}
+
+type invocation struct {
+	id        string
+	payload   []byte
+	deadline  time.Time
+	startedAt time.Time
+	result    chan *Result
+}
+
+type (
+	Option func(*Emulator)
+)
+
+// Emulator implements subset of the Lambda Runtime API sufficient for aws-lambda-go
+type Emulator struct {
+	logger      logger.Logger
+	timeout     time.Duration
+	functionArn string
+
+	listener net.Listener
+	server   *http.Server
+	queue    chan *invocation
+
+	mu        sync.Mutex
+	inflight  map[string]*invocation
+	responded map[string]struct{}
+	// recent is a ring of responded IDs, the oldest one is forgotten once the window is full
+	recent     [respondedWindow]string
+	recentNext int
+}
+
+func WithLogger(logger logger.Logger) Option {
+	return func(e *Emulator) {
+		e.logger = logger
+	}
+}
+
+// WithTimeout sets the function timeout reported via the deadline header
+func WithTimeout(timeout time.Duration) Option {
+	return func(e *Emulator) {
+		e.timeout = timeout
+	}
+}
+
+func WithFunctionArn(arn string) Option {
+	return func(e *Emulator) {
+		e.functionArn = arn
+	}
+}
+
+func New(opts ...Option) *Emulator {
+	e := &Emulator{
+		logger:      logger.NewLogger(),
+		timeout:     30 * time.Second,
+		functionArn: "arn:aws:lambda:us-east-1:123456789012:function:local",
+		queue:       make(chan *invocation),
+		inflight:    make(map[string]*invocation),
+		responded:   make(map[string]struct{}),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Start listens on the provided address (e.g. 127.0.0.1:0) and serves the Runtime API in background
+func (e *Emulator) Start(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return errors.Wrapf(err, "failed to listen on %s", addr)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(apiPrefix+"/invocation/next", e.next)
+	mux.HandleFunc(apiPrefix+"/invocation/", e.respond)
+	mux.HandleFunc(apiPrefix+"/init/error", e.initError)
+
+	e.listener = listener
+	e.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := e.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			e.logger.Errorf(context.Background(), "runtime api emulator failed: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Addr returns value for AWS_LAMBDA_RUNTIME_API environment variable
+func (e *Emulator) Addr() string {
+	return e.listener.Addr().String()
+}
+
+func (e *Emulator) Close() error {
+	return e.server.Close()
+}
+
+// Invoke queues event for the function and waits until the function responds
+func (e *Emulator) Invoke(ctx context.Context, event any) (*Result, error) {
+	payload, ok := event.([]byte)
+	if !ok {
+		var err error
+		if payload, err = json.Marshal(event); err != nil {
+			return nil, errors.Wrapf(err, "failed to marshal event")
+		}
+	}
+	startedAt := time.Now()
+	inv := &invocation{
+		id:        uuid.NewString(),
+		payload:   payload,
+		startedAt: startedAt,
+		deadline:  startedAt.Add(e.timeout),
+		result:    make(chan *Result, 1),
+	}
+	e.mu.Lock()
+	e.inflight[inv.id] = inv
+	e.mu.Unlock()
+	defer func() {
+		e.mu.Lock()
+		delete(e.inflight, inv.id)
+		e.mu.Unlock()
+	}()
+
+	select {
+	case e.queue <- inv:
+	case <-ctx.Done():
+		return nil, errors.Wrapf(ctx.Err(), "function did not pick up invocation")
+	}
+	select {
+	case res := <-inv.result:
+		return res, nil
+	case <-ctx.Done():
+		return nil, errors.Wrapf(ctx.Err(), "function did not respond")
+	case <-time.After(time.Until(inv.deadline)):
+		return nil, errors.Errorf("task timed out after %s", e.timeout)
+	}
+}
+
+func (e *Emulator) next(w http.ResponseWriter, r *http.Request) {
+	select {
+	case inv := <-e.queue:
+		w.Header().Set("Lambda-Runtime-Aws-Request-Id", inv.id)
+		w.Header().Set("Lambda-Runtime-Deadline-Ms", fmt.Sprint(inv.deadline.UnixMilli()))
+		w.Header().Set("Lambda-Runtime-Invoked-Function-Arn", e.functionArn)
+		w.Header().Set("Lambda-Runtime-Trace-Id", "Root=1-"+strings.ReplaceAll(inv.id, "-", "")[:24])
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(inv.payload)
+	case <-r.Context().Done():
+	}
+}
+
+// markResponded remembers id within the window of recent invocations, it is called with mu held
+func (e *Emulator) markResponded(id string) {
+	delete(e.responded, e.recent[e.recentNext])
+	e.recent[e.recentNext] = id
+	e.recentNext = (e.recentNext + 1) % respondedWindow
+	e.responded[id] = struct{}{}
+}
+
+func (e *Emulator) respond(w http.ResponseWriter, r *http.Request) {
+	// /2018-06-01/runtime/invocation/{id}/(response|error)
+	id, kind, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, apiPrefix+"/invocation/"), "/")
+	e.mu.Lock()
+	inv, ok := e.inflight[id]
+	_, duplicate := e.responded[id]
+	if ok {
+		// only the first response is accepted, result channel has room for a single result
+		delete(e.inflight, id)
+		e.markResponded(id)
+	}
+	e.mu.Unlock()
+	if duplicate {
+		http.Error(w, fmt.Sprintf("invocation %q has already been responded", id), http.StatusBadRequest)
+		return
+	} else if !ok {
+		http.Error(w, fmt.Sprintf("unknown invocation %q", id), http.StatusNotFound)
+		return
+	}
+
+	body := new(bytes.Buffer)
+	if _, err := body.ReadFrom(r.Body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	res := &Result{
+		RequestID: id,
+		Duration:  time.Since(inv.startedAt),
+	}
+	switch {
+	case kind == "error":
+		res.Error = &InvokeError{}
+		if err := json.Unmarshal(body.Bytes(), res.Error); err != nil {
+			res.Error.Message = body.String()
+		}
+	case r.Trailer.Get("Lambda-Runtime-Function-Error-Type") != "":
+		res.Error = &InvokeError{
+			Type:    r.Trailer.Get("Lambda-Runtime-Function-Error-Type"),
+			Message: r.Trailer.Get("Lambda-Runtime-Function-Error-Body"),
+		}
+	case r.Header.Get("Content-Type") == streamingContentType:
+		prelude, streamed, _ := bytes.Cut(body.Bytes(), make([]byte, 8))
+		res.Prelude = prelude
+		res.Body = string(streamed)
+	default:
+		res.Payload = body.Bytes()
+	}
+	inv.result <- res
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (e *Emulator) initError(w http.ResponseWriter, r *http.Request) {
+	body := new(bytes.Buffer)
+	_, _ = body.ReadFrom(r.Body)
+	e.logger.Errorf(context.Background(), "function failed to initialize: %s", body.String())
+	w.WriteHeader(http.StatusAccepted)
+}
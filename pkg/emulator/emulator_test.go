@@ -0,0 +1,101 @@
+package emulator
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRespond(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		kind      string
+		body      string
+		wantError *InvokeError
+		wantBody  string
+	}{
+		{
+			name:     "response",
+			kind:     "response",
+			body:     `{"ok":true}`,
+			wantBody: `{"ok":true}`,
+		},
+		{
+			name:      "error",
+			kind:      "error",
+			body:      `{"errorMessage":"boom","errorType":"Panic"}`,
+			wantError: &InvokeError{Message: "boom", Type: "Panic"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			e := New(WithTimeout(5 * time.Second))
+			require.NoError(t, e.Start("127.0.0.1:0"))
+			defer e.Close()
+			baseURL := "http://" + e.Addr() + apiPrefix
+
+			type invokeResult struct {
+				res *Result
+				err error
+			}
+			done := make(chan invokeResult, 1)
+			go func() {
+				res, err := e.Invoke(context.Background(), map[string]string{"hello": "world"})
+				done <- invokeResult{res, err}
+			}()
+
+			next, err := http.Get(baseURL + "/invocation/next")
+			require.NoError(t, err)
+			_ = next.Body.Close()
+			id := next.Header.Get("Lambda-Runtime-Aws-Request-Id")
+			require.NotEmpty(t, id)
+
+			post := func() int {
+				res, err := http.Post(baseURL+"/invocation/"+id+"/"+tt.kind, "application/json", bytes.NewBufferString(tt.body))
+				require.NoError(t, err)
+				_ = res.Body.Close()
+				return res.StatusCode
+			}
+			require.Equal(t, http.StatusAccepted, post())
+			require.Equal(t, http.StatusBadRequest, post(), "duplicate response must be rejected")
+
+			select {
+			case got := <-done:
+				require.NoError(t, got.err)
+				require.Equal(t, id, got.res.RequestID)
+				require.Equal(t, tt.wantError, got.res.Error)
+				if tt.wantBody != "" {
+					require.JSONEq(t, tt.wantBody, string(got.res.Payload))
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("invoke did not return")
+			}
+		})
+	}
+}
+
+func TestRespondUnknownInvocation(t *testing.T) {
+	e := New()
+	require.NoError(t, e.Start("127.0.0.1:0"))
+	defer e.Close()
+
+	res, err := http.Post("http://"+e.Addr()+apiPrefix+"/invocation/unknown/response", "application/json", bytes.NewBufferString("{}"))
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestRespondedWindow(t *testing.T) {
+	e := New()
+	for i := 0; i <= respondedWindow; i++ {
+		e.markResponded(fmt.Sprint(i))
+	}
+	require.Len(t, e.responded, respondedWindow)
+	require.NotContains(t, e.responded, "0", "the oldest invocation must be forgotten")
+	require.Contains(t, e.responded, "1")
+	require.Contains(t, e.responded, fmt.Sprint(respondedWindow))
+}
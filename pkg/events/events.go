@@ -0,0 +1,317 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/eventbridge"
+	"github.com/aws/aws-sdk-go/service/eventbridge/eventbridgeiface"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/util/retry"
+)
+
+const (
+	// PutEvents limits
+	maxBatchEntries = 10
+	maxBatchBytes   = 256 * 1024
+
+	defaultMaxRetries = 3
+	traceIDEnv        = "_X_AMZN_TRACE_ID"
+)
+
+// Publisher puts events to EventBridge bus, events are buffered and sent in batches
+// once batch is full or Flush is called (lambda freezes background work between invocations)
+type Publisher interface {
+	Publish(ctx context.Context, detailType string, payload any) error
+	Flush(ctx context.Context) error
+}
+
+type (
+	Option func(*publisher)
+)
+
+type publisher struct {
+	client     eventbridgeiface.EventBridgeAPI
+	busName    string
+	source     string
+	logger     logger.Logger
+	dryRun     bool
+	maxRetries int
+	schemas    map[string]string
+	compiled   map[string]*jsonschema.Schema
+
+	mu        sync.Mutex
+	buffer    []*eventbridge.PutEventsRequestEntry
+	bufferLen int
+}
+
+func WithClient(client eventbridgeiface.EventBridgeAPI) Option {
+	return func(p *publisher) {
+		p.client = client
+	}
+}
+
+func WithBusName(busName string) Option {
+	return func(p *publisher) {
+		p.busName = busName
+	}
+}
+
+func WithSource(source string) Option {
+	return func(p *publisher) {
+		p.source = source
+	}
+}
+
+func WithLogger(logger logger.Logger) Option {
+	return func(p *publisher) {
+		p.logger = logger
+	}
+}
+
+// WithDryRun makes publisher log events instead of sending them
+func WithDryRun() Option {
+	return func(p *publisher) {
+		p.dryRun = true
+	}
+}
+
+func WithMaxRetries(maxRetries int) Option {
+	return func(p *publisher) {
+		p.maxRetries = maxRetries
+	}
+}
+
+// WithSchema registers JSON schema which payloads of the detail type are validated against
+func WithSchema(detailType, schema string) Option {
+	return func(p *publisher) {
+		p.schemas[detailType] = schema
+	}
+}
+
+func New(opts ...Option) (Publisher, error) {
+	if os.Getenv("LOCAL_DEBUG") == "true" {
+		opts = append([]Option{WithDryRun()}, opts...)
+	}
+
+	p := &publisher{
+		busName:    "default",
+		logger:     logger.NewLogger(),
+		maxRetries: defaultMaxRetries,
+		schemas:    make(map[string]string),
+		compiled:   make(map[string]*jsonschema.Schema),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.source == "" {
+		return nil, errors.Errorf("event source must be set")
+	}
+	if p.maxRetries < 1 {
+		p.maxRetries = 1
+	}
+	for detailType, schema := range p.schemas {
+		compiled, err := jsonschema.CompileString(detailType+".json", schema)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to compile schema of %q", detailType)
+		}
+		p.compiled[detailType] = compiled
+	}
+
+	if !p.dryRun && p.client == nil {
+		sess, err := session.NewSession()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to init aws session")
+		}
+		p.client = eventbridge.New(sess)
+	}
+	return p, nil
+}
+
+var (
+	defaultMu        sync.RWMutex
+	defaultPublisher Publisher
+)
+
+// SetDefault sets publisher used by package level Publish and Flush
+func SetDefault(p Publisher) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultPublisher = p
+}
+
+func getDefault() (Publisher, error) {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	if defaultPublisher == nil {
+		return nil, errors.Errorf("default event publisher is not set, call events.SetDefault first")
+	}
+	return defaultPublisher, nil
+}
+
+// Publish publishes event via default publisher
+func Publish(ctx context.Context, detailType string, payload any) error {
+	p, err := getDefault()
+	if err != nil {
+		return err
+	}
+	return p.Publish(ctx, detailType, payload)
+}
+
+// Flush sends events buffered by default publisher
+func Flush(ctx context.Context) error {
+	p, err := getDefault()
+	if err != nil {
+		return err
+	}
+	return p.Flush(ctx)
+}
+
+func (p *publisher) Publish(ctx context.Context, detailType string, payload any) error {
+	detail, err := p.detail(ctx, detailType, payload)
+	if err != nil {
+		return err
+	}
+	entry := &eventbridge.PutEventsRequestEntry{
+		EventBusName: aws.String(p.busName),
+		Source:       aws.String(p.source),
+		DetailType:   aws.String(detailType),
+		Detail:       aws.String(string(detail)),
+	}
+	if traceID := os.Getenv(traceIDEnv); traceID != "" {
+		entry.TraceHeader = aws.String(traceID)
+	}
+	size := entrySize(entry)
+	if size > maxBatchBytes {
+		return errors.Errorf("event %q of %d bytes exceeds PutEvents limit", detailType, size)
+	}
+
+	p.mu.Lock()
+	var batch []*eventbridge.PutEventsRequestEntry
+	if p.bufferLen+size > maxBatchBytes {
+		batch = p.takeBuffer()
+	}
+	p.buffer = append(p.buffer, entry)
+	p.bufferLen += size
+	if len(p.buffer) >= maxBatchEntries {
+		batch = append(batch, p.takeBuffer()...)
+	}
+	p.mu.Unlock()
+
+	return p.send(ctx, batch)
+}
+
+func (p *publisher) Flush(ctx context.Context) error {
+	p.mu.Lock()
+	batch := p.takeBuffer()
+	p.mu.Unlock()
+	return p.send(ctx, batch)
+}
+
+func (p *publisher) takeBuffer() []*eventbridge.PutEventsRequestEntry {
+	batch := p.buffer
+	p.buffer, p.bufferLen = nil, 0
+	return batch
+}
+
+// detail validates payload against schema of the detail type and adds request UID to it
+func (p *publisher) detail(ctx context.Context, detailType string, payload any) ([]byte, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal payload of %q", detailType)
+	}
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal payload of %q", detailType)
+	}
+	if schema, ok := p.compiled[detailType]; ok {
+		if err := schema.Validate(doc); err != nil {
+			return nil, errors.Wrapf(err, "payload of %q does not match schema", detailType)
+		}
+	}
+	// request UID is added after validation so that schemas don't have to declare it
+	object, isObject := doc.(map[string]any)
+	requestUID, hasRequestUID := p.logger.GetValue(ctx, service.RequestUIDKey).(string)
+	if !isObject || !hasRequestUID {
+		return data, nil
+	}
+	if _, exists := object[service.RequestUIDKey]; !exists {
+		object[service.RequestUIDKey] = requestUID
+	}
+	return json.Marshal(object)
+}
+
+func (p *publisher) send(ctx context.Context, batch []*eventbridge.PutEventsRequestEntry) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	if p.dryRun {
+		for _, entry := range batch {
+			p.logger.Infof(p.logger.WithValue(ctx, "event", entry), "dry-run: skip publishing event %s", aws.StringValue(entry.DetailType))
+		}
+		return nil
+	}
+	pending := batch
+	_, err := retry.With(ctx, retry.Config[bool]{
+		MaxRetries: p.maxRetries,
+		Action: func(ctx context.Context) (bool, error) {
+			out, err := p.client.PutEventsWithContext(ctx, &eventbridge.PutEventsInput{Entries: pending})
+			if err != nil {
+				return false, err
+			}
+			if aws.Int64Value(out.FailedEntryCount) == 0 {
+				return true, nil
+			}
+			// only failed entries are retried, results are in the same order as entries
+			var failed []*eventbridge.PutEventsRequestEntry
+			var lastErr string
+			for i, res := range out.Entries {
+				if res.ErrorCode != nil && i < len(pending) {
+					failed = append(failed, pending[i])
+					lastErr = fmt.Sprintf("%s: %s", aws.StringValue(res.ErrorCode), aws.StringValue(res.ErrorMessage))
+				}
+			}
+			pending = failed
+			return false, errors.Errorf("%d events were not published: %s", len(failed), lastErr)
+		},
+		AttemptErrorCallback: func(ctx context.Context, attempt int, err error) {
+			p.logger.Warnf(ctx, "attempt %d to publish events failed: %v", attempt, err)
+		},
+		NoMoreAttemptsCallback: func(ctx context.Context, err error) {
+			p.logger.Errorf(ctx, "failed to publish %d events: %v", len(pending), err)
+		},
+	})
+	return err
+}
+
+// entrySize is calculated the way EventBridge does it for PutEvents size limit
+func entrySize(entry *eventbridge.PutEventsRequestEntry) int {
+	size := 14 // time
+	for _, s := range []*string{entry.Source, entry.DetailType, entry.Detail} {
+		size += len(aws.StringValue(s))
+	}
+	return size + len(lo.FromPtr(entry.TraceHeader))
+}
+
+// Middleware flushes events published during the request once the request is served
+func Middleware(p Publisher) service.HandlerMiddleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r)
+			// errors are already logged by the publisher
+			_ = p.Flush(context.WithoutCancel(r.Context()))
+		})
+	}
+}
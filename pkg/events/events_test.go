@@ -0,0 +1,65 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/eventbridge"
+	"github.com/aws/aws-sdk-go/service/eventbridge/eventbridgeiface"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+)
+
+type fakeEventBridge struct {
+	eventbridgeiface.EventBridgeAPI
+	batches [][]*eventbridge.PutEventsRequestEntry
+}
+
+func (f *fakeEventBridge) PutEventsWithContext(_ aws.Context, in *eventbridge.PutEventsInput, _ ...request.Option) (*eventbridge.PutEventsOutput, error) {
+	f.batches = append(f.batches, in.Entries)
+	return &eventbridge.PutEventsOutput{FailedEntryCount: aws.Int64(0)}, nil
+}
+
+const orderSchema = `{
+	"type": "object",
+	"required": ["orderId"],
+	"properties": {"orderId": {"type": "string"}}
+}`
+
+func TestPublish(t *testing.T) {
+	t.Setenv("LOCAL_DEBUG", "")
+	client := &fakeEventBridge{}
+	log := logger.NewLogger()
+	p, err := New(WithClient(client), WithSource("orders"), WithLogger(log), WithSchema("OrderPlaced", orderSchema))
+	assert.NoError(t, err)
+
+	ctx := log.WithValue(context.Background(), service.RequestUIDKey, "uid-1")
+	assert.Error(t, p.Publish(ctx, "OrderPlaced", map[string]any{"orderId": 42}))
+	assert.Empty(t, client.batches)
+
+	for i := 0; i < 12; i++ {
+		assert.NoError(t, p.Publish(ctx, "OrderPlaced", map[string]any{"orderId": "o-1"}))
+	}
+	assert.Len(t, client.batches, 1)
+	assert.Len(t, client.batches[0], 10)
+
+	assert.NoError(t, p.Flush(ctx))
+	assert.Len(t, client.batches, 2)
+	assert.Len(t, client.batches[1], 2)
+
+	var detail map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(aws.StringValue(client.batches[1][0].Detail)), &detail))
+	assert.Equal(t, map[string]any{"orderId": "o-1", service.RequestUIDKey: "uid-1"}, detail)
+	assert.Equal(t, "orders", aws.StringValue(client.batches[1][0].Source))
+}
+
+func TestPublishWithoutDefault(t *testing.T) {
+	SetDefault(nil)
+	assert.Error(t, Publish(context.Background(), "OrderPlaced", struct{}{}))
+}
@@ -0,0 +1,115 @@
+package httpclient
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+const defaultReserve = 500 * time.Millisecond
+
+// Strategy returns timeout of the call with index call out of calls planned ones given remaining time
+type Strategy func(remaining time.Duration, call, calls int) time.Duration
+
+// EvenSplit divides remaining time evenly across the calls left, so that time saved by fast calls is
+// available to the following ones
+func EvenSplit(remaining time.Duration, call, calls int) time.Duration {
+	return remaining / time.Duration(max(calls-call, 1))
+}
+
+// Weighted divides remaining time across the calls left proportionally to their weights, calls beyond
+// the weights get the whole remaining time
+func Weighted(weights ...float64) Strategy {
+	return func(remaining time.Duration, call, _ int) time.Duration {
+		if call >= len(weights) {
+			return remaining
+		}
+		total := lo.Sum(weights[call:])
+		if total <= 0 {
+			return remaining
+		}
+		return time.Duration(float64(remaining) * weights[call] / total)
+	}
+}
+
+type BudgetOption func(*Budget)
+
+// WithReserve keeps reserve of the deadline for responding after the calls, 500ms by default
+func WithReserve(reserve time.Duration) BudgetOption {
+	return func(b *Budget) {
+		b.reserve = reserve
+	}
+}
+
+// WithStrategy sets strategy of dividing time across the calls, EvenSplit by default
+func WithStrategy(strategy Strategy) BudgetOption {
+	return func(b *Budget) {
+		b.strategy = strategy
+	}
+}
+
+// WithFallbackTotal sets total time of the calls when context has no deadline (e.g. in server mode),
+// calls have no timeout then by default
+func WithFallbackTotal(total time.Duration) BudgetOption {
+	return func(b *Budget) {
+		b.fallbackTotal = total
+	}
+}
+
+// Budget derives timeouts of downstream calls from the remaining invocation time, so that a single slow
+// dependency fails with its own timeout instead of consuming the whole invocation
+type Budget struct {
+	reserve       time.Duration
+	strategy      Strategy
+	fallbackTotal time.Duration
+	calls         int
+	deadline      time.Time
+
+	mu   sync.Mutex
+	call int
+}
+
+// NewBudget plans calls within the deadline of ctx, which is the lambda deadline of invocation context
+func NewBudget(ctx context.Context, calls int, opts ...BudgetOption) *Budget {
+	b := &Budget{
+		reserve:  defaultReserve,
+		strategy: EvenSplit,
+		calls:    calls,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		b.deadline = deadline.Add(-b.reserve)
+	} else if b.fallbackTotal > 0 {
+		b.deadline = time.Now().Add(b.fallbackTotal)
+	}
+	return b
+}
+
+// Next returns context of the next call, timeout of which is computed when the call starts
+func (b *Budget) Next(ctx context.Context) (context.Context, context.CancelFunc) {
+	if b.deadline.IsZero() {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, b.nextTimeout())
+}
+
+// Remaining returns time left for the calls, zero when there is no deadline
+func (b *Budget) Remaining() time.Duration {
+	if b.deadline.IsZero() {
+		return 0
+	}
+	return max(time.Until(b.deadline), 0)
+}
+
+func (b *Budget) nextTimeout() time.Duration {
+	b.mu.Lock()
+	call := b.call
+	b.call++
+	b.mu.Unlock()
+	remaining := b.Remaining()
+	return min(max(b.strategy(remaining, call, b.calls), 0), remaining)
+}
@@ -0,0 +1,77 @@
+package httpclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStrategies(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy Strategy
+		call     int
+		calls    int
+		want     time.Duration
+	}{
+		{name: "even first", strategy: EvenSplit, call: 0, calls: 3, want: 3 * time.Second},
+		{name: "even last", strategy: EvenSplit, call: 2, calls: 3, want: 9 * time.Second},
+		{name: "even unplanned", strategy: EvenSplit, call: 5, calls: 3, want: 9 * time.Second},
+		{name: "weighted first", strategy: Weighted(1, 2), call: 0, calls: 2, want: 3 * time.Second},
+		{name: "weighted last", strategy: Weighted(1, 2), call: 1, calls: 2, want: 9 * time.Second},
+		{name: "weighted unplanned", strategy: Weighted(1, 2), call: 2, calls: 2, want: 9 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.strategy(9*time.Second, tt.call, tt.calls))
+		})
+	}
+}
+
+func TestBudget(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second+500*time.Millisecond)
+	defer cancel()
+	budget := NewBudget(ctx, 3)
+
+	first, cancelFirst := budget.Next(ctx)
+	defer cancelFirst()
+	assertTimeout(t, first, time.Second)
+
+	// time saved by the first call is passed on
+	second, cancelSecond := budget.Next(ctx)
+	defer cancelSecond()
+	assertTimeout(t, second, 1500*time.Millisecond)
+
+	assert.InDelta(t, 3*time.Second, budget.Remaining(), float64(100*time.Millisecond))
+}
+
+func TestBudgetWithoutDeadline(t *testing.T) {
+	budget := NewBudget(context.Background(), 2)
+	ctx, cancel := budget.Next(context.Background())
+	defer cancel()
+	_, ok := ctx.Deadline()
+	assert.False(t, ok)
+
+	budget = NewBudget(context.Background(), 2, WithFallbackTotal(4*time.Second), WithStrategy(Weighted(3, 1)))
+	ctx, cancel = budget.Next(context.Background())
+	defer cancel()
+	assertTimeout(t, ctx, 3*time.Second)
+}
+
+func TestBudgetExhausted(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	callCtx, cancelCall := NewBudget(ctx, 2).Next(ctx)
+	defer cancelCall()
+	require.Error(t, callCtx.Err())
+}
+
+func assertTimeout(t *testing.T, ctx context.Context, want time.Duration) {
+	t.Helper()
+	deadline, ok := ctx.Deadline()
+	require.True(t, ok)
+	assert.InDelta(t, want, time.Until(deadline), float64(100*time.Millisecond))
+}
@@ -0,0 +1,205 @@
+package oauth2
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	defaultExpiryDelta = 30 * time.Second
+	defaultTimeout     = 10 * time.Second
+)
+
+// Config of OAuth2 client credentials grant
+type Config struct {
+	TokenURL       string
+	ClientID       string
+	ClientSecret   string
+	Scopes         []string
+	EndpointParams url.Values    // extra parameters of token request, e.g. audience
+	AuthInParams   bool          // send client credentials as form parameters instead of basic auth
+	ExpiryDelta    time.Duration // tokens are refreshed this long before they expire, 30s by default
+	HTTPClient     *http.Client  // client of token requests, requests time out after 10s by default
+}
+
+type Token struct {
+	AccessToken string    `json:"accessToken" yaml:"accessToken"`
+	TokenType   string    `json:"tokenType" yaml:"tokenType"`
+	Expiry      time.Time `json:"expiry,omitempty" yaml:"expiry,omitempty"` // zero when token does not expire
+}
+
+// TokenSource returns cached token until it is about to expire
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+	// Invalidate drops cached token, e.g. when API rejects it before it expires
+	Invalidate()
+}
+
+type tokenSource struct {
+	cfg   Config
+	group singleflight.Group
+	mu    sync.Mutex
+	token *Token
+}
+
+// sources keeps token sources of the process so that tokens are reused across warm invocations
+// even when sources are created per invocation
+var sources sync.Map
+
+// NewTokenSource returns token source of cfg, sources of the same config share cached token
+func NewTokenSource(cfg Config) TokenSource {
+	if cfg.ExpiryDelta == 0 {
+		cfg.ExpiryDelta = defaultExpiryDelta
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: defaultTimeout}
+	}
+	source, _ := sources.LoadOrStore(cfg.key(), &tokenSource{cfg: cfg})
+	return source.(*tokenSource)
+}
+
+func (c Config) key() string {
+	secret := sha256.Sum256([]byte(c.ClientSecret))
+	return strings.Join([]string{
+		c.TokenURL, c.ClientID, hex.EncodeToString(secret[:]), strings.Join(c.Scopes, " "), c.EndpointParams.Encode(),
+	}, "\n")
+}
+
+func (s *tokenSource) Token(ctx context.Context) (*Token, error) {
+	if token := s.cached(); token != nil {
+		return token, nil
+	}
+	// concurrent callers share a single token request, which is not canceled along with the first caller
+	res, err, _ := s.group.Do("token", func() (any, error) {
+		if token := s.cached(); token != nil {
+			return token, nil
+		}
+		token, err := s.fetch(context.WithoutCancel(ctx))
+		if err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		s.token = token
+		s.mu.Unlock()
+		return token, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.(*Token), nil
+}
+
+func (s *tokenSource) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = nil
+}
+
+func (s *tokenSource) cached() *Token {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token == nil || (!s.token.Expiry.IsZero() && time.Now().Add(s.cfg.ExpiryDelta).After(s.token.Expiry)) {
+		return nil
+	}
+	return s.token
+}
+
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func (s *tokenSource) fetch(ctx context.Context) (*Token, error) {
+	params := url.Values{"grant_type": {"client_credentials"}}
+	for name, values := range s.cfg.EndpointParams {
+		params[name] = values
+	}
+	if len(s.cfg.Scopes) > 0 {
+		params.Set("scope", strings.Join(s.cfg.Scopes, " "))
+	}
+	if s.cfg.AuthInParams {
+		params.Set("client_id", s.cfg.ClientID)
+		params.Set("client_secret", s.cfg.ClientSecret)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.TokenURL, strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create token request")
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if !s.cfg.AuthInParams {
+		req.SetBasicAuth(url.QueryEscape(s.cfg.ClientID), url.QueryEscape(s.cfg.ClientSecret))
+	}
+	requestedAt := time.Now()
+	res, err := s.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to request token")
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read token response")
+	}
+	var tokenRes tokenResponse
+	if err := json.Unmarshal(body, &tokenRes); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode token response with status %d", res.StatusCode)
+	}
+	if res.StatusCode != http.StatusOK || tokenRes.Error != "" {
+		return nil, errors.Errorf("token request failed with status %d: %s %s", res.StatusCode, tokenRes.Error, tokenRes.ErrorDescription)
+	}
+	if tokenRes.AccessToken == "" {
+		return nil, errors.Errorf("token response has no access token")
+	}
+	token := &Token{AccessToken: tokenRes.AccessToken, TokenType: tokenRes.TokenType}
+	if token.TokenType == "" {
+		token.TokenType = "Bearer"
+	}
+	if tokenRes.ExpiresIn > 0 {
+		// expiry is counted from the moment of the request to stay on the safe side
+		token.Expiry = requestedAt.Add(time.Duration(tokenRes.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+// Transport authorizes requests with tokens of source, cached token is invalidated when response is 401
+type Transport struct {
+	Source TokenSource
+	Base   http.RoundTripper // http.DefaultTransport when nil
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.Source.Token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", token.TokenType+" "+token.AccessToken)
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	res, err := base.RoundTrip(req)
+	if err == nil && res.StatusCode == http.StatusUnauthorized {
+		t.Source.Invalidate()
+	}
+	return res, err
+}
+
+// NewClient returns HTTP client authorizing requests with client credentials tokens of cfg
+func NewClient(cfg Config) *http.Client {
+	return &http.Client{Transport: &Transport{Source: NewTokenSource(cfg)}}
+}
@@ -0,0 +1,122 @@
+package oauth2
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tokenServer struct {
+	*httptest.Server
+	requests  atomic.Int32
+	expiresIn int
+	status    int
+}
+
+func newTokenServer(t *testing.T, expiresIn int) *tokenServer {
+	srv := &tokenServer{expiresIn: expiresIn, status: http.StatusOK}
+	srv.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := srv.requests.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		if srv.status != http.StatusOK {
+			w.WriteHeader(srv.status)
+			_, _ = fmt.Fprint(w, `{"error":"invalid_client","error_description":"unknown client"}`)
+			return
+		}
+		id, secret, _ := r.BasicAuth()
+		_, _ = fmt.Fprintf(w, `{"access_token":"%s-%s-%s-%d","token_type":"Bearer","expires_in":%d}`,
+			id, secret, r.FormValue("scope"), n, srv.expiresIn)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestTokenSource(t *testing.T) {
+	srv := newTokenServer(t, 3600)
+	cfg := Config{TokenURL: srv.URL, ClientID: "id", ClientSecret: "secret", Scopes: []string{"read", "write"}}
+
+	var wg sync.WaitGroup
+	tokens := make([]string, 10)
+	for i := range tokens {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := NewTokenSource(cfg).Token(context.Background())
+			require.NoError(t, err)
+			tokens[i] = token.AccessToken
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), srv.requests.Load())
+	for _, token := range tokens {
+		assert.Equal(t, "id-secret-read write-1", token)
+	}
+
+	// invalidated token is fetched again
+	NewTokenSource(cfg).Invalidate()
+	token, err := NewTokenSource(cfg).Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "id-secret-read write-2", token.AccessToken)
+
+	// other credentials have their own token
+	other := cfg
+	other.ClientSecret = "rotated"
+	token, err = NewTokenSource(other).Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "id-rotated-read write-3", token.AccessToken)
+}
+
+func TestTokenSourceExpiry(t *testing.T) {
+	srv := newTokenServer(t, 20)
+	source := NewTokenSource(Config{TokenURL: srv.URL, ClientID: "expiry", ClientSecret: "secret"})
+
+	for i := 1; i <= 2; i++ {
+		token, err := source.Token(context.Background())
+		require.NoError(t, err)
+		// token expiring within expiry delta is refreshed
+		assert.Equal(t, fmt.Sprintf("expiry-secret--%d", i), token.AccessToken)
+	}
+	assert.Equal(t, int32(2), srv.requests.Load())
+}
+
+func TestTokenSourceError(t *testing.T) {
+	srv := newTokenServer(t, 3600)
+	srv.status = http.StatusUnauthorized
+
+	_, err := NewTokenSource(Config{TokenURL: srv.URL, ClientID: "error", ClientSecret: "secret"}).Token(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, "token request failed with status 401: invalid_client unknown client", err.Error())
+}
+
+func TestTransport(t *testing.T) {
+	srv := newTokenServer(t, 3600)
+	var authorizations []string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		if len(authorizations) == 2 {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	t.Cleanup(api.Close)
+
+	client := NewClient(Config{TokenURL: srv.URL, ClientID: "transport", ClientSecret: "secret", ExpiryDelta: time.Second})
+	for i := 0; i < 3; i++ {
+		res, err := client.Get(api.URL)
+		require.NoError(t, err)
+		_ = res.Body.Close()
+	}
+	assert.Equal(t, []string{
+		"Bearer transport-secret--1",
+		"Bearer transport-secret--1",
+		"Bearer transport-secret--2", // token rejected with 401 is refreshed
+	}, authorizations)
+}
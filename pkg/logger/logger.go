@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/samber/lo"
@@ -20,6 +22,8 @@ const (
 	Info  = "INFO"
 	Error = "ERROR"
 	Warn  = "WARN"
+	// Security is the level of security events, they are written regardless of the min level
+	Security = "SECURITY"
 )
 
 type Logger interface {
@@ -31,7 +35,56 @@ type Logger interface {
 	GetValue(ctx context.Context, key string) any
 }
 
-type logger struct{}
+// SecurityLogger is implemented by loggers writing security events (e.g. auth failure, blocked request) with
+// fields for audit, separately from other messages when a SecuritySink is set
+type SecurityLogger interface {
+	Security(ctx context.Context, event string, fields map[string]any)
+}
+
+// LogSecurity logs security event with l when it is a SecurityLogger, as a warning with fields otherwise
+func LogSecurity(ctx context.Context, l Logger, event string, fields map[string]any) {
+	if securityLogger, ok := l.(SecurityLogger); ok {
+		securityLogger.Security(ctx, event, fields)
+		return
+	}
+	l.Warnf(l.WithValues(ctx, fields), "security event %s", event)
+}
+
+// SecuritySink receives security events, e.g. to keep them in a distinct CloudWatch Logs stream
+type SecuritySink interface {
+	WriteSecurityEvent(ctx context.Context, msg Message) error
+}
+
+type logger struct {
+	minLevel     string
+	pretty       bool
+	securitySink SecuritySink
+}
+
+type Option func(*logger)
+
+// WithMinLevel drops messages of levels lower than level, e.g. Warn keeps warnings and errors only
+func WithMinLevel(level string) Option {
+	return func(l *logger) {
+		l.minLevel = level
+	}
+}
+
+// WithPrettyOutput prints human-readable lines instead of JSON, e.g. for local development
+func WithPrettyOutput() Option {
+	return func(l *logger) {
+		l.pretty = true
+	}
+}
+
+// WithSecuritySink routes security events to sink instead of stdout, events it fails to write are printed to stdout
+func WithSecuritySink(sink SecuritySink) Option {
+	return func(l *logger) {
+		l.securitySink = sink
+	}
+}
+
+var levelOrder = map[string]int{Info: 0, Warn: 1, Error: 2}
 
 type Message struct {
 	Date    string       `json:"date"`
@@ -40,8 +93,18 @@ type Message struct {
 	Context ContextValue `json:"context"`
 }
 
-func NewLogger() Logger {
-	return &logger{}
+func NewLogger(opts ...Option) Logger {
+	l := &logger{}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// GetValues returns all values attached to the context with WithValue
+func GetValues(ctx context.Context) ContextValue {
+	ctxValue, _ := ctx.Value(contextValueKey).(ContextValue)
+	return ctxValue
 }
 
 func (l logger) GetValue(ctx context.Context, key string) any {
@@ -81,26 +144,81 @@ func (l logger) Errorf(ctx context.Context, format string, args ...any) {
 	l.printWithLevel(ctx, format, args, Error)
 }
 
+func (l logger) Security(ctx context.Context, event string, fields map[string]any) {
+	msg := Message{
+		Date:    time.Now().Format(time.DateTime),
+		Level:   Security,
+		Message: event,
+		Context: lo.Assign(GetValues(ctx), fields),
+	}
+	if l.securitySink == nil {
+		l.print(msg)
+		return
+	}
+	if err := l.securitySink.WriteSecurityEvent(ctx, msg); err != nil {
+		l.print(msg)
+		l.Errorf(ctx, "failed to write security event %s: %v", event, err)
+	}
+}
+
 func (l logger) printWithLevel(ctx context.Context, format string, args []any, level string) {
+	if levelOrder[level] < levelOrder[l.minLevel] {
+		return
+	}
 	ctxValueOrNil := ctx.Value(contextValueKey)
 	contextValue := ContextValue{}
 	if ctxValueOrNil != nil {
 		contextValue = ctxValueOrNil.(ContextValue)
 	}
-	message := fmt.Sprintf(format, args...)
-	msg := Message{
+	l.print(Message{
 		Date:    time.Now().Format(time.DateTime),
 		Level:   level,
-		Message: message,
+		Message: fmt.Sprintf(format, args...),
 		Context: contextValue,
-	}
-	jsonOutput, err := json.Marshal(msg)
+	})
+}
+
+func (l logger) print(msg Message) {
+	level, message := msg.Level, msg.Message
 	printer := os.Stdout
 	if level == Error {
 		printer = os.Stderr
 	}
+	if l.pretty {
+		_, _ = printer.WriteString(prettyLine(msg) + "\n")
+		return
+	}
+	jsonOutput, err := json.Marshal(msg)
 	if err != nil {
 		_, _ = printer.WriteString(fmt.Sprintf(`{"level":"%s","message":"%s","context":{"error":"%s"}}`, level, message, err.Error()) + "\n")
 	}
 	_, _ = printer.WriteString(string(jsonOutput) + "\n")
 }
+
+// CopyLoggerValues returns to with values attached to from with WithValue, values of to are overridden
+func CopyLoggerValues(from, to context.Context) context.Context {
+	values := GetValues(from)
+	if len(values) == 0 {
+		return to
+	}
+	return context.WithValue(to, contextValueKey, lo.Assign(GetValues(to), values))
+}
+
+// prettyLine formats message as "date LEVEL message key=value ..." with context keys sorted
+func prettyLine(msg Message) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%s %-5s %s", msg.Date, msg.Level, msg.Message))
+	keys := make([]string, 0, len(msg.Context))
+	for key := range msg.Context {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value, err := json.Marshal(msg.Context[key])
+		if err != nil {
+			value = []byte(fmt.Sprint(msg.Context[key]))
+		}
+		b.WriteString(fmt.Sprintf(" %s=%s", key, value))
+	}
+	return b.String()
+}
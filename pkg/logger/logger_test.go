@@ -0,0 +1,102 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyLoggerValues(t *testing.T) {
+	log := NewLogger()
+	from := log.WithValues(context.Background(), map[string]any{"requestUID": "uid", "user": "from"})
+	to := log.WithValues(context.Background(), map[string]any{"user": "to", "job": "cleanup"})
+
+	copied := CopyLoggerValues(from, to)
+	assert.Equal(t, ContextValue{"requestUID": "uid", "user": "from", "job": "cleanup"}, GetValues(copied))
+	assert.Equal(t, ContextValue{"user": "to", "job": "cleanup"}, GetValues(to))
+
+	assert.Equal(t, to, CopyLoggerValues(context.Background(), to))
+	assert.Equal(t, ContextValue{"requestUID": "uid", "user": "from"}, GetValues(CopyLoggerValues(from, context.Background())))
+}
+
+func TestLoggerOptions(t *testing.T) {
+	read, write, err := os.Pipe()
+	require.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = write
+	defer func() { os.Stdout = stdout }()
+
+	log := NewLogger(WithMinLevel(Warn), WithPrettyOutput())
+	ctx := log.WithValues(context.Background(), map[string]any{"user": "u1", "attempt": 2})
+	log.Infof(ctx, "dropped")
+	log.Warnf(ctx, "slow call took %s", "2s")
+	require.NoError(t, write.Close())
+	output, err := io.ReadAll(read)
+	require.NoError(t, err)
+
+	assert.NotContains(t, string(output), "dropped")
+	assert.Regexp(t, `^\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2} WARN  slow call took 2s attempt=2 user="u1"\n$`, string(output))
+}
+
+type recordingSink struct {
+	messages []Message
+	err      error
+}
+
+func (s *recordingSink) WriteSecurityEvent(_ context.Context, msg Message) error {
+	s.messages = append(s.messages, msg)
+	return s.err
+}
+
+func TestSecurity(t *testing.T) {
+	read, write, err := os.Pipe()
+	require.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = write
+	defer func() { os.Stdout = stdout }()
+
+	sink := &recordingSink{}
+	log := NewLogger(WithMinLevel(Error), WithPrettyOutput(), WithSecuritySink(sink))
+	ctx := log.WithValue(context.Background(), "requestUID", "uid")
+	LogSecurity(ctx, log, "authFailure", map[string]any{"clientIP": "192.0.2.1"})
+	require.Len(t, sink.messages, 1)
+	assert.Equal(t, Security, sink.messages[0].Level)
+	assert.Equal(t, "authFailure", sink.messages[0].Message)
+	assert.Equal(t, ContextValue{"requestUID": "uid", "clientIP": "192.0.2.1"}, sink.messages[0].Context)
+
+	sink.err = assert.AnError
+	LogSecurity(ctx, log, "ipBlocked", nil)
+	LogSecurity(ctx, NewLogger(WithPrettyOutput()), "policyBlocked", nil)
+	require.NoError(t, write.Close())
+	output, err := io.ReadAll(read)
+	require.NoError(t, err)
+
+	assert.NotContains(t, string(output), "authFailure", "events written to the sink are not printed")
+	assert.Contains(t, string(output), `SECURITY ipBlocked requestUID="uid"`, "events the sink fails to write are printed")
+	assert.Contains(t, string(output), `SECURITY policyBlocked requestUID="uid"`, "events are printed without sink regardless of min level")
+}
+
+// warnLogger does not implement SecurityLogger, e.g. logger of an application
+type warnLogger struct {
+	Logger
+	warnings []string
+	values   []ContextValue
+}
+
+func (l *warnLogger) Warnf(ctx context.Context, format string, args ...any) {
+	l.warnings = append(l.warnings, fmt.Sprintf(format, args...))
+	l.values = append(l.values, GetValues(ctx))
+}
+
+func TestLogSecurityFallback(t *testing.T) {
+	log := &warnLogger{Logger: NewLogger()}
+	LogSecurity(context.Background(), log, "authFailure", map[string]any{"clientIP": "192.0.2.1"})
+
+	assert.Equal(t, []string{"security event authFailure"}, log.warnings)
+	assert.Equal(t, []ContextValue{{"clientIP": "192.0.2.1"}}, log.values)
+}
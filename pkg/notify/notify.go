@@ -0,0 +1,368 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	htmltemplate "html/template"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ses"
+	"github.com/aws/aws-sdk-go/service/ses/sesiface"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sns/snsiface"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/util/retry"
+)
+
+const defaultMaxRetries = 3
+
+type Notifier interface {
+	SendEmail(ctx context.Context, email Email) error
+	SendSMS(ctx context.Context, sms SMS) error
+	Push(ctx context.Context, push Push) error
+}
+
+// Email is sent either with explicit Subject/Text/HTML or rendered from a registered template
+type Email struct {
+	From     string   `json:"from" yaml:"from"`
+	To       []string `json:"to" yaml:"to"`
+	Cc       []string `json:"cc,omitempty" yaml:"cc,omitempty"`
+	Bcc      []string `json:"bcc,omitempty" yaml:"bcc,omitempty"`
+	ReplyTo  []string `json:"replyTo,omitempty" yaml:"replyTo,omitempty"`
+	Subject  string   `json:"subject,omitempty" yaml:"subject,omitempty"`
+	Text     string   `json:"text,omitempty" yaml:"text,omitempty"`
+	HTML     string   `json:"html,omitempty" yaml:"html,omitempty"`
+	Template string   `json:"template,omitempty" yaml:"template,omitempty"` // name of template registered with WithTemplate
+	Data     any      `json:"data,omitempty" yaml:"data,omitempty"`         // data passed to the template
+}
+
+type SMS struct {
+	PhoneNumber string `json:"phoneNumber" yaml:"phoneNumber"` // E.164 formatted phone number
+	Message     string `json:"message" yaml:"message"`
+	SenderID    string `json:"senderID,omitempty" yaml:"senderID,omitempty"`
+	Promotional bool   `json:"promotional,omitempty" yaml:"promotional,omitempty"`
+}
+
+type Push struct {
+	TargetArn string `json:"targetArn,omitempty" yaml:"targetArn,omitempty"` // platform endpoint ARN
+	TopicArn  string `json:"topicArn,omitempty" yaml:"topicArn,omitempty"`
+	Subject   string `json:"subject,omitempty" yaml:"subject,omitempty"`
+	Message   string `json:"message" yaml:"message"`
+	// MessageStructure should be set to "json" when Message contains per-protocol payloads
+	MessageStructure string `json:"messageStructure,omitempty" yaml:"messageStructure,omitempty"`
+}
+
+type Template struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+type (
+	Option func(*notifier)
+)
+
+type notifier struct {
+	ses         sesiface.SESAPI
+	sns         snsiface.SNSAPI
+	logger      logger.Logger
+	dryRun      bool
+	maxRetries  int
+	defaultFrom string
+	templates   map[string]Template
+}
+
+func WithSES(client sesiface.SESAPI) Option {
+	return func(n *notifier) {
+		n.ses = client
+	}
+}
+
+func WithSNS(client snsiface.SNSAPI) Option {
+	return func(n *notifier) {
+		n.sns = client
+	}
+}
+
+func WithLogger(logger logger.Logger) Option {
+	return func(n *notifier) {
+		n.logger = logger
+	}
+}
+
+// WithDryRun makes notifier log notifications instead of sending them
+func WithDryRun() Option {
+	return func(n *notifier) {
+		n.dryRun = true
+	}
+}
+
+func WithMaxRetries(maxRetries int) Option {
+	return func(n *notifier) {
+		n.maxRetries = maxRetries
+	}
+}
+
+func WithDefaultFrom(from string) Option {
+	return func(n *notifier) {
+		n.defaultFrom = from
+	}
+}
+
+func WithTemplate(name string, tpl Template) Option {
+	return func(n *notifier) {
+		n.templates[name] = tpl
+	}
+}
+
+func New(opts ...Option) (Notifier, error) {
+	if os.Getenv("LOCAL_DEBUG") == "true" {
+		opts = append([]Option{WithDryRun()}, opts...)
+	}
+
+	n := &notifier{
+		logger:     logger.NewLogger(),
+		maxRetries: defaultMaxRetries,
+		templates:  make(map[string]Template),
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	if n.maxRetries < 1 {
+		n.maxRetries = 1
+	}
+
+	if !n.dryRun && (n.ses == nil || n.sns == nil) {
+		sess, err := session.NewSession()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to init aws session")
+		}
+		if n.ses == nil {
+			n.ses = ses.New(sess)
+		}
+		if n.sns == nil {
+			n.sns = sns.New(sess)
+		}
+	}
+
+	return n, nil
+}
+
+func (n *notifier) SendEmail(ctx context.Context, email Email) error {
+	if err := n.render(&email); err != nil {
+		return err
+	}
+	if email.From == "" {
+		email.From = n.defaultFrom
+	}
+	if email.From == "" || len(email.To) == 0 {
+		return errors.Errorf("email sender and at least one recipient must be set")
+	}
+
+	ctx = n.logger.WithValues(ctx, map[string]any{
+		"to":      lo.Map(email.To, func(to string, _ int) string { return maskEmail(to) }),
+		"subject": email.Subject,
+	})
+	if n.dryRun {
+		masked := email
+		for _, addresses := range []*[]string{&masked.To, &masked.Cc, &masked.Bcc, &masked.ReplyTo} {
+			*addresses = lo.Map(*addresses, func(address string, _ int) string { return maskEmail(address) })
+		}
+		n.logger.Infof(n.logger.WithValue(ctx, "email", masked), "dry-run: skip sending email")
+		return nil
+	}
+
+	body := &ses.Body{}
+	if email.Text != "" {
+		body.Text = &ses.Content{Charset: aws.String("UTF-8"), Data: aws.String(email.Text)}
+	}
+	if email.HTML != "" {
+		body.Html = &ses.Content{Charset: aws.String("UTF-8"), Data: aws.String(email.HTML)}
+	}
+	input := &ses.SendEmailInput{
+		Source: aws.String(email.From),
+		Destination: &ses.Destination{
+			ToAddresses:  aws.StringSlice(email.To),
+			CcAddresses:  aws.StringSlice(email.Cc),
+			BccAddresses: aws.StringSlice(email.Bcc),
+		},
+		ReplyToAddresses: aws.StringSlice(email.ReplyTo),
+		Message: &ses.Message{
+			Subject: &ses.Content{Charset: aws.String("UTF-8"), Data: aws.String(email.Subject)},
+			Body:    body,
+		},
+	}
+	out, err := withRetries(ctx, n, "email", func() (*ses.SendEmailOutput, error) {
+		return n.ses.SendEmailWithContext(ctx, input)
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to send email")
+	}
+	n.logger.Infof(n.logger.WithValue(ctx, "messageId", aws.StringValue(out.MessageId)), "email sent")
+	return nil
+}
+
+func (n *notifier) SendSMS(ctx context.Context, sms SMS) error {
+	if sms.PhoneNumber == "" {
+		return errors.Errorf("phone number must be set")
+	}
+
+	ctx = n.logger.WithValue(ctx, "phoneNumber", maskPhoneNumber(sms.PhoneNumber))
+	if n.dryRun {
+		masked := sms
+		masked.PhoneNumber = maskPhoneNumber(sms.PhoneNumber)
+		n.logger.Infof(n.logger.WithValue(ctx, "sms", masked), "dry-run: skip sending sms")
+		return nil
+	}
+
+	attributes := map[string]*sns.MessageAttributeValue{
+		"AWS.SNS.SMS.SMSType": {
+			DataType:    aws.String("String"),
+			StringValue: aws.String(lo.If(sms.Promotional, "Promotional").Else("Transactional")),
+		},
+	}
+	if sms.SenderID != "" {
+		attributes["AWS.SNS.SMS.SenderID"] = &sns.MessageAttributeValue{
+			DataType:    aws.String("String"),
+			StringValue: aws.String(sms.SenderID),
+		}
+	}
+	out, err := withRetries(ctx, n, "sms", func() (*sns.PublishOutput, error) {
+		return n.sns.PublishWithContext(ctx, &sns.PublishInput{
+			PhoneNumber:       aws.String(sms.PhoneNumber),
+			Message:           aws.String(sms.Message),
+			MessageAttributes: attributes,
+		})
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to send sms")
+	}
+	n.logger.Infof(n.logger.WithValue(ctx, "messageId", aws.StringValue(out.MessageId)), "sms sent")
+	return nil
+}
+
+func (n *notifier) Push(ctx context.Context, push Push) error {
+	if push.TargetArn == "" && push.TopicArn == "" {
+		return errors.Errorf("either target or topic ARN must be set")
+	}
+
+	ctx = n.logger.WithValue(ctx, "target", lo.If(push.TargetArn != "", push.TargetArn).Else(push.TopicArn))
+	if n.dryRun {
+		n.logger.Infof(n.logger.WithValue(ctx, "push", push), "dry-run: skip sending push notification")
+		return nil
+	}
+
+	input := &sns.PublishInput{
+		Message: aws.String(push.Message),
+	}
+	if push.TargetArn != "" {
+		input.TargetArn = aws.String(push.TargetArn)
+	} else {
+		input.TopicArn = aws.String(push.TopicArn)
+	}
+	if push.Subject != "" {
+		input.Subject = aws.String(push.Subject)
+	}
+	if push.MessageStructure != "" {
+		input.MessageStructure = aws.String(push.MessageStructure)
+	}
+	out, err := withRetries(ctx, n, "push", func() (*sns.PublishOutput, error) {
+		return n.sns.PublishWithContext(ctx, input)
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to send push notification")
+	}
+	n.logger.Infof(n.logger.WithValue(ctx, "messageId", aws.StringValue(out.MessageId)), "push notification sent")
+	return nil
+}
+
+func (n *notifier) render(email *Email) error {
+	if email.Template == "" {
+		return nil
+	}
+	tpl, ok := n.templates[email.Template]
+	if !ok {
+		return errors.Errorf("email template %q is not registered", email.Template)
+	}
+	var err error
+	if email.Subject, err = renderText(tpl.Subject, email.Data); err != nil {
+		return errors.Wrapf(err, "failed to render subject of %q", email.Template)
+	}
+	if email.Text, err = renderText(tpl.Text, email.Data); err != nil {
+		return errors.Wrapf(err, "failed to render text of %q", email.Template)
+	}
+	if tpl.HTML == "" {
+		return nil
+	}
+	htmlTpl, err := htmltemplate.New("html").Parse(tpl.HTML)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse html of %q", email.Template)
+	}
+	var buf bytes.Buffer
+	if err := htmlTpl.Execute(&buf, email.Data); err != nil {
+		return errors.Wrapf(err, "failed to render html of %q", email.Template)
+	}
+	email.HTML = buf.String()
+	return nil
+}
+
+func renderText(text string, data any) (string, error) {
+	if text == "" {
+		return "", nil
+	}
+	tpl, err := template.New("text").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// maskEmail keeps the first letter of the local part and the domain, e.g. j***@example.com, so that logs
+// do not disclose recipients
+func maskEmail(email string) string {
+	local, domain, found := strings.Cut(email, "@")
+	if !found || local == "" {
+		return "***"
+	}
+	return string([]rune(local)[:1]) + "***@" + domain
+}
+
+// maskPhoneNumber keeps the last 4 digits of the number, e.g. ***4567
+func maskPhoneNumber(phoneNumber string) string {
+	if len(phoneNumber) <= 4 {
+		return "***"
+	}
+	return "***" + phoneNumber[len(phoneNumber)-4:]
+}
+
+// withRetries retries throttled and transient failures only, permanent ones (e.g. MessageRejected of SES or
+// InvalidParameter of SNS) fail the same way on every attempt
+func withRetries[T any](ctx context.Context, n *notifier, kind string, action func() (T, error)) (T, error) {
+	res, err := retry.With[T](ctx, retry.Config[T]{
+		Action: func(context.Context) (T, error) {
+			return action()
+		},
+		MaxRetries: n.maxRetries,
+		RetryIf:    retry.IsRetryable,
+		AttemptErrorCallback: func(ctx context.Context, attempt int, err error) {
+			n.logger.Warnf(ctx, "failed to send %s (attempt %d): %v", kind, attempt, err)
+		},
+	})
+	if err != nil {
+		var empty T
+		return empty, err
+	}
+	return *res, nil
+}
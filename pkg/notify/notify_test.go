@@ -0,0 +1,128 @@
+package notify
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/ses"
+	"github.com/aws/aws-sdk-go/service/ses/sesiface"
+)
+
+type fakeSES struct {
+	sesiface.SESAPI
+	failures int
+	err      error
+	inputs   []*ses.SendEmailInput
+}
+
+func (f *fakeSES) SendEmailWithContext(_ aws.Context, input *ses.SendEmailInput, _ ...request.Option) (*ses.SendEmailOutput, error) {
+	f.inputs = append(f.inputs, input)
+	if len(f.inputs) <= f.failures {
+		return nil, f.err
+	}
+	return &ses.SendEmailOutput{MessageId: aws.String("id")}, nil
+}
+
+func TestSendEmail(t *testing.T) {
+	tests := []struct {
+		name        string
+		failures    int
+		err         error
+		email       Email
+		wantErr     string
+		wantCalls   int
+		wantSubject string
+		wantHTML    string
+	}{
+		{
+			name: "should render registered template",
+			email: Email{
+				To:       []string{"john@example.com"},
+				Template: "welcome",
+				Data:     map[string]string{"Name": "<John>"},
+			},
+			wantCalls:   1,
+			wantSubject: "Welcome, <John>",
+			wantHTML:    "<p>Hello, &lt;John&gt;</p>",
+		},
+		{
+			name:     "should retry failed attempts",
+			failures: 2,
+			err:      awserr.New("Throttling", "throttled", nil),
+			email: Email{
+				To:      []string{"john@example.com"},
+				Subject: "Hello",
+			},
+			wantCalls:   3,
+			wantSubject: "Hello",
+		},
+		{
+			name:     "should fail when retries are exhausted",
+			failures: 3,
+			err:      awserr.New("Throttling", "throttled", nil),
+			email: Email{
+				To:      []string{"john@example.com"},
+				Subject: "Hello",
+			},
+			wantCalls: 3,
+			wantErr:   "failed to send email: Throttling: throttled",
+		},
+		{
+			name:     "should not retry permanent failures",
+			failures: 3,
+			err:      awserr.New(ses.ErrCodeMessageRejected, "Email address is not verified", nil),
+			email: Email{
+				To:      []string{"john@example.com"},
+				Subject: "Hello",
+			},
+			wantCalls: 1,
+			wantErr:   "failed to send email: MessageRejected: Email address is not verified",
+		},
+		{
+			name: "should fail on unknown template",
+			email: Email{
+				To:       []string{"john@example.com"},
+				Template: "unknown",
+			},
+			wantErr: `email template "unknown" is not registered`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeSES{failures: tt.failures, err: tt.err}
+			n, err := New(WithSES(client), WithDefaultFrom("noreply@example.com"), WithTemplate("welcome", Template{
+				Subject: "Welcome, {{ .Name }}",
+				HTML:    "<p>Hello, {{ .Name }}</p>",
+			}))
+			assert.NoError(t, err)
+
+			err = n.SendEmail(context.Background(), tt.email)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Len(t, client.inputs, tt.wantCalls)
+			if tt.wantErr == "" {
+				input := client.inputs[len(client.inputs)-1]
+				assert.Equal(t, "noreply@example.com", aws.StringValue(input.Source))
+				assert.Equal(t, tt.wantSubject, aws.StringValue(input.Message.Subject.Data))
+				if tt.wantHTML != "" {
+					assert.Equal(t, tt.wantHTML, aws.StringValue(input.Message.Body.Html.Data))
+				}
+			}
+		})
+	}
+}
+
+func TestMask(t *testing.T) {
+	assert.Equal(t, "j***@example.com", maskEmail("john@example.com"))
+	assert.Equal(t, "***", maskEmail("invalid"))
+	assert.Equal(t, "***4567", maskPhoneNumber("+14155554567"))
+	assert.Equal(t, "***", maskPhoneNumber("123"))
+}
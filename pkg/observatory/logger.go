@@ -0,0 +1,143 @@
+package observatory
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+)
+
+type observatoryLogger struct {
+	logger.Logger
+	client Client
+}
+
+// Logger writes messages with the base logger and pushes them to the Observatory, push errors are
+// reported with the base logger only
+func Logger(base logger.Logger, client Client) logger.Logger {
+	return &observatoryLogger{
+		Logger: base,
+		client: client,
+	}
+}
+
+func (l *observatoryLogger) Infof(ctx context.Context, format string, args ...any) {
+	l.Logger.Infof(ctx, format, args...)
+	l.push(ctx, logger.Info, format, args)
+}
+
+func (l *observatoryLogger) Warnf(ctx context.Context, format string, args ...any) {
+	l.Logger.Warnf(ctx, format, args...)
+	l.push(ctx, logger.Warn, format, args)
+}
+
+func (l *observatoryLogger) Errorf(ctx context.Context, format string, args ...any) {
+	l.Logger.Errorf(ctx, format, args...)
+	l.push(ctx, logger.Error, format, args)
+}
+
+func (l *observatoryLogger) Security(ctx context.Context, event string, fields map[string]any) {
+	logger.LogSecurity(ctx, l.Logger, event, fields)
+	l.push(l.Logger.WithValues(ctx, fields), logger.Security, "%s", []any{event})
+}
+
+func (l *observatoryLogger) push(ctx context.Context, level, format string, args []any) {
+	entry := LogEntry{
+		Date:    time.Now().UTC(),
+		Level:   level,
+		Message: fmt.Sprintf(format, args...),
+		Context: logger.GetValues(ctx),
+	}
+	// client warns once when circuit opens, it is not reported for every message
+	if err := l.client.PushLogs(context.WithoutCancel(ctx), []LogEntry{entry}); err != nil && !errors.Is(err, ErrCircuitOpen) {
+		l.Logger.Errorf(ctx, "failed to push log entry to observatory: %v", err)
+	}
+}
+
+type requestTagsKeyType struct{}
+
+var requestTagsKey = requestTagsKeyType{}
+
+type requestTags struct {
+	mu   sync.Mutex
+	tags Tags
+}
+
+// TagRequest adds tags to the metric Middleware pushes for the request of ctx, e.g. results of request
+// inspection; it does nothing outside of the Middleware
+func TagRequest(ctx context.Context, tags Tags) {
+	holder, ok := ctx.Value(requestTagsKey).(*requestTags)
+	if !ok {
+		return
+	}
+	holder.mu.Lock()
+	defer holder.mu.Unlock()
+	for k, v := range tags {
+		holder.tags[k] = v
+	}
+}
+
+// Middleware pushes duration of every request as a metric tagged with method and status code, and with
+// tags added by TagRequest
+func Middleware(client Client, log logger.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			startedAt := time.Now()
+			rw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			holder := &requestTags{tags: Tags{}}
+			next.ServeHTTP(rw, r.WithContext(context.WithValue(r.Context(), requestTagsKey, holder)))
+			tags := Tags{}
+			holder.mu.Lock()
+			for k, v := range holder.tags {
+				tags[k] = v
+			}
+			holder.mu.Unlock()
+			tags["method"] = r.Method
+			tags["status"] = fmt.Sprint(rw.status)
+			err := client.PushMetrics(context.WithoutCancel(r.Context()), []Metric{{
+				Name:      "request_duration",
+				Value:     float64(time.Since(startedAt).Milliseconds()),
+				Unit:      "ms",
+				Timestamp: startedAt.UTC(),
+				Tags:      tags,
+			}})
+			if err != nil && !errors.Is(err, ErrCircuitOpen) {
+				log.Errorf(r.Context(), "failed to push request metrics to observatory: %v", err)
+			}
+		})
+	}
+}
+
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *statusWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,293 @@
+package observatory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+)
+
+const (
+	logsPath    = "/api/v1/logs"
+	metricsPath = "/api/v1/metrics"
+	tracesPath  = "/api/v1/traces"
+
+	defaultTimeout          = 2 * time.Second
+	defaultFailureThreshold = 3
+	defaultCooldown         = 30 * time.Second
+)
+
+var ErrCircuitOpen = errors.New("observatory circuit breaker is open")
+
+type LogEntry struct {
+	Date    time.Time      `json:"date" yaml:"date"`
+	Level   string         `json:"level" yaml:"level"`
+	Message string         `json:"message" yaml:"message"`
+	Context map[string]any `json:"context,omitempty" yaml:"context,omitempty"`
+	Tags    Tags           `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+type Metric struct {
+	Name      string    `json:"name" yaml:"name"`
+	Value     float64   `json:"value" yaml:"value"`
+	Unit      string    `json:"unit,omitempty" yaml:"unit,omitempty"`
+	Timestamp time.Time `json:"timestamp" yaml:"timestamp"`
+	Tags      Tags      `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+type Span struct {
+	TraceID    string         `json:"traceId" yaml:"traceId"`
+	SpanID     string         `json:"spanId" yaml:"spanId"`
+	ParentID   string         `json:"parentId,omitempty" yaml:"parentId,omitempty"`
+	Name       string         `json:"name" yaml:"name"`
+	StartedAt  time.Time      `json:"startedAt" yaml:"startedAt"`
+	Duration   time.Duration  `json:"duration" yaml:"duration"`
+	Error      string         `json:"error,omitempty" yaml:"error,omitempty"`
+	Attributes map[string]any `json:"attributes,omitempty" yaml:"attributes,omitempty"`
+	Tags       Tags           `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+// Tags identify the source of pushed data, module and submodule tags are set by the client
+type Tags map[string]string
+
+const (
+	ModuleTag    = "module"
+	SubmoduleTag = "submodule"
+)
+
+// Client pushes logs, metrics and traces to the Observatory service
+type Client interface {
+	PushLogs(ctx context.Context, entries []LogEntry) error
+	PushMetrics(ctx context.Context, metrics []Metric) error
+	PushTraces(ctx context.Context, spans []Span) error
+}
+
+type (
+	Option func(*client)
+)
+
+type client struct {
+	baseURI          string
+	token            string
+	tags             Tags
+	logger           logger.Logger
+	httpClient       *http.Client
+	timeout          time.Duration
+	failureThreshold int
+	cooldown         time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+// WithToken authenticates pushes with bearer token
+func WithToken(token string) Option {
+	return func(c *client) {
+		c.token = token
+	}
+}
+
+// WithModule tags everything pushed by the client with module and submodule, empty values are omitted
+func WithModule(module, submodule string) Option {
+	return func(c *client) {
+		c.tags[ModuleTag] = module
+		c.tags[SubmoduleTag] = submodule
+	}
+}
+
+// WithTags adds static tags to everything pushed by the client
+func WithTags(tags Tags) Option {
+	return func(c *client) {
+		for k, v := range tags {
+			c.tags[k] = v
+		}
+	}
+}
+
+func WithLogger(logger logger.Logger) Option {
+	return func(c *client) {
+		c.logger = logger
+	}
+}
+
+// WithHTTPClient sets client used for pushes, timeout of the client applies in addition to WithTimeout
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTimeout limits duration of a single push, pushes happen on the request path so it defaults to 2s
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *client) {
+		c.timeout = timeout
+	}
+}
+
+// WithCircuitBreaker makes pushes fail fast with ErrCircuitOpen for cooldown after failureThreshold
+// consecutive failures, a single push is let through afterwards to probe the endpoint
+func WithCircuitBreaker(failureThreshold int, cooldown time.Duration) Option {
+	return func(c *client) {
+		c.failureThreshold = failureThreshold
+		c.cooldown = cooldown
+	}
+}
+
+func New(baseURI string, opts ...Option) (Client, error) {
+	u, err := url.Parse(baseURI)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid observatory base URI %q", baseURI)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return nil, errors.Errorf("invalid observatory base URI %q: absolute http(s) URI is expected", baseURI)
+	}
+	c := &client{
+		baseURI:          strings.TrimSuffix(baseURI, "/"),
+		tags:             Tags{},
+		logger:           logger.NewLogger(),
+		httpClient:       &http.Client{Timeout: defaultTimeout},
+		timeout:          defaultTimeout,
+		failureThreshold: defaultFailureThreshold,
+		cooldown:         defaultCooldown,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.tags = lo.OmitByValues(c.tags, []string{""})
+	return c, nil
+}
+
+func (c *client) PushLogs(ctx context.Context, entries []LogEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return c.push(ctx, logsPath, lo.Map(entries, func(e LogEntry, _ int) LogEntry {
+		e.Tags = c.tagged(e.Tags)
+		return e
+	}))
+}
+
+func (c *client) PushMetrics(ctx context.Context, metrics []Metric) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+	return c.push(ctx, metricsPath, lo.Map(metrics, func(m Metric, _ int) Metric {
+		m.Tags = c.tagged(m.Tags)
+		return m
+	}))
+}
+
+func (c *client) PushTraces(ctx context.Context, spans []Span) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	return c.push(ctx, tracesPath, lo.Map(spans, func(s Span, _ int) Span {
+		s.Tags = c.tagged(s.Tags)
+		return s
+	}))
+}
+
+// tagged adds client tags to the tags of the item, tags of the item take precedence
+func (c *client) tagged(tags Tags) Tags {
+	if len(c.tags) == 0 {
+		return tags
+	}
+	return lo.Assign(c.tags, tags)
+}
+
+func (c *client) push(ctx context.Context, path string, items any) error {
+	data, err := json.Marshal(items)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal %s payload", path)
+	}
+	if !c.allow() {
+		return errors.Wrapf(ErrCircuitOpen, "failed to push to %s", path)
+	}
+	pushCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	err = c.send(pushCtx, path, data)
+	// pushes cancelled by the caller tell nothing about the endpoint
+	if ctx.Err() == nil {
+		c.record(err != nil && !errors.Is(err, errRejected))
+	}
+	return err
+}
+
+// errRejected marks client errors of the endpoint, e.g. invalid token, which do not open the circuit
+var errRejected = errors.New("rejected")
+
+func (c *client) send(ctx context.Context, path string, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURI+path, bytes.NewReader(data))
+	if err != nil {
+		return errors.Wrapf(err, "failed to create %s request", path)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to push to %s", path)
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		err := errors.Errorf("failed to push to %s: %s", path, strings.TrimSpace(fmt.Sprintf("%d %s", res.StatusCode, body)))
+		if res.StatusCode < http.StatusInternalServerError && res.StatusCode != http.StatusTooManyRequests {
+			return &rejectedError{err}
+		}
+		return err
+	}
+	_, _ = io.Copy(io.Discard, res.Body)
+	return nil
+}
+
+type rejectedError struct {
+	error
+}
+
+func (e *rejectedError) Is(target error) bool {
+	return target == errRejected
+}
+
+func (c *client) allow() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.failureThreshold < 1 || c.failures < c.failureThreshold {
+		return true
+	}
+	if time.Now().Before(c.openUntil) {
+		return false
+	}
+	// half-open: let one push through and re-open circuit until it completes
+	c.openUntil = time.Now().Add(c.cooldown)
+	return true
+}
+
+func (c *client) record(failed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !failed {
+		c.failures = 0
+		return
+	}
+	c.failures++
+	if c.failures == c.failureThreshold {
+		c.logger.Warnf(context.Background(), "observatory circuit breaker is open for %s after %d failures", c.cooldown, c.failures)
+	}
+	if c.failureThreshold > 0 && c.failures >= c.failureThreshold {
+		c.openUntil = time.Now().Add(c.cooldown)
+	}
+}
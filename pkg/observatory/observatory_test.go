@@ -0,0 +1,173 @@
+package observatory_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/observatory"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+type push struct {
+	path  string
+	auth  string
+	items []map[string]any
+}
+
+type fakeObservatory struct {
+	*httptest.Server
+	status int
+
+	mu     sync.Mutex
+	pushes []push
+}
+
+func newFakeObservatory(t *testing.T) *fakeObservatory {
+	f := &fakeObservatory{status: http.StatusAccepted}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var items []map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&items))
+		f.mu.Lock()
+		f.pushes = append(f.pushes, push{path: r.URL.Path, auth: r.Header.Get("Authorization"), items: items})
+		f.mu.Unlock()
+		w.WriteHeader(f.status)
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeObservatory) pushed(path string) []push {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var res []push
+	for _, p := range f.pushes {
+		if p.path == path {
+			res = append(res, p)
+		}
+	}
+	return res
+}
+
+func TestNew(t *testing.T) {
+	for _, uri := range []string{"", "observatory.local", "ftp://observatory.local", "http://"} {
+		_, err := observatory.New(uri)
+		assert.Error(t, err, uri)
+	}
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeObservatory(t)
+	client, err := observatory.New(server.URL+"/", observatory.WithToken("t0ken"), observatory.WithModule("billing", ""),
+		observatory.WithTags(observatory.Tags{"env": "test"}))
+	require.NoError(t, err)
+
+	require.NoError(t, client.PushLogs(ctx, []observatory.LogEntry{{Level: logger.Info, Message: "hello", Tags: observatory.Tags{"env": "override"}}}))
+	require.NoError(t, client.PushMetrics(ctx, []observatory.Metric{{Name: "jobs", Value: 3}}))
+	require.NoError(t, client.PushTraces(ctx, []observatory.Span{{TraceID: "t", SpanID: "s", Name: "job"}}))
+	require.NoError(t, client.PushLogs(ctx, nil), "empty push must be skipped")
+
+	logs := server.pushed("/api/v1/logs")
+	require.Len(t, logs, 1)
+	assert.Equal(t, "Bearer t0ken", logs[0].auth)
+	assert.Equal(t, map[string]any{"module": "billing", "env": "override"}, logs[0].items[0]["tags"])
+	metrics := server.pushed("/api/v1/metrics")
+	require.Len(t, metrics, 1)
+	assert.Equal(t, map[string]any{"module": "billing", "env": "test"}, metrics[0].items[0]["tags"])
+	assert.Len(t, server.pushed("/api/v1/traces"), 1)
+
+	server.status = http.StatusUnauthorized
+	assert.EqualError(t, client.PushMetrics(ctx, []observatory.Metric{{Name: "jobs"}}), "failed to push to /api/v1/metrics: 401")
+}
+
+func TestWithObservatory(t *testing.T) {
+	server := newFakeObservatory(t)
+	h := servicetest.New(t, service.WithObservatory(server.URL, observatory.WithModule("shop", "api")),
+		service.WithRoutes(func(router service.HttpAdapterRouter) error {
+			router.GET("/api/items", func(c service.HttpAdapter) error {
+				c.JSON(http.StatusTeapot, service.M{})
+				return nil
+			})
+			return nil
+		}))
+
+	res := h.Invoke(http.MethodGet, "/api/items", nil, nil)
+	require.Equal(t, http.StatusTeapot, res.StatusCode)
+
+	metrics := server.pushed("/api/v1/metrics")
+	require.Len(t, metrics, 1)
+	assert.Equal(t, "request_duration", metrics[0].items[0]["name"])
+	assert.Equal(t, map[string]any{"module": "shop", "submodule": "api", "method": "GET", "status": "418"}, metrics[0].items[0]["tags"])
+	assert.NotEmpty(t, server.pushed("/api/v1/logs"), "service logs must be pushed")
+}
+
+func TestTagRequest(t *testing.T) {
+	server := newFakeObservatory(t)
+	h := servicetest.New(t, service.WithObservatory(server.URL),
+		service.WithRoutes(func(router service.HttpAdapterRouter) error {
+			router.GET("/api/items", func(c service.HttpAdapter) error {
+				observatory.TagRequest(c.Context(), observatory.Tags{"tier": "gold", "status": "ignored"})
+				c.JSON(http.StatusOK, service.M{})
+				return nil
+			})
+			return nil
+		}))
+
+	require.Equal(t, http.StatusOK, h.Invoke(http.MethodGet, "/api/items", nil, nil).StatusCode)
+
+	metrics := server.pushed("/api/v1/metrics")
+	require.Len(t, metrics, 1)
+	assert.Equal(t, map[string]any{"tier": "gold", "method": "GET", "status": "200"}, metrics[0].items[0]["tags"])
+	// tags outside of the middleware are dropped
+	observatory.TagRequest(context.Background(), observatory.Tags{"tier": "gold"})
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeObservatory(t)
+	server.status = http.StatusServiceUnavailable
+	client, err := observatory.New(server.URL, observatory.WithCircuitBreaker(2, time.Hour))
+	require.NoError(t, err)
+
+	metrics := []observatory.Metric{{Name: "jobs"}}
+	assert.NotErrorIs(t, client.PushMetrics(ctx, metrics), observatory.ErrCircuitOpen)
+	assert.NotErrorIs(t, client.PushMetrics(ctx, metrics), observatory.ErrCircuitOpen)
+	assert.ErrorIs(t, client.PushMetrics(ctx, metrics), observatory.ErrCircuitOpen)
+	assert.Len(t, server.pushed("/api/v1/metrics"), 2, "open circuit must not reach the endpoint")
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	rejected, err := observatory.New(server.URL, observatory.WithCircuitBreaker(1, time.Hour))
+	require.NoError(t, err)
+	assert.Error(t, rejected.PushMetrics(cancelled, metrics))
+	server.status = http.StatusUnauthorized
+	assert.Error(t, rejected.PushMetrics(ctx, metrics))
+	assert.NotErrorIs(t, rejected.PushMetrics(ctx, metrics), observatory.ErrCircuitOpen,
+		"cancelled pushes and rejected requests must not open the circuit")
+}
+
+func TestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+	client, err := observatory.New(server.URL, observatory.WithTimeout(20*time.Millisecond),
+		observatory.WithHTTPClient(&http.Client{}))
+	require.NoError(t, err)
+
+	startedAt := time.Now()
+	assert.ErrorIs(t, client.PushLogs(context.Background(), []observatory.LogEntry{{Message: "hello"}}), context.DeadlineExceeded)
+	assert.Less(t, time.Since(startedAt), time.Second)
+}
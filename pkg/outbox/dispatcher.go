@@ -0,0 +1,117 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	evbus "github.com/simple-container-com/go-aws-lambda-sdk/pkg/events"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/queue"
+)
+
+// PublishFunc delivers outbox record to its destination
+type PublishFunc func(ctx context.Context, record Record) error
+
+// Dispatcher delivers outbox records from DynamoDB stream of the outbox table, the stream must include
+// new images and the event source mapping must have ReportBatchItemFailures enabled
+type Dispatcher struct {
+	publish PublishFunc
+	logger  logger.Logger
+}
+
+func NewDispatcher(publish PublishFunc, log logger.Logger) *Dispatcher {
+	if log == nil {
+		log = logger.NewLogger()
+	}
+	return &Dispatcher{
+		publish: publish,
+		logger:  log,
+	}
+}
+
+// Handle is the lambda handler of the stream, delivery stops on the first failure to keep order of the records:
+// lambda retries the batch starting from the reported record (so delivery is at least once). Malformed records
+// are logged and skipped since retrying them would block the stream
+func (d *Dispatcher) Handle(ctx context.Context, event events.DynamoDBEvent) (events.DynamoDBEventResponse, error) {
+	var res events.DynamoDBEventResponse
+	for _, streamRecord := range event.Records {
+		if streamRecord.EventName != string(events.DynamoDBOperationTypeInsert) {
+			// updates and TTL removals of outbox items are not events
+			continue
+		}
+		record, err := recordOf(streamRecord.Change.NewImage)
+		if err != nil {
+			d.logger.Errorf(ctx, "skipping malformed outbox record %s: %v", streamRecord.EventID, err)
+			continue
+		}
+		recordCtx := d.logger.WithValues(ctx, map[string]any{
+			"outboxId":   record.ID,
+			"topic":      record.Topic,
+			"requestUID": record.RequestUID,
+		})
+		if err := d.publish(recordCtx, record); err != nil {
+			d.logger.Errorf(recordCtx, "failed to dispatch outbox record: %v", err)
+			res.BatchItemFailures = append(res.BatchItemFailures, events.DynamoDBBatchItemFailure{
+				ItemIdentifier: streamRecord.Change.SequenceNumber,
+			})
+			return res, nil
+		}
+	}
+	return res, nil
+}
+
+func recordOf(image map[string]events.DynamoDBAttributeValue) (Record, error) {
+	str := func(name string) string {
+		if v, ok := image[name]; ok && v.DataType() == events.DataTypeString {
+			return v.String()
+		}
+		return ""
+	}
+	record := Record{
+		ID:         str("id"),
+		Topic:      str("topic"),
+		Payload:    json.RawMessage(str("payload")),
+		RequestUID: str("requestUID"),
+	}
+	if record.ID == "" || record.Topic == "" {
+		return record, errors.Errorf("outbox record must have id and topic, make sure stream includes new images")
+	}
+	if !json.Valid(record.Payload) {
+		return record, errors.Errorf("payload of outbox record %s is not valid JSON", record.ID)
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, str("createdAt"))
+	if err != nil {
+		return record, errors.Wrapf(err, "invalid creation time of outbox record %s", record.ID)
+	}
+	record.CreatedAt = createdAt
+	return record, nil
+}
+
+// EventBridgePublisher publishes records to EventBridge using topic as detail type
+func EventBridgePublisher(p evbus.Publisher) PublishFunc {
+	return func(ctx context.Context, record Record) error {
+		if err := p.Publish(ctx, record.Topic, record.Payload); err != nil {
+			return err
+		}
+		return p.Flush(ctx)
+	}
+}
+
+// QueuePublisher sends records to SQS, outbox id is used as deduplication id of FIFO queues
+func QueuePublisher(p queue.Producer) PublishFunc {
+	return func(ctx context.Context, record Record) error {
+		return p.Send(ctx, queue.Message{
+			Body:            string(record.Payload),
+			GroupID:         record.Topic,
+			DeduplicationID: record.ID,
+			Attributes: map[string]string{
+				"topic": record.Topic,
+			},
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package outbox
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+func streamRecord(seq, id string, payload string) events.DynamoDBEventRecord {
+	image := map[string]events.DynamoDBAttributeValue{
+		"topic":     events.NewStringAttribute("orders"),
+		"payload":   events.NewStringAttribute(payload),
+		"createdAt": events.NewStringAttribute(time.Now().UTC().Format(time.RFC3339Nano)),
+	}
+	if id != "" {
+		image["id"] = events.NewStringAttribute(id)
+	}
+	return events.DynamoDBEventRecord{
+		EventID:   "event-" + seq,
+		EventName: string(events.DynamoDBOperationTypeInsert),
+		Change: events.DynamoDBStreamRecord{
+			SequenceNumber: seq,
+			NewImage:       image,
+		},
+	}
+}
+
+func TestDispatcherHandle(t *testing.T) {
+	removal := streamRecord("0", "removed", `{}`)
+	removal.EventName = string(events.DynamoDBOperationTypeRemove)
+	tests := []struct {
+		name          string
+		records       []events.DynamoDBEventRecord
+		failID        string
+		wantPublished []string
+		wantFailures  []string
+	}{
+		{
+			name:          "all published",
+			records:       []events.DynamoDBEventRecord{removal, streamRecord("1", "a", `{}`), streamRecord("2", "b", `{}`)},
+			wantPublished: []string{"a", "b"},
+		},
+		{
+			name: "malformed records are skipped",
+			records: []events.DynamoDBEventRecord{
+				streamRecord("1", "", `{}`),
+				streamRecord("2", "a", `not json`),
+				streamRecord("3", "b", `{}`),
+			},
+			wantPublished: []string{"b"},
+		},
+		{
+			name:          "publish failure stops the batch",
+			records:       []events.DynamoDBEventRecord{streamRecord("1", "a", `{}`), streamRecord("2", "b", `{}`), streamRecord("3", "c", `{}`)},
+			failID:        "b",
+			wantPublished: []string{"a"},
+			wantFailures:  []string{"2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var published []string
+			d := NewDispatcher(func(ctx context.Context, record Record) error {
+				if record.ID == tt.failID {
+					return errors.New("unavailable")
+				}
+				published = append(published, record.ID)
+				return nil
+			}, nil)
+
+			res, err := d.Handle(context.Background(), events.DynamoDBEvent{Records: tt.records})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPublished, published)
+			var failures []string
+			for _, failure := range res.BatchItemFailures {
+				failures = append(failures, failure.ItemIdentifier)
+			}
+			assert.Equal(t, tt.wantFailures, failures)
+		})
+	}
+}
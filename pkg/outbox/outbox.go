@@ -0,0 +1,148 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+)
+
+const (
+	defaultTTL = 7 * 24 * time.Hour
+	// TransactWriteItems limit
+	maxTransactItems = 100
+)
+
+// Event is enqueued together with the state change and delivered by Dispatcher once the transaction commits
+type Event struct {
+	Topic   string `json:"topic" yaml:"topic"`
+	Payload any    `json:"payload" yaml:"payload"`
+}
+
+// Record is the outbox item as it is stored in the table (hash key "id")
+type Record struct {
+	ID         string          `json:"id" yaml:"id"`
+	Topic      string          `json:"topic" yaml:"topic"`
+	Payload    json.RawMessage `json:"payload" yaml:"payload"`
+	CreatedAt  time.Time       `json:"createdAt" yaml:"createdAt"`
+	RequestUID string          `json:"requestUID,omitempty" yaml:"requestUID,omitempty"`
+}
+
+type Writer interface {
+	// Item builds transaction item putting the event into outbox table, so that it can be
+	// added to the caller's own TransactWriteItems call
+	Item(ctx context.Context, event Event) (*dynamodb.TransactWriteItem, error)
+	// Write applies state changes and puts events into outbox table in a single transaction
+	Write(ctx context.Context, state []*dynamodb.TransactWriteItem, events ...Event) error
+}
+
+type (
+	Option func(*writer)
+)
+
+type writer struct {
+	client dynamodbiface.DynamoDBAPI
+	table  string
+	ttl    time.Duration
+	logger logger.Logger
+}
+
+func WithClient(client dynamodbiface.DynamoDBAPI) Option {
+	return func(w *writer) {
+		w.client = client
+	}
+}
+
+// WithTTL sets expiration of outbox items, table TTL must be enabled on "ttl" attribute
+func WithTTL(ttl time.Duration) Option {
+	return func(w *writer) {
+		w.ttl = ttl
+	}
+}
+
+func WithLogger(logger logger.Logger) Option {
+	return func(w *writer) {
+		w.logger = logger
+	}
+}
+
+func NewWriter(table string, opts ...Option) (Writer, error) {
+	w := &writer{
+		table:  table,
+		ttl:    defaultTTL,
+		logger: logger.NewLogger(),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	if w.table == "" {
+		return nil, errors.Errorf("outbox table must be set")
+	}
+	if w.client == nil {
+		sess, err := session.NewSession()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to init aws session")
+		}
+		w.client = dynamodb.New(sess)
+	}
+	return w, nil
+}
+
+func (w *writer) Item(ctx context.Context, event Event) (*dynamodb.TransactWriteItem, error) {
+	if event.Topic == "" {
+		return nil, errors.Errorf("event topic must be set")
+	}
+	payload, err := json.Marshal(event.Payload)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal payload of %q", event.Topic)
+	}
+	now := time.Now().UTC()
+	item := map[string]*dynamodb.AttributeValue{
+		"id":        {S: aws.String(uuid.NewString())},
+		"topic":     {S: aws.String(event.Topic)},
+		"payload":   {S: aws.String(string(payload))},
+		"createdAt": {S: aws.String(now.Format(time.RFC3339Nano))},
+		"ttl":       {N: aws.String(strconv.FormatInt(now.Add(w.ttl).Unix(), 10))},
+	}
+	if requestUID, ok := w.logger.GetValue(ctx, service.RequestUIDKey).(string); ok {
+		item["requestUID"] = &dynamodb.AttributeValue{S: aws.String(requestUID)}
+	}
+	return &dynamodb.TransactWriteItem{
+		Put: &dynamodb.Put{
+			TableName:           aws.String(w.table),
+			Item:                item,
+			ConditionExpression: aws.String("attribute_not_exists(id)"),
+		},
+	}, nil
+}
+
+func (w *writer) Write(ctx context.Context, state []*dynamodb.TransactWriteItem, events ...Event) error {
+	if len(state)+len(events) > maxTransactItems {
+		return errors.Errorf("transaction of %d items exceeds limit of %d", len(state)+len(events), maxTransactItems)
+	}
+	items := append([]*dynamodb.TransactWriteItem{}, state...)
+	for _, event := range events {
+		item, err := w.Item(ctx, event)
+		if err != nil {
+			return err
+		}
+		items = append(items, item)
+	}
+	if _, err := w.client.TransactWriteItemsWithContext(ctx, &dynamodb.TransactWriteItemsInput{
+		TransactItems: items,
+	}); err != nil {
+		return errors.Wrapf(err, "failed to write %d state items with %d outbox events", len(state), len(events))
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+)
+
+const (
+	CursorParam  = "cursor"
+	LimitParam   = "limit"
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// ListResult is a response envelope shared by list endpoints
+type ListResult[T any] struct {
+	Items      []T                `json:"items" yaml:"items"`
+	NextCursor string             `json:"nextCursor,omitempty" yaml:"nextCursor,omitempty"`
+	PrevCursor string             `json:"prevCursor,omitempty" yaml:"prevCursor,omitempty"`
+	Limit      int                `json:"limit" yaml:"limit"`
+	Meta       service.ResultMeta `json:"meta" yaml:"meta"`
+}
+
+// Params are pagination parameters of the list request
+type Params struct {
+	Cursor string
+	Limit  int
+}
+
+type Option func(*config)
+
+type config struct {
+	defaultLimit int
+	maxLimit     int
+}
+
+func WithDefaultLimit(limit int) Option {
+	return func(c *config) {
+		c.defaultLimit = limit
+	}
+}
+
+func WithMaxLimit(limit int) Option {
+	return func(c *config) {
+		c.maxLimit = limit
+	}
+}
+
+// Encode turns any json-serializable position (e.g. DynamoDB LastEvaluatedKey) into opaque url-safe cursor
+func Encode(position any) (string, error) {
+	if position == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(position)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to encode cursor")
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// Decode reads position encoded by Encode into v, empty cursor leaves v untouched
+func Decode(cursor string, v any) error {
+	if cursor == "" {
+		return nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return errors.Wrapf(err, "malformed cursor")
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return errors.Wrapf(err, "malformed cursor")
+	}
+	return nil
+}
+
+// ClampLimit returns defaultLimit for non-positive limit and caps it at maxLimit
+func ClampLimit(limit, defaultLimit, maxLimit int) int {
+	if limit <= 0 {
+		return defaultLimit
+	}
+	return min(limit, maxLimit)
+}
+
+// FromRequest reads cursor and limit query parameters of the request
+func FromRequest(c service.HttpAdapter, opts ...Option) Params {
+	cfg := config{
+		defaultLimit: DefaultLimit,
+		maxLimit:     MaxLimit,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	limit, _ := strconv.Atoi(c.Query(LimitParam))
+	return Params{
+		Cursor: c.Query(CursorParam),
+		Limit:  ClampLimit(limit, cfg.defaultLimit, cfg.maxLimit),
+	}
+}
+
+// LinkHeader builds RFC 8288 Link header value with next and prev relations of the request url,
+// use service.OriginalURL so that links keep base path and stage stripped before routing
+func LinkHeader(u *url.URL, limit int, next, prev string) string {
+	var links []string
+	for _, link := range []struct {
+		rel    string
+		cursor string
+	}{{"next", next}, {"prev", prev}} {
+		if link.cursor == "" {
+			continue
+		}
+		linkURL := *u
+		query := linkURL.Query()
+		query.Set(CursorParam, link.cursor)
+		query.Set(LimitParam, strconv.Itoa(limit))
+		linkURL.RawQuery = query.Encode()
+		links = append(links, fmt.Sprintf("<%s>; rel=%q", linkURL.String(), link.rel))
+	}
+	return strings.Join(links, ", ")
+}
+
+// Respond writes list result with meta of the request and sets Link header
+func Respond[T any](c service.HttpAdapter, svc service.Service, items []T, params Params, next, prev string) {
+	if link := LinkHeader(service.OriginalURL(c.Request()), params.Limit, next, prev); link != "" {
+		c.SetHeader("Link", link)
+	}
+	c.JSON(http.StatusOK, ListResult[T]{
+		Items:      lo.Ternary(items == nil, []T{}, items),
+		NextCursor: next,
+		PrevCursor: prev,
+		Limit:      params.Limit,
+		Meta:       svc.GetMeta(c.Context()),
+	})
+}
@@ -0,0 +1,74 @@
+package pagination
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+type position struct {
+	ID    string `json:"id"`
+	Score int    `json:"score"`
+}
+
+func TestCursor(t *testing.T) {
+	cursor, err := Encode(position{ID: "a/b+c", Score: 42})
+	assert.NoError(t, err)
+	assert.NotContains(t, cursor, "/")
+
+	var decoded position
+	assert.NoError(t, Decode(cursor, &decoded))
+	assert.Equal(t, position{ID: "a/b+c", Score: 42}, decoded)
+
+	assert.Error(t, Decode("not a cursor!", &decoded))
+}
+
+func TestClampLimit(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit int
+		want  int
+	}{
+		{name: "default for zero", limit: 0, want: 20},
+		{name: "default for negative", limit: -5, want: 20},
+		{name: "within bounds", limit: 50, want: 50},
+		{name: "capped at max", limit: 500, want: 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ClampLimit(tt.limit, DefaultLimit, MaxLimit))
+		})
+	}
+}
+
+func TestLinkHeader(t *testing.T) {
+	u, _ := url.Parse("https://api.example.com/api/items?filter=x&cursor=old")
+	assert.Equal(t,
+		`<https://api.example.com/api/items?cursor=n&filter=x&limit=10>; rel="next", <https://api.example.com/api/items?cursor=p&filter=x&limit=10>; rel="prev"`,
+		LinkHeader(u, 10, "n", "p"))
+	assert.Empty(t, LinkHeader(u, 10, "", ""))
+}
+
+func TestRespondLinksOriginalPath(t *testing.T) {
+	var h *servicetest.Harness
+	h = servicetest.New(t, service.WithBasePath("/shop"), service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		api := router.Group("/api")
+		return service.Versioned(api, "v1", func(router service.HttpAdapterRouter) error {
+			router.GET("/items", func(c service.HttpAdapter) error {
+				Respond(c, h.Service, []string{"a"}, FromRequest(c), "n", "")
+				return nil
+			})
+			return nil
+		})
+	}))
+
+	res := h.Invoke(http.MethodGet, "/shop/api/items?limit=5", nil, map[string]string{service.AcceptVersionHeader: "v1"})
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, `</shop/api/items?cursor=n&limit=5>; rel="next"`, res.Headers.Get("Link"))
+}
@@ -0,0 +1,371 @@
+package queue
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+)
+
+const (
+	// SendMessageBatch limits
+	maxBatchEntries = 10
+	maxMessageBytes = 256 * 1024
+
+	// offloaded payloads use format of Amazon SQS Extended Client Library so that its consumers can read them
+	extendedPayloadSizeAttribute = "ExtendedPayloadSize"
+	s3PointerClass               = "software.amazon.payloadoffloading.PayloadS3Pointer"
+)
+
+type Producer interface {
+	Send(ctx context.Context, msg Message) error
+	SendBatch(ctx context.Context, msgs []Message) error
+}
+
+// Message body is sent as is when it is string or []byte and as JSON otherwise
+type Message struct {
+	Body            any               `json:"body" yaml:"body"`
+	GroupID         string            `json:"groupId,omitempty" yaml:"groupId,omitempty"`                 // FIFO only, default group is used when empty
+	DeduplicationID string            `json:"deduplicationId,omitempty" yaml:"deduplicationId,omitempty"` // FIFO only, hash of the body is used when empty
+	Attributes      map[string]string `json:"attributes,omitempty" yaml:"attributes,omitempty"`
+	DelaySeconds    int64             `json:"delaySeconds,omitempty" yaml:"delaySeconds,omitempty"` // standard queues only
+}
+
+type (
+	Option func(*producer)
+)
+
+type producer struct {
+	sqs            sqsiface.SQSAPI
+	s3             s3iface.S3API
+	queueURL       string
+	offloadBucket  string
+	offloadPrefix  string
+	defaultGroupID string
+	logger         logger.Logger
+	dryRun         bool
+}
+
+func WithSQS(client sqsiface.SQSAPI) Option {
+	return func(p *producer) {
+		p.sqs = client
+	}
+}
+
+func WithS3(client s3iface.S3API) Option {
+	return func(p *producer) {
+		p.s3 = client
+	}
+}
+
+// WithPayloadOffloading stores bodies exceeding 256KB in the bucket and sends pointer to the object instead
+func WithPayloadOffloading(bucket, prefix string) Option {
+	return func(p *producer) {
+		p.offloadBucket = bucket
+		p.offloadPrefix = prefix
+	}
+}
+
+// WithDefaultGroupID sets message group of FIFO messages which don't specify one
+func WithDefaultGroupID(groupID string) Option {
+	return func(p *producer) {
+		p.defaultGroupID = groupID
+	}
+}
+
+func WithLogger(logger logger.Logger) Option {
+	return func(p *producer) {
+		p.logger = logger
+	}
+}
+
+// WithDryRun makes producer log messages instead of sending them
+func WithDryRun() Option {
+	return func(p *producer) {
+		p.dryRun = true
+	}
+}
+
+func New(queueURL string, opts ...Option) (Producer, error) {
+	if os.Getenv("LOCAL_DEBUG") == "true" {
+		opts = append([]Option{WithDryRun()}, opts...)
+	}
+
+	p := &producer{
+		queueURL: queueURL,
+		logger:   logger.NewLogger(),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.queueURL == "" {
+		return nil, errors.Errorf("queue url must be set")
+	}
+
+	if !p.dryRun && (p.sqs == nil || p.s3 == nil && p.offloadBucket != "") {
+		sess, err := session.NewSession()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to init aws session")
+		}
+		if p.sqs == nil {
+			p.sqs = sqs.New(sess)
+		}
+		if p.s3 == nil && p.offloadBucket != "" {
+			p.s3 = s3.New(sess)
+		}
+	}
+	return p, nil
+}
+
+func (p *producer) fifo() bool {
+	return strings.HasSuffix(p.queueURL, ".fifo")
+}
+
+func (p *producer) Send(ctx context.Context, msg Message) error {
+	return p.SendBatch(ctx, []Message{msg})
+}
+
+// BatchError is returned by SendBatch when some of the messages were not sent, other messages were delivered
+type BatchError struct {
+	Failed []int // indexes of messages which were not sent
+	Total  int
+	Err    error // first failure
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("failed to send %d of %d messages: %v", len(e.Failed), e.Total, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+type batchEntry struct {
+	index int
+	entry *sqs.SendMessageBatchRequestEntry
+	// offload holds body exceeding SQS limit until it is stored in S3, offloadKey is set afterwards
+	offload    []byte
+	offloadKey string
+}
+
+// SendBatch sends messages in batches of up to 10 messages and 256KB. Nothing is sent when any of the messages
+// is invalid, failure to send some of the messages results in *BatchError with indexes of these messages
+func (p *producer) SendBatch(ctx context.Context, msgs []Message) error {
+	entries := make([]*batchEntry, 0, len(msgs))
+	for i, msg := range msgs {
+		entry, err := p.entry(strconv.Itoa(i), msg)
+		if err != nil {
+			return errors.Wrapf(err, "invalid message %d", i)
+		}
+		entry.index = i
+		entries = append(entries, entry)
+	}
+
+	batchErr := &BatchError{Total: len(msgs)}
+	fail := func(entries []*batchEntry, err error) {
+		if batchErr.Err == nil {
+			batchErr.Err = err
+		}
+		for _, e := range entries {
+			batchErr.Failed = append(batchErr.Failed, e.index)
+			// pointer was never delivered, nobody is going to read the payload
+			p.deleteOffloaded(ctx, e)
+		}
+	}
+	var batch []*batchEntry
+	batchSize := 0
+	flush := func() {
+		if failed, err := p.send(ctx, batch); err != nil {
+			fail(failed, err)
+		}
+		batch, batchSize = nil, 0
+	}
+	for _, e := range entries {
+		if e.offload != nil {
+			if err := p.offload(ctx, e); err != nil {
+				fail([]*batchEntry{e}, err)
+				continue
+			}
+		}
+		size := entrySize(e.entry)
+		if len(batch) == maxBatchEntries || batchSize+size > maxMessageBytes {
+			flush()
+		}
+		batch = append(batch, e)
+		batchSize += size
+	}
+	flush()
+	if len(batchErr.Failed) > 0 {
+		sort.Ints(batchErr.Failed)
+		return batchErr
+	}
+	return nil
+}
+
+// entry builds request entry of the message, body exceeding SQS limit is left for offloading
+func (p *producer) entry(id string, msg Message) (*batchEntry, error) {
+	body, err := encodeBody(msg.Body)
+	if err != nil {
+		return nil, err
+	}
+	entry := &sqs.SendMessageBatchRequestEntry{
+		Id:                aws.String(id),
+		MessageAttributes: map[string]*sqs.MessageAttributeValue{},
+	}
+	for name, value := range msg.Attributes {
+		entry.MessageAttributes[name] = &sqs.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(value)}
+	}
+	if p.fifo() {
+		groupID := lo.CoalesceOrEmpty(msg.GroupID, p.defaultGroupID)
+		if groupID == "" {
+			return nil, errors.Errorf("message group id must be set for FIFO queue")
+		}
+		entry.MessageGroupId = aws.String(groupID)
+		if msg.DeduplicationID == "" {
+			sum := sha256.Sum256(body)
+			msg.DeduplicationID = hex.EncodeToString(sum[:])
+		}
+		entry.MessageDeduplicationId = aws.String(msg.DeduplicationID)
+	} else if msg.DelaySeconds > 0 {
+		entry.DelaySeconds = aws.Int64(msg.DelaySeconds)
+	}
+
+	entry.MessageBody = aws.String(string(body))
+	res := &batchEntry{entry: entry}
+	if entrySize(entry) > maxMessageBytes {
+		if p.offloadBucket == "" {
+			return nil, errors.Errorf("message of %d bytes exceeds SQS limit and payload offloading is not configured", len(body))
+		}
+		res.offload = body
+	}
+	return res, nil
+}
+
+// offload stores body in S3 and replaces it with pointer to the object
+func (p *producer) offload(ctx context.Context, e *batchEntry) error {
+	key := path.Join(p.offloadPrefix, uuid.NewString())
+	if p.dryRun {
+		p.logger.Infof(ctx, "dry-run: skip offloading %d bytes to s3://%s/%s", len(e.offload), p.offloadBucket, key)
+	} else if _, err := p.s3.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(p.offloadBucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(e.offload),
+	}); err != nil {
+		return errors.Wrapf(err, "failed to offload message payload to s3://%s/%s", p.offloadBucket, key)
+	}
+	pointer, err := json.Marshal([]any{s3PointerClass, map[string]string{
+		"s3BucketName": p.offloadBucket,
+		"s3Key":        key,
+	}})
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal payload pointer")
+	}
+	e.offloadKey = key
+	e.entry.MessageBody = aws.String(string(pointer))
+	e.entry.MessageAttributes[extendedPayloadSizeAttribute] = &sqs.MessageAttributeValue{
+		DataType:    aws.String("Number"),
+		StringValue: aws.String(strconv.Itoa(len(e.offload))),
+	}
+	return nil
+}
+
+func (p *producer) deleteOffloaded(ctx context.Context, e *batchEntry) {
+	if e.offloadKey == "" || p.dryRun {
+		return
+	}
+	if _, err := p.s3.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(p.offloadBucket),
+		Key:    aws.String(e.offloadKey),
+	}); err != nil {
+		p.logger.Warnf(ctx, "failed to delete payload of unsent message s3://%s/%s: %v", p.offloadBucket, e.offloadKey, err)
+	}
+}
+
+// send returns entries which were not sent and the reason
+func (p *producer) send(ctx context.Context, batch []*batchEntry) ([]*batchEntry, error) {
+	if len(batch) == 0 {
+		return nil, nil
+	}
+	ctx = p.logger.WithValues(ctx, map[string]any{
+		"queueUrl": p.queueURL,
+		"messages": len(batch),
+	})
+	if p.dryRun {
+		// bodies may contain personal data, so only their sizes are logged
+		p.logger.Infof(p.logger.WithValue(ctx, "entries", lo.Map(batch, func(e *batchEntry, _ int) map[string]any {
+			return map[string]any{
+				"id":         aws.StringValue(e.entry.Id),
+				"bytes":      entrySize(e.entry),
+				"attributes": lo.Keys(e.entry.MessageAttributes),
+			}
+		})), "dry-run: skip sending messages")
+		return nil, nil
+	}
+	out, err := p.sqs.SendMessageBatchWithContext(ctx, &sqs.SendMessageBatchInput{
+		QueueUrl: aws.String(p.queueURL),
+		Entries:  lo.Map(batch, func(e *batchEntry, _ int) *sqs.SendMessageBatchRequestEntry { return e.entry }),
+	})
+	if err != nil {
+		p.logger.Errorf(ctx, "failed to send messages: %v", err)
+		return batch, errors.Wrapf(err, "failed to send %d messages", len(batch))
+	}
+	ctx = p.logger.WithValue(ctx, "messageIds", lo.Map(out.Successful, func(res *sqs.SendMessageBatchResultEntry, _ int) string {
+		return aws.StringValue(res.MessageId)
+	}))
+	if len(out.Failed) > 0 {
+		failures := lo.Map(out.Failed, func(res *sqs.BatchResultErrorEntry, _ int) string {
+			return fmt.Sprintf("%s: %s", aws.StringValue(res.Code), aws.StringValue(res.Message))
+		})
+		p.logger.Errorf(p.logger.WithValue(ctx, "failures", failures), "failed to send %d of %d messages", len(out.Failed), len(batch))
+		failedIDs := lo.Map(out.Failed, func(res *sqs.BatchResultErrorEntry, _ int) string { return aws.StringValue(res.Id) })
+		return lo.Filter(batch, func(e *batchEntry, _ int) bool {
+			return lo.Contains(failedIDs, aws.StringValue(e.entry.Id))
+		}), errors.New(failures[0])
+	}
+	p.logger.Infof(ctx, "sent %d messages", len(batch))
+	return nil, nil
+}
+
+func encodeBody(body any) ([]byte, error) {
+	switch b := body.(type) {
+	case string:
+		return []byte(b), nil
+	case []byte:
+		return b, nil
+	default:
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to marshal message body")
+		}
+		return data, nil
+	}
+}
+
+// entrySize counts body and attributes the way SQS does it for message size limit
+func entrySize(entry *sqs.SendMessageBatchRequestEntry) int {
+	size := len(aws.StringValue(entry.MessageBody))
+	for name, value := range entry.MessageAttributes {
+		size += len(name) + len(aws.StringValue(value.DataType)) + len(aws.StringValue(value.StringValue))
+	}
+	return size
+}
@@ -0,0 +1,150 @@
+package queue
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+)
+
+type fakeSQS struct {
+	sqsiface.SQSAPI
+	mu      sync.Mutex
+	batches [][]string
+	// failCall fails the whole call with the given number, failBody fails entries with the body
+	failCall int
+	failBody string
+}
+
+func (f *fakeSQS) SendMessageBatchWithContext(_ aws.Context, in *sqs.SendMessageBatchInput, _ ...request.Option) (*sqs.SendMessageBatchOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.batches = append(f.batches, lo.Map(in.Entries, func(e *sqs.SendMessageBatchRequestEntry, _ int) string {
+		return aws.StringValue(e.MessageBody)
+	}))
+	if len(f.batches) == f.failCall {
+		return nil, errors.New("throttled")
+	}
+	out := &sqs.SendMessageBatchOutput{}
+	for _, e := range in.Entries {
+		if f.failBody != "" && strings.Contains(aws.StringValue(e.MessageBody), f.failBody) {
+			out.Failed = append(out.Failed, &sqs.BatchResultErrorEntry{Id: e.Id, Code: aws.String("InternalError"), Message: aws.String("failed")})
+			continue
+		}
+		out.Successful = append(out.Successful, &sqs.SendMessageBatchResultEntry{Id: e.Id, MessageId: aws.String("m-" + aws.StringValue(e.Id))})
+	}
+	return out, nil
+}
+
+type fakeS3 struct {
+	s3iface.S3API
+	mu      sync.Mutex
+	objects map[string]bool
+}
+
+func (f *fakeS3) PutObjectWithContext(_ aws.Context, in *s3.PutObjectInput, _ ...request.Option) (*s3.PutObjectOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.objects[aws.StringValue(in.Key)] = true
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (f *fakeS3) DeleteObjectWithContext(_ aws.Context, in *s3.DeleteObjectInput, _ ...request.Option) (*s3.DeleteObjectOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.objects, aws.StringValue(in.Key))
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+func messages(n int) []Message {
+	return lo.Times(n, func(i int) Message {
+		return Message{Body: map[string]int{"n": i}}
+	})
+}
+
+func TestSendBatch(t *testing.T) {
+	t.Setenv("LOCAL_DEBUG", "")
+	large := Message{Body: strings.Repeat("x", maxMessageBytes+1)}
+	tests := []struct {
+		name        string
+		queueURL    string
+		msgs        []Message
+		failCall    int
+		failBody    string
+		wantErr     string
+		wantFailed  []int
+		wantBatches []int
+		wantObjects int
+	}{
+		{name: "split into batches", msgs: messages(25), wantBatches: []int{10, 10, 5}},
+		{
+			name:        "failed entries",
+			msgs:        messages(12),
+			failBody:    `"n":1`,
+			wantErr:     "failed to send 3 of 12 messages",
+			wantFailed:  []int{1, 10, 11},
+			wantBatches: []int{10, 2},
+		},
+		{
+			name:        "failed call does not stop other batches",
+			msgs:        messages(15),
+			failCall:    1,
+			wantErr:     "throttled",
+			wantFailed:  lo.Range(10),
+			wantBatches: []int{10, 5},
+		},
+		{name: "offloaded payload is kept", msgs: []Message{large}, wantBatches: []int{1}, wantObjects: 1},
+		{
+			name:        "offloaded payload of failed message is deleted",
+			msgs:        []Message{large},
+			failCall:    1,
+			wantErr:     "throttled",
+			wantFailed:  []int{0},
+			wantBatches: []int{1},
+		},
+		{
+			name:        "invalid message sends nothing",
+			queueURL:    "https://sqs.us-east-1.amazonaws.com/123456789012/queue.fifo",
+			msgs:        messages(3),
+			wantErr:     "invalid message 0: message group id must be set for FIFO queue",
+			wantBatches: []int{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqsClient := &fakeSQS{failCall: tt.failCall, failBody: tt.failBody}
+			s3Client := &fakeS3{objects: map[string]bool{}}
+			p, err := New(lo.CoalesceOrEmpty(tt.queueURL, "https://sqs.us-east-1.amazonaws.com/123456789012/queue"),
+				WithSQS(sqsClient), WithS3(s3Client), WithPayloadOffloading("bucket", "payloads"))
+			require.NoError(t, err)
+
+			err = p.SendBatch(context.Background(), tt.msgs)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tt.wantErr)
+			}
+			var batchErr *BatchError
+			if tt.wantFailed != nil {
+				require.ErrorAs(t, err, &batchErr)
+				assert.Equal(t, tt.wantFailed, batchErr.Failed)
+			} else {
+				assert.False(t, errors.As(err, &batchErr))
+			}
+			assert.Equal(t, tt.wantBatches, lo.Map(sqsClient.batches, func(batch []string, _ int) int { return len(batch) }))
+			assert.Len(t, s3Client.objects, tt.wantObjects)
+		})
+	}
+}
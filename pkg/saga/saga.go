@@ -0,0 +1,176 @@
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+)
+
+type Status string
+
+const (
+	StatusRunning      Status = "running"
+	StatusCompleted    Status = "completed"
+	StatusCompensating Status = "compensating"
+	StatusCompensated  Status = "compensated"
+)
+
+// Step of the saga, both actions may be re-run after a crash so they must be idempotent
+type Step[T any] struct {
+	Name       string
+	Do         func(ctx context.Context, data *T) error
+	Compensate func(ctx context.Context, data *T) error // optional
+}
+
+// Execution is the persisted state of the saga run
+type Execution[T any] struct {
+	ID        string    `json:"id" yaml:"id"`
+	Saga      string    `json:"saga" yaml:"saga"`
+	Status    Status    `json:"status" yaml:"status"`
+	Step      int       `json:"step" yaml:"step"` // next step to run or, when compensating, the last step to compensate + 1
+	Data      T         `json:"data" yaml:"data"`
+	Error     string    `json:"error,omitempty" yaml:"error,omitempty"` // error of the failed step
+	Version   int64     `json:"version" yaml:"version"`
+	UpdatedAt time.Time `json:"updatedAt" yaml:"updatedAt"`
+}
+
+type Saga[T any] struct {
+	name   string
+	steps  []Step[T]
+	store  Store
+	logger logger.Logger
+}
+
+func New[T any](name string, store Store, steps ...Step[T]) *Saga[T] {
+	return &Saga[T]{
+		name:   name,
+		steps:  steps,
+		store:  store,
+		logger: logger.NewLogger(),
+	}
+}
+
+// Run starts saga with the data or resumes existing execution with the same id (data is ignored then),
+// error is returned when saga failed and was compensated or when it could not be persisted/compensated
+func (s *Saga[T]) Run(ctx context.Context, id string, data T) (*Execution[T], error) {
+	exec, err := s.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if exec == nil {
+		exec = &Execution[T]{
+			ID:     id,
+			Saga:   s.name,
+			Status: StatusRunning,
+			Data:   data,
+		}
+		if err := s.save(ctx, exec); err != nil {
+			return nil, err
+		}
+	}
+	return s.resume(ctx, exec)
+}
+
+// Resume continues previously started execution
+func (s *Saga[T]) Resume(ctx context.Context, id string) (*Execution[T], error) {
+	exec, err := s.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if exec == nil {
+		return nil, errors.Errorf("execution %s of saga %s is not found", id, s.name)
+	}
+	return s.resume(ctx, exec)
+}
+
+func (s *Saga[T]) resume(ctx context.Context, exec *Execution[T]) (*Execution[T], error) {
+	ctx = s.logger.WithValues(ctx, map[string]any{"saga": s.name, "sagaId": exec.ID})
+	for exec.Status == StatusRunning && exec.Step < len(s.steps) {
+		step := s.steps[exec.Step]
+		if err := step.Do(ctx, &exec.Data); err != nil {
+			s.logger.Warnf(ctx, "step %s failed, compensating: %v", step.Name, err)
+			exec.Status = StatusCompensating
+			exec.Error = errors.Wrapf(err, "step %s failed", step.Name).Error()
+		} else {
+			exec.Step++
+		}
+		if exec.Status == StatusRunning && exec.Step == len(s.steps) {
+			exec.Status = StatusCompleted
+		}
+		if err := s.save(ctx, exec); err != nil {
+			return exec, err
+		}
+	}
+	for exec.Status == StatusCompensating {
+		if exec.Step > 0 {
+			step := s.steps[exec.Step-1]
+			if step.Compensate != nil {
+				if err := step.Compensate(ctx, &exec.Data); err != nil {
+					// execution stays in compensating status so that it can be resumed later
+					return exec, errors.Wrapf(err, "failed to compensate step %s of saga %s", step.Name, s.name)
+				}
+			}
+			exec.Step--
+		}
+		if exec.Step == 0 {
+			exec.Status = StatusCompensated
+		}
+		if err := s.save(ctx, exec); err != nil {
+			return exec, err
+		}
+	}
+	if exec.Status == StatusCompensated {
+		return exec, errors.Errorf("saga %s is compensated: %s", s.name, exec.Error)
+	}
+	return exec, nil
+}
+
+func (s *Saga[T]) load(ctx context.Context, id string) (*Execution[T], error) {
+	stored, err := s.store.Load(ctx, id)
+	if err != nil || stored == nil {
+		return nil, err
+	}
+	exec := &Execution[T]{
+		ID:        stored.ID,
+		Saga:      stored.Saga,
+		Status:    stored.Status,
+		Step:      stored.Step,
+		Error:     stored.Error,
+		Version:   stored.Version,
+		UpdatedAt: stored.UpdatedAt,
+	}
+	if exec.Saga != s.name {
+		return nil, errors.Errorf("execution %s belongs to saga %s, not %s", id, exec.Saga, s.name)
+	}
+	if err := json.Unmarshal(stored.Data, &exec.Data); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal data of execution %s", id)
+	}
+	return exec, nil
+}
+
+func (s *Saga[T]) save(ctx context.Context, exec *Execution[T]) error {
+	data, err := json.Marshal(exec.Data)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal data of execution %s", exec.ID)
+	}
+	exec.UpdatedAt = time.Now().UTC()
+	stored := StoredExecution{
+		ID:        exec.ID,
+		Saga:      exec.Saga,
+		Status:    exec.Status,
+		Step:      exec.Step,
+		Data:      data,
+		Error:     exec.Error,
+		Version:   exec.Version + 1,
+		UpdatedAt: exec.UpdatedAt,
+	}
+	if err := s.store.Save(ctx, stored, exec.Version); err != nil {
+		return errors.Wrapf(err, "failed to save execution %s of saga %s", exec.ID, s.name)
+	}
+	exec.Version = stored.Version
+	return nil
+}
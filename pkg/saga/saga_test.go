@@ -0,0 +1,87 @@
+package saga
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type order struct {
+	Log []string `json:"log"`
+}
+
+func step(name string, fail error, compensateFail *error) Step[order] {
+	return Step[order]{
+		Name: name,
+		Do: func(_ context.Context, o *order) error {
+			if fail != nil {
+				return fail
+			}
+			o.Log = append(o.Log, "do "+name)
+			return nil
+		},
+		Compensate: func(_ context.Context, o *order) error {
+			if compensateFail != nil && *compensateFail != nil {
+				return *compensateFail
+			}
+			o.Log = append(o.Log, "undo "+name)
+			return nil
+		},
+	}
+}
+
+func TestSaga(t *testing.T) {
+	tests := []struct {
+		name       string
+		steps      []Step[order]
+		wantStatus Status
+		wantLog    []string
+		wantErr    bool
+	}{
+		{
+			name:       "all steps succeed",
+			steps:      []Step[order]{step("reserve", nil, nil), step("charge", nil, nil)},
+			wantStatus: StatusCompleted,
+			wantLog:    []string{"do reserve", "do charge"},
+		},
+		{
+			name:       "failed step compensates previous steps in reverse order",
+			steps:      []Step[order]{step("reserve", nil, nil), step("charge", nil, nil), step("ship", errors.New("no courier"), nil)},
+			wantStatus: StatusCompensated,
+			wantLog:    []string{"do reserve", "do charge", "undo charge", "undo reserve"},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec, err := New("order", MemoryStore(), tt.steps...).Run(context.Background(), "o-1", order{})
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.wantStatus, exec.Status)
+			assert.Equal(t, tt.wantLog, exec.Data.Log)
+		})
+	}
+}
+
+func TestResumeCompensation(t *testing.T) {
+	ctx := context.Background()
+	store := MemoryStore()
+	compensateErr := errors.New("refund is unavailable")
+	s := New("order", store, step("charge", nil, &compensateErr), step("ship", errors.New("no courier"), nil))
+
+	exec, err := s.Run(ctx, "o-1", order{})
+	assert.Error(t, err)
+	assert.Equal(t, StatusCompensating, exec.Status)
+
+	compensateErr = nil
+	exec, err = s.Resume(ctx, "o-1")
+	assert.Error(t, err)
+	assert.Equal(t, StatusCompensated, exec.Status)
+	assert.Equal(t, []string{"do charge", "undo charge"}, exec.Data.Log)
+
+	// finished execution is returned as is
+	exec, err = s.Run(ctx, "o-1", order{})
+	assert.Error(t, err)
+	assert.Equal(t, StatusCompensated, exec.Status)
+}
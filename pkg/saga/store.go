@@ -0,0 +1,155 @@
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// ErrConcurrentUpdate is returned by Store.Save when execution was updated by someone else
+var ErrConcurrentUpdate = errors.New("execution was updated concurrently")
+
+// StoredExecution is the execution with serialized data
+type StoredExecution struct {
+	ID        string
+	Saga      string
+	Status    Status
+	Step      int
+	Data      json.RawMessage
+	Error     string
+	Version   int64
+	UpdatedAt time.Time
+}
+
+type Store interface {
+	// Load returns nil when execution doesn't exist
+	Load(ctx context.Context, id string) (*StoredExecution, error)
+	// Save persists execution if the stored version equals expectedVersion (0 for new executions)
+	Save(ctx context.Context, exec StoredExecution, expectedVersion int64) error
+}
+
+type memoryStore struct {
+	mu    sync.Mutex
+	execs map[string]StoredExecution
+}
+
+// MemoryStore keeps executions within the instance, meant for tests and local runs
+func MemoryStore() Store {
+	return &memoryStore{execs: map[string]StoredExecution{}}
+}
+
+func (m *memoryStore) Load(_ context.Context, id string) (*StoredExecution, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	exec, ok := m.execs[id]
+	if !ok {
+		return nil, nil
+	}
+	return &exec, nil
+}
+
+func (m *memoryStore) Save(_ context.Context, exec StoredExecution, expectedVersion int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.execs[exec.ID].Version != expectedVersion {
+		return ErrConcurrentUpdate
+	}
+	m.execs[exec.ID] = exec
+	return nil
+}
+
+type dynamoDBStore struct {
+	client dynamodbiface.DynamoDBAPI
+	table  string
+}
+
+// DynamoDBStore keeps executions in the table with "id" hash key
+func DynamoDBStore(client dynamodbiface.DynamoDBAPI, table string) Store {
+	return &dynamoDBStore{
+		client: client,
+		table:  table,
+	}
+}
+
+func (d *dynamoDBStore) Load(ctx context.Context, id string) (*StoredExecution, error) {
+	out, err := d.client.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(d.table),
+		Key:            map[string]*dynamodb.AttributeValue{"id": {S: aws.String(id)}},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load execution %s", id)
+	}
+	if len(out.Item) == 0 {
+		return nil, nil
+	}
+	item := out.Item
+	str := func(name string) string {
+		if v, ok := item[name]; ok {
+			return aws.StringValue(v.S)
+		}
+		return ""
+	}
+	num := func(name string) int64 {
+		if v, ok := item[name]; ok {
+			n, _ := strconv.ParseInt(aws.StringValue(v.N), 10, 64)
+			return n
+		}
+		return 0
+	}
+	updatedAt, _ := time.Parse(time.RFC3339Nano, str("updatedAt"))
+	return &StoredExecution{
+		ID:        str("id"),
+		Saga:      str("saga"),
+		Status:    Status(str("status")),
+		Step:      int(num("step")),
+		Data:      json.RawMessage(str("data")),
+		Error:     str("error"),
+		Version:   num("version"),
+		UpdatedAt: updatedAt,
+	}, nil
+}
+
+func (d *dynamoDBStore) Save(ctx context.Context, exec StoredExecution, expectedVersion int64) error {
+	item := map[string]*dynamodb.AttributeValue{
+		"id":        {S: aws.String(exec.ID)},
+		"saga":      {S: aws.String(exec.Saga)},
+		"status":    {S: aws.String(string(exec.Status))},
+		"step":      {N: aws.String(strconv.Itoa(exec.Step))},
+		"data":      {S: aws.String(string(exec.Data))},
+		"version":   {N: aws.String(strconv.FormatInt(exec.Version, 10))},
+		"updatedAt": {S: aws.String(exec.UpdatedAt.Format(time.RFC3339Nano))},
+	}
+	if exec.Error != "" {
+		item["error"] = &dynamodb.AttributeValue{S: aws.String(exec.Error)}
+	}
+	input := &dynamodb.PutItemInput{
+		TableName: aws.String(d.table),
+		Item:      item,
+	}
+	if expectedVersion == 0 {
+		input.ConditionExpression = aws.String("attribute_not_exists(id)")
+	} else {
+		input.ConditionExpression = aws.String("version = :expected")
+		input.ExpressionAttributeValues = map[string]*dynamodb.AttributeValue{
+			":expected": {N: aws.String(strconv.FormatInt(expectedVersion, 10))},
+		}
+	}
+	if _, err := d.client.PutItemWithContext(ctx, input); err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && awsErr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return ErrConcurrentUpdate
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package schedule
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/util/lock"
+)
+
+const defaultElectionWindow = time.Minute
+
+// Handler handles scheduled event, e.g. events.EventBridgeEvent
+type Handler[T any] func(ctx context.Context, event T) error
+
+type (
+	Option func(*config)
+)
+
+type config struct {
+	logger logger.Logger
+	client dynamodbiface.DynamoDBAPI
+	table  string
+	window time.Duration
+}
+
+func WithLogger(logger logger.Logger) Option {
+	return func(c *config) {
+		c.logger = logger
+	}
+}
+
+// WithLeaderElection makes only one of the concurrently triggered invocations (e.g. the same schedule
+// fanned out to several aliases or regions) execute the job, others log and skip it.
+// Leadership is kept for window after the job succeeds so that late invocations of the same schedule
+// are skipped as well, hence window must be shorter than the schedule interval
+func WithLeaderElection(client dynamodbiface.DynamoDBAPI, table string, window time.Duration) Option {
+	return func(c *config) {
+		c.client = client
+		c.table = table
+		c.window = window
+	}
+}
+
+// New wraps scheduled job handler, lock table of the leader election is keyed by job name
+func New[T any](job string, handler Handler[T], opts ...Option) Handler[T] {
+	cfg := &config{
+		logger: logger.NewLogger(),
+		window: defaultElectionWindow,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return func(ctx context.Context, event T) error {
+		ctx = cfg.logger.WithValue(ctx, "job", job)
+		if cfg.client == nil {
+			return run(ctx, cfg.logger, job, handler, event)
+		}
+		// every invocation is a separate candidate, even when lambda reuses execution environment
+		locker := lock.New(cfg.client, cfg.table, lock.WithOwner(invocationID(ctx)), lock.WithLogger(cfg.logger))
+		lease, err := locker.TryAcquire(ctx, job, cfg.window)
+		if errors.Is(err, lock.ErrLocked) {
+			cfg.logger.Infof(ctx, "job %s is executed by another instance, skipping", job)
+			return nil
+		} else if err != nil {
+			return errors.Wrapf(err, "failed to elect leader of job %s", job)
+		}
+		ctx = cfg.logger.WithValue(ctx, "fencingToken", lease.FencingToken)
+
+		jobCtx, stop := lease.Heartbeat(ctx, cfg.window/3)
+		err = run(jobCtx, cfg.logger, job, handler, event)
+		stop()
+		if err != nil {
+			// let another instance or retry of the invocation take over the failed job
+			if releaseErr := lease.Release(context.WithoutCancel(ctx)); releaseErr != nil {
+				cfg.logger.Warnf(ctx, "failed to release leadership of job %s: %v", job, releaseErr)
+			}
+		}
+		return err
+	}
+}
+
+func run[T any](ctx context.Context, log logger.Logger, job string, handler Handler[T], event T) error {
+	startedAt := time.Now()
+	log.Infof(ctx, "job %s started", job)
+	if err := handler(ctx, event); err != nil {
+		log.Errorf(ctx, "job %s failed after %s: %v", job, time.Since(startedAt), err)
+		return errors.Wrapf(err, "job %s failed", job)
+	}
+	log.Infof(ctx, "job %s finished in %s", job, time.Since(startedAt))
+	return nil
+}
+
+func invocationID(ctx context.Context) string {
+	if lc, ok := lambdacontext.FromContext(ctx); ok && lc.AwsRequestID != "" {
+		return lc.AwsRequestID
+	}
+	return uuid.NewString()
+}
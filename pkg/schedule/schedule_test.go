@@ -0,0 +1,96 @@
+package schedule_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/schedule"
+)
+
+// fakeLockTable grants leadership unless the job is held by another instance or acquisition fails
+type fakeLockTable struct {
+	dynamodbiface.DynamoDBAPI
+	mu       sync.Mutex
+	held     bool
+	err      error
+	releases int
+}
+
+func (f *fakeLockTable) UpdateItemWithContext(_ aws.Context, in *dynamodb.UpdateItemInput, _ ...request.Option) (*dynamodb.UpdateItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	expression := aws.StringValue(in.UpdateExpression)
+	switch {
+	case f.err != nil:
+		return nil, f.err
+	case strings.HasPrefix(expression, "REMOVE #owner"):
+		f.releases++
+		return &dynamodb.UpdateItemOutput{}, nil
+	case !strings.Contains(expression, "ADD fencingToken"):
+		// lease heartbeat
+		return &dynamodb.UpdateItemOutput{}, nil
+	case f.held:
+		return nil, awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", nil)
+	}
+	return &dynamodb.UpdateItemOutput{Attributes: map[string]*dynamodb.AttributeValue{
+		"fencingToken": {N: aws.String("1")},
+	}}, nil
+}
+
+func TestLeaderElection(t *testing.T) {
+	tests := []struct {
+		name         string
+		table        *fakeLockTable
+		jobErr       error
+		wantRuns     int
+		wantErr      string
+		wantReleases int
+	}{
+		{name: "leader runs the job", table: &fakeLockTable{}, wantRuns: 1},
+		{name: "non-leader skips the job", table: &fakeLockTable{held: true}},
+		{
+			name:    "lock error",
+			table:   &fakeLockTable{err: errors.New("throttled")},
+			wantErr: "failed to elect leader of job report: failed to acquire lock report: throttled",
+		},
+		{
+			name:         "failed job releases leadership",
+			table:        &fakeLockTable{},
+			jobErr:       errors.New("boom"),
+			wantRuns:     1,
+			wantErr:      "job report failed: boom",
+			wantReleases: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runs := 0
+			handler := schedule.New("report", func(ctx context.Context, event string) error {
+				runs++
+				return tt.jobErr
+			}, schedule.WithLeaderElection(tt.table, "locks", time.Minute))
+
+			err := handler(context.Background(), "tick")
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantRuns, runs)
+			assert.Equal(t, tt.wantReleases, tt.table.releases)
+		})
+	}
+}
@@ -0,0 +1,317 @@
+package service
+
+import (
+	"container/list"
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+)
+
+const (
+	defaultAuthLockoutThreshold = 5
+	defaultAuthLockoutBase      = time.Minute
+	defaultAuthLockoutMax       = time.Hour
+)
+
+// AuthLockoutConfig configures lockout of clients failing API key authentication
+type AuthLockoutConfig struct {
+	Threshold   int           // failures before the first lockout, defaults to 5
+	BaseLockout time.Duration // first lockout, doubled with every further failure, defaults to 1 minute
+	MaxLockout  time.Duration // longest lockout, failures are forgotten once it passes since the last one, defaults to 1 hour
+	Store       AuthFailureStore
+}
+
+// AuthFailures are failed authentication attempts of a client IP or an API key
+type AuthFailures struct {
+	Count         int
+	LastFailureAt time.Time
+}
+
+// AuthFailureStore counts authentication failures, Add restarts the count when the record expired before at;
+// Get may return expired records as the lockout ignores them
+type AuthFailureStore interface {
+	Get(ctx context.Context, key string) (AuthFailures, error)
+	Add(ctx context.Context, key string, at, expiresAt time.Time) (AuthFailures, error)
+	Reset(ctx context.Context, key string) error
+}
+
+// WithAuthLockout rejects requests with 429 once their client IP or API key fails authentication Threshold times,
+// for a lockout growing exponentially with further failures; failures are counted in memory of the instance
+// unless Store shares them, e.g. DynamoDBAuthFailureStore.
+// Client IP is resolved as ClientIP, so that service behind a proxy (e.g. CloudFront) requires WithTrustedProxies,
+// otherwise failures of every client are counted under the IP of the proxy and clients lock each other out
+func WithAuthLockout(cfg AuthLockoutConfig) Option {
+	return func(s *service) {
+		cfg.Threshold = lo.Ternary(cfg.Threshold > 0, cfg.Threshold, defaultAuthLockoutThreshold)
+		cfg.BaseLockout = lo.Ternary(cfg.BaseLockout > 0, cfg.BaseLockout, defaultAuthLockoutBase)
+		cfg.MaxLockout = lo.Ternary(cfg.MaxLockout > 0, cfg.MaxLockout, defaultAuthLockoutMax)
+		if cfg.Store == nil {
+			cfg.Store = NewMemoryAuthFailureStore()
+		}
+		s.authLockout = &cfg
+	}
+}
+
+// lockedUntil returns end of the lockout after failures, zero time when they do not lock
+func (cfg *AuthLockoutConfig) lockedUntil(failures AuthFailures) time.Time {
+	if failures.Count < cfg.Threshold {
+		return time.Time{}
+	}
+	lockout := cfg.BaseLockout
+	for i := cfg.Threshold; i < failures.Count && lockout < cfg.MaxLockout; i++ {
+		lockout *= 2
+	}
+	return failures.LastFailureAt.Add(min(lockout, cfg.MaxLockout))
+}
+
+const credentialFailureKeyPrefix = "key:"
+
+// authFailureKeys returns keys failures of the request are counted under, API key is never stored, only its hash
+func authFailureKeys(c HttpAdapter) []string {
+	keys := []string{"ip:" + c.RemoteIP()}
+	if parts := strings.Split(c.Header("Authorization"), " "); len(parts) >= 2 && parts[1] != "" {
+		keys = append(keys, credentialFailureKeyPrefix+keyID(parts[1]))
+	}
+	return keys
+}
+
+// authLockoutMiddleware wraps auth middleware to reject locked out clients and to count failures of auth, it
+// applies to requests requiring auth only: public routes and anonymous requests of optional auth routes are
+// served to locked out clients as well, and missing API key configuration is not a failure of the client
+func (s *service) authLockoutMiddleware(auth HttpAdapterHandler) HttpAdapterHandler {
+	return func(c HttpAdapter) error {
+		if s.apiKey == "" || s.isAuthSkipped(c.Request()) ||
+			(c.Header("Authorization") == "" && s.isOptionalAuthRoute(c.Request().URL.Path)) {
+			return auth(c)
+		}
+		ctx := c.Context()
+		keys := authFailureKeys(c)
+		failures := map[string]AuthFailures{}
+		for _, key := range keys {
+			res, err := s.authLockout.Store.Get(ctx, key)
+			if err != nil {
+				s.logger.Errorf(ctx, "failed to get auth failures: %v", err)
+				continue
+			}
+			if !s.clock.Now().Before(res.LastFailureAt.Add(s.authLockout.MaxLockout)) {
+				res = AuthFailures{}
+			}
+			failures[key] = res
+			if lockedUntil := s.authLockout.lockedUntil(res); s.clock.Now().Before(lockedUntil) {
+				retryAfter := lockedUntil.Sub(s.clock.Now())
+				logger.LogSecurity(ctx, s.logger, SecurityEventAuthLockout, securityFields(c, map[string]any{
+					"lockoutKey":   key,
+					"authFailures": res.Count,
+					"retryAfter":   retryAfter.String(),
+				}))
+				c.SetHeader("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				c.JSON(http.StatusTooManyRequests, M{"message": Localize(c, MessageTooManyRequests)})
+				c.AbortWithStatus(http.StatusTooManyRequests)
+				return nil
+			}
+		}
+
+		authenticated, ok := ctx.Value(authenticatedKey).(*atomic.Bool)
+		if !ok {
+			authenticated = &atomic.Bool{}
+			c.SetContext(context.WithValue(ctx, authenticatedKey, authenticated))
+		}
+		err := auth(c)
+		switch {
+		case err != nil:
+			now := s.clock.Now()
+			for _, key := range keys {
+				res, addErr := s.authLockout.Store.Add(ctx, key, now, now.Add(s.authLockout.MaxLockout))
+				if addErr != nil {
+					s.logger.Errorf(ctx, "failed to count auth failure: %v", addErr)
+					continue
+				}
+				if res.Count >= s.authLockout.Threshold {
+					logger.LogSecurity(ctx, s.logger, SecurityEventAuthLockout, securityFields(c, map[string]any{
+						"lockoutKey":   key,
+						"authFailures": res.Count,
+						"lockedUntil":  s.authLockout.lockedUntil(res),
+					}))
+				}
+			}
+		case authenticated.Load():
+			// only failures of the credential are forgiven, client IP may be shared with other clients guessing keys
+			for key, res := range failures {
+				if res.Count == 0 || !strings.HasPrefix(key, credentialFailureKeyPrefix) {
+					continue
+				}
+				if resetErr := s.authLockout.Store.Reset(ctx, key); resetErr != nil {
+					s.logger.Errorf(ctx, "failed to reset auth failures: %v", resetErr)
+				}
+			}
+		}
+		return err
+	}
+}
+
+// maxMemoryAuthFailureKeys bounds memory of the store, keys failing least recently are evicted beyond it
+const maxMemoryAuthFailureKeys = 10000
+
+type memoryAuthFailures struct {
+	AuthFailures
+	key       string
+	expiresAt time.Time
+}
+
+type memoryAuthFailureStore struct {
+	mu       sync.Mutex
+	failures map[string]*list.Element
+	// records ordered by last failure, the most recent first, so that expired records are at the back
+	recent *list.List
+}
+
+// NewMemoryAuthFailureStore counts failures in memory of the instance
+func NewMemoryAuthFailureStore() AuthFailureStore {
+	return &memoryAuthFailureStore{failures: map[string]*list.Element{}, recent: list.New()}
+}
+
+func (m *memoryAuthFailureStore) Get(_ context.Context, key string) (AuthFailures, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if el, ok := m.failures[key]; ok {
+		return el.Value.(*memoryAuthFailures).AuthFailures, nil
+	}
+	return AuthFailures{}, nil
+}
+
+func (m *memoryAuthFailureStore) Add(_ context.Context, key string, at, expiresAt time.Time) (AuthFailures, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f := &memoryAuthFailures{key: key}
+	if el, ok := m.failures[key]; ok {
+		f = m.recent.Remove(el).(*memoryAuthFailures)
+		delete(m.failures, key)
+		if !at.Before(f.expiresAt) {
+			f.AuthFailures = AuthFailures{}
+		}
+	}
+	m.evict(at)
+	f.Count++
+	f.LastFailureAt, f.expiresAt = at, expiresAt
+	m.failures[key] = m.recent.PushFront(f)
+	return f.AuthFailures, nil
+}
+
+// evict drops expired records and the least recent ones beyond the size limit, it stops at the first record
+// to keep so that every Add takes constant time on average
+func (m *memoryAuthFailureStore) evict(at time.Time) {
+	for el := m.recent.Back(); el != nil; el = m.recent.Back() {
+		f := el.Value.(*memoryAuthFailures)
+		if at.Before(f.expiresAt) && m.recent.Len() < maxMemoryAuthFailureKeys {
+			return
+		}
+		m.recent.Remove(el)
+		delete(m.failures, f.key)
+	}
+}
+
+func (m *memoryAuthFailureStore) Reset(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if el, ok := m.failures[key]; ok {
+		m.recent.Remove(el)
+		delete(m.failures, key)
+	}
+	return nil
+}
+
+type dynamoDBAuthFailureStore struct {
+	client dynamodbiface.DynamoDBAPI
+	table  string
+}
+
+// DynamoDBAuthFailureStore shares failures across instances in the table with "key" hash key, "expiresAt"
+// attribute holds epoch seconds to be used as the table TTL attribute
+func DynamoDBAuthFailureStore(client dynamodbiface.DynamoDBAPI, table string) AuthFailureStore {
+	return &dynamoDBAuthFailureStore{
+		client: client,
+		table:  table,
+	}
+}
+
+func (d *dynamoDBAuthFailureStore) Get(ctx context.Context, key string) (AuthFailures, error) {
+	out, err := d.client.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(d.table),
+		Key:            map[string]*dynamodb.AttributeValue{"key": {S: aws.String(key)}},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return AuthFailures{}, errors.Wrapf(err, "failed to get auth failures of %s", key)
+	}
+	return authFailuresOf(out.Item), nil
+}
+
+func (d *dynamoDBAuthFailureStore) Add(ctx context.Context, key string, at, expiresAt time.Time) (AuthFailures, error) {
+	values := map[string]*dynamodb.AttributeValue{
+		":one":       {N: aws.String("1")},
+		":at":        {N: aws.String(strconv.FormatInt(at.UnixMilli(), 10))},
+		":expiresAt": {N: aws.String(strconv.FormatInt(expiresAt.Unix(), 10))},
+	}
+	out, err := d.client.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(d.table),
+		Key:                       map[string]*dynamodb.AttributeValue{"key": {S: aws.String(key)}},
+		UpdateExpression:          aws.String("ADD failures :one SET lastFailureAt = :at, expiresAt = :expiresAt"),
+		ConditionExpression:       aws.String("attribute_not_exists(expiresAt) OR expiresAt > :now"),
+		ExpressionAttributeValues: lo.Assign(values, map[string]*dynamodb.AttributeValue{":now": {N: aws.String(strconv.FormatInt(at.Unix(), 10))}}),
+		ReturnValues:              aws.String(dynamodb.ReturnValueAllNew),
+	})
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) && awsErr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		// the record expired, failures are counted anew
+		out, err = d.client.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+			TableName:                 aws.String(d.table),
+			Key:                       map[string]*dynamodb.AttributeValue{"key": {S: aws.String(key)}},
+			UpdateExpression:          aws.String("SET failures = :one, lastFailureAt = :at, expiresAt = :expiresAt"),
+			ExpressionAttributeValues: values,
+			ReturnValues:              aws.String(dynamodb.ReturnValueAllNew),
+		})
+	}
+	if err != nil {
+		return AuthFailures{}, errors.Wrapf(err, "failed to add auth failure of %s", key)
+	}
+	return authFailuresOf(out.Attributes), nil
+}
+
+func (d *dynamoDBAuthFailureStore) Reset(ctx context.Context, key string) error {
+	_, err := d.client.DeleteItemWithContext(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(d.table),
+		Key:       map[string]*dynamodb.AttributeValue{"key": {S: aws.String(key)}},
+	})
+	return errors.Wrapf(err, "failed to reset auth failures of %s", key)
+}
+
+func authFailuresOf(item map[string]*dynamodb.AttributeValue) AuthFailures {
+	return AuthFailures{
+		Count:         int(numberAttribute(item, "failures")),
+		LastFailureAt: time.UnixMilli(numberAttribute(item, "lastFailureAt")),
+	}
+}
+
+func numberAttribute(item map[string]*dynamodb.AttributeValue, name string) int64 {
+	v, ok := item[name]
+	if !ok || v.N == nil {
+		return 0
+	}
+	res, _ := strconv.ParseInt(*v.N, 10, 64)
+	return res
+}
@@ -0,0 +1,99 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/util/clocktest"
+)
+
+func TestWithAuthLockout(t *testing.T) {
+	clock := clocktest.New(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		router.GET("/api/ping", func(c service.HttpAdapter) error {
+			c.JSON(http.StatusOK, service.M{})
+			return nil
+		})
+		return nil
+	})
+	h := servicetest.New(t, routes, service.WithClock(clock), service.WithApiKey("key"), service.WithAuthLockout(service.AuthLockoutConfig{
+		Threshold:   2,
+		BaseLockout: time.Minute,
+		MaxLockout:  10 * time.Minute,
+	}))
+	invoke := func(key string) *servicetest.Response {
+		return h.Invoke(http.MethodGet, "/api/ping", nil, map[string]string{"Authorization": "Bearer " + key})
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, invoke("guess1").StatusCode)
+	assert.Equal(t, http.StatusUnauthorized, invoke("guess2").StatusCode)
+	res := invoke("key")
+	assert.Equal(t, http.StatusTooManyRequests, res.StatusCode, "client IP is locked out")
+	assert.Equal(t, "60", res.Headers.Get("Retry-After"))
+
+	clock.Advance(time.Minute)
+	assert.Equal(t, http.StatusOK, invoke("key").StatusCode)
+	assert.Equal(t, http.StatusUnauthorized, invoke("guess3").StatusCode)
+	res = invoke("key")
+	assert.Equal(t, http.StatusTooManyRequests, res.StatusCode, "failures of client IP are not reset by successful auth")
+	assert.Equal(t, "120", res.Headers.Get("Retry-After"), "lockout doubles with further failures")
+
+	clock.Advance(10 * time.Minute)
+	assert.Equal(t, http.StatusUnauthorized, invoke("guess6").StatusCode, "failures are forgotten after max lockout")
+	assert.Equal(t, http.StatusOK, invoke("key").StatusCode)
+}
+
+func TestWithAuthLockoutPublicRoutes(t *testing.T) {
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		for _, path := range []string{"/api/ping", "/public/ping", "/optional/ping"} {
+			router.GET(path, func(c service.HttpAdapter) error {
+				c.JSON(http.StatusOK, service.M{"authorized": service.IsAuthorized(c.Context())})
+				return nil
+			})
+		}
+		return nil
+	})
+	h := servicetest.New(t, routes, service.WithApiKey("key"), service.WithSkipAuthRoutes("/public"),
+		service.WithOptionalAuthRoutes("/optional"), service.WithAuthLockout(service.AuthLockoutConfig{Threshold: 1}))
+
+	assert.Equal(t, http.StatusUnauthorized, h.Invoke(http.MethodGet, "/api/ping", nil, map[string]string{"Authorization": "Bearer guess"}).StatusCode)
+	assert.Equal(t, http.StatusTooManyRequests, h.Invoke(http.MethodGet, "/api/ping", nil, nil).StatusCode)
+	assert.Equal(t, http.StatusOK, h.Invoke(http.MethodGet, "/public/ping", nil, nil).StatusCode)
+	assert.Equal(t, http.StatusOK, h.Invoke(http.MethodGet, "/optional/ping", nil, nil).StatusCode)
+	assert.Equal(t, http.StatusTooManyRequests, h.Invoke(http.MethodGet, "/optional/ping", nil, map[string]string{"Authorization": "Bearer key"}).StatusCode)
+}
+
+func TestMemoryAuthFailureStore(t *testing.T) {
+	ctx := context.Background()
+	store := service.NewMemoryAuthFailureStore()
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	_, err := store.Add(ctx, "ip:1", at, at.Add(time.Minute))
+	require.NoError(t, err)
+	res, err := store.Add(ctx, "ip:1", at.Add(time.Second), at.Add(time.Minute+time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.Count)
+
+	res, err = store.Add(ctx, "ip:1", at.Add(2*time.Minute), at.Add(3*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.Count, "expired failures are counted anew")
+
+	for i := 0; i < 10000; i++ {
+		_, err := store.Add(ctx, fmt.Sprintf("ip:%d", i+2), at.Add(2*time.Minute), at.Add(3*time.Minute))
+		require.NoError(t, err)
+	}
+	res, err = store.Get(ctx, "ip:1")
+	require.NoError(t, err)
+	assert.Zero(t, res.Count, "the least recent key is evicted beyond the size limit")
+	res, err = store.Get(ctx, "ip:10001")
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.Count)
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+const PrincipalIDKey = "principalId"
+
+type authorizerKeyType struct{}
+
+var authorizerKey authorizerKeyType = struct{}{}
+
+// Claims are values passed by API Gateway authorizer (Lambda, Cognito, JWT or IAM), lambda authorizer
+// context values are delivered as strings, so typed accessors parse them when needed
+type Claims map[string]any
+
+// AuthorizerClaims returns claims of the authorizer which authorized the request
+func AuthorizerClaims(ctx context.Context) (Claims, bool) {
+	claims, ok := ctx.Value(authorizerKey).(Claims)
+	return claims, ok && len(claims) > 0
+}
+
+func (c Claims) PrincipalID() string {
+	return c.String(PrincipalIDKey)
+}
+
+// JWT returns claims of JWT/Cognito authorizers which are nested under "claims" key
+func (c Claims) JWT() Claims {
+	if nested, ok := c["claims"].(map[string]any); ok {
+		return nested
+	}
+	return Claims{}
+}
+
+func (c Claims) String(key string) string {
+	switch v := c[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (c Claims) Bool(key string) bool {
+	switch v := c[key].(type) {
+	case bool:
+		return v
+	case string:
+		b, _ := strconv.ParseBool(v)
+		return b
+	default:
+		return false
+	}
+}
+
+func (c Claims) Int64(key string) (int64, bool) {
+	switch v := c[key].(type) {
+	case float64:
+		return int64(v), true
+	case int64:
+		return v, true
+	case int:
+		return int64(v), true
+	case string:
+		i, err := strconv.ParseInt(v, 10, 64)
+		return i, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// withAuthorizerClaims stores authorizer claims in context and exposes principal id in logs
+func (s *service) withAuthorizerClaims(ctx context.Context, authorizer map[string]any) context.Context {
+	if len(authorizer) == 0 {
+		return ctx
+	}
+	claims := Claims(authorizer)
+	ctx = context.WithValue(ctx, authorizerKey, claims)
+	if principalID := claims.PrincipalID(); principalID != "" {
+		ctx = s.logger.WithValue(ctx, PrincipalIDKey, principalID)
+	} else if sub := claims.JWT().String("sub"); sub != "" {
+		ctx = s.logger.WithValue(ctx, PrincipalIDKey, sub)
+	}
+	return ctx
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const defaultStage = "$default"
+
+type stageKeyType struct{}
+
+var stageKey stageKeyType = struct{}{}
+
+type originalURLKeyType struct{}
+
+var originalURLKey originalURLKeyType = struct{}{}
+
+// WithBasePath strips prefix (e.g. custom domain base path mapping) from request path before routing
+func WithBasePath(prefix string) Option {
+	return func(s *service) {
+		s.basePath = "/" + strings.Trim(prefix, "/")
+	}
+}
+
+// withStage stores API Gateway stage when it is part of request path: REST API strips stage itself,
+// while HTTP API (payload 1.0) keeps non-default stage in the path so it has to be stripped before routing
+func withStage(ctx context.Context, request events.APIGatewayProxyRequest) context.Context {
+	stage := request.RequestContext.Stage
+	if stage == "" || stage == defaultStage || request.Path != request.RequestContext.Path {
+		return ctx
+	}
+	if stripPathPrefix(request.Path, "/"+stage) == request.Path {
+		return ctx
+	}
+	return context.WithValue(ctx, stageKey, stage)
+}
+
+func (s *service) stripBasePathHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefix := ""
+		if stage, ok := r.Context().Value(stageKey).(string); ok {
+			prefix = "/" + stage
+		}
+		path := stripPathPrefix(r.URL.Path, prefix)
+		if s.basePath != "" && s.basePath != "/" {
+			path = stripPathPrefix(path, s.basePath)
+		}
+		if path != r.URL.Path {
+			r = withPath(r, path)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// OriginalURL returns url of the request before stage and base path were stripped from its path
+// and version negotiated by Accept-Version header was added to it
+func OriginalURL(r *http.Request) *url.URL {
+	if u, ok := r.Context().Value(originalURLKey).(*url.URL); ok {
+		return u
+	}
+	return r.URL
+}
+
+func withPath(r *http.Request, path string) *http.Request {
+	r2 := withOriginalURL(r)
+	r2.URL.Path = path
+	r2.URL.RawPath = ""
+	r2.RequestURI = r2.URL.RequestURI()
+	return r2
+}
+
+// withOriginalURL clones request keeping its url for OriginalURL unless it was kept already
+func withOriginalURL(r *http.Request) *http.Request {
+	ctx := r.Context()
+	if _, ok := ctx.Value(originalURLKey).(*url.URL); !ok {
+		original := *r.URL
+		ctx = context.WithValue(ctx, originalURLKey, &original)
+	}
+	return r.Clone(ctx)
+}
+
+func stripPathPrefix(path, prefix string) string {
+	if prefix == "" {
+		return path
+	}
+	if path == prefix {
+		return "/"
+	}
+	if strings.HasPrefix(path, prefix+"/") {
+		return path[len(prefix):]
+	}
+	return path
+}
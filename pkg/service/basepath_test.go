@@ -0,0 +1,48 @@
+package service_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestBasePath(t *testing.T) {
+	h := servicetest.New(t, service.WithBasePath("/shop/"), service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		router.GET("/api/items", func(c service.HttpAdapter) error {
+			c.JSON(http.StatusOK, map[string]string{
+				"path":     c.Request().URL.Path,
+				"original": service.OriginalURL(c.Request()).RequestURI(),
+			})
+			return nil
+		})
+		return nil
+	}))
+	tests := []struct {
+		name         string
+		path         string
+		wantStatus   int
+		wantOriginal string
+	}{
+		{name: "prefixed path", path: "/shop/api/items?q=1", wantStatus: http.StatusOK, wantOriginal: "/shop/api/items?q=1"},
+		{name: "unprefixed path", path: "/api/items", wantStatus: http.StatusOK, wantOriginal: "/api/items"},
+		{name: "prefix is matched by segment", path: "/shopping/api/items", wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := h.Invoke(http.MethodGet, tt.path, nil, nil)
+			require.Equal(t, tt.wantStatus, res.StatusCode)
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var body map[string]string
+			require.NoError(t, res.JSON(&body))
+			assert.Equal(t, "/api/items", body["path"])
+			assert.Equal(t, tt.wantOriginal, body["original"])
+		})
+	}
+}
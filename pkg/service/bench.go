@@ -0,0 +1,158 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+const (
+	maxBenchRequests = 100000
+	benchRoute       = "/api/_bench"
+)
+
+type BenchRequest struct {
+	Method      string            `json:"method" yaml:"method"`
+	Path        string            `json:"path" yaml:"path"`
+	Body        string            `json:"body,omitempty" yaml:"body,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Requests    int               `json:"requests" yaml:"requests"`
+	Concurrency int               `json:"concurrency" yaml:"concurrency"`
+}
+
+type BenchResult struct {
+	Requests            int              `json:"requests" yaml:"requests"`
+	StatusCodes         map[int]int      `json:"statusCodes" yaml:"statusCodes"`
+	Total               time.Duration    `json:"total" yaml:"total"`
+	Latency             map[string]int64 `json:"latencyMicros" yaml:"latencyMicros"` // p50, p90, p99, max
+	AllocsPerRequest    uint64           `json:"allocsPerRequest" yaml:"allocsPerRequest"`
+	BytesPerRequest     uint64           `json:"bytesPerRequest" yaml:"bytesPerRequest"`
+	EstimatedCostPerReq float64          `json:"estimatedCostPerRequest" yaml:"estimatedCostPerRequest"`
+	Meta                ResultMeta       `json:"meta" yaml:"meta"`
+}
+
+// @Schemes
+// @Description run synthetic requests against a route and report latency, allocations and cost (local debug only)
+// @Tags debug
+// @Accept json
+// @Produce json
+// @Param request body BenchRequest true "benchmark parameters"
+// @Success 200 {object} BenchResult
+// @Router /api/_bench [post]
+func (s *service) benchEndpoint(c HttpAdapter) error {
+	ctx := c.Context()
+	handler := s.Handler()
+	if handler == nil {
+		c.JSON(http.StatusNotImplemented, Error{Message: "benchmark is not supported for custom http adapter routers", Meta: s.GetMeta(ctx)})
+		return nil
+	}
+	req, ok := ReadBody[BenchRequest](ctx, s, c)
+	if !ok {
+		return nil
+	}
+	if req.Path == "" {
+		c.JSON(http.StatusBadRequest, Error{Message: "path of the benchmarked route must be set", Meta: s.GetMeta(ctx)})
+		return nil
+	}
+	target, err := url.ParseRequestURI(req.Path)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Error{Message: fmt.Sprintf("invalid path of the benchmarked route: %v", err), Meta: s.GetMeta(ctx)})
+		return nil
+	}
+	if s.isBenchPath(target.Path) {
+		c.JSON(http.StatusBadRequest, Error{Message: "benchmark endpoint can not be benchmarked", Meta: s.GetMeta(ctx)})
+		return nil
+	}
+	req.Method = lo.If(req.Method != "", req.Method).Else(http.MethodGet)
+	req.Requests = lo.Clamp(req.Requests, 1, maxBenchRequests)
+	req.Concurrency = lo.Clamp(req.Concurrency, 1, req.Requests)
+
+	s.logger.Infof(ctx, "running %d requests against %s %s", req.Requests, req.Method, req.Path)
+	res := s.runBench(*req, handler)
+	res.Meta = s.GetMeta(ctx)
+	c.JSON(http.StatusOK, res)
+	return nil
+}
+
+// isBenchPath reports whether p may be routed to the benchmark endpoint: it is cleaned, stripped of the trailing
+// slash and of the base path like stripBasePathHandler does and compared ignoring case as case-insensitive route normalization does
+func (s *service) isBenchPath(p string) bool {
+	p = path.Clean("/" + p)
+	if s.basePath != "" && s.basePath != "/" {
+		p = stripPathPrefix(p, s.basePath)
+	}
+	return strings.EqualFold(p, benchRoute)
+}
+
+func (s *service) runBench(req BenchRequest, handler http.Handler) BenchResult {
+	latencies := make([]time.Duration, req.Requests)
+	statusCodes := map[int]int{}
+	var mu sync.Mutex
+	jobs := make(chan int)
+	wg := sync.WaitGroup{}
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	startedAt := time.Now()
+	for w := 0; w < req.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				r := httptest.NewRequest(req.Method, req.Path, bytes.NewBufferString(req.Body))
+				for name, value := range req.Headers {
+					r.Header.Set(name, value)
+				}
+				rec := httptest.NewRecorder()
+				reqStartedAt := time.Now()
+				handler.ServeHTTP(rec, r)
+				latencies[i] = time.Since(reqStartedAt)
+				mu.Lock()
+				statusCodes[rec.Code]++
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := 0; i < req.Requests; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	total := time.Since(startedAt)
+	runtime.ReadMemStats(&after)
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	percentile := func(p float64) int64 {
+		return latencies[int(p*float64(len(latencies)-1))].Microseconds()
+	}
+	var totalLatency time.Duration
+	for _, l := range latencies {
+		totalLatency += l
+	}
+	avgMs := float64(totalLatency.Microseconds()) / float64(len(latencies)) / 1000
+	return BenchResult{
+		Requests:    req.Requests,
+		StatusCodes: statusCodes,
+		Total:       total,
+		Latency: map[string]int64{
+			"p50": percentile(0.5),
+			"p90": percentile(0.9),
+			"p99": percentile(0.99),
+			"max": percentile(1),
+		},
+		AllocsPerRequest:    (after.Mallocs - before.Mallocs) / uint64(req.Requests),
+		BytesPerRequest:     (after.TotalAlloc - before.TotalAlloc) / uint64(req.Requests),
+		EstimatedCostPerReq: s.lambdaSize * avgMs * s.lambdaCostPerMbPerMillisecond,
+	}
+}
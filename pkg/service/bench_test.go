@@ -0,0 +1,51 @@
+package service_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestBenchEndpoint(t *testing.T) {
+	h := servicetest.New(t, service.WithLocalDebugMode(), service.WithBasePath("/base"), service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		router.GET("/api/ping", func(c service.HttpAdapter) error {
+			c.JSON(http.StatusOK, service.M{"status": "ok"})
+			return nil
+		})
+		return nil
+	}))
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+	}{
+		{name: "route", path: "/api/ping?x=1", wantStatus: http.StatusOK},
+		{name: "empty path", path: "", wantStatus: http.StatusBadRequest},
+		{name: "relative path", path: "api/ping", wantStatus: http.StatusBadRequest},
+		{name: "bench endpoint", path: "/api/_bench", wantStatus: http.StatusBadRequest},
+		{name: "bench endpoint with query", path: "/api/_bench?x=1", wantStatus: http.StatusBadRequest},
+		{name: "bench endpoint with trailing slash", path: "/api/_bench/", wantStatus: http.StatusBadRequest},
+		{name: "bench endpoint with dot segments", path: "/api/./x/../_bench", wantStatus: http.StatusBadRequest},
+		{name: "bench endpoint under base path", path: "/base/api/_bench", wantStatus: http.StatusBadRequest},
+		{name: "bench endpoint url", path: "http://localhost/API/_bench", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := h.Invoke(http.MethodPost, "/api/_bench", service.BenchRequest{Path: tt.path, Requests: 2}, nil)
+			require.Equal(t, tt.wantStatus, res.StatusCode, string(res.Body))
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var result service.BenchResult
+			require.NoError(t, res.JSON(&result))
+			assert.Equal(t, 2, result.Requests)
+			assert.Equal(t, map[int]int{http.StatusOK: 2}, result.StatusCodes)
+		})
+	}
+}
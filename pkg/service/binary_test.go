@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/awsutil/eventstest"
+)
+
+func TestProtoLambdaResponse(t *testing.T) {
+	svc, err := New(context.Background(), WithEnv(func(string) string { return "" }), WithRoutingType("function-url"),
+		WithRoutes(func(router HttpAdapterRouter) error {
+			router.GET("/api/message", func(c HttpAdapter) error {
+				c.Proto(http.StatusOK, wrapperspb.Int64(300))
+				return nil
+			})
+			return nil
+		}))
+	require.NoError(t, err)
+	s := svc.(*service)
+
+	res, err := s.ProxyLambdaApiGateway(context.Background(), eventstest.APIGatewayProxyRequest(http.MethodGet, "/api/message",
+		eventstest.WithHeader("Accept", ProtobufContentType)))
+	require.NoError(t, err)
+	require.True(t, res.IsBase64Encoded)
+	assertProtoBody(t, res.Body)
+
+	urlRes, err := s.ProxyLambdaFunctionURL(context.Background(), eventstest.LambdaFunctionURLRequest(http.MethodGet, "/api/message",
+		eventstest.WithHeader("Accept", ProtobufContentType)))
+	require.NoError(t, err)
+	require.True(t, urlRes.(events.LambdaFunctionURLResponse).IsBase64Encoded)
+	assertProtoBody(t, urlRes.(events.LambdaFunctionURLResponse).Body)
+
+	jsonRes, err := s.ProxyLambdaFunctionURL(context.Background(), eventstest.LambdaFunctionURLRequest(http.MethodGet, "/api/message"))
+	require.NoError(t, err)
+	assert.False(t, jsonRes.(events.LambdaFunctionURLResponse).IsBase64Encoded)
+	assert.JSONEq(t, `"300"`, jsonRes.(events.LambdaFunctionURLResponse).Body)
+}
+
+func TestFileLambdaResponse(t *testing.T) {
+	svc, err := New(context.Background(), WithEnv(func(string) string { return "" }), WithRoutingType("function-url"),
+		WithRoutes(func(router HttpAdapterRouter) error {
+			router.GET("/api/file", func(c HttpAdapter) error {
+				c.FileContent(strings.NewReader("0123456789"), "digits.bin", time.Time{})
+				return nil
+			})
+			return nil
+		}))
+	require.NoError(t, err)
+	s := svc.(*service)
+
+	res, err := s.ProxyLambdaApiGateway(context.Background(), eventstest.APIGatewayProxyRequest(http.MethodGet, "/api/file",
+		eventstest.WithHeader("Range", "bytes=2-5")))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusPartialContent, res.StatusCode)
+	require.True(t, res.IsBase64Encoded)
+	data, err := base64.StdEncoding.DecodeString(res.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "2345", string(data))
+
+	urlRes, err := s.ProxyLambdaFunctionURL(context.Background(), eventstest.LambdaFunctionURLRequest(http.MethodGet, "/api/file",
+		eventstest.WithHeader("Range", "bytes=0-1,8-9")))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusPartialContent, urlRes.(events.LambdaFunctionURLResponse).StatusCode)
+	require.True(t, urlRes.(events.LambdaFunctionURLResponse).IsBase64Encoded)
+	data, err = base64.StdEncoding.DecodeString(urlRes.(events.LambdaFunctionURLResponse).Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "01")
+	assert.Contains(t, string(data), "89")
+}
+
+func assertProtoBody(t *testing.T, body string) {
+	t.Helper()
+	data, err := base64.StdEncoding.DecodeString(body)
+	require.NoError(t, err)
+	var message wrapperspb.Int64Value
+	require.NoError(t, proto.Unmarshal(data, &message))
+	assert.Equal(t, int64(300), message.GetValue())
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"net/http"
+)
+
+// WithMaxBodySize rejects requests with bodies larger than size bytes, requests declaring larger Content-Length
+// are answered with 413 right away, otherwise reading past the limit fails with *http.MaxBytesError
+func WithMaxBodySize(size int64) Option {
+	return func(s *service) {
+		s.maxBodySize = size
+	}
+}
+
+// maxBodySizeHandler limits request bodies once for both engines
+func (s *service) maxBodySizeHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > s.maxBodySize {
+			http.Error(w, "request body is too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, s.maxBodySize)
+		next.ServeHTTP(w, r)
+	})
+}
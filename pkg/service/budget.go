@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// WithCostBudget warns when estimated cost of a single invocation or of all invocations of the instance
+// within a day (UTC) exceeds the threshold, 0 disables the threshold; see WithCostBudgetRejection
+func WithCostBudget(maxPerInvocation, maxPerDay float64) Option {
+	return func(s *service) {
+		if s.budget == nil {
+			s.handlerMiddlewares = append(s.handlerMiddlewares, s.costBudgetMiddleware())
+			s.budget = &costBudget{}
+		}
+		s.budget.maxPerInvocation = maxPerInvocation
+		s.budget.maxPerDay = maxPerDay
+	}
+}
+
+// WithCostBudgetRejection makes cost budget reject requests with 503 once daily budget is spent
+// and cancel context of invocations exceeding per-invocation budget instead of only logging
+func WithCostBudgetRejection() Option {
+	return func(s *service) {
+		if s.budget == nil {
+			WithCostBudget(0, 0)(s)
+		}
+		s.budget.reject = true
+	}
+}
+
+// WithCostReport logs duration and estimated cost of every request
+func WithCostReport() Option {
+	return func(s *service) {
+		if !s.costReport {
+			s.handlerMiddlewares = append(s.handlerMiddlewares, s.costReportMiddleware())
+		}
+		s.costReport = true
+	}
+}
+
+type costBudget struct {
+	maxPerInvocation float64
+	maxPerDay        float64
+	reject           bool
+
+	mu       sync.Mutex
+	day      string
+	dayTotal float64
+}
+
+func (b *costBudget) spent(day string) float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.day != day {
+		return 0
+	}
+	return b.dayTotal
+}
+
+func (b *costBudget) add(day string, cost float64) float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.day != day {
+		b.day, b.dayTotal = day, 0
+	}
+	b.dayTotal += cost
+	return b.dayTotal
+}
+
+func (s *service) costOf(d time.Duration) float64 {
+	return s.lambdaSize * float64(d.Milliseconds()) * s.lambdaCostPerMbPerMillisecond
+}
+
+func (s *service) costBudgetMiddleware() HandlerMiddleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b := s.budget
+			day := s.clock.Now().UTC().Format(time.DateOnly)
+			if b.maxPerDay > 0 && b.spent(day) >= b.maxPerDay {
+				s.logger.Warnf(r.Context(), "daily cost budget %f is exceeded: %f spent", b.maxPerDay, b.spent(day))
+				if b.reject {
+					http.Error(w, "daily cost budget is exceeded", http.StatusServiceUnavailable)
+					return
+				}
+			}
+			costPerMs := s.lambdaSize * s.lambdaCostPerMbPerMillisecond
+			if b.reject && b.maxPerInvocation > 0 && costPerMs > 0 {
+				ctx, cancel := context.WithTimeout(r.Context(), time.Duration(b.maxPerInvocation/costPerMs*float64(time.Millisecond)))
+				defer cancel()
+				r = r.WithContext(ctx)
+			}
+
+			startedAt := s.clock.Now()
+			next.ServeHTTP(w, r)
+			cost := s.costOf(s.clock.Since(startedAt))
+
+			if b.maxPerInvocation > 0 && cost > b.maxPerInvocation {
+				s.logger.Warnf(r.Context(), "invocation cost %f exceeds budget %f: %s %s", cost, b.maxPerInvocation, r.Method, r.URL.Path)
+			}
+			if total := b.add(day, cost); b.maxPerDay > 0 && total >= b.maxPerDay && total-cost < b.maxPerDay {
+				s.logger.Warnf(r.Context(), "daily cost budget %f is spent", b.maxPerDay)
+			}
+		})
+	}
+}
+
+func (s *service) costReportMiddleware() HandlerMiddleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			startedAt := s.clock.Now()
+			next.ServeHTTP(w, r)
+			duration := s.clock.Since(startedAt)
+			ctx := s.logger.WithValues(r.Context(), map[string]any{
+				"durationMs": duration.Milliseconds(),
+				"cost":       s.costOf(duration),
+			})
+			s.logger.Infof(ctx, "request %s %s took %s, estimated cost %f", r.Method, r.URL.Path, duration, s.costOf(duration))
+		})
+	}
+}
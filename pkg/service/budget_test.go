@@ -0,0 +1,49 @@
+package service_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/util/clocktest"
+)
+
+func TestCostBudget(t *testing.T) {
+	tests := []struct {
+		name           string
+		opts           []service.Option
+		wantSecondCall int
+	}{
+		{name: "warning only", opts: []service.Option{service.WithCostBudget(0, 1)}, wantSecondCall: http.StatusOK},
+		{
+			name:           "rejection",
+			opts:           []service.Option{service.WithCostBudget(0, 1), service.WithCostBudgetRejection()},
+			wantSecondCall: http.StatusServiceUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := clocktest.New(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+			h := servicetest.New(t, append([]service.Option{
+				service.WithClock(clock),
+				service.WithLambdaSize(1024),
+				service.WithLambdaCostPerMbPerMs(1),
+				service.WithRoutes(func(router service.HttpAdapterRouter) error {
+					router.GET("/api/slow", func(c service.HttpAdapter) error {
+						clock.Advance(5 * time.Millisecond)
+						c.JSON(http.StatusOK, map[string]string{"status": "ok"})
+						return nil
+					})
+					return nil
+				}),
+			}, tt.opts...)...)
+
+			assert.Equal(t, http.StatusOK, h.Invoke(http.MethodGet, "/api/slow", nil, nil).StatusCode)
+			assert.Equal(t, tt.wantSecondCall, h.Invoke(http.MethodGet, "/api/slow", nil, nil).StatusCode)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+const (
+	lambdaMemorySizeEnv = "AWS_LAMBDA_FUNCTION_MEMORY_SIZE"
+	awsRegionEnv        = "AWS_REGION"
+)
+
+// BuildInfo is reported by status endpoint with WithBuildInfo, VCS fields are only known when binary
+// is built from a checkout with VCS stamping enabled (default for go build)
+type BuildInfo struct {
+	Commit       string     `json:"commit,omitempty" yaml:"commit,omitempty"`
+	Modified     bool       `json:"modified,omitempty" yaml:"modified,omitempty"`
+	BuildTime    *time.Time `json:"buildTime,omitempty" yaml:"buildTime,omitempty"`
+	GoVersion    string     `json:"goVersion" yaml:"goVersion"`
+	MemorySizeMb float64    `json:"memorySizeMb,omitempty" yaml:"memorySizeMb,omitempty"`
+	Region       string     `json:"region,omitempty" yaml:"region,omitempty"`
+	Architecture string     `json:"architecture" yaml:"architecture"`
+}
+
+// WithBuildInfo adds commit, build time, Go version, memory size, region and architecture to the status endpoint
+func WithBuildInfo() Option {
+	return func(s *service) {
+		s.buildInfo = true
+	}
+}
+
+// readBuildInfo collects build info once, it does not change during the lifetime of the process
+func (s *service) readBuildInfo() *BuildInfo {
+	res := &BuildInfo{
+		GoVersion:    runtime.Version(),
+		MemorySizeMb: s.lambdaSize,
+		Region:       s.getenv(awsRegionEnv),
+		Architecture: runtime.GOARCH,
+	}
+	if size, err := strconv.ParseFloat(s.getenv(lambdaMemorySizeEnv), 64); err == nil {
+		res.MemorySizeMb = size
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return res
+	}
+	res.GoVersion = lo.CoalesceOrEmpty(info.GoVersion, res.GoVersion)
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			res.Commit = setting.Value
+		case "vcs.modified":
+			res.Modified = setting.Value == "true"
+		case "vcs.time":
+			if t, err := time.Parse(time.RFC3339, setting.Value); err == nil {
+				res.BuildTime = &t
+			}
+		}
+	}
+	return res
+}
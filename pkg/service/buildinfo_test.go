@@ -0,0 +1,34 @@
+package service_test
+
+import (
+	"net/http"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestBuildInfo(t *testing.T) {
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error { return nil })
+	env := map[string]string{"AWS_REGION": "eu-west-1", "AWS_LAMBDA_FUNCTION_MEMORY_SIZE": "512"}
+
+	h := servicetest.New(t, routes, service.WithBuildInfo(), service.WithEnv(func(name string) string { return env[name] }))
+	var status struct {
+		Build *service.BuildInfo `json:"build"`
+	}
+	require.NoError(t, h.Invoke(http.MethodGet, "/api/status", nil, nil).JSON(&status))
+	require.NotNil(t, status.Build)
+	assert.Equal(t, "eu-west-1", status.Build.Region)
+	assert.Equal(t, float64(512), status.Build.MemorySizeMb)
+	assert.Equal(t, runtime.GOARCH, status.Build.Architecture)
+	assert.NotEmpty(t, status.Build.GoVersion)
+
+	h = servicetest.New(t, routes)
+	status.Build = nil
+	require.NoError(t, h.Invoke(http.MethodGet, "/api/status", nil, nil).JSON(&status))
+	assert.Nil(t, status.Build, "build info is reported only when enabled")
+}
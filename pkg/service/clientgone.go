@@ -0,0 +1,89 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ErrClientGone is wrapped by errors of response writes once the client disconnected
+var ErrClientGone = errors.New("client is gone")
+
+type clientGoneKeyType struct{}
+
+var clientGoneKey clientGoneKeyType = struct{}{}
+
+type clientGone struct {
+	once sync.Once
+	ch   chan struct{}
+}
+
+func (g *clientGone) close() {
+	g.once.Do(func() {
+		close(g.ch)
+	})
+}
+
+// clientGoneOf returns channel closed when the client of the request is gone, it falls back to the context
+// being done for requests which did not pass clientGoneHandler
+func clientGoneOf(ctx context.Context) <-chan struct{} {
+	if g, ok := ctx.Value(clientGoneKey).(*clientGone); ok {
+		return g.ch
+	}
+	return ctx.Done()
+}
+
+// clientGoneHandler tracks the client of the request: it is gone once the request context of the server is
+// done or a response write fails, e.g. when function URL response stream is closed
+func (s *service) clientGoneHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gone := &clientGone{ch: make(chan struct{})}
+		stop := context.AfterFunc(r.Context(), gone.close)
+		defer stop()
+		next.ServeHTTP(&clientWriter{ResponseWriter: w, gone: gone}, r.WithContext(context.WithValue(r.Context(), clientGoneKey, gone)))
+	})
+}
+
+// clientWriter fails writes once the client is gone, so that handlers stop producing the response
+type clientWriter struct {
+	http.ResponseWriter
+	gone *clientGone
+}
+
+func (w *clientWriter) Write(p []byte) (int, error) {
+	select {
+	case <-w.gone.ch:
+		return 0, ErrClientGone
+	default:
+	}
+	n, err := w.ResponseWriter.Write(p)
+	if err != nil {
+		w.gone.close()
+		return n, fmt.Errorf("%w: %w", ErrClientGone, err)
+	}
+	return n, nil
+}
+
+// Flush flushes writers down the chain which support it, streaming lambda writers write through and have
+// nothing to flush
+func (w *clientWriter) Flush() {
+	if err := flushWriter(w.ResponseWriter); err != nil {
+		w.gone.close()
+	}
+}
+
+func (w *clientWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return hijacker.Hijack()
+	}
+	return nil, nil, errors.Errorf("ResponseWriter does not implement http.Hijacker")
+}
+
+func (w *clientWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,66 @@
+package service_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestClientGone(t *testing.T) {
+	for _, streaming := range []bool{false, true} {
+		t.Run(fmt.Sprintf("streaming: %t", streaming), func(t *testing.T) {
+			stopped := make(chan int, 1)
+			routes := service.WithRoutes(func(router service.HttpAdapterRouter) error {
+				router.GET("/api/events", func(c service.HttpAdapter) error {
+					c.SetHeader("Content-Type", "text/event-stream")
+					writer := c.Writer()
+					for i := 0; ; i++ {
+						select {
+						case <-c.ClientGone():
+							stopped <- i
+							return nil
+						case <-time.After(10 * time.Millisecond):
+						}
+						if _, err := fmt.Fprintf(writer, "data: %d\n\n", i); err != nil {
+							assert.ErrorIs(t, err, service.ErrClientGone)
+							stopped <- i
+							return nil
+						}
+						writer.Flush()
+					}
+				})
+				return nil
+			})
+			h := servicetest.New(t, routes, service.UseResponseStreaming(streaming), service.WithServerMode())
+			server := httptest.NewServer(h.Service.Handler())
+			defer server.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/events", nil)
+			require.NoError(t, err)
+			res, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			line, err := bufio.NewReader(res.Body).ReadString('\n')
+			require.NoError(t, err)
+			assert.Equal(t, "data: 0\n", line, "events are flushed as they are written")
+			cancel()
+			_ = res.Body.Close()
+
+			select {
+			case <-stopped:
+			case <-time.After(5 * time.Second):
+				t.Fatal("handler is not notified about the client gone")
+			}
+		})
+	}
+}
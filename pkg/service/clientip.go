@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+type (
+	sourceIPKeyType struct{}
+	clientIPKeyType struct{}
+)
+
+var (
+	sourceIPKey = sourceIPKeyType{}
+	clientIPKey = clientIPKeyType{}
+)
+
+// WithTrustedProxies makes client IP resolution follow X-Forwarded-For header through the proxies, entries are
+// IPs or CIDRs (e.g. CloudFront ranges in front of function URL); without trusted proxies the header is ignored
+// and the source IP of the event is the client IP
+func WithTrustedProxies(proxies ...string) Option {
+	return func(s *service) {
+		s.trustedProxies = append(s.trustedProxies, proxies...)
+	}
+}
+
+// ClientIP returns IP of the client which made the request, as resolved by the service
+func ClientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey).(string)
+	return ip
+}
+
+// withSourceIP stores source IP of the lambda event, adapters do not always keep it in RemoteAddr
+func withSourceIP(ctx context.Context, ip string) context.Context {
+	if ip == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, sourceIPKey, ip)
+}
+
+func parseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	return parseNetworks("trusted proxy", proxies)
+}
+
+// parseNetworks parses IPs and CIDRs, kind describes entries in errors
+func parseNetworks(kind string, entries []string) ([]*net.IPNet, error) {
+	res := make([]*net.IPNet, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, errors.Errorf("invalid %s %q", kind, entry)
+			}
+			res = append(res, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s %q", kind, entry)
+		}
+		res = append(res, network)
+	}
+	return res, nil
+}
+
+// containsIP reports whether ip is in any of the networks, unparseable IPs are in none
+func containsIP(networks []*net.IPNet, ip string) bool {
+	parsed := net.ParseIP(ip)
+	return parsed != nil && lo.SomeBy(networks, func(network *net.IPNet) bool { return network.Contains(parsed) })
+}
+
+// clientIPHandler resolves client IP once per request so that every engine reports the same RemoteIP
+func (s *service) clientIPHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ip := clientIP(r, s.trustedProxyNets); ip != "" {
+			r = r.WithContext(context.WithValue(r.Context(), clientIPKey, ip))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientIP walks X-Forwarded-For from the right while hops are trusted proxies, the first untrusted hop is the client
+func clientIP(r *http.Request, trusted []*net.IPNet) string {
+	peer, _ := r.Context().Value(sourceIPKey).(string)
+	if peer == "" {
+		peer = hostOf(r.RemoteAddr)
+	}
+	if !containsIP(trusted, peer) {
+		return peer
+	}
+	var hops []string
+	for _, value := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(value, ",")...)
+	}
+	res := peer
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := hostOf(hops[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+		res = hop
+		if !containsIP(trusted, hop) {
+			break
+		}
+	}
+	return res
+}
+
+// hostOf strips port from the address if there is one
+func hostOf(addr string) string {
+	addr = strings.TrimSpace(addr)
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return strings.Trim(addr, "[]")
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/awsutil/eventstest"
+)
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		sourceIP     string
+		forwardedFor []string
+		trusted      []string
+		want         string
+	}{
+		{name: "remote addr", remoteAddr: "198.51.100.7:4321", want: "198.51.100.7"},
+		{name: "remote addr without port", remoteAddr: "198.51.100.7", want: "198.51.100.7"},
+		{name: "source ip of the event", remoteAddr: "198.51.100.7", sourceIP: "203.0.113.10", want: "203.0.113.10"},
+		{
+			name:         "forwarded for is ignored without trusted proxies",
+			remoteAddr:   "198.51.100.7:4321",
+			forwardedFor: []string{"192.0.2.1"},
+			want:         "198.51.100.7",
+		},
+		{
+			name:         "forwarded for from untrusted peer is ignored",
+			remoteAddr:   "198.51.100.7:4321",
+			forwardedFor: []string{"192.0.2.1"},
+			trusted:      []string{"10.0.0.0/8"},
+			want:         "198.51.100.7",
+		},
+		{
+			name:         "first untrusted hop",
+			sourceIP:     "10.0.0.2",
+			forwardedFor: []string{"192.0.2.66, 192.0.2.1", "10.1.0.1"},
+			trusted:      []string{"10.0.0.0/8"},
+			want:         "192.0.2.1",
+		},
+		{
+			name:         "all hops trusted",
+			sourceIP:     "10.0.0.2",
+			forwardedFor: []string{"10.1.0.1"},
+			trusted:      []string{"10.0.0.0/8"},
+			want:         "10.1.0.1",
+		},
+		{
+			name:         "invalid hop stops the walk",
+			remoteAddr:   "192.0.2.9:80",
+			forwardedFor: []string{"192.0.2.1, garbage"},
+			trusted:      []string{"192.0.2.9"},
+			want:         "192.0.2.9",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trusted, err := parseTrustedProxies(tt.trusted)
+			require.NoError(t, err)
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			r = r.WithContext(withSourceIP(r.Context(), tt.sourceIP))
+			for _, value := range tt.forwardedFor {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+			assert.Equal(t, tt.want, clientIP(r, trusted))
+		})
+	}
+}
+
+func TestTrustedProxiesValidation(t *testing.T) {
+	_, err := New(context.Background(), WithRoutingType("function-url"), WithTrustedProxies("not-an-ip"),
+		WithRoutes(func(router HttpAdapterRouter) error { return nil }))
+	assert.EqualError(t, err, `invalid service configuration: invalid trusted proxy "not-an-ip"`)
+}
+
+func TestRemoteIPOfLambdaEvent(t *testing.T) {
+	svc, err := New(context.Background(), WithEnv(func(string) string { return "" }), WithRoutingType("api-gateway"),
+		WithRoutes(func(router HttpAdapterRouter) error {
+			router.GET("/api/ip", func(c HttpAdapter) error {
+				c.JSON(http.StatusOK, M{"ip": c.RemoteIP()})
+				return nil
+			})
+			return nil
+		}))
+	require.NoError(t, err)
+	s := svc.(*service)
+
+	res, err := s.ProxyLambdaApiGateway(context.Background(),
+		eventstest.APIGatewayProxyRequest(http.MethodGet, "/api/ip", eventstest.WithSourceIP("203.0.113.44")))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"ip":"203.0.113.44"}`, res.Body)
+
+	urlRes, err := s.ProxyLambdaFunctionURL(context.Background(),
+		eventstest.LambdaFunctionURLRequest(http.MethodGet, "/api/ip", eventstest.WithSourceIP("203.0.113.45")))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"ip":"203.0.113.45"}`, urlRes.(events.LambdaFunctionURLResponse).Body)
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const ColdStartKey = "coldStart"
+
+// processStartedAt approximates the moment runtime started the process
+var processStartedAt = time.Now()
+
+// InitStats describes time spent initializing the service before the first request
+type InitStats struct {
+	ProcessStartedAt  time.Time     `json:"processStartedAt" yaml:"processStartedAt"`
+	BeforeNew         time.Duration `json:"beforeNew" yaml:"beforeNew"` // package init and application code before New
+	Options           time.Duration `json:"options" yaml:"options"`
+	RouterBuild       time.Duration `json:"routerBuild" yaml:"routerBuild"`
+	RouteRegistration time.Duration `json:"routeRegistration" yaml:"routeRegistration"`
+	Total             time.Duration `json:"total" yaml:"total"` // process start until first request
+}
+
+type initTimer struct {
+	// guards stats, Total is set by the first request while InitStats may be read concurrently
+	mu       sync.Mutex
+	stats    InitStats
+	phaseEnd time.Time
+}
+
+func newInitTimer() *initTimer {
+	now := time.Now()
+	return &initTimer{
+		stats: InitStats{
+			ProcessStartedAt: processStartedAt,
+			BeforeNew:        now.Sub(processStartedAt),
+		},
+		phaseEnd: now,
+	}
+}
+
+// phase returns time since the previous phase ended
+func (t *initTimer) phase() time.Duration {
+	now := time.Now()
+	res := now.Sub(t.phaseEnd)
+	t.phaseEnd = now
+	return res
+}
+
+func (s *service) InitStats() InitStats {
+	s.init.mu.Lock()
+	defer s.init.mu.Unlock()
+	return s.init.stats
+}
+
+// markColdStart flags context of the very first request handled by this instance
+func (s *service) markColdStart(ctx context.Context) context.Context {
+	if !s.firstRequest.CompareAndSwap(false, true) {
+		return ctx
+	}
+	s.init.mu.Lock()
+	s.init.stats.Total = time.Since(processStartedAt)
+	s.init.mu.Unlock()
+	stats := s.InitStats()
+	ctx = s.logger.WithValue(ctx, ColdStartKey, true)
+	s.logger.Infof(s.logger.WithValue(ctx, "init", stats), "cold start took %s", stats.Total)
+	return ctx
+}
+
+func (s *service) initStatsOf(ctx context.Context) *InitStats {
+	if coldStart, _ := s.logger.GetValue(ctx, ColdStartKey).(bool); coldStart {
+		stats := s.InitStats()
+		return &stats
+	}
+	return nil
+}
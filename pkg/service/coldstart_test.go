@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+)
+
+func TestMarkColdStart(t *testing.T) {
+	s := &service{logger: logger.NewLogger(), init: newInitTimer()}
+
+	wg := sync.WaitGroup{}
+	coldStarts := make(chan context.Context, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = s.InitStats()
+			coldStarts <- s.markColdStart(context.Background())
+		}()
+	}
+	wg.Wait()
+	close(coldStarts)
+
+	var marked int
+	for ctx := range coldStarts {
+		if stats := s.initStatsOf(ctx); stats != nil {
+			marked++
+			assert.Positive(t, stats.Total)
+		}
+	}
+	assert.Equal(t, 1, marked)
+	assert.Positive(t, s.InitStats().Total)
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"bytes"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/observatory"
+)
+
+// configFile is the document parsed by FromConfigFile, JSON documents are parsed as YAML
+type configFile struct {
+	Port             string                  `yaml:"port"`
+	RoutingType      string                  `yaml:"routingType"`
+	Environment      Environment             `yaml:"environment"`
+	Swagger          *bool                   `yaml:"swagger"`
+	MaxBodySize      int64                   `yaml:"maxBodySize"`
+	TrustedProxies   []string                `yaml:"trustedProxies"`
+	IPFilter         *configIPFilter         `yaml:"ipFilter"`
+	Auth             configAuth              `yaml:"auth"`
+	RequestRecorders []configRequestRecorder `yaml:"requestRecorders"`
+	Observatory      *configObservatory      `yaml:"observatory"`
+}
+
+type configAuth struct {
+	Required       bool     `yaml:"required"`
+	Strict         bool     `yaml:"strict"`
+	SkipRoutes     []string `yaml:"skipRoutes"`
+	OptionalRoutes []string `yaml:"optionalRoutes"`
+	ApiKeyScopes   []string `yaml:"apiKeyScopes"`
+}
+
+type configIPFilter struct {
+	Allow []string `yaml:"allow"`
+	Deny  []string `yaml:"deny"`
+}
+
+type configRequestRecorder struct {
+	Target            string   `yaml:"target"`
+	SampleRate        float64  `yaml:"sampleRate"`
+	RedactHeaders     []string `yaml:"redactHeaders"`
+	RedactQueryParams []string `yaml:"redactQueryParams"`
+	RedactBodyFields  []string `yaml:"redactBodyFields"`
+	MaxBodyBytes      int      `yaml:"maxBodyBytes"`
+	MaxPendingSaves   int      `yaml:"maxPendingSaves"`
+	SkipPathPrefixes  []string `yaml:"skipPathPrefixes"`
+}
+
+type configObservatory struct {
+	BaseURI   string           `yaml:"baseURI"`
+	Module    string           `yaml:"module"`
+	Submodule string           `yaml:"submodule"`
+	Tags      observatory.Tags `yaml:"tags"`
+}
+
+// FromConfigFile parses YAML or JSON document at path into options, e.g. to tweak a deployment with mounted
+// config without code changes; only keys present in the document produce options and unknown keys are rejected:
+//
+//	port: "8080"
+//	routingType: function-url
+//	environment: prod
+//	swagger: false
+//	maxBodySize: 1048576
+//	trustedProxies: [10.0.0.0/8]
+//	ipFilter: {allow: [10.0.0.0/8], deny: [10.0.0.13]}
+//	auth: {required: true, strict: true, skipRoutes: [/api/public], optionalRoutes: [/api/catalog], apiKeyScopes: [admin]}
+//	requestRecorders: [{target: s3://bucket/prefix, sampleRate: 0.1}]
+//	observatory: {baseURI: https://observatory.example.com, module: orders}
+//
+// Options are applied in their order, append them after options set in code for the file to take precedence
+func FromConfigFile(path string) ([]Option, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read config file %q", path)
+	}
+	var cfg configFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, errors.Wrapf(err, "failed to parse config file %q", path)
+	}
+	opts, err := cfg.options()
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid config file %q", path)
+	}
+	return opts, nil
+}
+
+func (c configFile) options() ([]Option, error) {
+	var opts []Option
+	if c.Port != "" {
+		opts = append(opts, WithPort(c.Port))
+	}
+	if c.RoutingType != "" {
+		opts = append(opts, WithRoutingType(c.RoutingType))
+	}
+	if c.Environment != "" {
+		if _, ok := environmentProfiles[c.Environment]; !ok {
+			return nil, errors.Errorf("unknown environment %q", c.Environment)
+		}
+		opts = append(opts, WithEnvironment(c.Environment))
+	}
+	if c.Swagger != nil {
+		opts = append(opts, WithSwagger(*c.Swagger))
+	}
+	if c.MaxBodySize > 0 {
+		opts = append(opts, WithMaxBodySize(c.MaxBodySize))
+	}
+	if len(c.TrustedProxies) > 0 {
+		opts = append(opts, WithTrustedProxies(c.TrustedProxies...))
+	}
+	if c.IPFilter != nil {
+		opts = append(opts, WithIPFilter(c.IPFilter.Allow, c.IPFilter.Deny))
+	}
+	if c.Auth.Required {
+		opts = append(opts, WithRequiredAuth())
+	}
+	if c.Auth.Strict {
+		opts = append(opts, WithStrictAuth())
+	}
+	if len(c.Auth.SkipRoutes) > 0 {
+		opts = append(opts, WithSkipAuthRoutes(c.Auth.SkipRoutes...))
+	}
+	if len(c.Auth.OptionalRoutes) > 0 {
+		opts = append(opts, WithOptionalAuthRoutes(c.Auth.OptionalRoutes...))
+	}
+	if len(c.Auth.ApiKeyScopes) > 0 {
+		opts = append(opts, WithApiKeyScopes(c.Auth.ApiKeyScopes...))
+	}
+	for i, recorder := range c.RequestRecorders {
+		if recorder.Target == "" {
+			return nil, errors.Errorf("target of request recorder #%d is not set", i)
+		}
+		opts = append(opts, WithRequestRecorder(recorder.Target, RequestRecorderConfig{
+			SampleRate:        recorder.SampleRate,
+			RedactHeaders:     recorder.RedactHeaders,
+			RedactQueryParams: recorder.RedactQueryParams,
+			RedactBodyFields:  recorder.RedactBodyFields,
+			MaxBodyBytes:      recorder.MaxBodyBytes,
+			MaxPendingSaves:   recorder.MaxPendingSaves,
+			SkipPathPrefixes:  recorder.SkipPathPrefixes,
+		}))
+	}
+	if c.Observatory != nil {
+		if c.Observatory.BaseURI == "" {
+			return nil, errors.Errorf("observatory baseURI is not set")
+		}
+		var observatoryOpts []observatory.Option
+		if c.Observatory.Module != "" {
+			observatoryOpts = append(observatoryOpts, observatory.WithModule(c.Observatory.Module, c.Observatory.Submodule))
+		}
+		if len(c.Observatory.Tags) > 0 {
+			observatoryOpts = append(observatoryOpts, observatory.WithTags(c.Observatory.Tags))
+		}
+		opts = append(opts, WithObservatory(c.Observatory.BaseURI, observatoryOpts...))
+	}
+	return opts, nil
+}
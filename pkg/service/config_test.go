@@ -0,0 +1,85 @@
+package service_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestFromConfigFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		document string
+	}{
+		{
+			name: "yaml",
+			file: "config.yaml",
+			document: `
+port: "9090"
+auth:
+  required: true
+  skipRoutes: [/api/public]
+`,
+		},
+		{
+			name:     "json",
+			file:     "config.json",
+			document: `{"port": "9090", "auth": {"required": true, "skipRoutes": ["/api/public"]}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			require.NoError(t, os.WriteFile(path, []byte(tt.document), 0o600))
+			opts, err := service.FromConfigFile(path)
+			require.NoError(t, err)
+
+			opts = append([]service.Option{service.WithApiKey("key"), service.WithRoutes(func(router service.HttpAdapterRouter) error {
+				for _, route := range []string{"/api/public", "/api/private"} {
+					router.GET(route, func(c service.HttpAdapter) error {
+						c.JSON(http.StatusOK, service.M{})
+						return nil
+					})
+				}
+				return nil
+			})}, opts...)
+			h := servicetest.New(t, opts...)
+
+			assert.Equal(t, "9090", h.Service.Port())
+			assert.Equal(t, http.StatusOK, h.Invoke(http.MethodGet, "/api/public", nil, nil).StatusCode)
+			assert.Equal(t, http.StatusUnauthorized, h.Invoke(http.MethodGet, "/api/private", nil, nil).StatusCode)
+		})
+	}
+}
+
+func TestFromConfigFileErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		wantErr  string
+	}{
+		{name: "unknown key", document: "prot: 8080\n", wantErr: "failed to parse config file"},
+		{name: "unknown environment", document: "environment: qa\n", wantErr: `unknown environment "qa"`},
+		{name: "recorder without target", document: "requestRecorders: [{sampleRate: 0.5}]\n", wantErr: "target of request recorder #0 is not set"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.document), 0o600))
+			_, err := service.FromConfigFile(path)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	_, err := service.FromConfigFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorContains(t, err, "failed to read config file")
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// RequireContentType rejects requests having body of other media types with 415 Unsupported Media Type,
+// type may end with /* (e.g. text/*) and types with structured syntax suffix are accepted as well
+// (e.g. application/problem+json for application/json); use it with router.Use for a group of routes
+// or with Consumes for a single route
+func RequireContentType(mediaTypes ...string) HttpAdapterHandler {
+	return func(c HttpAdapter) error {
+		if err := checkContentType(c.Request(), mediaTypes); err != nil {
+			Fail(c, err)
+			c.AbortWithStatus(http.StatusUnsupportedMediaType)
+		}
+		return nil
+	}
+}
+
+// Consumes wraps handler of a single route to accept request bodies of media types only, see RequireContentType
+func Consumes(h HttpAdapterHandler, mediaTypes ...string) HttpAdapterHandler {
+	check := RequireContentType(mediaTypes...)
+	return func(c HttpAdapter) error {
+		if err := check(c); err != nil || c.IsAborted() {
+			return err
+		}
+		return h(c)
+	}
+}
+
+// checkContentType returns error with status 415 when request has body of unexpected media type
+func checkContentType(r *http.Request, mediaTypes []string) error {
+	if r.ContentLength == 0 || r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+	contentType := r.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err == nil && lo.SomeBy(mediaTypes, func(expected string) bool {
+		return mediaTypeMatches(strings.ToLower(expected), mediaType)
+	}) {
+		return nil
+	}
+	if contentType == "" {
+		contentType = "<none>"
+	}
+	return ErrorWithStatus(http.StatusUnsupportedMediaType, errors.New(
+		localize(r.Context(), r.Header.Get("Accept-Language"), MessageUnsupportedMediaType, contentType, strings.Join(mediaTypes, ", "))))
+}
+
+func mediaTypeMatches(expected, mediaType string) bool {
+	if prefix, ok := strings.CutSuffix(expected, "/*"); ok {
+		return expected == "*/*" || strings.HasPrefix(mediaType, prefix+"/")
+	}
+	if expected == mediaType {
+		return true
+	}
+	// e.g. application/problem+json is json as well
+	typ, subtype, _ := strings.Cut(expected, "/")
+	return subtype != "" && strings.HasPrefix(mediaType, typ+"/") && strings.HasSuffix(mediaType, "+"+subtype)
+}
@@ -0,0 +1,81 @@
+package service_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestRequireContentType(t *testing.T) {
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		ok := func(c service.HttpAdapter) error {
+			c.JSON(http.StatusOK, service.M{"ok": true})
+			return nil
+		}
+		router.POST("/api/items", service.Consumes(ok, "application/json"))
+		uploads := router.Group("/api/uploads")
+		uploads.Use(service.RequireContentType("image/*", "application/pdf"))
+		uploads.POST("/file", ok)
+		return nil
+	})
+
+	tests := []struct {
+		name        string
+		path        string
+		body        any
+		contentType string
+		wantStatus  int
+		wantError   string
+	}{
+		{name: "json", path: "/api/items", body: `{"name":"test"}`, contentType: "application/json; charset=utf-8", wantStatus: http.StatusOK},
+		{name: "structured syntax suffix", path: "/api/items", body: `{}`, contentType: "application/merge-patch+json", wantStatus: http.StatusOK},
+		{name: "empty body", path: "/api/items", wantStatus: http.StatusOK},
+		{
+			name:        "unexpected type",
+			path:        "/api/items",
+			body:        "name=test",
+			contentType: "application/x-www-form-urlencoded",
+			wantStatus:  http.StatusUnsupportedMediaType,
+			wantError:   "content type application/x-www-form-urlencoded is not supported, expected: application/json",
+		},
+		{
+			name:       "missing type",
+			path:       "/api/items",
+			body:       `{}`,
+			wantStatus: http.StatusUnsupportedMediaType,
+			wantError:  "content type <none> is not supported, expected: application/json",
+		},
+		{name: "wildcard", path: "/api/uploads/file", body: "png", contentType: "image/png", wantStatus: http.StatusOK},
+		{
+			name:        "group middleware",
+			path:        "/api/uploads/file",
+			body:        "text",
+			contentType: "text/plain",
+			wantStatus:  http.StatusUnsupportedMediaType,
+			wantError:   "content type text/plain is not supported, expected: image/*, application/pdf",
+		},
+	}
+	for _, tt := range tests {
+		for _, streaming := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s, streaming: %t", tt.name, streaming), func(t *testing.T) {
+				h := servicetest.New(t, routes, service.UseResponseStreaming(streaming))
+
+				res := h.Invoke(http.MethodPost, tt.path, tt.body, map[string]string{"Content-Type": tt.contentType})
+				require.Equal(t, tt.wantStatus, res.StatusCode, string(res.Body))
+				if tt.wantError == "" {
+					return
+				}
+				var body service.Response
+				require.NoError(t, res.JSON(&body))
+				require.NotNil(t, body.Meta.Error)
+				assert.Equal(t, tt.wantError, *body.Meta.Error)
+			})
+		}
+	}
+}
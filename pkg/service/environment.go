@@ -0,0 +1,81 @@
+package service
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+)
+
+const environmentEnv = "SIMPLE_CONTAINER_ENV"
+
+type Environment string
+
+const (
+	EnvironmentDev     Environment = "dev"
+	EnvironmentStaging Environment = "staging"
+	EnvironmentProd    Environment = "prod"
+)
+
+// environmentProfile holds defaults of an environment, explicit options and env variables override them
+type environmentProfile struct {
+	minLogLevel  string
+	prettyLogs   bool
+	requestDebug bool
+	swagger      bool
+	costReport   bool
+}
+
+var environmentProfiles = map[Environment]environmentProfile{
+	EnvironmentDev:     {minLogLevel: logger.Info, prettyLogs: true, requestDebug: true, swagger: true, costReport: true},
+	EnvironmentStaging: {minLogLevel: logger.Info, swagger: true},
+	EnvironmentProd:    {minLogLevel: logger.Warn},
+}
+
+// WithEnvironment adjusts defaults of the service to the environment (dev, staging or prod): log level, pretty
+// console logs, request debug mode, swagger exposure and per-request cost logs; SIMPLE_CONTAINER_ENV is used
+// when the option is not set, defaults are not adjusted when neither is set
+func WithEnvironment(name Environment) Option {
+	return func(s *service) {
+		s.environment = name
+	}
+}
+
+// resolveEnvironmentProfile returns profile of the environment set with option or env variable, nil when none is set
+func resolveEnvironmentProfile(environment Environment, getenv func(string) string) (*environmentProfile, error) {
+	if environment == "" {
+		environment = Environment(getenv(environmentEnv))
+	}
+	if environment == "" {
+		return nil, nil
+	}
+	profile, ok := environmentProfiles[environment]
+	if !ok {
+		return nil, errors.Errorf("unknown environment %q", environment)
+	}
+	return &profile, nil
+}
+
+func (p *environmentProfile) loggerOptions() []logger.Option {
+	if p == nil {
+		return nil
+	}
+	opts := []logger.Option{logger.WithMinLevel(p.minLogLevel)}
+	if p.prettyLogs {
+		opts = append(opts, logger.WithPrettyOutput())
+	}
+	return opts
+}
+
+func (p *environmentProfile) options() []Option {
+	if p == nil {
+		return nil
+	}
+	opts := []Option{WithSwagger(p.swagger)}
+	if p.requestDebug {
+		opts = append(opts, WithRequestDebugMode())
+	}
+	if p.costReport {
+		opts = append(opts, WithCostReport())
+	}
+	return opts
+}
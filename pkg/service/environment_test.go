@@ -0,0 +1,57 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestWithEnvironment(t *testing.T) {
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error { return nil })
+	tests := []struct {
+		name             string
+		envVar           string
+		opts             []service.Option
+		wantSwagger      bool
+		wantRequestDebug bool
+	}{
+		{name: "not set", wantSwagger: true},
+		{name: "dev", opts: []service.Option{service.WithEnvironment(service.EnvironmentDev)}, wantSwagger: true, wantRequestDebug: true},
+		{name: "staging", envVar: "staging", wantSwagger: true},
+		{name: "prod", envVar: "prod"},
+		{name: "option overrides env variable", envVar: "dev", opts: []service.Option{service.WithEnvironment(service.EnvironmentProd)}},
+		{
+			name:             "explicit options override defaults",
+			envVar:           "dev",
+			opts:             []service.Option{service.WithSwagger(false)},
+			wantRequestDebug: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := map[string]string{"SIMPLE_CONTAINER_ENV": tt.envVar}
+			opts := append([]service.Option{routes, service.WithEnv(func(name string) string { return env[name] })}, tt.opts...)
+			h := servicetest.New(t, opts...)
+
+			assert.Equal(t, tt.wantRequestDebug, h.Service.IsRequestDebugEnabled())
+			res := h.Invoke(http.MethodGet, "/api/swagger/index.html", nil, nil)
+			assert.Equal(t, tt.wantSwagger, res.StatusCode != http.StatusNotFound, "swagger status %d", res.StatusCode)
+		})
+	}
+}
+
+func TestUnknownEnvironment(t *testing.T) {
+	_, err := service.New(context.Background(),
+		service.WithEnv(func(string) string { return "" }),
+		service.WithRoutingType("function-url"),
+		service.WithEnvironment("qa"),
+		service.WithRoutes(func(router service.HttpAdapterRouter) error { return nil }))
+	require.Error(t, err)
+	assert.EqualError(t, err, `invalid service configuration: unknown environment "qa"`)
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"crypto/subtle"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+const (
+	faultInjectionEnv           = "SIMPLE_CONTAINER_FAULT_INJECTION"
+	defaultFaultInjectionHeader = "X-Fault-Injection"
+)
+
+// FaultInjectionConfig describes faults injected into requests, percentages are in range [0, 100]
+// and are evaluated independently for every request
+type FaultInjectionConfig struct {
+	LatencyPercent float64
+	Latency        time.Duration
+	ErrorPercent   float64
+	ErrorStatus    int // defaults to 503
+	DropPercent    float64
+	// Header enables faults for a single request when its value equals Secret (defaults to X-Fault-Injection),
+	// faults are enabled for all requests when SIMPLE_CONTAINER_FAULT_INJECTION=true
+	Header string
+	// Secret must be sent in Header to inject faults, header is ignored when Secret is empty since
+	// the middleware runs before authentication
+	Secret string
+}
+
+// WithFaultInjection injects latency, errors or dropped responses to validate resilience of callers
+func WithFaultInjection(cfg FaultInjectionConfig) Option {
+	return func(s *service) {
+		s.handlerMiddlewares = append(s.handlerMiddlewares, s.faultInjectionMiddleware(cfg))
+	}
+}
+
+func (s *service) faultInjectionMiddleware(cfg FaultInjectionConfig) HandlerMiddleware {
+	header := lo.If(cfg.Header != "", cfg.Header).Else(defaultFaultInjectionHeader)
+	errorStatus := lo.If(cfg.ErrorStatus != 0, cfg.ErrorStatus).Else(http.StatusServiceUnavailable)
+	enabledForAll := s.getenv(faultInjectionEnv) == "true"
+	enabledFor := func(r *http.Request) bool {
+		value := r.Header.Get(header)
+		return cfg.Secret != "" && subtle.ConstantTimeCompare([]byte(value), []byte(cfg.Secret)) == 1
+	}
+	hit := func(percent float64) bool {
+		return percent > 0 && rand.Float64()*100 < percent
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !enabledForAll && !enabledFor(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ctx := r.Context()
+			if hit(cfg.LatencyPercent) {
+				s.logger.Warnf(ctx, "fault injection: delaying request %s for %s", r.URL.Path, cfg.Latency)
+				select {
+				case <-time.After(cfg.Latency):
+				case <-ctx.Done():
+					return
+				}
+			}
+			if hit(cfg.ErrorPercent) {
+				s.logger.Warnf(ctx, "fault injection: failing request %s with %d", r.URL.Path, errorStatus)
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				w.WriteHeader(errorStatus)
+				_, _ = w.Write([]byte(`{"message":"injected fault"}`))
+				return
+			}
+			if hit(cfg.DropPercent) {
+				// handler still does the work, but caller never gets its response
+				s.logger.Warnf(ctx, "fault injection: dropping response of %s", r.URL.Path)
+				next.ServeHTTP(newResponseCapture(discardResponseWriter{header: http.Header{}}, 0), r)
+				w.WriteHeader(http.StatusGatewayTimeout)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (d discardResponseWriter) Header() http.Header {
+	return d.header
+}
+
+func (d discardResponseWriter) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func (d discardResponseWriter) WriteHeader(int) {}
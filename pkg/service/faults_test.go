@@ -0,0 +1,48 @@
+package service_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestFaultInjection(t *testing.T) {
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		router.GET("/api/ping", func(c service.HttpAdapter) error {
+			c.JSON(http.StatusOK, map[string]string{"status": "ok"})
+			return nil
+		})
+		return nil
+	})
+	tests := []struct {
+		name       string
+		env        map[string]string
+		secret     string
+		header     string
+		wantStatus int
+	}{
+		{name: "no header", secret: "s3cr3t", wantStatus: http.StatusOK},
+		{name: "header without configured secret", header: "true", wantStatus: http.StatusOK},
+		{name: "wrong secret", secret: "s3cr3t", header: "true", wantStatus: http.StatusOK},
+		{name: "matching secret", secret: "s3cr3t", header: "s3cr3t", wantStatus: http.StatusServiceUnavailable},
+		{name: "enabled for all requests", env: map[string]string{"SIMPLE_CONTAINER_FAULT_INJECTION": "true"}, wantStatus: http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := servicetest.New(t, routes,
+				service.WithEnv(func(name string) string { return tt.env[name] }),
+				service.WithFaultInjection(service.FaultInjectionConfig{ErrorPercent: 100, Secret: tt.secret}))
+
+			headers := map[string]string{}
+			if tt.header != "" {
+				headers["X-Fault-Injection"] = tt.header
+			}
+			res := h.Invoke(http.MethodGet, "/api/ping", nil, headers)
+			assert.Equal(t, tt.wantStatus, res.StatusCode)
+		})
+	}
+}
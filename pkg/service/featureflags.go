@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+)
+
+const featureFlagEnvPrefix = "FEATURE_"
+
+// FeatureFlags tells whether feature is enabled, context of the request is passed when flag is evaluated per request
+type FeatureFlags interface {
+	Enabled(ctx context.Context, flag string) bool
+}
+
+type FeatureFlagsFunc func(ctx context.Context, flag string) bool
+
+func (f FeatureFlagsFunc) Enabled(ctx context.Context, flag string) bool {
+	return f(ctx, flag)
+}
+
+// EnvFeatureFlags enables feature when FEATURE_<FLAG> env variable is true, e.g. FEATURE_NEW_SEARCH=true for new-search
+func EnvFeatureFlags(getenv func(string) string) FeatureFlags {
+	return FeatureFlagsFunc(func(_ context.Context, flag string) bool {
+		enabled, _ := strconv.ParseBool(getenv(featureFlagEnv(flag)))
+		return enabled
+	})
+}
+
+func featureFlagEnv(flag string) string {
+	return featureFlagEnvPrefix + strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, strings.ToUpper(flag))
+}
+
+// WithFeatureFlags sets feature flags evaluated by Feature routes, env variables are used by default (see EnvFeatureFlags)
+func WithFeatureFlags(flags FeatureFlags) Option {
+	return func(s *service) {
+		s.featureFlags = flags
+	}
+}
+
+type (
+	FeatureOption func(*featureRouter)
+)
+
+// PerRequest evaluates feature flag on every request instead of once at cold start, routes are always
+// registered then and respond with 404 while feature is disabled
+func PerRequest() FeatureOption {
+	return func(r *featureRouter) {
+		r.perRequest = true
+	}
+}
+
+// FeatureRouter is optionally implemented by HttpAdapterRouter to evaluate feature flags configured for the service
+type FeatureRouter interface {
+	Feature(flag string, opts ...FeatureOption) HttpAdapterRouter
+}
+
+// Feature returns router registering routes only when feature flag is enabled, flag is evaluated once at cold start
+// unless PerRequest is used; routers of the service use its feature flags, env variables are used otherwise
+func Feature(router HttpAdapterRouter, flag string, opts ...FeatureOption) HttpAdapterRouter {
+	if f, ok := router.(FeatureRouter); ok {
+		return f.Feature(flag, opts...)
+	}
+	return feature(router, EnvFeatureFlags(os.Getenv), nil, flag, opts...)
+}
+
+// GETIf registers GET route when feature flag is enabled, see Feature
+func GETIf(router HttpAdapterRouter, flag string, p string, h HttpAdapterHandler, opts ...FeatureOption) {
+	Feature(router, flag, opts...).GET(p, h)
+}
+
+// POSTIf registers POST route when feature flag is enabled, see Feature
+func POSTIf(router HttpAdapterRouter, flag string, p string, h HttpAdapterHandler, opts ...FeatureOption) {
+	Feature(router, flag, opts...).POST(p, h)
+}
+
+// featureRouter skips registration of routes when feature is disabled at cold start or guards their handlers
+// when flag is evaluated per request, middlewares are added to the delegate router
+type featureRouter struct {
+	delegate   HttpAdapterRouter
+	flags      FeatureFlags
+	logger     logger.Logger
+	flag       string
+	perRequest bool
+	enabled    bool
+}
+
+func feature(delegate HttpAdapterRouter, flags FeatureFlags, log logger.Logger, flag string, opts ...FeatureOption) HttpAdapterRouter {
+	r := &featureRouter{
+		delegate: delegate,
+		flags:    flags,
+		logger:   log,
+		flag:     flag,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if !r.perRequest {
+		r.enabled = flags.Enabled(context.Background(), flag)
+	}
+	return r
+}
+
+func (r *featureRouter) register(method, p string, h HttpAdapterHandler, register func(p string, h HttpAdapterHandler)) {
+	if r.perRequest {
+		register(p, r.guard(h))
+		return
+	}
+	if !r.enabled {
+		if r.logger != nil {
+			r.logger.Infof(context.Background(), "route %s %s is not registered: feature %q is disabled", method, p, r.flag)
+		}
+		return
+	}
+	register(p, h)
+}
+
+// guard responds with 404 while feature is disabled
+func (r *featureRouter) guard(h HttpAdapterHandler) HttpAdapterHandler {
+	return func(c HttpAdapter) error {
+		if !r.flags.Enabled(c.Context(), r.flag) {
+			c.JSON(http.StatusNotFound, M{"message": Localize(c, MessageNotFound)})
+			c.AbortWithStatus(http.StatusNotFound)
+			return nil
+		}
+		return h(c)
+	}
+}
+
+func (r *featureRouter) Use(mw HttpAdapterHandler) {
+	if r.perRequest || r.enabled {
+		r.delegate.Use(mw)
+	}
+}
+
+func (r *featureRouter) Any(p string, h HttpAdapterHandler) {
+	r.register("ANY", p, h, r.delegate.Any)
+}
+
+func (r *featureRouter) GET(p string, h HttpAdapterHandler) {
+	r.register(http.MethodGet, p, h, r.delegate.GET)
+}
+
+func (r *featureRouter) POST(p string, h HttpAdapterHandler) {
+	r.register(http.MethodPost, p, h, r.delegate.POST)
+}
+
+func (r *featureRouter) DELETE(p string, h HttpAdapterHandler) {
+	r.register(http.MethodDelete, p, h, r.delegate.DELETE)
+}
+
+func (r *featureRouter) PATCH(p string, h HttpAdapterHandler) {
+	r.register(http.MethodPatch, p, h, r.delegate.PATCH)
+}
+
+func (r *featureRouter) PUT(p string, h HttpAdapterHandler) {
+	r.register(http.MethodPut, p, h, r.delegate.PUT)
+}
+
+func (r *featureRouter) OPTIONS(p string, h HttpAdapterHandler) {
+	r.register(http.MethodOptions, p, h, r.delegate.OPTIONS)
+}
+
+func (r *featureRouter) HEAD(p string, h HttpAdapterHandler) {
+	r.register(http.MethodHead, p, h, r.delegate.HEAD)
+}
+
+func (r *featureRouter) Group(name string) HttpAdapterRouter {
+	return &featureRouter{
+		delegate:   r.delegate.Group(name),
+		flags:      r.flags,
+		logger:     r.logger,
+		flag:       r.flag,
+		perRequest: r.perRequest,
+		enabled:    r.enabled,
+	}
+}
+
+// Feature nests feature flags: routes are registered only when both features are enabled
+func (r *featureRouter) Feature(flag string, opts ...FeatureOption) HttpAdapterRouter {
+	return feature(r, r.flags, r.logger, flag, opts...)
+}
+
+// Feature evaluates feature flags configured for the service, routes of enabled features are recorded
+func (r *introspectingRouter) Feature(flag string, opts ...FeatureOption) HttpAdapterRouter {
+	return feature(r, r.flags, r.logger, flag, opts...)
+}
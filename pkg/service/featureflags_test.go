@@ -0,0 +1,95 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestFeatureRoutes(t *testing.T) {
+	ok := func(c service.HttpAdapter) error {
+		c.JSON(http.StatusOK, service.M{"ok": true})
+		return nil
+	}
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		service.GETIf(router, "new-search", "/api/search", ok)
+		service.GETIf(router, "beta", "/api/beta", ok, service.PerRequest())
+		service.Feature(router.Group("/api/v2"), "v2").POST("/items", ok)
+		return nil
+	})
+	var beta atomic.Bool
+	flags := service.WithFeatureFlags(service.FeatureFlagsFunc(func(_ context.Context, flag string) bool {
+		return flag == "beta" && beta.Load()
+	}))
+
+	tests := []struct {
+		name   string
+		env    map[string]string
+		opts   []service.Option
+		beta   bool
+		method string
+		path   string
+		want   int
+	}{
+		{
+			name:   "enabled by env",
+			env:    map[string]string{"FEATURE_NEW_SEARCH": "true"},
+			method: http.MethodGet,
+			path:   "/api/search",
+			want:   http.StatusOK,
+		},
+		{
+			name:   "disabled at cold start",
+			method: http.MethodGet,
+			path:   "/api/search",
+			want:   http.StatusNotFound,
+		},
+		{
+			name:   "group enabled by env",
+			env:    map[string]string{"FEATURE_V2": "1"},
+			method: http.MethodPost,
+			path:   "/api/v2/items",
+			want:   http.StatusOK,
+		},
+		{
+			name:   "enabled per request",
+			opts:   []service.Option{flags},
+			beta:   true,
+			method: http.MethodGet,
+			path:   "/api/beta",
+			want:   http.StatusOK,
+		},
+		{
+			name:   "disabled per request",
+			opts:   []service.Option{flags},
+			method: http.MethodGet,
+			path:   "/api/beta",
+			want:   http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			beta.Store(tt.beta)
+			opts := append([]service.Option{routes, service.WithEnv(func(name string) string { return tt.env[name] })}, tt.opts...)
+			h := servicetest.New(t, opts...)
+
+			res := h.Invoke(tt.method, tt.path, nil, nil)
+			assert.Equal(t, tt.want, res.StatusCode, string(res.Body))
+		})
+	}
+
+	t.Run("flag is re-evaluated per request", func(t *testing.T) {
+		beta.Store(false)
+		h := servicetest.New(t, routes, flags)
+		require.Equal(t, http.StatusNotFound, h.Invoke(http.MethodGet, "/api/beta", nil, nil).StatusCode)
+		beta.Store(true)
+		assert.Equal(t, http.StatusOK, h.Invoke(http.MethodGet, "/api/beta", nil, nil).StatusCode)
+	})
+}
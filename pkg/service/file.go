@@ -0,0 +1,40 @@
+package service
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// ServeFile responds with content as an attachment named name, content type is detected from the name extension
+// or sniffed from the content, Range and conditional requests are answered with partial content or not modified
+func ServeFile(w http.ResponseWriter, r *http.Request, content io.ReadSeeker, name string, modTime time.Time) {
+	if name != "" {
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	}
+	http.ServeContent(w, r, name, modTime, content)
+}
+
+// serveFilePath serves file at path, name defaults to the base name of the path
+func serveFilePath(w http.ResponseWriter, r *http.Request, path, name string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open file %s", path)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return errors.Wrapf(err, "failed to stat file %s", path)
+	}
+	if info.IsDir() {
+		return errors.Errorf("%s is a directory", path)
+	}
+	ServeFile(w, r, file, lo.If(name != "", name).Else(filepath.Base(path)), info.ModTime())
+	return nil
+}
@@ -0,0 +1,128 @@
+package service_test
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestFile(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "report.csv"), []byte("id,name\n1,a\n"), 0o600))
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		router.GET("/files/report", func(c service.HttpAdapter) error {
+			return c.File(filepath.Join(dir, "report.csv"), c.Query("name"))
+		})
+		router.GET("/files/missing", func(c service.HttpAdapter) error {
+			if err := c.File(filepath.Join(dir, "missing.csv"), ""); err != nil {
+				service.Fail(c, service.ErrorWithStatus(http.StatusNotFound, err))
+			}
+			return nil
+		})
+		router.GET("/files/content", func(c service.HttpAdapter) error {
+			c.FileContent(strings.NewReader("0123456789"), "digits.bin", modTime)
+			return nil
+		})
+		return nil
+	})
+
+	tests := []struct {
+		name            string
+		path            string
+		headers         map[string]string
+		wantStatus      int
+		wantBody        string
+		wantType        string
+		wantDisposition string
+		wantRange       string
+	}{
+		{
+			name:            "path",
+			path:            "/files/report",
+			wantStatus:      http.StatusOK,
+			wantBody:        "id,name\n1,a\n",
+			wantType:        "text/csv; charset=utf-8",
+			wantDisposition: `attachment; filename=report.csv`,
+		},
+		{
+			name:            "non ascii name",
+			path:            "/files/report?name=отчёт.csv",
+			wantStatus:      http.StatusOK,
+			wantBody:        "id,name\n1,a\n",
+			wantType:        "text/csv; charset=utf-8",
+			wantDisposition: `attachment; filename*=utf-8''%D0%BE%D1%82%D1%87%D1%91%D1%82.csv`,
+		},
+		{
+			name:            "content",
+			path:            "/files/content",
+			wantStatus:      http.StatusOK,
+			wantBody:        "0123456789",
+			wantType:        "application/octet-stream",
+			wantDisposition: `attachment; filename=digits.bin`,
+		},
+		{
+			name:            "range",
+			path:            "/files/content",
+			headers:         map[string]string{"Range": "bytes=2-5"},
+			wantStatus:      http.StatusPartialContent,
+			wantBody:        "2345",
+			wantType:        "application/octet-stream",
+			wantDisposition: `attachment; filename=digits.bin`,
+			wantRange:       "bytes 2-5/10",
+		},
+		{
+			name:            "suffix range",
+			path:            "/files/report",
+			headers:         map[string]string{"Range": "bytes=-4"},
+			wantStatus:      http.StatusPartialContent,
+			wantBody:        "1,a\n",
+			wantType:        "text/csv; charset=utf-8",
+			wantDisposition: `attachment; filename=report.csv`,
+			wantRange:       "bytes 8-11/12",
+		},
+		{
+			name:       "unsatisfiable range",
+			path:       "/files/content",
+			headers:    map[string]string{"Range": "bytes=20-30"},
+			wantStatus: http.StatusRequestedRangeNotSatisfiable,
+			wantRange:  "bytes */10",
+		},
+		{
+			name:       "not modified",
+			path:       "/files/content",
+			headers:    map[string]string{"If-Modified-Since": modTime.Format(http.TimeFormat)},
+			wantStatus: http.StatusNotModified,
+		},
+		{name: "missing file", path: "/files/missing", wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		for _, streaming := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s, streaming: %t", tt.name, streaming), func(t *testing.T) {
+				h := servicetest.New(t, routes, service.UseResponseStreaming(streaming))
+
+				res := h.Invoke(http.MethodGet, tt.path, nil, tt.headers)
+				require.Equal(t, tt.wantStatus, res.StatusCode, string(res.Body))
+				assert.Equal(t, tt.wantRange, res.Headers.Get("Content-Range"))
+				if tt.wantBody == "" {
+					return
+				}
+				assert.Equal(t, tt.wantBody, string(res.Body))
+				assert.Equal(t, tt.wantType, res.Headers.Get("Content-Type"))
+				assert.Equal(t, tt.wantDisposition, res.Headers.Get("Content-Disposition"))
+				assert.Equal(t, "bytes", res.Headers.Get("Accept-Ranges"))
+			})
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package service
+
+import (
+	"context"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/util"
+)
+
+// Group runs concurrent tasks sharing context which is canceled once a task fails, panics of tasks
+// are recovered as their errors and failures are logged with the name of the task
+type Group = util.BoundedGroup
+
+// NewGroup returns group of tasks bound to ctx
+func NewGroup(ctx context.Context, log logger.Logger) *Group {
+	return util.NewBoundedGroup(ctx, 0).WithLogger(log)
+}
+
+// Group returns group of tasks bound to ctx (e.g. of the request) and to the service, tasks are canceled
+// when either is done
+func (s *service) Group(ctx context.Context) *Group {
+	g := NewGroup(ctx, s.logger)
+	stop := context.AfterFunc(s.ctx, g.Cancel)
+	context.AfterFunc(g.Context(), func() { stop() })
+	return g
+}
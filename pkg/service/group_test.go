@@ -0,0 +1,89 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestGroup(t *testing.T) {
+	var svc service.Service
+	var canceled atomic.Bool
+	h := servicetest.New(t, service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		router.GET("/api/tasks/:mode", func(c service.HttpAdapter) error {
+			canceled.Store(false)
+			g := svc.Group(c.Context())
+			g.Go("slow", func(ctx context.Context) error {
+				select {
+				case <-ctx.Done():
+					canceled.Store(true)
+					return ctx.Err()
+				case <-time.After(100 * time.Millisecond):
+					return nil
+				}
+			})
+			g.Go("mode", func(ctx context.Context) error {
+				switch c.Param("mode") {
+				case "fail":
+					return errors.New("downstream is unavailable")
+				case "panic":
+					panic("nil map")
+				}
+				return nil
+			})
+			if err := g.Wait(); err != nil {
+				service.Fail(c, err)
+				return nil
+			}
+			service.OK(c, "done")
+			return nil
+		})
+		return nil
+	}))
+	svc = h.Service
+
+	tests := []struct {
+		mode         string
+		wantStatus   int
+		wantError    string
+		wantCanceled bool
+	}{
+		{mode: "ok", wantStatus: http.StatusOK},
+		{mode: "fail", wantStatus: http.StatusInternalServerError, wantError: "downstream is unavailable", wantCanceled: true},
+		{mode: "panic", wantStatus: http.StatusInternalServerError, wantError: "task mode panicked: nil map", wantCanceled: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			res := h.Invoke(http.MethodGet, "/api/tasks/"+tt.mode, nil, nil)
+			require.Equal(t, tt.wantStatus, res.StatusCode, string(res.Body))
+			assert.Equal(t, tt.wantCanceled, canceled.Load())
+			if tt.wantError == "" {
+				return
+			}
+			var body service.Response
+			require.NoError(t, res.JSON(&body))
+			require.NotNil(t, body.Meta.Error)
+			assert.Equal(t, tt.wantError, *body.Meta.Error)
+		})
+	}
+}
+
+func TestGroupServiceStop(t *testing.T) {
+	h := servicetest.New(t, service.WithRoutes(func(service.HttpAdapterRouter) error { return nil }))
+	g := h.Service.Group(context.Background())
+	g.Go("worker", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	h.Service.Stop()
+	assert.ErrorIs(t, g.Wait(), context.Canceled)
+}
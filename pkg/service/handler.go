@@ -0,0 +1,103 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// HandlerMiddleware wraps the root http handler of the service, it is applied regardless of
+// the engine and the way service is run (local server, buffered or streaming lambda)
+type HandlerMiddleware func(next http.Handler) http.Handler
+
+// handlerChain returns enabled built-in stages of the http handler, the first stage is the outermost one;
+// handler middlewares wrap the chain
+func (s *service) handlerChain() []HandlerMiddleware {
+	stages := []HandlerMiddleware{
+		lo.Ternary(s.problemDetails, s.problemDetailsHandler, nil),
+		s.clientGoneHandler,
+		s.serviceContextHandler,
+		lo.Ternary(!s.serverMode, s.responseSizeHandler, nil),
+		s.clientIPHandler,
+		lo.Ternary(s.maxBodySize > 0, s.maxBodySizeHandler, nil),
+		lo.Ternary(len(s.uploadInspectors) > 0, s.uploadInspectionHandler, nil),
+		s.stripBasePathHandler,
+		// rewriters are registered along with routes (e.g. swagger), so the stage is always there
+		s.rewriteRequestHandler,
+		s.responseHeadersHandler,
+		s.versionNegotiationHandler,
+		lo.Ternary(s.routeNormalization != nil, s.normalizeRouteHandler, nil),
+	}
+	return lo.Filter(stages, func(stage HandlerMiddleware, _ int) bool {
+		return stage != nil
+	})
+}
+
+func (s *service) wrapHandler(handler http.Handler) http.Handler {
+	return chainHandler(handler, s.handlerMiddlewares)
+}
+
+// chainHandler wraps handler with middlewares, the first middleware is the outermost one
+func chainHandler(handler http.Handler, middlewares []HandlerMiddleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// responseCapture keeps status code and up to maxBody bytes of the response written by the handler
+type responseCapture struct {
+	http.ResponseWriter
+	status      int
+	size        int
+	maxBody     int
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func newResponseCapture(w http.ResponseWriter, maxBody int) *responseCapture {
+	return &responseCapture{
+		ResponseWriter: w,
+		status:         http.StatusOK,
+		maxBody:        maxBody,
+	}
+}
+
+func (r *responseCapture) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.wroteHeader = true
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseCapture) Write(p []byte) (int, error) {
+	r.wroteHeader = true
+	if remaining := r.maxBody - r.body.Len(); remaining > 0 {
+		r.body.Write(p[:min(len(p), remaining)])
+	}
+	n, err := r.ResponseWriter.Write(p)
+	r.size += n
+	return n, err
+}
+
+func (r *responseCapture) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (r *responseCapture) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := r.ResponseWriter.(http.Hijacker); ok {
+		return hijacker.Hijack()
+	}
+	return nil, nil, errors.Errorf("ResponseWriter does not implement http.Hijacker")
+}
+
+func (r *responseCapture) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// routeHeaders are response headers of the routes under path prefix
+type routeHeaders struct {
+	prefix  string
+	headers map[string]string
+}
+
+// WithDefaultResponseHeaders sets headers of every response, e.g. Cache-Control, handlers may still override them
+func WithDefaultResponseHeaders(headers map[string]string) Option {
+	return WithRouteResponseHeaders("/", headers)
+}
+
+// WithRouteResponseHeaders sets headers of responses of the routes under path prefix, they override defaults
+// and headers of the shorter prefixes, empty value removes the header
+func WithRouteResponseHeaders(prefix string, headers map[string]string) Option {
+	return func(s *service) {
+		s.responseHeaders = append(s.responseHeaders, routeHeaders{
+			prefix:  "/" + strings.Trim(prefix, "/"),
+			headers: headers,
+		})
+	}
+}
+
+// responseHeadersOf merges headers of all prefixes matching path, longer prefixes win
+func (s *service) responseHeadersOf(path string) map[string]string {
+	var matched []routeHeaders
+	for _, h := range s.responseHeaders {
+		if h.prefix == "/" || stripPathPrefix(path, h.prefix) != path {
+			matched = append(matched, h)
+		}
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return len(matched[i].prefix) < len(matched[j].prefix)
+	})
+	res := map[string]string{}
+	for _, h := range matched {
+		for name, value := range h.headers {
+			res[name] = value
+		}
+	}
+	return res
+}
+
+// responseHeadersHandler sets configured headers before the handler is called so that handlers can override them
+func (s *service) responseHeadersHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for name, value := range s.responseHeadersOf(r.URL.Path) {
+			if value == "" {
+				w.Header().Del(name)
+				continue
+			}
+			w.Header().Set(name, value)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,61 @@
+package service_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestResponseHeaders(t *testing.T) {
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		ok := func(c service.HttpAdapter) error {
+			c.JSON(http.StatusOK, service.M{"ok": true})
+			return nil
+		}
+		router.GET("/api/items", ok)
+		router.GET("/api/public/items", ok)
+		router.GET("/api/custom", func(c service.HttpAdapter) error {
+			c.SetHeader("Cache-Control", "max-age=10")
+			return ok(c)
+		})
+		return nil
+	})
+	headers := []service.Option{
+		service.WithDefaultResponseHeaders(map[string]string{
+			"Cache-Control": "no-store",
+			"X-Service":     "orders",
+		}),
+		service.WithRouteResponseHeaders("/api/public/", map[string]string{
+			"Cache-Control": "public, max-age=60",
+			"X-Service":     "",
+		}),
+	}
+
+	tests := []struct {
+		name      string
+		streaming bool
+		path      string
+		want      map[string]string
+	}{
+		{name: "defaults", path: "/api/items", want: map[string]string{"Cache-Control": "no-store", "X-Service": "orders"}},
+		{name: "route overrides", path: "/api/public/items", want: map[string]string{"Cache-Control": "public, max-age=60", "X-Service": ""}},
+		{name: "handler overrides", path: "/api/custom", want: map[string]string{"Cache-Control": "max-age=10", "X-Service": "orders"}},
+		{name: "echo engine", streaming: true, path: "/api/public/items", want: map[string]string{"Cache-Control": "public, max-age=60", "X-Service": ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := servicetest.New(t, append(headers, routes, service.UseResponseStreaming(tt.streaming))...)
+
+			res := h.Invoke(http.MethodGet, tt.path, nil, nil)
+			require.Equal(t, http.StatusOK, res.StatusCode, string(res.Body))
+			for name, value := range tt.want {
+				assert.Equal(t, value, res.Headers.Get(name), name)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/samber/lo"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/util"
+)
+
+const defaultHeartbeatPayload = ": heartbeat\n\n"
+
+// StreamingHeartbeatConfig configures heartbeats sent by streaming responses while handler is computing.
+// When heartbeat is sent before handler responded, response is committed with status 200 and ContentType,
+// so routes using heartbeats must not rely on setting other status codes.
+type StreamingHeartbeatConfig struct {
+	Interval     time.Duration
+	Payload      string   // defaults to SSE comment, use " " for JSON responses (leading whitespace is valid JSON)
+	ContentType  string   // defaults to text/event-stream
+	PathPrefixes []string // routes to send heartbeats for, all routes when empty
+}
+
+// WithStreamingHeartbeat periodically writes heartbeat payload into streaming responses which were idle
+// for the interval, preventing Function URL and ALB idle timeouts
+func WithStreamingHeartbeat(cfg StreamingHeartbeatConfig) Option {
+	return func(s *service) {
+		cfg.Payload = lo.If(cfg.Payload != "", cfg.Payload).Else(defaultHeartbeatPayload)
+		cfg.ContentType = lo.If(cfg.ContentType != "", cfg.ContentType).Else("text/event-stream")
+		s.handlerMiddlewares = append(s.handlerMiddlewares, s.streamingHeartbeatMiddleware(cfg))
+	}
+}
+
+func (s *service) streamingHeartbeatMiddleware(cfg StreamingHeartbeatConfig) HandlerMiddleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !s.useResponseStreaming || cfg.Interval <= 0 || (len(cfg.PathPrefixes) > 0 && !lo.SomeBy(cfg.PathPrefixes, func(prefix string) bool {
+				return strings.HasPrefix(r.URL.Path, prefix)
+			})) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			hw := &heartbeatWriter{ResponseWriter: w, header: w.Header().Clone(), clock: s.clock, lastWrite: s.clock.Now()}
+			done := make(chan struct{})
+			wg := sync.WaitGroup{}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					timer := s.clock.NewTimer(cfg.Interval)
+					select {
+					case <-done:
+						timer.Stop()
+						return
+					case <-r.Context().Done():
+						timer.Stop()
+						return
+					case <-timer.C():
+						if err := hw.heartbeat(cfg); err != nil {
+							s.logger.Warnf(r.Context(), "failed to write heartbeat: %v", err)
+							return
+						}
+					}
+				}
+			}()
+			// heartbeat must not write into the response after handler returned
+			defer wg.Wait()
+			defer close(done)
+			next.ServeHTTP(hw, r)
+		})
+	}
+}
+
+// heartbeatWriter serializes handler and heartbeat writes, handler sets headers on its own map which is copied
+// into the response once handler commits it, so that heartbeat does not share the map with handler
+type heartbeatWriter struct {
+	http.ResponseWriter
+	header      http.Header
+	clock       util.Clock
+	mu          sync.Mutex
+	lastWrite   time.Time
+	wroteHeader bool
+}
+
+func (h *heartbeatWriter) heartbeat(cfg StreamingHeartbeatConfig) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.clock.Since(h.lastWrite) < cfg.Interval {
+		return nil
+	}
+	if !h.wroteHeader {
+		h.wroteHeader = true
+		h.ResponseWriter.Header().Set("Content-Type", cfg.ContentType)
+		h.ResponseWriter.WriteHeader(http.StatusOK)
+	}
+	h.lastWrite = h.clock.Now()
+	_, err := h.ResponseWriter.Write([]byte(cfg.Payload))
+	if flusher, ok := h.ResponseWriter.(http.Flusher); ok && err == nil {
+		flusher.Flush()
+	}
+	return err
+}
+
+func (h *heartbeatWriter) Header() http.Header {
+	return h.header
+}
+
+func (h *heartbeatWriter) WriteHeader(status int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.writeHeader(status)
+}
+
+// writeHeader commits handler headers unless heartbeat committed the response already, it is called with mu held
+func (h *heartbeatWriter) writeHeader(status int) {
+	if h.wroteHeader {
+		return
+	}
+	h.wroteHeader = true
+	header := h.ResponseWriter.Header()
+	for k := range header {
+		if _, ok := h.header[k]; !ok {
+			delete(header, k)
+		}
+	}
+	for k, v := range h.header {
+		header[k] = v
+	}
+	h.ResponseWriter.WriteHeader(status)
+}
+
+func (h *heartbeatWriter) Write(p []byte) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.writeHeader(http.StatusOK)
+	h.lastWrite = h.clock.Now()
+	return h.ResponseWriter.Write(p)
+}
+
+func (h *heartbeatWriter) Flush() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.writeHeader(http.StatusOK)
+	if flusher, ok := h.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (h *heartbeatWriter) Unwrap() http.ResponseWriter {
+	return h.ResponseWriter
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/util/clocktest"
+)
+
+// closingRecorder fails writes made after the handler chain returned
+type closingRecorder struct {
+	*httptest.ResponseRecorder
+	mu     sync.Mutex
+	closed bool
+	late   int
+}
+
+func (c *closingRecorder) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		c.late++
+	}
+	return c.ResponseRecorder.Write(p)
+}
+
+func (c *closingRecorder) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+}
+
+func TestStreamingHeartbeat(t *testing.T) {
+	tests := []struct {
+		name      string
+		streaming bool
+		path      string
+		wantBody  string
+	}{
+		{name: "idle handler gets heartbeats", streaming: true, path: "/api/stream", wantBody: ": heartbeat\n\n"},
+		{name: "buffered responses are not touched", streaming: false, path: "/api/stream"},
+		{name: "other prefixes are not touched", streaming: true, path: "/api/other"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := clocktest.New(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+			s := &service{logger: logger.NewLogger(), clock: clock, useResponseStreaming: tt.streaming}
+			cfg := StreamingHeartbeatConfig{
+				Interval:     5 * time.Millisecond,
+				Payload:      defaultHeartbeatPayload,
+				ContentType:  "text/event-stream",
+				PathPrefixes: []string{"/api/stream"},
+			}
+			rec := &closingRecorder{ResponseRecorder: httptest.NewRecorder()}
+			handler := s.streamingHeartbeatMiddleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.wantBody != "" {
+					// handler stays idle for the interval once heartbeat timer is started
+					require.Eventually(t, func() bool { return clock.Timers() > 0 }, time.Second, time.Millisecond)
+					clock.Advance(cfg.Interval)
+					require.Eventually(t, func() bool {
+						rec.mu.Lock()
+						defer rec.mu.Unlock()
+						return rec.Body.Len() > 0
+					}, time.Second, time.Millisecond)
+				} else {
+					clock.Advance(cfg.Interval)
+				}
+				_, _ = w.Write([]byte("data: done\n\n"))
+			}))
+
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			rec.close()
+			clock.Advance(cfg.Interval)
+
+			body := rec.Body.String()
+			assert.True(t, strings.HasSuffix(body, "data: done\n\n"), body)
+			if tt.wantBody != "" {
+				assert.Contains(t, body, tt.wantBody)
+				assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+			} else {
+				assert.Equal(t, "data: done\n\n", body)
+			}
+			rec.mu.Lock()
+			defer rec.mu.Unlock()
+			assert.Zero(t, rec.late, "heartbeat written after handler returned")
+		})
+	}
+}
+
+func TestStreamingHeartbeatHandlerHeaders(t *testing.T) {
+	clock := clocktest.New(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	s := &service{logger: logger.NewLogger(), clock: clock, useResponseStreaming: true}
+	cfg := StreamingHeartbeatConfig{Interval: 5 * time.Millisecond, Payload: defaultHeartbeatPayload, ContentType: "text/event-stream"}
+	rec := &closingRecorder{ResponseRecorder: httptest.NewRecorder()}
+	handler := s.streamingHeartbeatMiddleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Eventually(t, func() bool { return clock.Timers() > 0 }, time.Second, time.Millisecond)
+		// handler keeps setting headers while heartbeat commits the response, run with -race
+		for i := 0; i < 100; i++ {
+			clock.Advance(cfg.Interval)
+			w.Header().Set("X-Progress", strconv.Itoa(i))
+		}
+		require.Eventually(t, func() bool {
+			rec.mu.Lock()
+			defer rec.mu.Unlock()
+			return rec.Body.Len() > 0
+		}, time.Second, time.Millisecond)
+		_, _ = w.Write([]byte("data: done\n\n"))
+	}))
+
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/stream", nil))
+	rec.close()
+
+	assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+	assert.True(t, strings.HasSuffix(rec.Body.String(), "data: done\n\n"))
+}
+
+func TestHeartbeatWriterCommitsHandlerHeaders(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rec.Header().Set("X-Request-Id", "1")
+	rec.Header().Set("X-Removed", "1")
+	hw := &heartbeatWriter{ResponseWriter: rec, header: rec.Header().Clone(), clock: clocktest.New(time.Now())}
+
+	hw.Header().Set("Content-Type", "application/json")
+	hw.Header().Del("X-Removed")
+	assert.Empty(t, rec.Header().Get("Content-Type"), "headers are not shared before the response is committed")
+	hw.WriteHeader(http.StatusCreated)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, http.Header{"Content-Type": {"application/json"}, "X-Request-Id": {"1"}}, rec.Header())
+}
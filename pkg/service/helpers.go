@@ -3,10 +3,14 @@ package service
 import (
 	"context"
 	"encoding/json"
-	"fmt"
+	"io"
+	"mime"
 	"net/http"
 
 	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"github.com/ugorji/go/codec"
+	"gopkg.in/yaml.v3"
 )
 
 func WithReadBody[T any, R any](ctx context.Context, s Service, c HttpAdapter, action string, callback func(cfg *T) (*R, error)) (*R, bool) {
@@ -16,7 +20,7 @@ func WithReadBody[T any, R any](ctx context.Context, s Service, c HttpAdapter, a
 		res, err = callback(model)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, Error{
-				Message: fmt.Sprintf("failed to %s: %v", action, err),
+				Message: Localize(c, MessageActionFailed, action, err),
 				Meta:    s.GetMeta(ctx),
 			})
 			return res, false
@@ -25,19 +29,98 @@ func WithReadBody[T any, R any](ctx context.Context, s Service, c HttpAdapter, a
 	return res, true
 }
 
+// ReadBody decodes body according to its Content-Type: YAML (application/yaml), MessagePack (application/msgpack)
+// or JSON which is used by default
 func ReadBody[T any](ctx context.Context, s Service, c HttpAdapter) (*T, bool) {
 	var runConfig T
 	bodyBytes := ReadBytes(c.RequestBody())
-	if err := json.Unmarshal(bodyBytes, &runConfig); err != nil {
+	if err := unmarshalBody(c.Header("Content-Type"), bodyBytes, &runConfig); err != nil {
 		if s.IsRequestDebugEnabled() {
 			s.Logger().Errorf(ctx, "Failed to unmarshal request body: %v, got body: %q", err, string(bodyBytes))
 		} else {
 			s.Logger().Errorf(ctx, "Failed to unmarshal request body: %v", err)
 		}
 		c.JSON(500, Error{
-			Message: errors.Wrapf(err, "failed to unmarshal request body to Config").Error(),
+			Message: Localize(c, MessageInvalidBody, err),
 		})
 		return nil, false
 	}
 	return &runConfig, true
 }
+
+// ReadBodyStream decodes JSON body while reading it instead of buffering the whole body first like ReadBody does
+func ReadBodyStream[T any](ctx context.Context, s Service, c HttpAdapter) (*T, bool) {
+	var model T
+	decoder := json.NewDecoder(c.RequestBody())
+	err := decoder.Decode(&model)
+	if err == nil {
+		if _, err = decoder.Token(); err == io.EOF {
+			return &model, true
+		}
+		err = errors.Errorf("unexpected data after JSON value")
+	}
+	respondInvalidBody(ctx, s, c, err)
+	return nil, false
+}
+
+// ReadBodyStreamElements decodes JSON array body element by element calling callback for each of them,
+// so that memory is not held for the whole array; elements passed before failure are already processed
+func ReadBodyStreamElements[T any](ctx context.Context, s Service, c HttpAdapter, action string, callback func(item *T) error) bool {
+	decoder := json.NewDecoder(c.RequestBody())
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		respondInvalidBody(ctx, s, c, errors.Wrapf(lo.If(err != nil, err).Else(errors.Errorf("got %v", token)), "JSON array is expected"))
+		return false
+	}
+	for decoder.More() {
+		var item T
+		if err := decoder.Decode(&item); err != nil {
+			respondInvalidBody(ctx, s, c, err)
+			return false
+		}
+		if err := callback(&item); err != nil {
+			c.JSON(http.StatusInternalServerError, Error{
+				Message: Localize(c, MessageActionFailed, action, err),
+				Meta:    s.GetMeta(ctx),
+			})
+			return false
+		}
+	}
+	if _, err := decoder.Token(); err != nil {
+		respondInvalidBody(ctx, s, c, err)
+		return false
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		respondInvalidBody(ctx, s, c, errors.Errorf("unexpected data after JSON array"))
+		return false
+	}
+	return true
+}
+
+func respondInvalidBody(ctx context.Context, s Service, c HttpAdapter, err error) {
+	s.Logger().Errorf(ctx, "Failed to decode request body: %v", err)
+	code := http.StatusInternalServerError
+	if tooLarge := (*http.MaxBytesError)(nil); errors.As(err, &tooLarge) {
+		code = http.StatusRequestEntityTooLarge
+	}
+	c.JSON(code, Error{
+		Message: Localize(c, MessageInvalidBody, err),
+	})
+}
+
+var (
+	yamlMediaTypes    = []string{"application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml"}
+	msgpackMediaTypes = []string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"}
+)
+
+// unmarshalBody decodes YAML using yaml tags and MessagePack using json tags of the model
+func unmarshalBody(contentType string, data []byte, v any) error {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case lo.Contains(yamlMediaTypes, mediaType):
+		return yaml.Unmarshal(data, v)
+	case lo.Contains(msgpackMediaTypes, mediaType):
+		return codec.NewDecoderBytes(data, &codec.MsgpackHandle{}).Decode(v)
+	default:
+		return json.Unmarshal(data, v)
+	}
+}
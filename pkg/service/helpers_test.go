@@ -0,0 +1,128 @@
+package service_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ugorji/go/codec"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+type item struct {
+	Name string `json:"name" yaml:"name"`
+}
+
+func TestReadBodyStream(t *testing.T) {
+	var (
+		svc   service.Service
+		names []string
+	)
+	h := servicetest.New(t, service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		router.POST("/api/item", func(c service.HttpAdapter) error {
+			if body, ok := service.ReadBodyStream[item](c.Context(), svc, c); ok {
+				c.JSON(http.StatusOK, body)
+			}
+			return nil
+		})
+		router.POST("/api/items", func(c service.HttpAdapter) error {
+			names = nil
+			if service.ReadBodyStreamElements[item](c.Context(), svc, c, "import items", func(it *item) error {
+				if it.Name == "fail" {
+					return errors.New("item is rejected")
+				}
+				names = append(names, it.Name)
+				return nil
+			}) {
+				c.JSON(http.StatusOK, service.M{"count": len(names)})
+			}
+			return nil
+		})
+		return nil
+	}))
+	svc = h.Service
+
+	tests := []struct {
+		name        string
+		path        string
+		body        string
+		wantStatus  int
+		wantMessage string
+		wantNames   []string
+	}{
+		{name: "object", path: "/api/item", body: `{"name":"a"}`, wantStatus: http.StatusOK},
+		{name: "trailing data", path: "/api/item", body: `{"name":"a"} {}`, wantStatus: http.StatusInternalServerError, wantMessage: "failed to unmarshal request body to Config: unexpected data after JSON value"},
+		{name: "elements", path: "/api/items", body: `[{"name":"a"}, {"name":"b"}]`, wantStatus: http.StatusOK, wantNames: []string{"a", "b"}},
+		{name: "empty array", path: "/api/items", body: `[]`, wantStatus: http.StatusOK},
+		{name: "not an array", path: "/api/items", body: `{"name":"a"}`, wantStatus: http.StatusInternalServerError, wantMessage: "failed to unmarshal request body to Config: JSON array is expected: got {"},
+		{name: "invalid element", path: "/api/items", body: `[{"name":"a"}, 1]`, wantStatus: http.StatusInternalServerError, wantNames: []string{"a"},
+			wantMessage: "failed to unmarshal request body to Config: json: cannot unmarshal number into Go value of type service_test.item"},
+		{name: "callback failure", path: "/api/items", body: `[{"name":"a"}, {"name":"fail"}]`, wantStatus: http.StatusInternalServerError, wantNames: []string{"a"},
+			wantMessage: "failed to import items: item is rejected"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := h.Invoke(http.MethodPost, tt.path, tt.body, map[string]string{"Content-Type": "application/json"})
+			require.Equal(t, tt.wantStatus, res.StatusCode, string(res.Body))
+			if tt.wantMessage != "" {
+				var body service.Error
+				require.NoError(t, res.JSON(&body))
+				assert.Equal(t, tt.wantMessage, body.Message)
+			}
+			if tt.path == "/api/items" {
+				assert.Equal(t, tt.wantNames, names)
+			}
+		})
+	}
+}
+
+func TestReadBody(t *testing.T) {
+	var svc service.Service
+	h := servicetest.New(t, service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		router.POST("/api/item", func(c service.HttpAdapter) error {
+			if body, ok := service.ReadBody[item](c.Context(), svc, c); ok {
+				c.JSON(http.StatusOK, body)
+			}
+			return nil
+		})
+		return nil
+	}))
+	svc = h.Service
+
+	var msgpack []byte
+	require.NoError(t, codec.NewEncoderBytes(&msgpack, &codec.MsgpackHandle{}).Encode(map[string]string{"name": "packed"}))
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        any
+		wantStatus  int
+		wantName    string
+	}{
+		{name: "json", contentType: "application/json", body: `{"name":"a"}`, wantStatus: http.StatusOK, wantName: "a"},
+		{name: "json by default", body: `{"name":"a"}`, wantStatus: http.StatusOK, wantName: "a"},
+		{name: "yaml", contentType: "application/yaml; charset=utf-8", body: "name: b\n", wantStatus: http.StatusOK, wantName: "b"},
+		{name: "msgpack", contentType: "application/msgpack", body: msgpack, wantStatus: http.StatusOK, wantName: "packed"},
+		{name: "invalid yaml", contentType: "application/x-yaml", body: "name: [", wantStatus: http.StatusInternalServerError},
+		{name: "invalid msgpack", contentType: "application/msgpack", body: []byte{0xc1}, wantStatus: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := h.Invoke(http.MethodPost, "/api/item", tt.body, map[string]string{"Content-Type": tt.contentType})
+			require.Equal(t, tt.wantStatus, res.StatusCode, string(res.Body))
+			if tt.wantStatus != http.StatusOK {
+				var body service.Error
+				require.NoError(t, res.JSON(&body))
+				assert.Contains(t, body.Message, "failed to unmarshal request body")
+				return
+			}
+			var body item
+			require.NoError(t, res.JSON(&body))
+			assert.Equal(t, tt.wantName, body.Name)
+		})
+	}
+}
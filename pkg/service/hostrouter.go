@@ -0,0 +1,135 @@
+package service
+
+import (
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// WithHostRouter registers separate route trees keyed by Host header, pattern is either exact host name
+// (api.example.com) or wildcard (*.example.com); requests for other hosts are served by WithRoutes callback
+func WithHostRouter(routers map[string]RegisterRoutesCallback) Option {
+	return func(s *service) {
+		if s.hostRouters == nil {
+			s.hostRouters = make(map[string]RegisterRoutesCallback, len(routers))
+		}
+		for pattern, callback := range routers {
+			s.hostRouters[strings.ToLower(pattern)] = callback
+		}
+	}
+}
+
+// WithTrustedProxyHeaders makes host router match X-Forwarded-Host and Host headers of the event, enable it
+// only when service is reachable exclusively through a proxy which sets them (e.g. CloudFront in front of
+// function URL), otherwise clients can pick host routes by sending the headers
+func WithTrustedProxyHeaders() Option {
+	return func(s *service) {
+		s.trustProxyHeaders = true
+	}
+}
+
+type hostRoute struct {
+	pattern  string
+	handler  http.Handler
+	router   HttpAdapterRouter
+	callback RegisterRoutesCallback
+}
+
+func (h *hostRoute) matches(host string) bool {
+	if suffix, ok := strings.CutPrefix(h.pattern, "*"); ok {
+		return strings.HasSuffix(host, suffix)
+	}
+	return host == h.pattern
+}
+
+// newEngine creates router of the same kind as the default one (echo for streaming, gin otherwise)
+func (s *service) newEngine() (http.Handler, HttpAdapterRouter) {
+	if s.useResponseStreaming {
+		echoRouter := echo.New()
+		s.handleEchoNoRoute(echoRouter)
+		return echoRouter, EchoRouter(echoRouter, s.logger, s.localDebugMode)
+	}
+	ginRouter := s.newGinEngine()
+	return ginRouter, GinRouter(ginRouter, s.logger, s.localDebugMode)
+}
+
+func (s *service) newGinEngine() *gin.Engine {
+	engine := gin.New()
+	engine.Use(gin.Recovery())
+	// trailing slashes are handled by route normalization the same way for both engines
+	engine.RedirectTrailingSlash = s.routeNormalization == nil
+	s.handleGinNoRoute(engine)
+	return engine
+}
+
+func (s *service) initHostRoutes() {
+	for pattern, callback := range s.hostRouters {
+		handler, router := s.newEngine()
+		s.hostRoutes = append(s.hostRoutes, &hostRoute{
+			pattern: pattern,
+			handler: handler,
+			router: &introspectingRouter{
+				delegate: router,
+				registry: s.routes,
+				logger:   s.logger,
+				flags:    s.featureFlags,
+				host:     pattern,
+			},
+			callback: callback,
+		})
+	}
+	// exact hosts take precedence over wildcards, longer wildcards over shorter ones
+	sort.Slice(s.hostRoutes, func(i, j int) bool {
+		iWildcard, jWildcard := strings.HasPrefix(s.hostRoutes[i].pattern, "*"), strings.HasPrefix(s.hostRoutes[j].pattern, "*")
+		if iWildcard != jWildcard {
+			return !iWildcard
+		}
+		return len(s.hostRoutes[i].pattern) > len(s.hostRoutes[j].pattern)
+	})
+}
+
+func (s *service) registerHostRoutes() error {
+	for _, route := range s.hostRoutes {
+		s.useMiddlewares(route.router)
+		if s.registerStatusEndpoint == nil || lo.FromPtr(s.registerStatusEndpoint) {
+			route.router.GET(statusRoute, s.statusEndpoint)
+		}
+		if err := route.callback(route.router); err != nil {
+			return errors.Wrapf(err, "failed to register routes for host %q", route.pattern)
+		}
+	}
+	return nil
+}
+
+func (s *service) hostDispatchHandler(fallback http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := requestHost(r, s.trustProxyHeaders)
+		for _, route := range s.hostRoutes {
+			if route.matches(host) {
+				route.handler.ServeHTTP(w, r)
+				return
+			}
+		}
+		fallback.ServeHTTP(w, r)
+	})
+}
+
+// requestHost returns r.Host, which lambda adapters set to the domain name of the event; forwarded host
+// and Host header which adapters keep in headers are preferred only when proxy headers are trusted
+func requestHost(r *http.Request, trustProxyHeaders bool) string {
+	host := r.Host
+	if trustProxyHeaders {
+		host = lo.CoalesceOrEmpty(r.Header.Get("X-Forwarded-Host"), r.Header.Get("Host"), r.Host)
+	}
+	host = strings.ToLower(strings.TrimSpace(strings.Split(host, ",")[0]))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return host
+}
@@ -0,0 +1,75 @@
+package service_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestHostRouter(t *testing.T) {
+	routesFor := func(name string) service.RegisterRoutesCallback {
+		return func(router service.HttpAdapterRouter) error {
+			router.GET("/api/whoami", func(c service.HttpAdapter) error {
+				c.JSON(http.StatusOK, map[string]string{"router": name})
+				return nil
+			})
+			return nil
+		}
+	}
+	hostRouter := service.WithHostRouter(map[string]service.RegisterRoutesCallback{
+		"api.example.com": routesFor("api"),
+		"*.example.com":   routesFor("wildcard"),
+	})
+	tests := []struct {
+		name    string
+		url     string
+		headers map[string]string
+		trusted bool
+		want    string
+	}{
+		{name: "exact host", url: "http://api.example.com/api/whoami", want: "api"},
+		{name: "host with port", url: "http://API.example.com:443/api/whoami", want: "api"},
+		{name: "wildcard host", url: "http://admin.example.com/api/whoami", want: "wildcard"},
+		{name: "unknown host falls back", url: "http://other.com/api/whoami", want: "default"},
+		{
+			name:    "forwarded host is ignored by default",
+			url:     "http://other.com/api/whoami",
+			headers: map[string]string{"X-Forwarded-Host": "api.example.com", "Host": "api.example.com"},
+			want:    "default",
+		},
+		{
+			name:    "forwarded host of trusted proxy",
+			url:     "http://other.com/api/whoami",
+			headers: map[string]string{"X-Forwarded-Host": "api.example.com, cdn.example.com"},
+			trusted: true,
+			want:    "api",
+		},
+		{
+			name:    "host header of trusted proxy",
+			url:     "http://other.com/api/whoami",
+			headers: map[string]string{"Host": "admin.example.com"},
+			trusted: true,
+			want:    "wildcard",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := []service.Option{service.WithRoutes(routesFor("default")), hostRouter}
+			if tt.trusted {
+				opts = append(opts, service.WithTrustedProxyHeaders())
+			}
+			h := servicetest.New(t, opts...)
+
+			res := h.Invoke(http.MethodGet, tt.url, nil, tt.headers)
+			require.Equal(t, http.StatusOK, res.StatusCode, string(res.Body))
+			var body map[string]string
+			require.NoError(t, res.JSON(&body))
+			assert.Equal(t, tt.want, body["router"])
+		})
+	}
+}
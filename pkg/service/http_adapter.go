@@ -8,10 +8,12 @@ import (
 	"net"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/labstack/echo/v4"
 	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
 )
@@ -43,15 +45,33 @@ type HttpAdapter interface {
 	SetHeader(name, value string)
 	Writer() HttpWriterFlusher
 	JSON(code int, obj any)
+	// Proto responds with binary protobuf when Accept header prefers it, with protojson otherwise
+	Proto(code int, message proto.Message)
+	// File responds with file at path as an attachment named name, or base name of the path when name is empty,
+	// Range requests are answered with partial content
+	File(path, name string) error
+	// FileContent responds with content as an attachment, see ServeFile
+	FileContent(content io.ReadSeeker, name string, modTime time.Time)
 	RequestBody() io.Reader
 	Request() *http.Request
 	AbortWithStatus(status int)
+	// IsAborted reports whether AbortWithStatus was called, remaining middlewares and handler are skipped then
+	IsAborted() bool
 	RemoteIP() string
 	Query(name string) string
+	QueryArray(name string) []string
+	Header(name string) string
+	Headers() http.Header
 	Param(name string) string
 	FormFile(name string) (*multipart.FileHeader, error)
 	MultipartForm() (*multipart.Form, error)
+	// MultipartStream calls callback for each part as it arrives instead of buffering the upload, errors wrap
+	// *http.MaxBytesError when the body exceeds WithMaxBodySize
+	MultipartStream(callback func(part *multipart.Part) error) error
 	Redirect(code int, location string) error
+	// ClientGone is closed once the client disconnected or a response write failed, handlers producing long
+	// responses should stop then; writes fail with errors wrapping ErrClientGone afterwards
+	ClientGone() <-chan struct{}
 }
 
 type ginAdapter struct {
@@ -65,6 +85,18 @@ func (g *ginAdapter) Redirect(code int, location string) error {
 	return nil
 }
 
+func (g *ginAdapter) QueryArray(name string) []string {
+	return g.c.QueryArray(name)
+}
+
+func (g *ginAdapter) Header(name string) string {
+	return g.c.GetHeader(name)
+}
+
+func (g *ginAdapter) Headers() http.Header {
+	return g.c.Request.Header
+}
+
 func (g *ginAdapter) Param(name string) string {
 	return g.c.Param(name)
 }
@@ -81,6 +113,10 @@ func (g *ginAdapter) MultipartForm() (*multipart.Form, error) {
 	return g.c.MultipartForm()
 }
 
+func (g *ginAdapter) MultipartStream(callback func(part *multipart.Part) error) error {
+	return streamMultipart(g.c.Request, callback)
+}
+
 func (g *ginAdapter) SetContext(ctx context.Context) {
 	g.c.Request = g.Request().WithContext(ctx)
 }
@@ -89,7 +125,18 @@ func (g *ginAdapter) AbortWithStatus(status int) {
 	g.c.AbortWithStatus(status)
 }
 
+func (g *ginAdapter) IsAborted() bool {
+	return g.c.IsAborted()
+}
+
+func (g *ginAdapter) ClientGone() <-chan struct{} {
+	return clientGoneOf(g.Context())
+}
+
 func (g *ginAdapter) RemoteIP() string {
+	if ip := ClientIP(g.Context()); ip != "" {
+		return ip
+	}
 	return g.c.RemoteIP()
 }
 
@@ -103,6 +150,18 @@ func (e *echoAdapter) Redirect(code int, location string) error {
 	return e.c.Redirect(code, location)
 }
 
+func (e *echoAdapter) QueryArray(name string) []string {
+	return e.c.QueryParams()[name]
+}
+
+func (e *echoAdapter) Header(name string) string {
+	return e.c.Request().Header.Get(name)
+}
+
+func (e *echoAdapter) Headers() http.Header {
+	return e.c.Request().Header
+}
+
 func (e *echoAdapter) Param(name string) string {
 	return e.c.Param(name)
 }
@@ -119,15 +178,37 @@ func (e *echoAdapter) MultipartForm() (*multipart.Form, error) {
 	return e.c.MultipartForm()
 }
 
+func (e *echoAdapter) MultipartStream(callback func(part *multipart.Part) error) error {
+	return streamMultipart(e.c.Request(), callback)
+}
+
 func (e *echoAdapter) SetContext(ctx context.Context) {
 	e.c.SetRequest(e.c.Request().WithContext(ctx))
 }
 
+// abortedKey marks echo context as aborted, echo has no abort notion so router wrappers stop the chain
+const abortedKey = "simple-container.aborted"
+
 func (e *echoAdapter) AbortWithStatus(status int) {
-	e.c.Response().WriteHeader(status)
+	e.c.Set(abortedKey, true)
+	if !e.c.Response().Committed {
+		e.c.Response().WriteHeader(status)
+	}
+}
+
+func (e *echoAdapter) IsAborted() bool {
+	aborted, _ := e.c.Get(abortedKey).(bool)
+	return aborted
+}
+
+func (e *echoAdapter) ClientGone() <-chan struct{} {
+	return clientGoneOf(e.Context())
 }
 
 func (e *echoAdapter) RemoteIP() string {
+	if ip := ClientIP(e.Context()); ip != "" {
+		return ip
+	}
 	ip, _, err := net.SplitHostPort(strings.TrimSpace(e.Request().RemoteAddr))
 	if err != nil {
 		return ""
@@ -145,8 +226,6 @@ func (e *echoAdapter) SetHeader(name, value string) {
 
 type withEchoFlusher struct {
 	http.ResponseWriter
-	c          echo.Context
-	localDebug bool
 }
 
 func (w *withEchoFlusher) Hijack() (net.Conn, *bufio.ReadWriter, error) {
@@ -157,22 +236,77 @@ func (w *withEchoFlusher) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 	}
 }
 
+// Flush flushes the response in every mode, echo panics on writers not supporting it while streaming lambda
+// writers write through and have nothing to flush
 func (w *withEchoFlusher) Flush() {
-	if w.localDebug {
-		w.c.Response().Flush()
+	_ = flushWriter(w.ResponseWriter)
+}
+
+// ginFlushableWriter gives gin handlers the same HttpWriterFlusher behavior echo ones have: gin writer panics
+// on Flush when the writers down the chain do not support it, here such a flush is a logical chunk boundary only,
+// written chunks are delivered with the rest of the buffered response
+type ginFlushableWriter struct {
+	gin.ResponseWriter
+}
+
+func (w *ginFlushableWriter) Flush() {
+	w.WriteHeaderNow()
+	// gin writer itself asserts the next writer is http.Flusher, so the flush starts past it
+	if unwrapper, ok := w.ResponseWriter.(interface{ Unwrap() http.ResponseWriter }); ok {
+		_ = flushWriter(unwrapper.Unwrap())
 	}
 }
 
+// flushWriter flushes w when any writer down the chain supports it, http.ErrNotSupported is not reported
+func flushWriter(w http.ResponseWriter) error {
+	if err := http.NewResponseController(w).Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
+
 func (e *echoAdapter) Writer() HttpWriterFlusher {
 	return &withEchoFlusher{
 		ResponseWriter: e.c.Response().Writer,
-		c:              e.c,
-		localDebug:     e.localDebug,
 	}
 }
 
 func (e *echoAdapter) JSON(code int, obj any) {
-	_ = e.c.JSON(code, obj)
+	data, err := encodeJSON(obj)
+	if err != nil {
+		e.logger.Errorf(e.Context(), "failed to write response: %v", err)
+		e.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	if !bodyAllowed(code) || e.c.Request().Method == http.MethodHead {
+		e.c.Response().Header().Set(echo.HeaderContentType, JSONContentType)
+		e.c.Response().WriteHeader(code)
+		return
+	}
+	_ = e.c.Blob(code, JSONContentType, data)
+}
+
+func (e *echoAdapter) File(path, name string) error {
+	return serveFilePath(e.c.Response(), e.c.Request(), path, name)
+}
+
+func (e *echoAdapter) FileContent(content io.ReadSeeker, name string, modTime time.Time) {
+	ServeFile(e.c.Response(), e.c.Request(), content, name, modTime)
+}
+
+func (e *echoAdapter) Proto(code int, message proto.Message) {
+	contentType, data, err := EncodeProto(e.Header("Accept"), message)
+	if err != nil {
+		e.logger.Errorf(e.Context(), "failed to write response: %v", err)
+		e.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	if !bodyAllowed(code) || e.c.Request().Method == http.MethodHead {
+		e.c.Response().Header().Set(echo.HeaderContentType, contentType)
+		e.c.Response().WriteHeader(code)
+		return
+	}
+	_ = e.c.Blob(code, contentType, data)
 }
 
 func (e *echoAdapter) Request() *http.Request {
@@ -244,6 +378,10 @@ func (g *ginRouter) Group(name string) HttpAdapterRouter {
 	return GinRouter(g.router.Group(name), g.logger, g.localDebug)
 }
 
+func (g *ginRouter) Versioned(version string, callback RegisterRoutesCallback, opts ...VersionOption) error {
+	return versioned(g, g.logger, version, callback, opts...)
+}
+
 func (g *ginRouter) Any(p string, h HttpAdapterHandler) {
 	g.router.Any(p, GinAdapter(h, g.logger, g.localDebug))
 }
@@ -280,6 +418,7 @@ func (g *ginRouter) newGinAdapter(c *gin.Context) HttpAdapter {
 	return &ginAdapter{
 		c:          c,
 		localDebug: g.localDebug,
+		logger:     g.logger,
 	}
 }
 
@@ -303,6 +442,14 @@ func (e *echoGroup) Group(name string) HttpAdapterRouter {
 	}
 }
 
+func (e *echoGroup) Versioned(version string, callback RegisterRoutesCallback, opts ...VersionOption) error {
+	return versioned(e, e.logger, version, callback, opts...)
+}
+
+func (e *echoRouter) Versioned(version string, callback RegisterRoutesCallback, opts ...VersionOption) error {
+	return versioned(e, e.logger, version, callback, opts...)
+}
+
 func (e *echoRouter) Group(prefix string) HttpAdapterRouter {
 	return &echoGroup{
 		router:     e.router.Group(prefix),
@@ -349,6 +496,9 @@ func (e *echoGroup) Use(mw HttpAdapterHandler) {
 			if err := EchoAdapter(mw, e.logger, e.localDebug)(c); err != nil {
 				return err
 			}
+			if aborted, _ := c.Get(abortedKey).(bool); aborted {
+				return nil
+			}
 			return next(c)
 		}
 	})
@@ -392,6 +542,9 @@ func (e *echoRouter) Use(mw HttpAdapterHandler) {
 			if err := EchoAdapter(mw, e.logger, e.localDebug)(c); err != nil {
 				return err
 			}
+			if aborted, _ := c.Get(abortedKey).(bool); aborted {
+				return nil
+			}
 			return next(c)
 		}
 	})
@@ -414,11 +567,35 @@ func (g *ginAdapter) Next(h HttpAdapter) {
 }
 
 func (g *ginAdapter) Writer() HttpWriterFlusher {
-	return g.c.Writer
+	return &ginFlushableWriter{ResponseWriter: g.c.Writer}
 }
 
 func (g *ginAdapter) JSON(code int, obj any) {
-	g.c.JSON(code, obj)
+	data, err := encodeJSON(obj)
+	if err != nil {
+		g.logger.Errorf(g.Context(), "failed to write response: %v", err)
+		g.c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	g.c.Data(code, JSONContentType, data)
+}
+
+func (g *ginAdapter) Proto(code int, message proto.Message) {
+	contentType, data, err := EncodeProto(g.Header("Accept"), message)
+	if err != nil {
+		g.logger.Errorf(g.Context(), "failed to write response: %v", err)
+		g.c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	g.c.Data(code, contentType, data)
+}
+
+func (g *ginAdapter) File(path, name string) error {
+	return serveFilePath(g.c.Writer, g.c.Request, path, name)
+}
+
+func (g *ginAdapter) FileContent(content io.ReadSeeker, name string, modTime time.Time) {
+	ServeFile(g.c.Writer, g.c.Request, content, name, modTime)
 }
 
 func (g *ginAdapter) RequestBody() io.Reader {
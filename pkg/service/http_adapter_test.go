@@ -0,0 +1,94 @@
+package service_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestAbortStopsChain(t *testing.T) {
+	for _, streaming := range []bool{false, true} {
+		var handled, afterAbort atomic.Bool
+		h := servicetest.New(t, service.UseResponseStreaming(streaming), service.WithRoutes(func(router service.HttpAdapterRouter) error {
+			group := router.Group("/api")
+			group.Use(func(c service.HttpAdapter) error {
+				if c.Header("X-Block") != "" {
+					c.JSON(http.StatusForbidden, service.M{"message": "blocked"})
+					c.AbortWithStatus(http.StatusForbidden)
+					assert.True(t, c.IsAborted())
+				}
+				return nil
+			})
+			group.Use(func(c service.HttpAdapter) error {
+				afterAbort.Store(c.IsAborted())
+				return nil
+			})
+			group.GET("/items", func(c service.HttpAdapter) error {
+				handled.Store(true)
+				c.JSON(http.StatusOK, service.M{"items": []string{}})
+				return nil
+			})
+			return nil
+		}))
+
+		res := h.Invoke(http.MethodGet, "/api/items", nil, map[string]string{"X-Block": "true"})
+		assert.Equal(t, http.StatusForbidden, res.StatusCode, "streaming: %t", streaming)
+		assert.JSONEq(t, `{"message":"blocked"}`, string(res.Body), "streaming: %t", streaming)
+		assert.False(t, handled.Load(), "handler must not run after abort, streaming: %t", streaming)
+		assert.False(t, afterAbort.Load(), "middlewares must not run after abort, streaming: %t", streaming)
+
+		res = h.Invoke(http.MethodGet, "/api/items", nil, nil)
+		assert.Equal(t, http.StatusOK, res.StatusCode, "streaming: %t", streaming)
+		assert.True(t, handled.Load(), "streaming: %t", streaming)
+	}
+}
+
+// nonFlushingWriter hides Flush of the recorder as buffered lambda adapters do not support it
+type nonFlushingWriter struct {
+	http.ResponseWriter
+}
+
+func TestWriterFlush(t *testing.T) {
+	gin.SetMode(gin.ReleaseMode)
+	ginEngine := gin.New()
+	echoEngine := echo.New()
+	routers := map[string]struct {
+		router  service.HttpAdapterRouter
+		handler http.Handler
+	}{
+		"gin":  {router: service.GinRouter(ginEngine, logger.NewLogger(), false), handler: ginEngine},
+		"echo": {router: service.EchoRouter(echoEngine, logger.NewLogger(), false), handler: echoEngine},
+	}
+	for name, tt := range routers {
+		t.Run(name, func(t *testing.T) {
+			tt.router.GET("/api/events", func(c service.HttpAdapter) error {
+				c.SetHeader("Content-Type", "text/plain")
+				for i := 0; i < 3; i++ {
+					if _, err := fmt.Fprintf(c.Writer(), "chunk %d\n", i); err != nil {
+						return err
+					}
+					c.Writer().Flush()
+				}
+				return nil
+			})
+
+			rec := httptest.NewRecorder()
+			assert.NotPanics(t, func() {
+				tt.handler.ServeHTTP(nonFlushingWriter{ResponseWriter: rec}, httptest.NewRequest(http.MethodGet, "/api/events", nil))
+			})
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "chunk 0\nchunk 1\nchunk 2\n", rec.Body.String())
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/samber/lo"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/observatory"
+)
+
+// InspectionTagsKey is the logger context key of tags set by request inspectors
+const InspectionTagsKey = "inspection"
+
+type InspectionAction string
+
+const (
+	// InspectionPass lets the request through to the next inspector
+	InspectionPass InspectionAction = ""
+	// InspectionAllow lets the request through skipping the rest of inspectors
+	InspectionAllow InspectionAction = "allow"
+	// InspectionDeny rejects the request with 403
+	InspectionDeny InspectionAction = "deny"
+	// InspectionTag lets the request through to the next inspector tagging it with Tags
+	InspectionTag InspectionAction = "tag"
+)
+
+// InspectionResult is the verdict of an inspector, Tags of every result are kept regardless of the action
+type InspectionResult struct {
+	Action InspectionAction
+	Reason string
+	Tags   map[string]string
+}
+
+// RequestInspector inspects requests before auth, e.g. for bots or anomalies, errors are logged and the request
+// is passed to the next inspector
+type RequestInspector interface {
+	Inspect(ctx context.Context, r *http.Request) (InspectionResult, error)
+}
+
+// RequestInspectorFunc is a function implementing RequestInspector
+type RequestInspectorFunc func(ctx context.Context, r *http.Request) (InspectionResult, error)
+
+func (f RequestInspectorFunc) Inspect(ctx context.Context, r *http.Request) (InspectionResult, error) {
+	return f(ctx, r)
+}
+
+// WithRequestInspector runs inspectors in order before auth, see MiddlewareRequestInspection; tags of the results
+// are added to logger context under InspectionTagsKey and to observatory request metrics
+func WithRequestInspector(inspectors ...RequestInspector) Option {
+	return func(s *service) {
+		s.requestInspectors = append(s.requestInspectors, inspectors...)
+	}
+}
+
+var botUserAgentPattern = regexp.MustCompile(`(?i)bot|crawler|spider|scraper|curl|wget|python-requests|go-http-client|headless`)
+
+// BotUserAgentInspector tags requests of known automated clients with bot=true, and requests without user agent
+// with bot=unknown; deny rejects them instead
+func BotUserAgentInspector(deny bool) RequestInspector {
+	return RequestInspectorFunc(func(_ context.Context, r *http.Request) (InspectionResult, error) {
+		userAgent := r.UserAgent()
+		var bot string
+		switch {
+		case userAgent == "":
+			bot = "unknown"
+		case botUserAgentPattern.MatchString(userAgent):
+			bot = "true"
+		default:
+			return InspectionResult{}, nil
+		}
+		return InspectionResult{
+			Action: lo.Ternary(deny, InspectionDeny, InspectionTag),
+			Reason: "bot user agent " + userAgent,
+			Tags:   map[string]string{"bot": bot},
+		}, nil
+	})
+}
+
+// HeaderAnomalyInspector tags requests lacking headers every browser sends with anomaly listing them
+func HeaderAnomalyInspector() RequestInspector {
+	return RequestInspectorFunc(func(_ context.Context, r *http.Request) (InspectionResult, error) {
+		missing := lo.Filter([]string{"User-Agent", "Accept", "Accept-Language"}, func(header string, _ int) bool {
+			return r.Header.Get(header) == ""
+		})
+		if len(missing) == 0 {
+			return InspectionResult{}, nil
+		}
+		return InspectionResult{
+			Action: InspectionTag,
+			Reason: "missing headers " + strings.Join(missing, ", "),
+			Tags:   map[string]string{"anomaly": "missing " + strings.Join(missing, ",")},
+		}, nil
+	})
+}
+
+// InspectionRule is a WAF-style rule matching Pattern against the header, or against the unescaped path and
+// decoded query values when Header is empty
+type InspectionRule struct {
+	Name    string
+	Header  string
+	Pattern *regexp.Regexp
+	Action  InspectionAction
+}
+
+// RuleInspector applies action of the first matching rule tagging the request with rule=<name>
+func RuleInspector(rules ...InspectionRule) RequestInspector {
+	return RequestInspectorFunc(func(_ context.Context, r *http.Request) (InspectionResult, error) {
+		rule, found := lo.Find(rules, func(rule InspectionRule) bool {
+			if rule.Header == "" {
+				return rule.Pattern.MatchString(r.URL.Path) || lo.SomeBy(lo.Flatten(lo.Values(r.URL.Query())), rule.Pattern.MatchString)
+			}
+			return lo.SomeBy(r.Header.Values(rule.Header), rule.Pattern.MatchString)
+		})
+		if !found {
+			return InspectionResult{}, nil
+		}
+		return InspectionResult{
+			Action: rule.Action,
+			Reason: "rule " + rule.Name,
+			Tags:   map[string]string{"rule": rule.Name},
+		}, nil
+	})
+}
+
+func (s *service) requestInspectionMiddleware() HttpAdapterHandler {
+	return func(c HttpAdapter) error {
+		ctx := c.Context()
+		tags := map[string]string{}
+		var denied *InspectionResult
+		for _, inspector := range s.requestInspectors {
+			res, err := inspector.Inspect(ctx, c.Request())
+			if err != nil {
+				s.logger.Errorf(ctx, "failed to inspect request: %v", err)
+				continue
+			}
+			for k, v := range res.Tags {
+				tags[k] = v
+			}
+			if res.Action == InspectionDeny {
+				denied = &res
+			}
+			if res.Action == InspectionDeny || res.Action == InspectionAllow {
+				break
+			}
+		}
+		if len(tags) > 0 {
+			ctx = s.logger.WithValue(ctx, InspectionTagsKey, tags)
+			c.SetContext(ctx)
+			observatory.TagRequest(ctx, tags)
+		}
+		if denied == nil {
+			return nil
+		}
+		logger.LogSecurity(ctx, s.logger, SecurityEventInspectionDenied, securityFields(c, map[string]any{"reason": denied.Reason}))
+		c.JSON(http.StatusForbidden, M{"message": Localize(c, MessageForbidden)})
+		c.AbortWithStatus(http.StatusForbidden)
+		return nil
+	}
+}
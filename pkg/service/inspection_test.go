@@ -0,0 +1,82 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestWithRequestInspector(t *testing.T) {
+	var gotTags any
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		router.GET("/api/ping", func(c service.HttpAdapter) error {
+			gotTags = logger.NewLogger().GetValue(c.Context(), service.InspectionTagsKey)
+			c.JSON(http.StatusOK, service.M{})
+			return nil
+		})
+		return nil
+	})
+	failing := service.RequestInspectorFunc(func(context.Context, *http.Request) (service.InspectionResult, error) {
+		return service.InspectionResult{}, errors.New("inspector is down")
+	})
+	browser := map[string]string{"User-Agent": "Mozilla/5.0", "Accept": "*/*", "Accept-Language": "en"}
+	tests := []struct {
+		name       string
+		inspectors []service.RequestInspector
+		path       string
+		headers    map[string]string
+		wantStatus int
+		wantTags   any
+	}{
+		{name: "browser", inspectors: []service.RequestInspector{service.BotUserAgentInspector(false), service.HeaderAnomalyInspector()}, headers: browser, wantStatus: http.StatusOK},
+		{
+			name:       "tagged bot",
+			inspectors: []service.RequestInspector{service.BotUserAgentInspector(false), service.HeaderAnomalyInspector()},
+			headers:    map[string]string{"User-Agent": "curl/8.0"},
+			wantStatus: http.StatusOK,
+			wantTags:   map[string]string{"bot": "true", "anomaly": "missing Accept,Accept-Language"},
+		},
+		{name: "denied bot", inspectors: []service.RequestInspector{service.BotUserAgentInspector(true)}, wantStatus: http.StatusForbidden},
+		{
+			name: "rule",
+			inspectors: []service.RequestInspector{service.RuleInspector(
+				service.InspectionRule{Name: "sqli", Pattern: regexp.MustCompile(`(?i)union\s+select`), Action: service.InspectionDeny},
+			)},
+			path:       "/api/ping?q=1%20UNION%20SELECT%20password",
+			headers:    browser,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "allow skips the rest",
+			inspectors: []service.RequestInspector{
+				service.RuleInspector(service.InspectionRule{Name: "monitoring", Header: "User-Agent", Pattern: regexp.MustCompile(`^uptime-bot`), Action: service.InspectionAllow}),
+				service.BotUserAgentInspector(true),
+			},
+			headers:    map[string]string{"User-Agent": "uptime-bot/1.0"},
+			wantStatus: http.StatusOK,
+			wantTags:   map[string]string{"rule": "monitoring"},
+		},
+		{name: "failing inspector is skipped", inspectors: []service.RequestInspector{failing}, wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotTags = nil
+			h := servicetest.New(t, routes, service.WithRequestInspector(tt.inspectors...))
+			path := tt.path
+			if path == "" {
+				path = "/api/ping"
+			}
+
+			assert.Equal(t, tt.wantStatus, h.Invoke(http.MethodGet, path, nil, tt.headers).StatusCode)
+			assert.Equal(t, tt.wantTags, gotTags)
+		})
+	}
+}
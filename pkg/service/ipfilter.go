@@ -0,0 +1,66 @@
+package service
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+)
+
+type ipFilter struct {
+	allow     []string
+	deny      []string
+	allowNets []*net.IPNet
+	denyNets  []*net.IPNet
+}
+
+// WithIPFilter rejects requests with 403 unless client IP (resolved with WithTrustedProxies) is in one of allowCIDRs,
+// requests from denyCIDRs are rejected even when allowed; empty allowCIDRs allows all but denied, entries are IPs or CIDRs.
+// The filter runs before auth, see MiddlewareIPFilter
+func WithIPFilter(allowCIDRs, denyCIDRs []string) Option {
+	return func(s *service) {
+		s.ipFilter = &ipFilter{allow: allowCIDRs, deny: denyCIDRs}
+	}
+}
+
+func (s *service) initIPFilter() error {
+	if s.ipFilter == nil {
+		return nil
+	}
+	allowNets, err := parseNetworks("allowed network", s.ipFilter.allow)
+	if err != nil {
+		return err
+	}
+	denyNets, err := parseNetworks("denied network", s.ipFilter.deny)
+	if err != nil {
+		return err
+	}
+	s.ipFilter.allowNets, s.ipFilter.denyNets = allowNets, denyNets
+	return nil
+}
+
+// blockReason returns why ip is not let through, empty when it is
+func (f *ipFilter) blockReason(ip string) string {
+	switch {
+	case containsIP(f.denyNets, ip):
+		return "denied"
+	case len(f.allowNets) > 0 && !containsIP(f.allowNets, ip):
+		return "not allowed"
+	default:
+		return ""
+	}
+}
+
+func (s *service) ipFilterMiddleware() HttpAdapterHandler {
+	return func(c HttpAdapter) error {
+		ip := c.RemoteIP()
+		reason := s.ipFilter.blockReason(ip)
+		if reason == "" {
+			return nil
+		}
+		logger.LogSecurity(c.Context(), s.logger, SecurityEventIPBlocked, securityFields(c, map[string]any{"reason": reason}))
+		c.JSON(http.StatusForbidden, M{"message": Localize(c, MessageForbidden)})
+		c.AbortWithStatus(http.StatusForbidden)
+		return nil
+	}
+}
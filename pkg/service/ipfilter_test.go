@@ -0,0 +1,56 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestWithIPFilter(t *testing.T) {
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		router.GET("/api/ping", func(c service.HttpAdapter) error {
+			c.JSON(http.StatusOK, service.M{})
+			return nil
+		})
+		return nil
+	})
+	tests := []struct {
+		name       string
+		allow      []string
+		deny       []string
+		clientIP   string
+		wantStatus int
+	}{
+		{name: "allowed", allow: []string{"10.0.0.0/8"}, clientIP: "10.1.2.3", wantStatus: http.StatusOK},
+		{name: "not allowed", allow: []string{"10.0.0.0/8"}, clientIP: "198.51.100.7", wantStatus: http.StatusForbidden},
+		{name: "denied", deny: []string{"198.51.100.7"}, clientIP: "198.51.100.7", wantStatus: http.StatusForbidden},
+		{name: "not denied", deny: []string{"198.51.100.7"}, clientIP: "198.51.100.8", wantStatus: http.StatusOK},
+		{name: "deny wins over allow", allow: []string{"10.0.0.0/8"}, deny: []string{"10.0.0.0/16"}, clientIP: "10.0.1.1", wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// httptest requests come from 192.0.2.1 which forwards the client IP
+			h := servicetest.New(t, routes, service.WithTrustedProxies("192.0.2.1"), service.WithIPFilter(tt.allow, tt.deny))
+
+			res := h.Invoke(http.MethodGet, "/api/ping", nil, map[string]string{"X-Forwarded-For": tt.clientIP})
+			assert.Equal(t, tt.wantStatus, res.StatusCode)
+			if tt.wantStatus == http.StatusForbidden {
+				assert.JSONEq(t, `{"message":"access is forbidden"}`, string(res.Body))
+			}
+		})
+	}
+}
+
+func TestWithIPFilterInvalidNetwork(t *testing.T) {
+	_, err := service.New(context.Background(),
+		service.WithEnv(func(string) string { return "" }),
+		service.WithRoutingType("function-url"),
+		service.WithIPFilter(nil, []string{"10.0.0.0/33"}),
+		service.WithRoutes(func(router service.HttpAdapterRouter) error { return nil }))
+	assert.EqualError(t, err, `invalid service configuration: invalid denied network "10.0.0.0/33": invalid CIDR address: 10.0.0.0/33`)
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// JSONContentType is the content type of JSON responses of both engines
+const JSONContentType = "application/json; charset=utf-8"
+
+// M is an engine-neutral JSON object, use it instead of gin.H or echo.Map
+type M map[string]any
+
+// encodeJSON encodes responses of every engine the same way gin does: compact, HTML-escaped
+// and without trailing newline (echo encoder appends one)
+func encodeJSON(obj any) ([]byte, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to encode %T response", obj)
+	}
+	return data, nil
+}
+
+// bodyAllowed reports whether response with the status may have a body
+func bodyAllowed(status int) bool {
+	return status >= http.StatusOK && status != http.StatusNoContent && status != http.StatusNotModified
+}
@@ -0,0 +1,54 @@
+package service_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestJSONWireFormat(t *testing.T) {
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		router.GET("/api/html", func(c service.HttpAdapter) error {
+			c.JSON(http.StatusOK, service.M{"html": "<b>"})
+			return nil
+		})
+		router.GET("/api/invalid", func(c service.HttpAdapter) error {
+			c.JSON(http.StatusOK, service.M{"ch": make(chan int)})
+			return nil
+		})
+		return nil
+	})
+	tests := []struct {
+		name       string
+		path       string
+		headers    map[string]string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "html is escaped", path: "/api/html", wantStatus: http.StatusOK, wantBody: `{"html":"\u003cb\u003e"}`},
+		{name: "unauthorized", path: "/api/html", headers: map[string]string{}, wantStatus: http.StatusUnauthorized, wantBody: `{"message":"authorization key is not provided"}`},
+		{name: "encoding error", path: "/api/invalid", wantStatus: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := map[string]string{"Authorization": "Bearer key"}
+			if tt.headers != nil {
+				headers = tt.headers
+			}
+			for _, streaming := range []bool{false, true} {
+				h := servicetest.New(t, routes, service.WithApiKey("key"), service.UseResponseStreaming(streaming))
+				res := h.Invoke(http.MethodGet, tt.path, nil, headers)
+				require.Equal(t, tt.wantStatus, res.StatusCode, "streaming: %t", streaming)
+				assert.Equal(t, tt.wantBody, string(res.Body), "streaming: %t", streaming)
+				if tt.wantBody != "" {
+					assert.Equal(t, service.JSONContentType, res.Headers.Get("Content-Type"), "streaming: %t", streaming)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// streamJSONFlushSize is the amount of encoded items StreamJSONArray buffers before writing and flushing them
+const streamJSONFlushSize = 32 * 1024
+
+// JSONArrayIterator calls yield for each item in order, it stops and returns the error yield returns
+type JSONArrayIterator[T any] func(yield func(item T) error) error
+
+// StreamJSONArray responds with 200 and JSON array of items encoded as the iterator yields them, items are
+// flushed every 32KB so that the whole result is never held in memory and clients receive it progressively
+// when response streaming is used. When the iterator fails before any item is written nothing is written and
+// the error is returned for the handler to respond with; later failures leave the array unterminated for
+// clients to fail to parse the truncated response
+func StreamJSONArray[T any](c HttpAdapter, iterator JSONArrayIterator[T]) error {
+	writer := c.Writer()
+	var buf bytes.Buffer
+	started, written := false, 0
+	flush := func() error {
+		if !started {
+			started = true
+			c.SetHeader("Content-Type", JSONContentType)
+			writer.WriteHeader(http.StatusOK)
+		}
+		if _, err := writer.Write(buf.Bytes()); err != nil {
+			return errors.Wrapf(err, "failed to write JSON array")
+		}
+		buf.Reset()
+		writer.Flush()
+		return nil
+	}
+
+	buf.WriteByte('[')
+	err := iterator(func(item T) error {
+		if err := c.Context().Err(); err != nil {
+			return err
+		}
+		data, err := json.Marshal(item)
+		if err != nil {
+			return errors.Wrapf(err, "failed to marshal JSON array item %d", written)
+		}
+		if written > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(data)
+		written++
+		if buf.Len() >= streamJSONFlushSize {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		if started {
+			// items buffered so far are written for the truncated response to end at the failed item
+			_ = flush()
+		}
+		return err
+	}
+	buf.WriteByte(']')
+	return flush()
+}
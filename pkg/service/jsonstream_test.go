@@ -0,0 +1,73 @@
+package service_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestStreamJSONArray(t *testing.T) {
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		router.GET("/api/items", func(c service.HttpAdapter) error {
+			count, _ := strconv.Atoi(c.Query("count"))
+			err := service.StreamJSONArray(c, func(yield func(item service.M) error) error {
+				if c.Query("fail") != "" {
+					return errors.New("query failed")
+				}
+				for i := 0; i < count; i++ {
+					if err := yield(service.M{"index": i, "padding": strings.Repeat("x", 100)}); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				service.Fail(c, err)
+			}
+			return nil
+		})
+		return nil
+	})
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantItems  int
+	}{
+		{name: "empty", query: "count=0", wantStatus: http.StatusOK},
+		{name: "few items", query: "count=3", wantStatus: http.StatusOK, wantItems: 3},
+		{name: "items flushed in chunks", query: "count=1000", wantStatus: http.StatusOK, wantItems: 1000},
+		{name: "failed before first item", query: "fail=true", wantStatus: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		for _, streaming := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s, streaming: %t", tt.name, streaming), func(t *testing.T) {
+				h := servicetest.New(t, routes, service.UseResponseStreaming(streaming))
+
+				res := h.Invoke(http.MethodGet, "/api/items?"+tt.query, nil, nil)
+				require.Equal(t, tt.wantStatus, res.StatusCode, string(res.Body))
+				if tt.wantStatus != http.StatusOK {
+					return
+				}
+				assert.Equal(t, service.JSONContentType, res.Headers.Get("Content-Type"))
+				var items []service.M
+				require.NoError(t, json.Unmarshal(res.Body, &items))
+				require.Len(t, items, tt.wantItems)
+				for i, item := range items {
+					assert.EqualValues(t, i, item["index"])
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"os"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+// environment variables the lambda runtime sets for custom (provided.al2) and legacy go1.x runtimes, they are
+// read from the process environment as lambda.Start does regardless of WithEnv
+var lambdaRuntimeEnvs = []string{"AWS_LAMBDA_RUNTIME_API", "_LAMBDA_SERVER_PORT"}
+
+// WithLambdaOptions passes runtime options (e.g. JSON decoding settings) to lambda.StartWithOptions
+func WithLambdaOptions(opts ...lambda.Option) Option {
+	return func(s *service) {
+		s.lambdaOptions = append(s.lambdaOptions, opts...)
+	}
+}
+
+// startLambda validates handler and environment before handing control to the runtime, which
+// otherwise only reports such errors with log.Fatal or on every invocation
+func (s *service) startLambda() error {
+	startFunc := s.startFunc()
+	if err := validateLambdaHandler(startFunc); err != nil {
+		return errors.Wrapf(err, "invalid lambda handler")
+	}
+	if !lo.SomeBy(lambdaRuntimeEnvs, func(name string) bool { return os.Getenv(name) != "" }) {
+		return errors.Errorf("lambda runtime is not detected (none of %v is set), use LOCAL_DEBUG=true to serve requests locally", lambdaRuntimeEnvs)
+	}
+	startOpts := append([]lambda.Option{lambda.WithContext(s.ctx)}, s.lambdaOptions...)
+	if len(s.shutdownHooks) > 0 {
+		// enabling SIGTERM registers an internal extension, so it is done only when there are hooks to run
+		startOpts = append(startOpts, lambda.WithEnableSIGTERM(func() {
+			s.runShutdownHooks()
+		}))
+	}
+	s.Logger().Infof(context.Background(), "starting lambda handler...")
+	lambda.StartWithOptions(startFunc, startOpts...)
+	s.Logger().Infof(context.Background(), "finished lambda handler...")
+	return nil
+}
+
+// LambdaHandler returns handler of lambda events the runtime invokes, nil when routing type is not configured;
+// tests can invoke it with raw event payloads to exercise the same path as deployed functions
+func (s *service) LambdaHandler() lambda.Handler {
+	startFunc := s.startFunc()
+	if startFunc == nil {
+		return nil
+	}
+	return lambda.NewHandlerWithOptions(startFunc, append([]lambda.Option{lambda.WithContext(s.ctx)}, s.lambdaOptions...)...)
+}
+
+// startFunc returns the function lambda events are passed to, MigrateEvent is intercepted when migrations are used
+func (s *service) startFunc() any {
+	if s.migrationsFS != nil {
+		return s.migrationsLambdaStartFunc()
+	}
+	return s.lambdaStartFunc
+}
+
+// validateLambdaHandler applies the signature rules of lambda.Start
+func validateLambdaHandler(handler any) error {
+	if handler == nil {
+		return errors.Errorf("handler is nil, routing type is not configured")
+	}
+	if _, ok := handler.(lambda.Handler); ok {
+		return nil
+	}
+	handlerType := reflect.TypeOf(handler)
+	if handlerType.Kind() != reflect.Func {
+		return errors.Errorf("handler kind %s is not %s", handlerType.Kind(), reflect.Func)
+	}
+	contextType := reflect.TypeOf((*context.Context)(nil)).Elem()
+	isContext := func(t reflect.Type) bool {
+		return t.Kind() == reflect.Interface && contextType.Implements(t) && t.Implements(contextType)
+	}
+	switch handlerType.NumIn() {
+	case 0:
+	case 1:
+		if in := handlerType.In(0); in.Kind() == reflect.Interface && in.NumMethod() > 0 && !isContext(in) {
+			return errors.Errorf("handler takes an interface, but it is not context.Context: %q", in.Name())
+		}
+	case 2:
+		if !isContext(handlerType.In(0)) {
+			return errors.Errorf("handler takes two arguments, but the first is not context.Context, got %s", handlerType.In(0))
+		}
+	default:
+		return errors.Errorf("handler may not take more than two arguments, but handler takes %d", handlerType.NumIn())
+	}
+	errorType := reflect.TypeOf((*error)(nil)).Elem()
+	switch n := handlerType.NumOut(); {
+	case n > 2:
+		return errors.Errorf("handler may not return more than two values")
+	case n == 2 && !handlerType.Out(1).Implements(errorType):
+		return errors.Errorf("handler returns two values, but the second does not implement error")
+	case n == 1 && !handlerType.Out(0).Implements(errorType):
+		return errors.Errorf("handler returns a single value, but it does not implement error")
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+)
+
+func TestValidateLambdaHandler(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler any
+		wantErr string
+	}{
+		{name: "api gateway", handler: func(context.Context, events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			return events.APIGatewayProxyResponse{}, nil
+		}},
+		{name: "no arguments", handler: func() error { return nil }},
+		{name: "any event", handler: func(any) {}},
+		{name: "lambda handler", handler: lambda.NewHandler(func() {})},
+		{name: "nil", wantErr: "handler is nil, routing type is not configured"},
+		{name: "not a function", handler: "handler", wantErr: "handler kind string is not func"},
+		{name: "first argument is not context", handler: func(string, string) error { return nil }, wantErr: "handler takes two arguments, but the first is not context.Context, got string"},
+		{name: "too many arguments", handler: func(context.Context, string, string) {}, wantErr: "handler may not take more than two arguments, but handler takes 3"},
+		{name: "result without error", handler: func() string { return "" }, wantErr: "handler returns a single value, but it does not implement error"},
+		{name: "second result is not error", handler: func() (string, string) { return "", "" }, wantErr: "handler returns two values, but the second does not implement error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateLambdaHandler(tt.handler)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStartLambdaErrors(t *testing.T) {
+	t.Setenv("AWS_LAMBDA_RUNTIME_API", "")
+	t.Setenv("_LAMBDA_SERVER_PORT", "")
+	s := &service{ctx: context.Background(), logger: logger.NewLogger(), getenv: func(string) string { return "" }}
+
+	assert.EqualError(t, s.Start(), "invalid lambda handler: handler is nil, routing type is not configured")
+
+	s.lambdaStartFunc = s.ProxyLambdaApiGateway
+	assert.ErrorContains(t, s.Start(), "lambda runtime is not detected")
+}
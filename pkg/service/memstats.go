@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+)
+
+const (
+	// memoryWarnRatio is the share of lambda memory size after which memory usage is reported
+	memoryWarnRatio = 0.8
+	// memoryWarnInterval limits how often memory usage warning is logged
+	memoryWarnInterval = time.Minute
+)
+
+type memStatsKeyType struct{}
+
+var memStatsKey memStatsKeyType = struct{}{}
+
+// MemoryStats describes memory allocated and GC work done while processing request, values
+// are deltas except HeapInUse, Sys and Goroutines which are sampled when meta is collected
+// for the first time during the request
+type MemoryStats struct {
+	AllocBytes   uint64        `json:"allocBytes" yaml:"allocBytes"`
+	Mallocs      uint64        `json:"mallocs" yaml:"mallocs"`
+	NumGC        uint32        `json:"numGC" yaml:"numGC"`
+	GCPauseTotal time.Duration `json:"gcPauseTotal" yaml:"gcPauseTotal"`
+	HeapInUse    uint64        `json:"heapInUse" yaml:"heapInUse"`
+	Sys          uint64        `json:"sys" yaml:"sys"`
+	Goroutines   int           `json:"goroutines" yaml:"goroutines"`
+}
+
+type memStatsSample struct {
+	before runtime.MemStats
+	once   sync.Once
+	res    *MemoryStats
+}
+
+func withMemStatsSnapshot(ctx context.Context) context.Context {
+	sample := &memStatsSample{}
+	runtime.ReadMemStats(&sample.before)
+	return context.WithValue(ctx, memStatsKey, sample)
+}
+
+func (s *service) memoryStatsOf(ctx context.Context) *MemoryStats {
+	sample, ok := ctx.Value(memStatsKey).(*memStatsSample)
+	if !ok {
+		return nil
+	}
+	// ReadMemStats stops the world, so stats are sampled once however many times meta is collected
+	sample.once.Do(func() {
+		var after runtime.MemStats
+		runtime.ReadMemStats(&after)
+		before := sample.before
+		sample.res = &MemoryStats{
+			AllocBytes:   after.TotalAlloc - before.TotalAlloc,
+			Mallocs:      after.Mallocs - before.Mallocs,
+			NumGC:        after.NumGC - before.NumGC,
+			GCPauseTotal: time.Duration(after.PauseTotalNs - before.PauseTotalNs),
+			HeapInUse:    after.HeapInuse,
+			Sys:          after.Sys,
+			Goroutines:   runtime.NumGoroutine(),
+		}
+		if limit := s.lambdaSize * 1024 * 1024; limit > 0 && float64(after.Sys) > limit*memoryWarnRatio && s.allowMemoryWarning() {
+			s.logger.Warnf(s.logger.WithValue(ctx, "memory", sample.res), "memory usage %d MB is approaching lambda size %.0f MB",
+				after.Sys/1024/1024, s.lambdaSize)
+		}
+	})
+	return sample.res
+}
+
+func (s *service) allowMemoryWarning() bool {
+	now := time.Now().UnixNano()
+	last := s.memoryWarnedAt.Load()
+	return now-last >= int64(memoryWarnInterval) && s.memoryWarnedAt.CompareAndSwap(last, now)
+}
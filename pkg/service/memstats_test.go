@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+)
+
+func TestMemoryStatsSampledOnce(t *testing.T) {
+	s := &service{logger: logger.NewLogger()}
+	ctx := withMemStatsSnapshot(context.Background())
+
+	first := s.memoryStatsOf(ctx)
+	require.NotNil(t, first)
+	_ = make([]byte, 1<<20)
+	assert.Same(t, first, s.memoryStatsOf(ctx))
+	assert.Nil(t, s.memoryStatsOf(context.Background()))
+}
+
+func TestMemoryWarningRateLimit(t *testing.T) {
+	s := &service{}
+	assert.True(t, s.allowMemoryWarning())
+	assert.False(t, s.allowMemoryWarning())
+
+	s.memoryWarnedAt.Store(time.Now().Add(-memoryWarnInterval).UnixNano())
+	assert.True(t, s.allowMemoryWarning())
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+)
+
+// keys of the messages returned by the SDK, bundles may override any of them
+const (
+	MessageUnauthorized         = "unauthorized"
+	MessageForbidden            = "forbidden"
+	MessageTooManyRequests      = "tooManyRequests"
+	MessageInvalidBody          = "invalidBody"
+	MessageActionFailed         = "actionFailed"
+	MessageNotFound             = "notFound"
+	MessageMethodNotAllowed     = "methodNotAllowed"
+	MessageUnsupportedMediaType = "unsupportedMediaType"
+	MessageResponseTooLarge     = "responseTooLarge"
+)
+
+var defaultMessages = map[string]string{
+	MessageUnauthorized:         "authorization key is not provided",
+	MessageForbidden:            "access is forbidden",
+	MessageTooManyRequests:      "too many requests",
+	MessageInvalidBody:          "failed to unmarshal request body to Config: %v",
+	MessageActionFailed:         "failed to %s: %v",
+	MessageNotFound:             "resource is not found",
+	MessageMethodNotAllowed:     "method %s is not allowed",
+	MessageUnsupportedMediaType: "content type %s is not supported, expected: %s",
+	MessageResponseTooLarge:     "response exceeds %d bytes limit",
+}
+
+// WithMessageBundle loads localized messages from <language>.json files of fsys (e.g. de.json, pt-BR.json),
+// each file maps message key to fmt template; language is negotiated using Accept-Language header
+func WithMessageBundle(fsys fs.FS) Option {
+	return func(s *service) {
+		s.messageFS = fsys
+	}
+}
+
+type messageBundle struct {
+	tags     []language.Tag
+	messages []map[string]string
+	matcher  language.Matcher
+}
+
+func loadMessageBundle(fsys fs.FS) (*messageBundle, error) {
+	files, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list message files")
+	}
+	// english defaults go first so that they are used when nothing matches
+	bundle := &messageBundle{
+		tags:     []language.Tag{language.English},
+		messages: []map[string]string{defaultMessages},
+	}
+	for _, file := range files {
+		tag, err := language.Parse(strings.TrimSuffix(path.Base(file), ".json"))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid language of message file %q", file)
+		}
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read message file %q", file)
+		}
+		messages := map[string]string{}
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse message file %q", file)
+		}
+		if tag == language.English {
+			for key, message := range defaultMessages {
+				if _, ok := messages[key]; !ok {
+					messages[key] = message
+				}
+			}
+			bundle.messages[0] = messages
+			continue
+		}
+		bundle.tags = append(bundle.tags, tag)
+		bundle.messages = append(bundle.messages, messages)
+	}
+	bundle.matcher = language.NewMatcher(bundle.tags)
+	return bundle, nil
+}
+
+func (b *messageBundle) message(acceptLanguage, key string) string {
+	tags, _, _ := language.ParseAcceptLanguage(acceptLanguage)
+	_, index, confidence := b.matcher.Match(tags...)
+	if confidence != language.No {
+		if message, ok := b.messages[index][key]; ok {
+			return message
+		}
+	}
+	if message, ok := b.messages[0][key]; ok {
+		return message
+	}
+	return key
+}
+
+// Localize formats message with the key in the language negotiated from Accept-Language header of the request
+func Localize(c HttpAdapter, key string, args ...any) string {
+	return localize(c.Context(), c.Header("Accept-Language"), key, args...)
+}
+
+func localize(ctx context.Context, acceptLanguage, key string, args ...any) string {
+	template, ok := defaultMessages[key]
+	if !ok {
+		template = key
+	}
+	if s, ok := ctx.Value(serviceKey).(*service); ok && s.messages != nil {
+		template = s.messages.message(acceptLanguage, key)
+	}
+	if len(args) == 0 {
+		return template
+	}
+	return fmt.Sprintf(template, args...)
+}
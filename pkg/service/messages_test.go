@@ -0,0 +1,62 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestMessageBundle(t *testing.T) {
+	bundle := fstest.MapFS{
+		"de.json":    {Data: []byte(`{"unauthorized": "Autorisierungsschlüssel fehlt"}`)},
+		"pt-BR.json": {Data: []byte(`{"greeting": "olá %s"}`)},
+	}
+	h := servicetest.New(t,
+		service.WithApiKey("secret"),
+		service.WithMessageBundle(bundle),
+		service.WithRoutes(func(router service.HttpAdapterRouter) error {
+			router.GET("/api/greeting", func(c service.HttpAdapter) error {
+				c.JSON(http.StatusOK, map[string]string{"message": service.Localize(c, "greeting", "Ana")})
+				return nil
+			})
+			return nil
+		}))
+	tests := []struct {
+		name           string
+		path           string
+		acceptLanguage string
+		apiKey         string
+		wantMessage    string
+	}{
+		{name: "translated sdk message", path: "/api/greeting", acceptLanguage: "de-DE,de;q=0.9", wantMessage: "Autorisierungsschlüssel fehlt"},
+		{name: "default sdk message", path: "/api/greeting", acceptLanguage: "fr", wantMessage: "authorization key is not provided"},
+		{name: "application message", path: "/api/greeting", acceptLanguage: "pt-BR", apiKey: "secret", wantMessage: "olá Ana"},
+		{name: "missing translation falls back to default", path: "/api/greeting", acceptLanguage: "pt-BR", wantMessage: "authorization key is not provided"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := h.Invoke(http.MethodGet, tt.path, nil, map[string]string{
+				"Accept-Language": tt.acceptLanguage,
+				"Authorization":   "Bearer " + tt.apiKey,
+			})
+			var body map[string]string
+			require.NoError(t, res.JSON(&body))
+			assert.Equal(t, tt.wantMessage, body["message"])
+		})
+	}
+}
+
+func TestMessageBundleInvalidFile(t *testing.T) {
+	_, err := service.New(context.Background(), service.WithEnv(func(string) string { return "" }),
+		service.WithRoutingType("function-url"),
+		service.WithRoutes(func(router service.HttpAdapterRouter) error { return nil }),
+		service.WithMessageBundle(fstest.MapFS{"de.json": {Data: []byte(`not json`)}}))
+	assert.ErrorContains(t, err, `failed to parse message file "de.json"`)
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// names of the built-in stages of the middleware chain, in the order they run
+const (
+	MiddlewareRequestUID        = "requestUID"
+	MiddlewareTimeoutWatchdog   = "timeoutWatchdog"
+	MiddlewareDebugLog          = "debugLog"
+	MiddlewareIPFilter          = "ipFilter"
+	MiddlewareRequestPolicy     = "requestPolicy"
+	MiddlewareRequestInspection = "requestInspection"
+	MiddlewareAuth              = "auth"
+	MiddlewareUsageQuota        = "usageQuota"
+	MiddlewarePolicyEngine      = "policyEngine"
+)
+
+// NamedMiddleware is a router middleware of the chain installed on all route trees of the service, Handler
+// is nil for built-in stages which are disabled by configuration (e.g. auth without API key)
+type NamedMiddleware struct {
+	Name    string
+	Handler HttpAdapterHandler
+}
+
+type MiddlewareChain []NamedMiddleware
+
+// MiddlewareChainEdit modifies the chain, edits are applied in order of WithMiddlewareChain calls
+type MiddlewareChainEdit func(chain MiddlewareChain) (MiddlewareChain, error)
+
+// WithMiddlewareChain edits the middleware chain run before route handlers, e.g. to insert IP allow-listing
+// before auth or to replace the built-in auth; stages are referenced by name, New fails when a stage is not found
+func WithMiddlewareChain(edits ...MiddlewareChainEdit) Option {
+	return func(s *service) {
+		s.middlewareChainEdits = append(s.middlewareChainEdits, edits...)
+	}
+}
+
+// InsertMiddlewareBefore inserts mw named name right before stage
+func InsertMiddlewareBefore(stage, name string, mw HttpAdapterHandler) MiddlewareChainEdit {
+	return func(chain MiddlewareChain) (MiddlewareChain, error) {
+		i, err := chain.indexOf(stage)
+		if err != nil {
+			return nil, err
+		}
+		return chain.insert(i, NamedMiddleware{Name: name, Handler: mw})
+	}
+}
+
+// InsertMiddlewareAfter inserts mw named name right after stage
+func InsertMiddlewareAfter(stage, name string, mw HttpAdapterHandler) MiddlewareChainEdit {
+	return func(chain MiddlewareChain) (MiddlewareChain, error) {
+		i, err := chain.indexOf(stage)
+		if err != nil {
+			return nil, err
+		}
+		return chain.insert(i+1, NamedMiddleware{Name: name, Handler: mw})
+	}
+}
+
+// AppendMiddleware adds mw named name to the end of the chain, right before route handlers
+func AppendMiddleware(name string, mw HttpAdapterHandler) MiddlewareChainEdit {
+	return func(chain MiddlewareChain) (MiddlewareChain, error) {
+		return chain.insert(len(chain), NamedMiddleware{Name: name, Handler: mw})
+	}
+}
+
+// ReplaceMiddleware replaces handler of stage keeping its position, it also enables built-in stages
+// disabled by configuration
+func ReplaceMiddleware(stage string, mw HttpAdapterHandler) MiddlewareChainEdit {
+	return func(chain MiddlewareChain) (MiddlewareChain, error) {
+		i, err := chain.indexOf(stage)
+		if err != nil {
+			return nil, err
+		}
+		res := append(MiddlewareChain{}, chain...)
+		res[i].Handler = mw
+		return res, nil
+	}
+}
+
+// RemoveMiddleware removes stage from the chain
+func RemoveMiddleware(stage string) MiddlewareChainEdit {
+	return func(chain MiddlewareChain) (MiddlewareChain, error) {
+		i, err := chain.indexOf(stage)
+		if err != nil {
+			return nil, err
+		}
+		return append(append(MiddlewareChain{}, chain[:i]...), chain[i+1:]...), nil
+	}
+}
+
+// Names returns names of the stages in the order they run
+func (c MiddlewareChain) Names() []string {
+	return lo.Map(c, func(mw NamedMiddleware, _ int) string {
+		return mw.Name
+	})
+}
+
+func (c MiddlewareChain) indexOf(stage string) (int, error) {
+	_, i, found := lo.FindIndexOf(c, func(mw NamedMiddleware) bool {
+		return mw.Name == stage
+	})
+	if !found {
+		return -1, errors.Errorf("middleware %q is not found in chain %v", stage, c.Names())
+	}
+	return i, nil
+}
+
+func (c MiddlewareChain) insert(i int, mw NamedMiddleware) (MiddlewareChain, error) {
+	if mw.Name == "" {
+		return nil, errors.Errorf("middleware name is not set")
+	}
+	if _, err := c.indexOf(mw.Name); err == nil {
+		return nil, errors.Errorf("middleware %q is already in the chain", mw.Name)
+	}
+	res := make(MiddlewareChain, 0, len(c)+1)
+	res = append(append(append(res, c[:i]...), mw), c[i:]...)
+	return res, nil
+}
+
+// authMiddleware returns API key auth guarded by lockout when it is configured, nil without API key
+func (s *service) authMiddleware() HttpAdapterHandler {
+	if s.apiKey == "" {
+		return nil
+	}
+	if s.authLockout != nil {
+		return s.authLockoutMiddleware(s.apiKeyAuthMiddleware())
+	}
+	return s.apiKeyAuthMiddleware()
+}
+
+// buildMiddlewareChain returns built-in stages edited with WithMiddlewareChain
+func (s *service) buildMiddlewareChain() (MiddlewareChain, error) {
+	chain := MiddlewareChain{
+		{Name: MiddlewareRequestUID, Handler: s.requestUIDMiddleware()},
+		{Name: MiddlewareTimeoutWatchdog, Handler: lo.Ternary(s.timeoutWatchdogThreshold > 0, s.timeoutWatchdogMiddleware(), nil)},
+		{Name: MiddlewareDebugLog, Handler: s.debugLogMiddleware()},
+		{Name: MiddlewareIPFilter, Handler: lo.Ternary(s.ipFilter != nil, s.ipFilterMiddleware(), nil)},
+		{Name: MiddlewareRequestPolicy, Handler: lo.Ternary(s.requestPolicy != nil, s.requestPolicyMiddleware(), nil)},
+		{Name: MiddlewareRequestInspection, Handler: lo.Ternary(len(s.requestInspectors) > 0, s.requestInspectionMiddleware(), nil)},
+		{Name: MiddlewareAuth, Handler: s.authMiddleware()},
+		{Name: MiddlewareUsageQuota, Handler: lo.Ternary(s.usage != nil && s.usage.cfg.MonthlyQuota > 0, s.usageQuotaMiddleware(), nil)},
+		{Name: MiddlewarePolicyEngine, Handler: lo.Ternary(s.policyEngine != nil, s.policyEngineMiddleware(), nil)},
+	}
+	for _, edit := range s.middlewareChainEdits {
+		edited, err := edit(chain)
+		if err != nil {
+			return nil, err
+		}
+		chain = edited
+	}
+	return chain, nil
+}
@@ -0,0 +1,110 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestWithMiddlewareChain(t *testing.T) {
+	var calls []string
+	recording := func(name string) service.HttpAdapterHandler {
+		return func(c service.HttpAdapter) error {
+			calls = append(calls, name)
+			if c.Request().Header.Get("X-Block") == name {
+				c.JSON(http.StatusForbidden, service.M{"message": "blocked"})
+				c.AbortWithStatus(http.StatusForbidden)
+			}
+			return nil
+		}
+	}
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		router.GET("/api/ping", func(c service.HttpAdapter) error {
+			calls = append(calls, "handler")
+			c.JSON(http.StatusOK, service.M{})
+			return nil
+		})
+		return nil
+	})
+	h := servicetest.New(t, routes, service.WithApiKey("key"), service.WithMiddlewareChain(
+		service.InsertMiddlewareBefore(service.MiddlewareAuth, "allowList", recording("allowList")),
+		service.AppendMiddleware("last", recording("last")),
+	))
+
+	res := h.Invoke(http.MethodGet, "/api/ping", nil, map[string]string{"X-Block": "allowList"})
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	assert.Equal(t, []string{"allowList"}, calls)
+
+	calls = nil
+	res = h.Invoke(http.MethodGet, "/api/ping", nil, nil)
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode, "auth runs after inserted middleware")
+	assert.Equal(t, []string{"allowList"}, calls)
+
+	calls = nil
+	res = h.Invoke(http.MethodGet, "/api/ping", nil, map[string]string{"Authorization": "Bearer key"})
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, []string{"allowList", "last", "handler"}, calls)
+}
+
+func TestReplaceMiddleware(t *testing.T) {
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		router.GET("/api/ping", func(c service.HttpAdapter) error {
+			c.JSON(http.StatusOK, service.M{})
+			return nil
+		})
+		return nil
+	})
+	customAuth := func(c service.HttpAdapter) error {
+		if c.Request().Header.Get("X-Token") != "t0ken" {
+			c.JSON(http.StatusUnauthorized, service.M{})
+			c.AbortWithStatus(http.StatusUnauthorized)
+		}
+		return nil
+	}
+	h := servicetest.New(t, routes, service.WithMiddlewareChain(service.ReplaceMiddleware(service.MiddlewareAuth, customAuth)))
+
+	assert.Equal(t, http.StatusUnauthorized, h.Invoke(http.MethodGet, "/api/ping", nil, nil).StatusCode, "replaced auth is enabled without API key")
+	assert.Equal(t, http.StatusOK, h.Invoke(http.MethodGet, "/api/ping", nil, map[string]string{"X-Token": "t0ken"}).StatusCode)
+}
+
+func TestMiddlewareChainErrors(t *testing.T) {
+	noop := func(service.HttpAdapter) error { return nil }
+	tests := []struct {
+		name    string
+		edit    service.MiddlewareChainEdit
+		wantErr string
+	}{
+		{
+			name:    "unknown stage",
+			edit:    service.InsertMiddlewareAfter("cors", "custom", noop),
+			wantErr: `invalid middleware chain: middleware "cors" is not found in chain [requestUID timeoutWatchdog debugLog ipFilter requestPolicy requestInspection auth usageQuota policyEngine]`,
+		},
+		{
+			name:    "duplicate name",
+			edit:    service.AppendMiddleware(service.MiddlewareAuth, noop),
+			wantErr: `invalid middleware chain: middleware "auth" is already in the chain`,
+		},
+		{
+			name:    "removed stage",
+			edit:    service.RemoveMiddleware("custom"),
+			wantErr: `invalid middleware chain: middleware "custom" is not found in chain [requestUID timeoutWatchdog debugLog ipFilter requestPolicy requestInspection auth usageQuota policyEngine]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.New(context.Background(),
+				service.WithEnv(func(string) string { return "" }),
+				service.WithRoutingType("function-url"),
+				service.WithMiddlewareChain(tt.edit),
+				service.WithRoutes(func(router service.HttpAdapterRouter) error { return nil }))
+			require.Error(t, err)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/util/lock"
+)
+
+const (
+	migrateEnv         = "SIMPLE_CONTAINER_MIGRATE"
+	migrationsLockName = "migrations"
+	migrationsLockTTL  = time.Minute
+	migrationsTable    = "schema_migrations"
+)
+
+// MigrationConfig configures targets of the migrations:
+// *.sql files are executed against DB once and recorded in schema_migrations table (driver must support
+// multiple statements per Exec when files contain several of them), *.json files contain DynamoDB
+// CreateTable input and are applied unless the table already exists.
+// Concurrent runs are serialized via pkg/util/lock when LockTable is set
+type MigrationConfig struct {
+	DB        *sql.DB
+	DynamoDB  dynamodbiface.DynamoDBAPI
+	LockTable string
+}
+
+// MigrateEvent is the lambda invocation payload applying migrations instead of serving http request
+type MigrateEvent struct {
+	Migrate bool `json:"migrate"`
+}
+
+type MigrateResult struct {
+	Applied []string `json:"applied"`
+}
+
+// WithMigrations enables migrations invoke mode: they are applied when the lambda is invoked with MigrateEvent
+// payload or when SIMPLE_CONTAINER_MIGRATE=true, in which case Start exits once migrations are applied
+func WithMigrations(fsys fs.FS, cfg MigrationConfig) Option {
+	return func(s *service) {
+		s.migrationsFS = fsys
+		s.migrationConfig = cfg
+	}
+}
+
+// RunMigrations applies migrations from the root of fsys in the lexical order of file names
+func (s *service) RunMigrations(ctx context.Context, fsys fs.FS) error {
+	_, err := s.runMigrations(ctx, fsys)
+	return err
+}
+
+func (s *service) runMigrations(ctx context.Context, fsys fs.FS) ([]string, error) {
+	cfg := s.migrationConfig
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read migrations")
+	}
+	names := lo.FilterMap(entries, func(entry fs.DirEntry, _ int) (string, bool) {
+		ext := path.Ext(entry.Name())
+		return entry.Name(), !entry.IsDir() && (ext == ".sql" || ext == ".json")
+	})
+	sort.Strings(names)
+
+	if cfg.LockTable != "" {
+		if cfg.DynamoDB == nil {
+			return nil, errors.Errorf("migrations lock requires DynamoDB client")
+		}
+		lease, err := lock.New(cfg.DynamoDB, cfg.LockTable, lock.WithLogger(s.logger)).Acquire(ctx, migrationsLockName, migrationsLockTTL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to lock migrations")
+		}
+		defer func() {
+			if err := lease.Release(context.WithoutCancel(ctx)); err != nil {
+				s.logger.Warnf(ctx, "failed to release migrations lock: %v", err)
+			}
+		}()
+		var stop func()
+		ctx, stop = lease.Heartbeat(ctx, migrationsLockTTL/3)
+		// heartbeat is stopped before the lease is released
+		defer stop()
+	}
+
+	applied, err := s.appliedMigrations(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	var res []string
+	for _, name := range names {
+		if applied[name] {
+			continue
+		}
+		body, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return res, errors.Wrapf(err, "failed to read migration %s", name)
+		}
+		startedAt := time.Now()
+		if path.Ext(name) == ".sql" {
+			err = s.applySQLMigration(ctx, name, string(body))
+		} else {
+			err = s.applyDynamoDBMigration(ctx, name, body)
+		}
+		if err != nil {
+			return res, err
+		}
+		s.logger.Infof(ctx, "applied migration %s in %s", name, time.Since(startedAt))
+		res = append(res, name)
+	}
+	return res, nil
+}
+
+// appliedMigrations returns sql migrations recorded as applied, the table is created on first run
+func (s *service) appliedMigrations(ctx context.Context, names []string) (map[string]bool, error) {
+	db := s.migrationConfig.DB
+	if !lo.ContainsBy(names, func(name string) bool { return path.Ext(name) == ".sql" }) {
+		return nil, nil
+	}
+	if db == nil {
+		return nil, errors.Errorf("sql migrations require database")
+	}
+	if _, err := db.ExecContext(ctx, fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (name VARCHAR(255) PRIMARY KEY, applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP)", migrationsTable)); err != nil {
+		return nil, errors.Wrapf(err, "failed to create %s table", migrationsTable)
+	}
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("SELECT name FROM %s", migrationsTable))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list applied migrations")
+	}
+	defer rows.Close()
+	res := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, errors.Wrapf(err, "failed to list applied migrations")
+		}
+		res[name] = true
+	}
+	return res, errors.Wrapf(rows.Err(), "failed to list applied migrations")
+}
+
+func (s *service) applySQLMigration(ctx context.Context, name, body string) error {
+	tx, err := s.migrationConfig.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to apply migration %s", name)
+	}
+	defer func() { _ = tx.Rollback() }()
+	if _, err := tx.ExecContext(ctx, body); err != nil {
+		return errors.Wrapf(err, "failed to apply migration %s", name)
+	}
+	// placeholders differ between drivers, file names are escaped instead
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (name) VALUES ('%s')", migrationsTable, strings.ReplaceAll(name, "'", "''"))); err != nil {
+		return errors.Wrapf(err, "failed to record migration %s", name)
+	}
+	return errors.Wrapf(tx.Commit(), "failed to apply migration %s", name)
+}
+
+func (s *service) applyDynamoDBMigration(ctx context.Context, name string, body []byte) error {
+	if s.migrationConfig.DynamoDB == nil {
+		return errors.Errorf("DynamoDB migration %s requires DynamoDB client", name)
+	}
+	var input dynamodb.CreateTableInput
+	if err := json.Unmarshal(body, &input); err != nil {
+		return errors.Wrapf(err, "invalid DynamoDB migration %s", name)
+	}
+	_, err := s.migrationConfig.DynamoDB.CreateTableWithContext(ctx, &input)
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) && awsErr.Code() == dynamodb.ErrCodeResourceInUseException {
+		return nil
+	} else if err != nil {
+		return errors.Wrapf(err, "failed to apply migration %s", name)
+	}
+	return errors.Wrapf(s.migrationConfig.DynamoDB.WaitUntilTableExistsWithContext(ctx, &dynamodb.DescribeTableInput{
+		TableName: input.TableName,
+	}), "failed to wait for table of migration %s", name)
+}
+
+func (s *service) isMigrateEnv() bool {
+	return s.getenv(migrateEnv) == "true"
+}
+
+// migrate runs migrations in env flag mode
+func (s *service) migrate() error {
+	applied, err := s.runMigrations(s.ctx, s.migrationsFS)
+	if err != nil {
+		return err
+	}
+	s.logger.Infof(s.ctx, "applied %d migrations", len(applied))
+	return nil
+}
+
+// migrationsLambdaStartFunc intercepts MigrateEvent invocations before passing payload to the http start func
+func (s *service) migrationsLambdaStartFunc() any {
+	switch next := s.lambdaStartFunc.(type) {
+	case func(context.Context, events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error):
+		return withMigrateEvent(s, next)
+	case func(context.Context, events.LambdaFunctionURLRequest) (events.LambdaFunctionURLResponse, error):
+		return withMigrateEvent(s, next)
+	case func(context.Context, events.LambdaFunctionURLRequest) (*events.LambdaFunctionURLStreamingResponse, error):
+		return withMigrateEvent(s, next)
+	default:
+		return s.lambdaStartFunc
+	}
+}
+
+func withMigrateEvent[E, R any](s *service, next func(context.Context, E) (R, error)) func(context.Context, json.RawMessage) (any, error) {
+	return func(ctx context.Context, payload json.RawMessage) (any, error) {
+		var migrate MigrateEvent
+		if err := json.Unmarshal(payload, &migrate); err == nil && migrate.Migrate {
+			applied, err := s.runMigrations(ctx, s.migrationsFS)
+			if err != nil {
+				return nil, err
+			}
+			return MigrateResult{Applied: applied}, nil
+		}
+		var event E
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal lambda event")
+		}
+		return next(ctx, event)
+	}
+}
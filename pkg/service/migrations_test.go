@@ -0,0 +1,97 @@
+package service_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+type fakeTables struct {
+	dynamodbiface.DynamoDBAPI
+	mu      sync.Mutex
+	tables  map[string]bool
+	created []string
+}
+
+func (f *fakeTables) CreateTableWithContext(_ aws.Context, in *dynamodb.CreateTableInput, _ ...request.Option) (*dynamodb.CreateTableOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	name := aws.StringValue(in.TableName)
+	if f.tables[name] {
+		return nil, awserr.New(dynamodb.ErrCodeResourceInUseException, "table exists", nil)
+	}
+	f.tables[name] = true
+	f.created = append(f.created, name)
+	return &dynamodb.CreateTableOutput{}, nil
+}
+
+func (f *fakeTables) WaitUntilTableExistsWithContext(aws.Context, *dynamodb.DescribeTableInput, ...request.WaiterOption) error {
+	return nil
+}
+
+func TestRunMigrations(t *testing.T) {
+	tests := []struct {
+		name        string
+		fsys        fstest.MapFS
+		existing    []string
+		wantCreated []string
+		wantErr     string
+	}{
+		{
+			name: "tables are created in order of file names",
+			fsys: fstest.MapFS{
+				"002_orders.json": {Data: []byte(`{"TableName": "orders"}`)},
+				"001_users.json":  {Data: []byte(`{"TableName": "users"}`)},
+				"README.md":       {Data: []byte(`ignored`)},
+			},
+			wantCreated: []string{"users", "orders"},
+		},
+		{
+			name:        "existing tables are skipped",
+			fsys:        fstest.MapFS{"001_users.json": {Data: []byte(`{"TableName": "users"}`)}},
+			existing:    []string{"users"},
+			wantCreated: nil,
+		},
+		{
+			name:    "invalid migration",
+			fsys:    fstest.MapFS{"001_users.json": {Data: []byte(`not json`)}},
+			wantErr: "invalid DynamoDB migration 001_users.json",
+		},
+		{
+			name:    "sql migration without database",
+			fsys:    fstest.MapFS{"001_init.sql": {Data: []byte(`CREATE TABLE users (id INT)`)}},
+			wantErr: "sql migrations require database",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tables := &fakeTables{tables: map[string]bool{}}
+			for _, name := range tt.existing {
+				tables.tables[name] = true
+			}
+			h := servicetest.New(t, service.WithMigrations(tt.fsys, service.MigrationConfig{DynamoDB: tables}),
+				service.WithRoutes(func(router service.HttpAdapterRouter) error { return nil }))
+
+			err := h.Service.RunMigrations(context.Background(), tt.fsys)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCreated, tables.created)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	EngineGin    = "gin"
+	EngineEcho   = "echo"
+	EngineCustom = "custom"
+
+	RuntimeLambda     = "lambda"
+	RuntimeServer     = "server"
+	RuntimeLocalDebug = "local-debug"
+)
+
+// Mode describes configuration the service has resolved from options and environment
+type Mode struct {
+	Engine            string `json:"engine" yaml:"engine"`
+	Runtime           string `json:"runtime" yaml:"runtime"`
+	RoutingType       string `json:"routingType,omitempty" yaml:"routingType,omitempty"`
+	ResponseStreaming bool   `json:"responseStreaming" yaml:"responseStreaming"`
+}
+
+func (m Mode) String() string {
+	res := fmt.Sprintf("%s engine, %s runtime", m.Engine, m.Runtime)
+	if m.RoutingType != "" {
+		res += fmt.Sprintf(", %s routing", m.RoutingType)
+	}
+	if m.ResponseStreaming {
+		res += ", response streaming"
+	}
+	return res
+}
+
+func (s *service) Mode() Mode {
+	return s.mode
+}
+
+// validateStreaming rejects combinations which would silently serve buffered responses or none at all
+func (s *service) validateStreaming() error {
+	if !s.useResponseStreaming {
+		return nil
+	}
+	if s.httpRouter != nil {
+		return errors.Errorf("response streaming is not supported with custom http adapter router")
+	}
+	// routing type is irrelevant in server mode, local debug may omit it
+	if s.serverMode || s.localDebugMode && s.routingType == "" {
+		return nil
+	}
+	if s.routingType != lambdaRoutingTypeFunctionUrl {
+		return errors.Errorf("response streaming requires %q routing type, got %q", lambdaRoutingTypeFunctionUrl, s.routingType)
+	}
+	return nil
+}
+
+func (s *service) resolveMode(customRouter bool) Mode {
+	res := Mode{
+		Engine:            EngineGin,
+		Runtime:           RuntimeLambda,
+		RoutingType:       s.routingType,
+		ResponseStreaming: s.useResponseStreaming,
+	}
+	if customRouter {
+		res.Engine = EngineCustom
+	} else if s.useResponseStreaming {
+		res.Engine = EngineEcho
+	}
+	if s.serverMode {
+		res.Runtime = RuntimeServer
+		res.RoutingType = ""
+	} else if s.localDebugMode {
+		res.Runtime = RuntimeLocalDebug
+	}
+	return res
+}
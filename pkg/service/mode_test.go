@@ -0,0 +1,75 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicefake"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestStreamingValidation(t *testing.T) {
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error { return nil })
+	tests := []struct {
+		name     string
+		opts     []service.Option
+		wantErr  string
+		wantMode service.Mode
+	}{
+		{
+			name:     "function url",
+			opts:     []service.Option{routes, service.WithRoutingType("function-url"), service.UseResponseStreaming(true)},
+			wantMode: service.Mode{Engine: service.EngineEcho, Runtime: service.RuntimeLambda, RoutingType: "function-url", ResponseStreaming: true},
+		},
+		{
+			name:    "api gateway",
+			opts:    []service.Option{routes, service.WithRoutingType("api-gateway"), service.UseResponseStreaming(true)},
+			wantErr: `invalid service configuration: response streaming requires "function-url" routing type, got "api-gateway"`,
+		},
+		{
+			name:    "custom router",
+			opts:    []service.Option{routes, service.WithHttpAdapterRouter(servicefake.NewHttpAdapterRouter()), service.UseResponseStreaming(true)},
+			wantErr: "invalid service configuration: response streaming is not supported with custom http adapter router",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := service.New(context.Background(), tt.opts...)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantMode, s.Mode())
+		})
+	}
+}
+
+func TestEngines(t *testing.T) {
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error { return nil })
+
+	ginService := servicetest.New(t, routes)
+	require.NotNil(t, ginService.Service.GinEngine())
+	assert.Nil(t, ginService.Service.EchoEngine())
+	ginService.Service.GinEngine().GET("/native", func(c *gin.Context) { c.String(http.StatusOK, "gin") })
+	assert.Equal(t, "gin", string(ginService.Invoke(http.MethodGet, "/native", nil, nil).Body))
+
+	echoService := servicetest.New(t, routes, service.UseResponseStreaming(true))
+	require.NotNil(t, echoService.Service.EchoEngine())
+	assert.Nil(t, echoService.Service.GinEngine())
+	echoService.Service.EchoEngine().GET("/native", func(c echo.Context) error { return c.String(http.StatusOK, "echo") })
+	assert.Equal(t, "echo", string(echoService.Invoke(http.MethodGet, "/native", nil, nil).Body))
+
+	custom, err := service.New(context.Background(), routes, service.WithRoutingType("function-url"),
+		service.WithHttpAdapterRouter(servicefake.NewHttpAdapterRouter()))
+	require.NoError(t, err)
+	assert.Nil(t, custom.GinEngine())
+	assert.Nil(t, custom.EchoEngine())
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"io"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// streamMultipart calls callback for each part of multipart request body as it is read, nothing is buffered
+// so that callback must consume the part before it returns
+func streamMultipart(r *http.Request, callback func(part *multipart.Part) error) error {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return errors.Wrapf(err, "failed to read multipart body")
+	}
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to read multipart body")
+		}
+		err = callback(part)
+		_ = part.Close()
+		if err != nil {
+			return errors.Wrapf(err, "failed to process part %q", part.FormName())
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package service_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestMultipartStream(t *testing.T) {
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		// hashes uploaded parts without buffering them
+		router.POST("/uploads", func(c service.HttpAdapter) error {
+			var parts []string
+			if err := c.MultipartStream(func(part *multipart.Part) error {
+				hash := sha256.New()
+				size, err := io.Copy(hash, part)
+				if err != nil {
+					return err
+				}
+				parts = append(parts, fmt.Sprintf("%s:%s:%d:%s", part.FormName(), part.FileName(), size, hex.EncodeToString(hash.Sum(nil))[:8]))
+				return nil
+			}); err != nil {
+				service.Fail(c, service.ErrorWithStatus(http.StatusBadRequest, err))
+				return nil
+			}
+			c.JSON(http.StatusOK, service.M{"parts": parts})
+			return nil
+		})
+		return nil
+	})
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	require.NoError(t, writer.WriteField("title", "report"))
+	file, err := writer.CreateFormFile("file", "report.txt")
+	require.NoError(t, err)
+	_, err = file.Write([]byte(strings.Repeat("a", 1024)))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	tests := []struct {
+		name        string
+		opts        []service.Option
+		contentType string
+		wantStatus  int
+		wantParts   []string
+	}{
+		{
+			name:        "parts",
+			contentType: writer.FormDataContentType(),
+			wantStatus:  http.StatusOK,
+			wantParts:   []string{"title::6:845e9183", "file:report.txt:1024:2edc9868"},
+		},
+		{name: "not multipart", contentType: "application/json", wantStatus: http.StatusBadRequest},
+		{
+			name:        "body too large",
+			opts:        []service.Option{service.WithMaxBodySize(512)},
+			contentType: writer.FormDataContentType(),
+			wantStatus:  http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:        "body within limit",
+			opts:        []service.Option{service.WithMaxBodySize(int64(body.Len()))},
+			contentType: writer.FormDataContentType(),
+			wantStatus:  http.StatusOK,
+			wantParts:   []string{"title::6:845e9183", "file:report.txt:1024:2edc9868"},
+		},
+	}
+	for _, tt := range tests {
+		for _, streaming := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s, streaming: %t", tt.name, streaming), func(t *testing.T) {
+				h := servicetest.New(t, append(tt.opts, routes, service.UseResponseStreaming(streaming))...)
+
+				res := h.Invoke(http.MethodPost, "/uploads", body.Bytes(), map[string]string{"Content-Type": tt.contentType})
+				require.Equal(t, tt.wantStatus, res.StatusCode, string(res.Body))
+				if tt.wantStatus != http.StatusOK {
+					return
+				}
+				var got struct {
+					Parts []string `json:"parts"`
+				}
+				require.NoError(t, res.JSON(&got))
+				assert.Equal(t, tt.wantParts, got.Parts)
+			})
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/util"
+)
+
+const NDJSONContentType = "application/x-ndjson"
+
+// ReadNDJSON decodes newline delimited JSON body line by line calling callback for each item, lines preceding
+// the failed one are already processed when false is returned; lines are limited to 1MB
+func ReadNDJSON[T any](ctx context.Context, s Service, c HttpAdapter, action string, callback func(item *T) error) bool {
+	scanner := util.NewLineOrReturnScanner(c.RequestBody())
+	line := 0
+	for scanner.Scan() {
+		line++
+		var item T
+		if err := json.Unmarshal(scanner.Bytes(), &item); err != nil {
+			respondInvalidBody(ctx, s, c, errors.Wrapf(err, "line %d", line))
+			return false
+		}
+		if err := callback(&item); err != nil {
+			c.JSON(http.StatusInternalServerError, Error{
+				Message: Localize(c, MessageActionFailed, action, errors.Wrapf(err, "line %d", line)),
+				Meta:    s.GetMeta(ctx),
+			})
+			return false
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		respondInvalidBody(ctx, s, c, errors.Wrapf(err, "line %d", line+1))
+		return false
+	}
+	return true
+}
+
+// NDJSONWriter writes items as newline delimited JSON flushing each of them, so that clients receive items
+// as soon as they are written when response streaming is used
+type NDJSONWriter struct {
+	c           HttpAdapter
+	code        int
+	wroteHeader bool
+}
+
+// NewNDJSONWriter returns writer responding with status code once the first item is written
+func NewNDJSONWriter(c HttpAdapter, code int) *NDJSONWriter {
+	return &NDJSONWriter{c: c, code: code}
+}
+
+// Write encodes item into a single line, it fails once request context is done
+func (w *NDJSONWriter) Write(item any) error {
+	if err := w.c.Context().Err(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(item)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal NDJSON item")
+	}
+	writer := w.c.Writer()
+	w.writeHeader()
+	if _, err := writer.Write(append(data, '\n')); err != nil {
+		return errors.Wrapf(err, "failed to write NDJSON item")
+	}
+	writer.Flush()
+	return nil
+}
+
+// Close writes status code when nothing was written, e.g. for empty result sets
+func (w *NDJSONWriter) Close() error {
+	w.writeHeader()
+	return nil
+}
+
+func (w *NDJSONWriter) writeHeader() {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.c.SetHeader("Content-Type", NDJSONContentType)
+	w.c.Writer().WriteHeader(w.code)
+}
@@ -0,0 +1,84 @@
+package service_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestNDJSON(t *testing.T) {
+	var svc service.Service
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		// echoes items back with their position
+		router.POST("/api/ingest", func(c service.HttpAdapter) error {
+			var items []item
+			if !service.ReadNDJSON[item](c.Context(), svc, c, "ingest", func(it *item) error {
+				if it.Name == "fail" {
+					return errors.New("item is rejected")
+				}
+				items = append(items, *it)
+				return nil
+			}) {
+				return nil
+			}
+			w := service.NewNDJSONWriter(c, http.StatusOK)
+			for i, it := range items {
+				if err := w.Write(service.M{"index": i, "name": it.Name}); err != nil {
+					return err
+				}
+			}
+			return w.Close()
+		})
+		return nil
+	})
+
+	tests := []struct {
+		name        string
+		body        string
+		wantStatus  int
+		wantBody    string
+		wantMessage string
+	}{
+		{name: "items", body: "{\"name\":\"a\"}\n{\"name\":\"b\"}\r\n\n", wantStatus: http.StatusOK, wantBody: "{\"index\":0,\"name\":\"a\"}\n{\"index\":1,\"name\":\"b\"}\n"},
+		{name: "no trailing newline", body: `{"name":"a"}`, wantStatus: http.StatusOK, wantBody: "{\"index\":0,\"name\":\"a\"}\n"},
+		{name: "empty body", wantStatus: http.StatusOK},
+		{
+			name:        "invalid line",
+			body:        "{\"name\":\"a\"}\n{\"name\":",
+			wantStatus:  http.StatusInternalServerError,
+			wantMessage: "failed to unmarshal request body to Config: line 2: unexpected end of JSON input",
+		},
+		{
+			name:        "rejected item",
+			body:        "{\"name\":\"fail\"}\n",
+			wantStatus:  http.StatusInternalServerError,
+			wantMessage: "failed to ingest: line 1: item is rejected",
+		},
+	}
+	for _, tt := range tests {
+		for _, streaming := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s, streaming: %t", tt.name, streaming), func(t *testing.T) {
+				h := servicetest.New(t, routes, service.UseResponseStreaming(streaming))
+				svc = h.Service
+
+				res := h.Invoke(http.MethodPost, "/api/ingest", tt.body, map[string]string{"Content-Type": service.NDJSONContentType})
+				require.Equal(t, tt.wantStatus, res.StatusCode, string(res.Body))
+				if tt.wantMessage != "" {
+					var body service.Error
+					require.NoError(t, res.JSON(&body))
+					assert.Equal(t, tt.wantMessage, body.Message)
+					return
+				}
+				assert.Equal(t, service.NDJSONContentType, res.Headers.Get("Content-Type"))
+				assert.Equal(t, tt.wantBody, string(res.Body))
+			})
+		}
+	}
+}
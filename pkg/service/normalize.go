@@ -0,0 +1,77 @@
+package service
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+type routeNormalization struct {
+	trailingSlash   bool
+	caseInsensitive bool
+}
+
+// WithRouteNormalization makes routing behave the same for gin and echo: gin redirects of trailing slashes
+// are disabled and paths are matched exactly unless trailingSlash makes trailing slash optional and
+// caseInsensitive makes static segments of the paths case-insensitive (parameters keep their case)
+func WithRouteNormalization(trailingSlash, caseInsensitive bool) Option {
+	return func(s *service) {
+		s.routeNormalization = &routeNormalization{
+			trailingSlash:   trailingSlash,
+			caseInsensitive: caseInsensitive,
+		}
+	}
+}
+
+// normalizeRouteHandler rewrites request path to the registered route it matches when normalized
+func (s *service) normalizeRouteHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := requestHost(r, s.trustProxyHeaders)
+		var best string
+		bestScore := -1
+		for _, route := range s.routes.list() {
+			if route.Host != "" && !(&hostRoute{pattern: route.Host}).matches(host) {
+				continue
+			}
+			if p, score, ok := s.routeNormalization.match(route.Path, r.URL.Path); ok && score > bestScore {
+				best, bestScore = p, score
+			}
+		}
+		if bestScore >= 0 && best != r.URL.Path {
+			r = withPath(r, best)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// match returns path matching pattern exactly and the number of static segments matched,
+// routes having more static segments take precedence like they do in gin and echo
+func (n *routeNormalization) match(pattern, path string) (string, int, bool) {
+	patternSlash, pathSlash := len(pattern) > 1 && strings.HasSuffix(pattern, "/"), len(path) > 1 && strings.HasSuffix(path, "/")
+	patternSegments := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+	res := make([]string, 0, len(pathSegments))
+	static := 0
+	for i, segment := range patternSegments {
+		switch {
+		case strings.HasPrefix(segment, "*"):
+			res = append(res, pathSegments[min(i, len(pathSegments)):]...)
+			// rest of the path is kept as is including trailing slash
+			return "/" + strings.Join(res, "/") + lo.If(pathSlash && len(res) > 0, "/").Else(""), static, true
+		case i >= len(pathSegments):
+			return "", 0, false
+		case strings.HasPrefix(segment, ":") && pathSegments[i] != "":
+			res = append(res, pathSegments[i])
+		case segment == pathSegments[i] || n.caseInsensitive && strings.EqualFold(segment, pathSegments[i]):
+			res = append(res, segment)
+			static++
+		default:
+			return "", 0, false
+		}
+	}
+	if len(pathSegments) != len(patternSegments) || patternSlash != pathSlash && !n.trailingSlash {
+		return "", 0, false
+	}
+	return "/" + strings.Join(res, "/") + lo.If(patternSlash, "/").Else(""), static, true
+}
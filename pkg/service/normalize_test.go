@@ -0,0 +1,65 @@
+package service_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestRouteNormalization(t *testing.T) {
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		respond := func(c service.HttpAdapter) error {
+			c.JSON(http.StatusOK, map[string]string{"path": c.Request().URL.Path, "id": c.Param("id")})
+			return nil
+		}
+		router.GET("/api/items", respond)
+		router.GET("/api/items/:id", respond)
+		router.GET("/api/items/new", respond)
+		router.GET("/api/folders/", respond)
+		return nil
+	})
+
+	tests := []struct {
+		name            string
+		trailingSlash   bool
+		caseInsensitive bool
+		path            string
+		wantStatus      int
+		wantPath        string
+		wantID          string
+	}{
+		{name: "exact path", path: "/api/items", wantStatus: http.StatusOK, wantPath: "/api/items"},
+		{name: "strict trailing slash", path: "/api/items/", wantStatus: http.StatusNotFound},
+		{name: "strict case", path: "/API/items", wantStatus: http.StatusNotFound},
+		{name: "optional trailing slash", trailingSlash: true, path: "/api/items/", wantStatus: http.StatusOK, wantPath: "/api/items"},
+		{name: "registered trailing slash", trailingSlash: true, path: "/api/folders", wantStatus: http.StatusOK, wantPath: "/api/folders/"},
+		{name: "case-insensitive", caseInsensitive: true, path: "/API/Items", wantStatus: http.StatusOK, wantPath: "/api/items"},
+		{name: "parameters keep case", caseInsensitive: true, path: "/Api/Items/AbC", wantStatus: http.StatusOK, wantPath: "/api/items/AbC", wantID: "AbC"},
+		{name: "static segment wins", caseInsensitive: true, path: "/api/items/NEW", wantStatus: http.StatusOK, wantPath: "/api/items/new"},
+		{name: "both", trailingSlash: true, caseInsensitive: true, path: "/API/ITEMS/", wantStatus: http.StatusOK, wantPath: "/api/items"},
+	}
+	for _, tt := range tests {
+		for _, streaming := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s, streaming: %t", tt.name, streaming), func(t *testing.T) {
+				h := servicetest.New(t, routes, service.UseResponseStreaming(streaming),
+					service.WithRouteNormalization(tt.trailingSlash, tt.caseInsensitive))
+
+				res := h.Invoke(http.MethodGet, tt.path, nil, nil)
+				require.Equal(t, tt.wantStatus, res.StatusCode, string(res.Body))
+				if tt.wantStatus != http.StatusOK {
+					return
+				}
+				var body map[string]string
+				require.NoError(t, res.JSON(&body))
+				assert.Equal(t, tt.wantPath, body["path"])
+				assert.Equal(t, tt.wantID, body["id"])
+			})
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+)
+
+// WithNotFoundHandler sets handler of requests not matching any route, by default error is returned
+// in the standard envelope with meta of the request
+func WithNotFoundHandler(h HttpAdapterHandler) Option {
+	return func(s *service) {
+		s.notFoundHandler = h
+	}
+}
+
+// WithMethodNotAllowedHandler sets handler of requests matching route registered for other methods only
+func WithMethodNotAllowedHandler(h HttpAdapterHandler) Option {
+	return func(s *service) {
+		s.methodNotAllowedHandler = h
+	}
+}
+
+func notFoundHandler(c HttpAdapter) error {
+	Fail(c, ErrorWithStatus(http.StatusNotFound, errors.New(Localize(c, MessageNotFound))))
+	return nil
+}
+
+func methodNotAllowedHandler(c HttpAdapter) error {
+	Fail(c, ErrorWithStatus(http.StatusMethodNotAllowed, errors.New(Localize(c, MessageMethodNotAllowed, c.Request().Method))))
+	return nil
+}
+
+func (s *service) notFound() HttpAdapterHandler {
+	if s.notFoundHandler != nil {
+		return s.notFoundHandler
+	}
+	return notFoundHandler
+}
+
+func (s *service) methodNotAllowed() HttpAdapterHandler {
+	if s.methodNotAllowedHandler != nil {
+		return s.methodNotAllowedHandler
+	}
+	return methodNotAllowedHandler
+}
+
+// handleGinNoRoute responds to unknown routes, gin runs middlewares of the engine for them
+func (s *service) handleGinNoRoute(engine *gin.Engine) {
+	engine.HandleMethodNotAllowed = true
+	engine.NoRoute(GinAdapter(s.notFound(), s.logger, s.localDebugMode))
+	engine.NoMethod(GinAdapter(s.methodNotAllowed(), s.logger, s.localDebugMode))
+}
+
+// handleEchoNoRoute responds to unknown routes, echo runs middlewares for them and reports them as errors
+func (s *service) handleEchoNoRoute(e *echo.Echo) {
+	e.HTTPErrorHandler = func(err error, c echo.Context) {
+		var httpErr *echo.HTTPError
+		if errors.As(err, &httpErr) && !c.Response().Committed {
+			var h HttpAdapterHandler
+			switch httpErr.Code {
+			case http.StatusNotFound:
+				h = s.notFound()
+			case http.StatusMethodNotAllowed:
+				h = s.methodNotAllowed()
+			}
+			if h != nil && EchoAdapter(h, s.logger, s.localDebugMode)(c) == nil {
+				return
+			}
+		}
+		e.DefaultHTTPErrorHandler(err, c)
+	}
+}
@@ -0,0 +1,70 @@
+package service_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestUnknownRoutes(t *testing.T) {
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		router.GET("/api/items", func(c service.HttpAdapter) error {
+			c.JSON(http.StatusOK, service.M{"ok": true})
+			return nil
+		})
+		return nil
+	})
+	custom := []service.Option{
+		service.WithNotFoundHandler(func(c service.HttpAdapter) error {
+			c.JSON(http.StatusNotFound, service.M{"message": "no such page: " + c.Request().URL.Path})
+			return nil
+		}),
+		service.WithMethodNotAllowedHandler(func(c service.HttpAdapter) error {
+			c.JSON(http.StatusMethodNotAllowed, service.M{"message": "try GET"})
+			return nil
+		}),
+	}
+
+	tests := []struct {
+		name        string
+		opts        []service.Option
+		method      string
+		path        string
+		wantStatus  int
+		wantError   string
+		wantMessage string
+	}{
+		{name: "not found", method: http.MethodGet, path: "/api/unknown", wantStatus: http.StatusNotFound, wantError: "resource is not found"},
+		{name: "method not allowed", method: http.MethodDelete, path: "/api/items", wantStatus: http.StatusMethodNotAllowed, wantError: "method DELETE is not allowed"},
+		{name: "custom not found", opts: custom, method: http.MethodGet, path: "/api/unknown", wantStatus: http.StatusNotFound, wantMessage: "no such page: /api/unknown"},
+		{name: "custom method not allowed", opts: custom, method: http.MethodPost, path: "/api/items", wantStatus: http.StatusMethodNotAllowed, wantMessage: "try GET"},
+	}
+	for _, tt := range tests {
+		for _, streaming := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s, streaming: %t", tt.name, streaming), func(t *testing.T) {
+				h := servicetest.New(t, append([]service.Option{routes, service.UseResponseStreaming(streaming)}, tt.opts...)...)
+
+				res := h.Invoke(tt.method, tt.path, nil, nil)
+				require.Equal(t, tt.wantStatus, res.StatusCode, string(res.Body))
+				assert.Equal(t, service.JSONContentType, res.Headers.Get("Content-Type"))
+				if tt.wantMessage != "" {
+					var body map[string]string
+					require.NoError(t, res.JSON(&body))
+					assert.Equal(t, tt.wantMessage, body["message"])
+					return
+				}
+				var body service.Response
+				require.NoError(t, res.JSON(&body))
+				require.NotNil(t, body.Meta.Error)
+				assert.Equal(t, tt.wantError, *body.Meta.Error)
+				assert.NotEmpty(t, body.Meta.RequestUID)
+			})
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/observatory"
+)
+
+type observatoryConfig struct {
+	baseURI string
+	opts    []observatory.Option
+}
+
+// WithObservatory pushes service logs and request metrics to the Observatory service at baseURI
+func WithObservatory(baseURI string, opts ...observatory.Option) Option {
+	return func(s *service) {
+		s.observatory = &observatoryConfig{baseURI: baseURI, opts: opts}
+	}
+}
+
+// initObservatory wraps the logger and installs metrics middleware as the outermost one
+func (s *service) initObservatory() error {
+	if s.observatory == nil {
+		return nil
+	}
+	// client warnings go to the base logger, pushing them would only feed the failing endpoint
+	opts := append([]observatory.Option{observatory.WithLogger(s.logger)}, s.observatory.opts...)
+	client, err := observatory.New(s.observatory.baseURI, opts...)
+	if err != nil {
+		return errors.Wrapf(err, "failed to init observatory client")
+	}
+	s.logger = observatory.Logger(s.logger, client)
+	s.handlerMiddlewares = append([]HandlerMiddleware{observatory.Middleware(client, s.logger)}, s.handlerMiddlewares...)
+	return nil
+}
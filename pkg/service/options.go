@@ -1,9 +1,13 @@
 package service
 
 import (
+	"os"
+
 	"github.com/samber/lo"
 
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/awsutil"
 	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/util"
 )
 
 type (
@@ -23,6 +27,13 @@ func WithHttpAdapterRouter(a HttpAdapterRouter) Option {
 	}
 }
 
+// WithHandlerMiddleware adds middleware wrapping the root http handler of the service
+func WithHandlerMiddleware(mw ...HandlerMiddleware) Option {
+	return func(s *service) {
+		s.handlerMiddlewares = append(s.handlerMiddlewares, mw...)
+	}
+}
+
 func WithLogger(logger logger.Logger) Option {
 	return func(s *service) {
 		s.logger = logger
@@ -41,12 +52,36 @@ func WithSkipAuthRoutes(routes ...string) Option {
 	}
 }
 
+// WithOptionalAuthRoutes serves routes with the prefixes to anonymous requests as well, requests presenting
+// API key or signed URL are authenticated as usual and handlers branch on IsAuthorized; invalid credentials
+// are still rejected with 401, so that clients learn about them and failures count towards WithAuthLockout
+func WithOptionalAuthRoutes(routes ...string) Option {
+	return func(s *service) {
+		s.optionalAuthRoutes = append(s.optionalAuthRoutes, routes...)
+	}
+}
+
+// WithStrictAuth makes New fail when auth self-check detects misconfiguration instead of logging warnings
+func WithStrictAuth() Option {
+	return func(s *service) {
+		s.strictAuth = true
+	}
+}
+
 func WithApiKey(key string) Option {
 	return func(s *service) {
 		s.apiKey = key
 	}
 }
 
+// WithRequiredAuth makes service fail to start when API key is not configured or its secret
+// can not be resolved, otherwise the service starts with API key authentication disabled
+func WithRequiredAuth() Option {
+	return func(s *service) {
+		s.requiredAuth = true
+	}
+}
+
 func WithRoutingType(routingType string) Option {
 	return func(s *service) {
 		s.routingType = routingType
@@ -71,12 +106,26 @@ func WithLambdaCostPerMbPerMs(cost float64) Option {
 	}
 }
 
+// WithMemoryStats adds memory and GC statistics of the request to ResultMeta
+func WithMemoryStats() Option {
+	return func(s *service) {
+		s.memoryStats = true
+	}
+}
+
 func WithLambdaSize(size float64) Option {
 	return func(s *service) {
 		s.lambdaSize = size
 	}
 }
 
+// WithLosslessEvents stores raw lambda event in request context, see awsutil.OriginalEvent
+func WithLosslessEvents() Option {
+	return func(s *service) {
+		s.losslessEvents = true
+	}
+}
+
 func WithRequestDebugMode() Option {
 	return func(s *service) {
 		s.requestDebugMode = true
@@ -94,3 +143,40 @@ func WithLocalDebugMode() Option {
 		s.localDebugMode = true
 	}
 }
+
+// WithEnv sets lookup of environment variables configuring the service, e.g. to isolate tests from
+// the process environment, os.Getenv is used by default
+func WithEnv(getenv func(string) string) Option {
+	return func(s *service) {
+		s.getenv = getenv
+	}
+}
+
+// WithSecretsProvider sets provider of secrets configuring the service (API_KEY), by default env variables
+// are used and values referencing Secrets Manager, SSM or files are resolved
+func WithSecretsProvider(provider awsutil.SecretsProvider) Option {
+	return func(s *service) {
+		s.secretsProvider = provider
+	}
+}
+
+// WithClock sets clock of request timing in response meta, cost budgets, timeout watchdog and streaming heartbeats,
+// e.g. clocktest.Clock for deterministic tests
+func WithClock(clock util.Clock) Option {
+	return func(s *service) {
+		s.clock = clock
+	}
+}
+
+// probeOf resolves env lookup, secrets provider and environment which provide defaults for other options,
+// options only configure the service they are applied to, so the probe is discarded once resolved
+func probeOf(opts []Option) *service {
+	probe := &service{getenv: os.Getenv}
+	for _, opt := range opts {
+		opt(probe)
+	}
+	if probe.secretsProvider == nil {
+		probe.secretsProvider = awsutil.DefaultSecretsProvider(probe.getenv)
+	}
+	return probe
+}
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProbeOf(t *testing.T) {
+	opts := []Option{
+		WithEnv(func(name string) string { return "env:" + name }),
+		WithEnvironment(EnvironmentDev),
+		WithUsageTracking(UsageConfig{}),
+	}
+	probe := probeOf(opts)
+
+	assert.Equal(t, "env:PORT", probe.getenv("PORT"))
+	assert.Equal(t, EnvironmentDev, probe.environment)
+	assert.NotNil(t, probe.secretsProvider)
+
+	s := &service{}
+	for _, opt := range opts {
+		opt(s)
+	}
+	assert.NotSame(t, probe.usage, s.usage, "probing does not share state with the configured service")
+	assert.Len(t, s.handlerMiddlewares, 1)
+}
@@ -0,0 +1,120 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/awsutil"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestWithSecretsProvider(t *testing.T) {
+	secrets := awsutil.SecretsProviderFunc(func(_ context.Context, name string) (string, bool, error) {
+		return "s3cr3t", name == "API_KEY", nil
+	})
+	h := servicetest.New(t, service.WithSecretsProvider(secrets), service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		router.GET("/api/ping", func(c service.HttpAdapter) error {
+			c.JSON(http.StatusOK, service.M{})
+			return nil
+		})
+		return nil
+	}))
+
+	assert.Equal(t, http.StatusUnauthorized, h.Invoke(http.MethodGet, "/api/ping", nil, nil).StatusCode)
+	res := h.Invoke(http.MethodGet, "/api/ping", nil, map[string]string{"Authorization": "Bearer s3cr3t"})
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestRequiredAuth(t *testing.T) {
+	failing := awsutil.SecretsProviderFunc(func(context.Context, string) (string, bool, error) {
+		return "", false, errors.New("access denied")
+	})
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error { return nil })
+	tests := []struct {
+		name     string
+		opts     []service.Option
+		wantErr  string
+		wantAuth string
+	}{
+		{name: "disabled", opts: []service.Option{}, wantAuth: service.AuthDisabled},
+		{name: "enabled", opts: []service.Option{service.WithApiKey("key"), service.WithRequiredAuth()}, wantAuth: service.AuthEnabled},
+		{name: "unavailable", opts: []service.Option{service.WithSecretsProvider(failing)}, wantAuth: service.AuthUnavailable},
+		{
+			name:    "required but not configured",
+			opts:    []service.Option{service.WithRequiredAuth()},
+			wantErr: "invalid service configuration: API key is required but not configured",
+		},
+		{
+			name:    "required but unavailable",
+			opts:    []service.Option{service.WithSecretsProvider(failing), service.WithRequiredAuth()},
+			wantErr: "invalid service configuration: API key is required but its secret can not be resolved: access denied",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]service.Option{routes}, tt.opts...)
+			if tt.wantErr != "" {
+				_, err := service.New(context.Background(), append([]service.Option{service.WithEnv(func(string) string { return "" })}, opts...)...)
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			res := servicetest.New(t, opts...).Invoke(http.MethodGet, "/api/status", nil, nil)
+			var status struct {
+				Status service.Status `json:"status"`
+			}
+			require.NoError(t, res.JSON(&status))
+			assert.Equal(t, tt.wantAuth, status.Status.Auth)
+		})
+	}
+}
+
+func TestWithOptionalAuthRoutes(t *testing.T) {
+	h := servicetest.New(t, service.WithApiKey("key"), service.WithOptionalAuthRoutes("/api/catalog"),
+		service.WithRoutes(func(router service.HttpAdapterRouter) error {
+			handler := func(c service.HttpAdapter) error {
+				c.JSON(http.StatusOK, service.M{"authorized": service.IsAuthorized(c.Context())})
+				return nil
+			}
+			router.GET("/api/catalog", handler)
+			router.GET("/api/orders", handler)
+			return nil
+		}))
+
+	tests := []struct {
+		name       string
+		path       string
+		key        string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "anonymous", path: "/api/catalog", wantStatus: http.StatusOK, wantBody: `{"authorized":false}`},
+		{name: "authenticated", path: "/api/catalog", key: "key", wantStatus: http.StatusOK, wantBody: `{"authorized":true}`},
+		{name: "invalid key", path: "/api/catalog", key: "guess", wantStatus: http.StatusUnauthorized},
+		{name: "anonymous on required auth route", path: "/api/orders", wantStatus: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var headers map[string]string
+			if tt.key != "" {
+				headers = map[string]string{"Authorization": "Bearer " + tt.key}
+			}
+			res := h.Invoke(http.MethodGet, tt.path, nil, headers)
+			require.Equal(t, tt.wantStatus, res.StatusCode)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, string(res.Body))
+			}
+		})
+	}
+
+	route, found := lo.Find(h.Service.Routes(), func(route service.RouteInfo) bool { return route.Path == "/api/catalog" })
+	require.True(t, found)
+	assert.True(t, route.AuthOptional)
+	assert.False(t, route.AuthRequired)
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+)
+
+// PolicyInput describes request evaluated by PolicyEngine, it is sent as "input" document to OPA
+type PolicyInput struct {
+	Principal     string      `json:"principal,omitempty"`
+	Authenticated bool        `json:"authenticated"`
+	Scopes        []string    `json:"scopes,omitempty"`
+	Tenant        string      `json:"tenant,omitempty"`
+	Method        string      `json:"method"`
+	Route         string      `json:"route,omitempty"` // registered route pattern, e.g. /api/orders/:id, empty when none matches
+	Path          string      `json:"path"`
+	ClientIP      string      `json:"clientIP,omitempty"`
+	Headers       http.Header `json:"headers,omitempty"` // credentials (Authorization, Cookie) are not passed
+	Claims        Claims      `json:"claims,omitempty"`
+}
+
+// PolicyDecision is the result of policy evaluation, Reason is returned to clients of denied requests
+type PolicyDecision struct {
+	Allow  bool   `json:"allow"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// PolicyEngine evaluates requests against centrally managed policies (e.g. OPA rego or Cedar)
+type PolicyEngine interface {
+	Evaluate(ctx context.Context, input PolicyInput) (PolicyDecision, error)
+}
+
+type PolicyEngineFunc func(ctx context.Context, input PolicyInput) (PolicyDecision, error)
+
+func (f PolicyEngineFunc) Evaluate(ctx context.Context, input PolicyInput) (PolicyDecision, error) {
+	return f(ctx, input)
+}
+
+// TenantResolver returns tenant of the request for policy evaluation
+type TenantResolver func(c HttpAdapter) string
+
+// WithPolicyEngine evaluates every request with engine after auth and before handlers run, denied requests are
+// rejected with 403 Forbidden; requests are denied as well when evaluation fails. Tenant is taken from "tenant"
+// claims of the authorizer unless WithTenantResolver is used
+func WithPolicyEngine(engine PolicyEngine) Option {
+	return func(s *service) {
+		s.policyEngine = engine
+	}
+}
+
+// WithTenantResolver overrides how tenant of PolicyInput is resolved, e.g. from a header or host of the request
+func WithTenantResolver(resolver TenantResolver) Option {
+	return func(s *service) {
+		s.tenantResolver = resolver
+	}
+}
+
+// defaultOPATimeout bounds policy evaluation as it runs on the path of every request
+const defaultOPATimeout = 2 * time.Second
+
+// OPAPolicyEngine evaluates policies with OPA REST API at url of the decision document, e.g.
+// http://localhost:8181/v1/data/httpapi/authz; the document is either a boolean or an object with "allow"
+// and optional "reason", undefined decisions deny requests. Requests to OPA time out after 2 seconds unless
+// client is passed, e.g. &http.Client{Timeout: 500 * time.Millisecond} or one with a custom transport
+func OPAPolicyEngine(url string, client *http.Client) PolicyEngine {
+	if client == nil {
+		client = &http.Client{Timeout: defaultOPATimeout}
+	}
+	return PolicyEngineFunc(func(ctx context.Context, input PolicyInput) (PolicyDecision, error) {
+		body, err := json.Marshal(map[string]any{"input": input})
+		if err != nil {
+			return PolicyDecision{}, errors.Wrapf(err, "failed to marshal policy input")
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return PolicyDecision{}, errors.Wrapf(err, "failed to create OPA request")
+		}
+		req.Header.Set("Content-Type", JSONContentType)
+		res, err := client.Do(req)
+		if err != nil {
+			return PolicyDecision{}, errors.Wrapf(err, "failed to query OPA")
+		}
+		defer func() { _ = res.Body.Close() }()
+		if res.StatusCode != http.StatusOK {
+			return PolicyDecision{}, errors.Errorf("OPA responded with status %d", res.StatusCode)
+		}
+		var result struct {
+			Result json.RawMessage `json:"result"`
+		}
+		if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+			return PolicyDecision{}, errors.Wrapf(err, "failed to decode OPA response")
+		}
+		var allow bool
+		if err := json.Unmarshal(result.Result, &allow); err == nil {
+			return PolicyDecision{Allow: allow}, nil
+		}
+		var decision PolicyDecision
+		if len(result.Result) > 0 {
+			if err := json.Unmarshal(result.Result, &decision); err != nil {
+				return PolicyDecision{}, errors.Wrapf(err, "unexpected OPA decision %s", result.Result)
+			}
+		}
+		return decision, nil
+	})
+}
+
+func (s *service) policyEngineMiddleware() HttpAdapterHandler {
+	return func(c HttpAdapter) error {
+		input := s.policyInput(c)
+		decision, err := s.policyEngine.Evaluate(c.Context(), input)
+		if err != nil {
+			s.logger.Errorf(c.Context(), "failed to evaluate policy: %v", err)
+			decision = PolicyDecision{Reason: "policy evaluation failed"}
+		}
+		if decision.Allow {
+			return nil
+		}
+		logger.LogSecurity(c.Context(), s.logger, SecurityEventPolicyDenied, securityFields(c, map[string]any{
+			"principal": input.Principal,
+			"tenant":    input.Tenant,
+			"route":     input.Route,
+			"reason":    decision.Reason,
+		}))
+		res := M{"message": Localize(c, MessageForbidden)}
+		if decision.Reason != "" {
+			res["reason"] = decision.Reason
+		}
+		c.JSON(http.StatusForbidden, res)
+		c.AbortWithStatus(http.StatusForbidden)
+		return nil
+	}
+}
+
+func (s *service) policyInput(c HttpAdapter) PolicyInput {
+	claims, _ := AuthorizerClaims(c.Context())
+	input := PolicyInput{
+		Principal:     claims.PrincipalID(),
+		Authenticated: IsAuthorized(c.Context()),
+		Scopes:        Scopes(c.Context()),
+		Method:        c.Request().Method,
+		Route:         s.routes.match(c.Request().Method, c.Request().URL.Path),
+		Path:          c.Request().URL.Path,
+		ClientIP:      c.RemoteIP(),
+		Headers:       c.Headers().Clone(),
+		Claims:        claims,
+	}
+	input.Headers.Del("Authorization")
+	input.Headers.Del("Cookie")
+	if input.Principal == "" {
+		input.Principal = claims.JWT().String("sub")
+	}
+	if s.tenantResolver != nil {
+		input.Tenant = s.tenantResolver(c)
+	} else {
+		input.Tenant = lo.CoalesceOrEmpty(claims.JWT().String("custom:tenant"), claims.JWT().String("tenant"), claims.String("tenant"))
+	}
+	return input
+}
+
+// match returns pattern of the route registered for method matching path, routes having more static
+// segments take precedence like they do in gin and echo
+func (r *routeRegistry) match(method, p string) string {
+	var best string
+	bestScore := -1
+	for _, route := range r.list() {
+		if route.Method != method && route.Method != "ANY" {
+			continue
+		}
+		if _, score, ok := (&routeNormalization{}).match(route.Path, p); ok && score > bestScore {
+			best, bestScore = route.Path, score
+		}
+	}
+	return best
+}
@@ -0,0 +1,127 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestPolicyEngine(t *testing.T) {
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		router.GET("/api/orders/:id", func(c service.HttpAdapter) error {
+			c.JSON(http.StatusOK, service.M{"id": c.Param("id")})
+			return nil
+		})
+		return nil
+	})
+	var inputs []service.PolicyInput
+	engine := service.PolicyEngineFunc(func(ctx context.Context, input service.PolicyInput) (service.PolicyDecision, error) {
+		inputs = append(inputs, input)
+		switch input.Tenant {
+		case "acme":
+			return service.PolicyDecision{Allow: true}, nil
+		case "broken":
+			return service.PolicyDecision{}, errors.New("policy bundle is not loaded")
+		default:
+			return service.PolicyDecision{Reason: "tenant is not allowed"}, nil
+		}
+	})
+	h := servicetest.New(t, routes, service.WithApiKey("key"), service.WithPolicyEngine(engine),
+		service.WithTenantResolver(func(c service.HttpAdapter) string { return c.Header("X-Tenant-Id") }))
+
+	tests := []struct {
+		name       string
+		tenant     string
+		wantStatus int
+		wantReason string
+	}{
+		{name: "allowed", tenant: "acme", wantStatus: http.StatusOK},
+		{name: "denied", tenant: "globex", wantStatus: http.StatusForbidden, wantReason: "tenant is not allowed"},
+		{name: "evaluation failed", tenant: "broken", wantStatus: http.StatusForbidden, wantReason: "policy evaluation failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inputs = nil
+			res := h.Invoke(http.MethodGet, "/api/orders/42", nil, map[string]string{"Authorization": "Bearer key", "X-Tenant-Id": tt.tenant})
+			require.Equal(t, tt.wantStatus, res.StatusCode, string(res.Body))
+			require.Len(t, inputs, 1)
+			assert.Equal(t, tt.tenant, inputs[0].Tenant)
+			assert.Equal(t, http.MethodGet, inputs[0].Method)
+			assert.Equal(t, "/api/orders/:id", inputs[0].Route)
+			assert.Equal(t, "/api/orders/42", inputs[0].Path)
+			assert.True(t, inputs[0].Authenticated)
+			assert.Empty(t, inputs[0].Headers.Get("Authorization"), "credentials are not passed to the engine")
+			if tt.wantReason != "" {
+				var body struct {
+					Reason string `json:"reason"`
+				}
+				require.NoError(t, res.JSON(&body))
+				assert.Equal(t, tt.wantReason, body.Reason)
+			}
+		})
+	}
+
+	t.Run("unauthenticated requests are rejected before evaluation", func(t *testing.T) {
+		inputs = nil
+		res := h.Invoke(http.MethodGet, "/api/orders/42", nil, map[string]string{"X-Tenant-Id": "acme"})
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+		assert.Empty(t, inputs)
+	})
+}
+
+func TestOPAPolicyEngine(t *testing.T) {
+	opa := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Input service.PolicyInput `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/data/authz/allow":
+			_, _ = w.Write([]byte(`{"result": true}`))
+		case "/v1/data/authz/slow":
+			<-r.Context().Done()
+		case "/v1/data/authz/decision":
+			_, _ = w.Write([]byte(`{"result": {"allow": false, "reason": "` + req.Input.Method + ` is read-only"}}`))
+		default:
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+	defer opa.Close()
+
+	tests := []struct {
+		name         string
+		path         string
+		wantDecision service.PolicyDecision
+	}{
+		{name: "boolean", path: "/v1/data/authz/allow", wantDecision: service.PolicyDecision{Allow: true}},
+		{name: "object", path: "/v1/data/authz/decision", wantDecision: service.PolicyDecision{Reason: "DELETE is read-only"}},
+		{name: "undefined", path: "/v1/data/authz/missing", wantDecision: service.PolicyDecision{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := service.OPAPolicyEngine(opa.URL+tt.path, nil)
+			decision, err := engine.Evaluate(context.Background(), service.PolicyInput{Method: http.MethodDelete, Path: "/api/orders/42"})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantDecision, decision)
+		})
+	}
+
+	t.Run("client timeout", func(t *testing.T) {
+		engine := service.OPAPolicyEngine(opa.URL+"/v1/data/authz/slow", &http.Client{Timeout: 50 * time.Millisecond})
+		_, err := engine.Evaluate(context.Background(), service.PolicyInput{Method: http.MethodGet, Path: "/api/orders/42"})
+		assert.ErrorContains(t, err, "failed to query OPA")
+	})
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+const (
+	pprofRoute        = "/debug/pprof/"
+	runtimeDebugRoute = "/api/debug/runtime"
+	recentGCPauses    = 10
+)
+
+// profiles served by pprof.Index under /debug/pprof/<name>
+var pprofProfiles = []string{"allocs", "block", "goroutine", "heap", "mutex", "threadcreate"}
+
+// WithPprof registers /debug/pprof and /api/debug/runtime endpoints, they require API key and are only
+// registered without one in local debug mode
+func WithPprof() Option {
+	return func(s *service) {
+		s.pprof = true
+	}
+}
+
+type RuntimeInfo struct {
+	GoVersion     string               `json:"goVersion" yaml:"goVersion"`
+	Module        string               `json:"module,omitempty" yaml:"module,omitempty"`
+	Revision      string               `json:"revision,omitempty" yaml:"revision,omitempty"`
+	Goroutines    int                  `json:"goroutines" yaml:"goroutines"`
+	GOMAXPROCS    int                  `json:"gomaxprocs" yaml:"gomaxprocs"`
+	NumCPU        int                  `json:"numCPU" yaml:"numCPU"`
+	Memory        RuntimeMemory        `json:"memory" yaml:"memory"`
+	GC            RuntimeGC            `json:"gc" yaml:"gc"`
+	BuildSettings []debug.BuildSetting `json:"buildSettings,omitempty" yaml:"buildSettings,omitempty"`
+}
+
+type RuntimeMemory struct {
+	HeapAllocBytes uint64 `json:"heapAllocBytes" yaml:"heapAllocBytes"`
+	HeapInuseBytes uint64 `json:"heapInuseBytes" yaml:"heapInuseBytes"`
+	HeapObjects    uint64 `json:"heapObjects" yaml:"heapObjects"`
+	StackBytes     uint64 `json:"stackBytes" yaml:"stackBytes"`
+	SysBytes       uint64 `json:"sysBytes" yaml:"sysBytes"`
+}
+
+type RuntimeGC struct {
+	NumGC        uint32          `json:"numGC" yaml:"numGC"`
+	LastGC       *time.Time      `json:"lastGC,omitempty" yaml:"lastGC,omitempty"`
+	PauseTotal   time.Duration   `json:"pauseTotal" yaml:"pauseTotal"`
+	RecentPauses []time.Duration `json:"recentPauses,omitempty" yaml:"recentPauses,omitempty"`
+	NextGCBytes  uint64          `json:"nextGCBytes" yaml:"nextGCBytes"`
+}
+
+func (s *service) registerPprofEndpoints() {
+	s.httpRouter.GET(pprofRoute, httpHandlerOf(pprof.Index))
+	for _, name := range pprofProfiles {
+		s.httpRouter.GET(pprofRoute+name, httpHandlerOf(pprof.Index))
+	}
+	s.httpRouter.GET(pprofRoute+"cmdline", httpHandlerOf(pprof.Cmdline))
+	s.httpRouter.GET(pprofRoute+"profile", httpHandlerOf(pprof.Profile))
+	s.httpRouter.GET(pprofRoute+"symbol", httpHandlerOf(pprof.Symbol))
+	s.httpRouter.GET(pprofRoute+"trace", httpHandlerOf(pprof.Trace))
+	s.httpRouter.GET(runtimeDebugRoute, s.runtimeDebugEndpoint)
+}
+
+// httpHandlerOf adapts net/http handler, pprof handlers match profiles by URL path
+func httpHandlerOf(handler http.HandlerFunc) HttpAdapterHandler {
+	return func(c HttpAdapter) error {
+		handler(c.Writer(), c.Request())
+		return nil
+	}
+}
+
+// @Schemes
+// @Description goroutines, memory, GC and build info of current instance
+// @Tags debug
+// @Produce json
+// @Success 200 {object} RuntimeInfo
+// @Router /api/debug/runtime [get]
+func (s *service) runtimeDebugEndpoint(c HttpAdapter) error {
+	c.JSON(http.StatusOK, runtimeInfo())
+	return nil
+}
+
+func runtimeInfo() RuntimeInfo {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	res := RuntimeInfo{
+		GoVersion:  runtime.Version(),
+		Goroutines: runtime.NumGoroutine(),
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+		NumCPU:     runtime.NumCPU(),
+		Memory: RuntimeMemory{
+			HeapAllocBytes: mem.HeapAlloc,
+			HeapInuseBytes: mem.HeapInuse,
+			HeapObjects:    mem.HeapObjects,
+			StackBytes:     mem.StackInuse,
+			SysBytes:       mem.Sys,
+		},
+		GC: RuntimeGC{
+			NumGC:       mem.NumGC,
+			PauseTotal:  time.Duration(mem.PauseTotalNs),
+			NextGCBytes: mem.NextGC,
+		},
+	}
+	if mem.LastGC > 0 {
+		lastGC := time.Unix(0, int64(mem.LastGC)).UTC()
+		res.GC.LastGC = &lastGC
+	}
+	// PauseNs is a circular buffer, the most recent pause is at (NumGC+255)%256
+	for i := uint32(0); i < min(mem.NumGC, recentGCPauses); i++ {
+		res.GC.RecentPauses = append(res.GC.RecentPauses, time.Duration(mem.PauseNs[(mem.NumGC-i+255)%256]))
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		res.Module = info.Main.Path
+		res.BuildSettings = info.Settings
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				res.Revision = setting.Value
+			}
+		}
+	}
+	return res
+}
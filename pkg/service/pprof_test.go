@@ -0,0 +1,56 @@
+package service_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestPprof(t *testing.T) {
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error { return nil })
+	auth := map[string]string{"Authorization": "Bearer key"}
+	tests := []struct {
+		name       string
+		opts       []service.Option
+		headers    map[string]string
+		wantStatus int
+	}{
+		{name: "not registered without api key", opts: []service.Option{service.WithPprof()}, wantStatus: http.StatusNotFound},
+		{name: "requires api key", opts: []service.Option{service.WithPprof(), service.WithApiKey("key")}, wantStatus: http.StatusUnauthorized},
+		{name: "with api key", opts: []service.Option{service.WithPprof(), service.WithApiKey("key")}, headers: auth, wantStatus: http.StatusOK},
+		{name: "disabled", opts: []service.Option{service.WithApiKey("key")}, headers: auth, wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, streaming := range []bool{false, true} {
+				h := servicetest.New(t, append(tt.opts, routes, service.UseResponseStreaming(streaming))...)
+				for _, path := range []string{"/debug/pprof/", "/debug/pprof/goroutine?debug=1", "/api/debug/runtime"} {
+					res := h.Invoke(http.MethodGet, path, nil, tt.headers)
+					assert.Equal(t, tt.wantStatus, res.StatusCode, "%s, streaming: %t", path, streaming)
+				}
+			}
+		})
+	}
+}
+
+func TestRuntimeEndpoint(t *testing.T) {
+	h := servicetest.New(t, service.WithPprof(), service.WithApiKey("key"),
+		service.WithRoutes(func(router service.HttpAdapterRouter) error { return nil }))
+	res := h.Invoke(http.MethodGet, "/api/debug/runtime", nil, map[string]string{"Authorization": "Bearer key"})
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var info service.RuntimeInfo
+	require.NoError(t, res.JSON(&info))
+	assert.NotEmpty(t, info.GoVersion)
+	assert.Positive(t, info.Goroutines)
+	assert.Positive(t, info.Memory.HeapAllocBytes)
+
+	res = h.Invoke(http.MethodGet, "/debug/pprof/heap?debug=1", nil, map[string]string{"Authorization": "Bearer key"})
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Contains(t, string(res.Body), "heap profile")
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+const (
+	ProblemContentType = "application/problem+json"
+	problemMaxBody     = 64 * 1024
+)
+
+// Problem is RFC 7807 problem details object
+type Problem struct {
+	Type     string `json:"type" yaml:"type"`
+	Title    string `json:"title" yaml:"title"`
+	Status   int    `json:"status" yaml:"status"`
+	Detail   string `json:"detail,omitempty" yaml:"detail,omitempty"`
+	Instance string `json:"instance,omitempty" yaml:"instance,omitempty"` // request UID
+}
+
+// WithProblemDetails renders all error responses (status >= 400) as application/problem+json,
+// message of the original response (if any) becomes the detail of the problem
+func WithProblemDetails() Option {
+	return func(s *service) {
+		s.problemDetails = true
+	}
+}
+
+func (s *service) problemDetailsHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// request UID is assigned here so that it is known to the problem, requestUIDMiddleware reuses it
+		requestUID, err := uuid.NewUUID()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		ctx := s.logger.WithValue(r.Context(), RequestUIDKey, requestUID.String())
+		pw := &problemWriter{ResponseWriter: w}
+		next.ServeHTTP(pw, r.WithContext(ctx))
+		if pw.intercepted {
+			pw.writeProblem(requestUID.String())
+		}
+	})
+}
+
+// problemWriter holds back error responses which are not problem details yet
+type problemWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	intercepted bool
+	body        bytes.Buffer
+}
+
+func (w *problemWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.status = code
+	if code >= http.StatusBadRequest && !strings.HasPrefix(w.Header().Get("Content-Type"), ProblemContentType) {
+		w.intercepted = true
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *problemWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.intercepted {
+		if remaining := problemMaxBody - w.body.Len(); remaining > 0 {
+			w.body.Write(b[:min(len(b), remaining)])
+		}
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *problemWriter) Flush() {
+	if w.intercepted {
+		return
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *problemWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, errors.Errorf("response writer does not support hijacking")
+}
+
+func (w *problemWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *problemWriter) writeProblem(requestUID string) {
+	problem := NewProblem(w.status, problemDetail(w.body.Bytes()))
+	if problem.Detail == problem.Title {
+		problem.Detail = ""
+	}
+	problem.Instance = requestUID
+	header := w.ResponseWriter.Header()
+	header.Del("Content-Length")
+	header.Set("Content-Type", ProblemContentType)
+	w.ResponseWriter.WriteHeader(w.status)
+	_ = json.NewEncoder(w.ResponseWriter).Encode(problem)
+}
+
+func NewProblem(status int, detail string) Problem {
+	return Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// problemDetail extracts message from error bodies produced by the SDK, gin and echo
+func problemDetail(body []byte) string {
+	var payload struct {
+		Message string `json:"message"`
+		Error   any    `json:"error"`
+		Meta    struct {
+			Error string `json:"error"`
+		} `json:"meta"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return strings.TrimSpace(string(body))
+	}
+	if errMessage, ok := payload.Error.(string); ok && payload.Message == "" {
+		return errMessage
+	}
+	if payload.Message == "" {
+		return payload.Meta.Error
+	}
+	return payload.Message
+}
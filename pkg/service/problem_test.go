@@ -0,0 +1,72 @@
+package service_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestProblemDetails(t *testing.T) {
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		router.GET("/api/invalid", func(c service.HttpAdapter) error {
+			c.JSON(http.StatusBadRequest, map[string]string{"message": "name is required"})
+			return nil
+		})
+		router.GET("/api/ok", func(c service.HttpAdapter) error {
+			c.JSON(http.StatusOK, map[string]string{"status": "ok"})
+			return nil
+		})
+		return nil
+	})
+	tests := []struct {
+		name        string
+		streaming   bool
+		path        string
+		wantStatus  int
+		wantProblem *service.Problem
+	}{
+		{name: "success is not touched", path: "/api/ok", wantStatus: http.StatusOK},
+		{
+			name:        "message becomes detail",
+			path:        "/api/invalid",
+			wantStatus:  http.StatusBadRequest,
+			wantProblem: &service.Problem{Type: "about:blank", Title: "Bad Request", Status: http.StatusBadRequest, Detail: "name is required"},
+		},
+		{
+			name:        "unknown route",
+			path:        "/api/unknown",
+			wantStatus:  http.StatusNotFound,
+			wantProblem: &service.Problem{Type: "about:blank", Title: "Not Found", Status: http.StatusNotFound, Detail: "resource is not found"},
+		},
+		{
+			name:        "unknown route of streaming engine",
+			streaming:   true,
+			path:        "/api/unknown",
+			wantStatus:  http.StatusNotFound,
+			wantProblem: &service.Problem{Type: "about:blank", Title: "Not Found", Status: http.StatusNotFound, Detail: "resource is not found"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := servicetest.New(t, routes, service.WithProblemDetails(), service.UseResponseStreaming(tt.streaming))
+
+			res := h.Invoke(http.MethodGet, tt.path, nil, nil)
+			require.Equal(t, tt.wantStatus, res.StatusCode)
+			if tt.wantProblem == nil {
+				assert.NotEqual(t, service.ProblemContentType, res.Headers.Get("Content-Type"))
+				return
+			}
+			assert.Equal(t, service.ProblemContentType, res.Headers.Get("Content-Type"))
+			var problem service.Problem
+			require.NoError(t, res.JSON(&problem))
+			assert.NotEmpty(t, problem.Instance)
+			problem.Instance = ""
+			assert.Equal(t, *tt.wantProblem, problem)
+		})
+	}
+}
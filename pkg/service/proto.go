@@ -0,0 +1,58 @@
+package service
+
+import (
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// ProtobufContentType is the content type of binary protobuf responses
+const ProtobufContentType = "application/x-protobuf"
+
+var protobufMediaTypes = []string{ProtobufContentType, "application/protobuf", "application/vnd.google.protobuf"}
+
+// EncodeProto encodes message as binary protobuf when accept header prefers it over JSON,
+// otherwise message is encoded with protojson; it returns content type of the encoded message
+func EncodeProto(accept string, message proto.Message) (string, []byte, error) {
+	if acceptsProtobuf(accept) {
+		data, err := proto.Marshal(message)
+		if err != nil {
+			return "", nil, errors.Wrapf(err, "failed to encode %T response", message)
+		}
+		return ProtobufContentType, data, nil
+	}
+	data, err := protojson.Marshal(message)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "failed to encode %T response", message)
+	}
+	return JSONContentType, data, nil
+}
+
+// acceptsProtobuf reports whether any protobuf media type has quality not lower than JSON
+func acceptsProtobuf(accept string) bool {
+	protobufQuality, jsonQuality := 0.0, 0.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		switch {
+		case lo.Contains(protobufMediaTypes, mediaType):
+			protobufQuality = max(protobufQuality, quality)
+		case mediaType == "application/json":
+			jsonQuality = max(jsonQuality, quality)
+		}
+	}
+	return protobufQuality > 0 && protobufQuality >= jsonQuality
+}
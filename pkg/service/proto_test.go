@@ -0,0 +1,54 @@
+package service_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestProto(t *testing.T) {
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		router.GET("/api/message", func(c service.HttpAdapter) error {
+			c.Proto(http.StatusOK, wrapperspb.String("ok"))
+			return nil
+		})
+		return nil
+	})
+
+	tests := []struct {
+		name     string
+		accept   string
+		wantType string
+	}{
+		{name: "protobuf", accept: service.ProtobufContentType, wantType: service.ProtobufContentType},
+		{name: "no accept header", wantType: service.JSONContentType},
+		{name: "json preferred", accept: "application/json, application/x-protobuf;q=0.5", wantType: service.JSONContentType},
+		{name: "protobuf preferred", accept: "application/json;q=0.5, application/protobuf", wantType: service.ProtobufContentType},
+	}
+	for _, tt := range tests {
+		for _, streaming := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s, streaming: %t", tt.name, streaming), func(t *testing.T) {
+				h := servicetest.New(t, routes, service.UseResponseStreaming(streaming))
+
+				res := h.Invoke(http.MethodGet, "/api/message", nil, map[string]string{"Accept": tt.accept})
+				require.Equal(t, http.StatusOK, res.StatusCode, string(res.Body))
+				assert.Contains(t, res.Headers.Get("Content-Type"), tt.wantType)
+				if tt.wantType == service.ProtobufContentType {
+					var message wrapperspb.StringValue
+					require.NoError(t, proto.Unmarshal(res.Body, &message))
+					assert.Equal(t, "ok", message.GetValue())
+					return
+				}
+				assert.JSONEq(t, `"ok"`, string(res.Body))
+			})
+		}
+	}
+}
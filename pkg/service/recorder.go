@@ -0,0 +1,340 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+)
+
+const (
+	defaultRecorderMaxBody      = 64 * 1024
+	defaultRecorderPendingSaves = 16
+)
+
+// RedactedValue replaces values of credentials (headers, query parameters and body fields) in recorded exchanges
+const RedactedValue = "<redacted>"
+
+var (
+	defaultRedactedHeaders     = []string{"Authorization", "Cookie", "Set-Cookie", "X-Api-Key", "Proxy-Authorization"}
+	defaultRedactedQueryParams = []string{SignedURLSignatureParam, "token", "access_token", "api_key", "apiKey", "X-Amz-Signature", "X-Amz-Credential", "X-Amz-Security-Token"}
+	defaultRedactedBodyFields  = []string{"password", "secret", "token", "access_token", "accessToken", "refresh_token", "refreshToken", "id_token", "idToken", "client_secret", "clientSecret", "api_key", "apiKey"}
+)
+
+type RecordedRequest struct {
+	Method    string      `json:"method" yaml:"method"`
+	URI       string      `json:"uri" yaml:"uri"`
+	Headers   http.Header `json:"headers" yaml:"headers"`
+	Body      string      `json:"body,omitempty" yaml:"body,omitempty"`
+	Truncated bool        `json:"truncated,omitempty" yaml:"truncated,omitempty"` // body exceeded MaxBodyBytes, such requests are not replayed
+}
+
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode" yaml:"statusCode"`
+	Headers    http.Header `json:"headers" yaml:"headers"`
+	Body       string      `json:"body,omitempty" yaml:"body,omitempty"`
+	Truncated  bool        `json:"truncated,omitempty" yaml:"truncated,omitempty"`
+}
+
+// RecordedExchange is a sanitized request/response pair captured by the request recorder
+type RecordedExchange struct {
+	ID         string           `json:"id" yaml:"id"`
+	Version    string           `json:"version" yaml:"version"`
+	RecordedAt time.Time        `json:"recordedAt" yaml:"recordedAt"`
+	Duration   time.Duration    `json:"duration" yaml:"duration"`
+	Request    RecordedRequest  `json:"request" yaml:"request"`
+	Response   RecordedResponse `json:"response" yaml:"response"`
+}
+
+type RecordStore interface {
+	Save(ctx context.Context, exchange RecordedExchange) error
+}
+
+type RequestRecorderConfig struct {
+	SampleRate        float64  // share of requests to record in range (0, 1], defaults to 1
+	RedactHeaders     []string // extra headers to redact, credentials are always redacted
+	RedactQueryParams []string // extra query parameters to redact, signatures and tokens are always redacted
+	RedactBodyFields  []string // extra fields of JSON and form bodies to redact, passwords and tokens are always redacted
+	MaxBodyBytes      int      // max bytes of request and response body to keep
+	MaxPendingSaves   int      // exchanges being saved at once, exchanges recorded while all are busy are dropped, defaults to 16
+	S3Client          s3iface.S3API
+	SkipPathPrefixes  []string
+}
+
+// WithRequestRecorder records sampled request/response pairs into the target which is either
+// a local directory or s3://bucket/prefix, New fails when the target cannot be initialized; exchanges are
+// saved in background once responses are sent and pending saves are awaited on shutdown
+func WithRequestRecorder(target string, cfg RequestRecorderConfig) Option {
+	return func(s *service) {
+		recorder := &requestRecorder{
+			target:  target,
+			cfg:     cfg,
+			pending: make(chan struct{}, lo.If(cfg.MaxPendingSaves <= 0, defaultRecorderPendingSaves).Else(cfg.MaxPendingSaves)),
+		}
+		s.recorders = append(s.recorders, recorder)
+		s.handlerMiddlewares = append(s.handlerMiddlewares, s.requestRecorderMiddleware(recorder))
+		s.shutdownHooks = append(s.shutdownHooks, recorder.flush)
+	}
+}
+
+// requestRecorder keeps its position among handler middlewares while the store is initialized in New
+type requestRecorder struct {
+	target  string
+	cfg     RequestRecorderConfig
+	store   RecordStore
+	pending chan struct{}
+	saves   sync.WaitGroup
+}
+
+// save stores exchange in background unless MaxPendingSaves exchanges are being saved already
+func (r *requestRecorder) save(ctx context.Context, log logger.Logger, exchange RecordedExchange) {
+	select {
+	case r.pending <- struct{}{}:
+	default:
+		log.Warnf(ctx, "request recorder is busy, exchange %s is dropped", exchange.ID)
+		return
+	}
+	r.saves.Add(1)
+	go func() {
+		defer func() {
+			<-r.pending
+			r.saves.Done()
+		}()
+		if err := r.store.Save(ctx, exchange); err != nil {
+			log.Warnf(ctx, "failed to record request: %v", err)
+		}
+	}()
+}
+
+// flush waits for pending saves
+func (r *requestRecorder) flush(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		r.saves.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrapf(ctx.Err(), "failed to save recorded exchanges")
+	}
+}
+
+func (s *service) initRecorders() error {
+	for _, recorder := range s.recorders {
+		store, err := newRecordStore(recorder.target, recorder.cfg.S3Client)
+		if err != nil {
+			return errors.Wrapf(err, "failed to init request recorder")
+		}
+		recorder.store = store
+	}
+	return nil
+}
+
+func (s *service) requestRecorderMiddleware(recorder *requestRecorder) HandlerMiddleware {
+	cfg := recorder.cfg
+	sampleRate := lo.If(cfg.SampleRate <= 0, 1.0).Else(cfg.SampleRate)
+	maxBody := lo.If(cfg.MaxBodyBytes <= 0, defaultRecorderMaxBody).Else(cfg.MaxBodyBytes)
+	redact := append(append([]string{}, defaultRedactedHeaders...), cfg.RedactHeaders...)
+	redactParams := append(append([]string{}, defaultRedactedQueryParams...), cfg.RedactQueryParams...)
+	redactFields := append(append([]string{}, defaultRedactedBodyFields...), cfg.RedactBodyFields...)
+	redactJSON := jsonFieldsPattern(redactFields)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if rand.Float64() >= sampleRate || lo.SomeBy(cfg.SkipPathPrefixes, func(prefix string) bool {
+				return strings.HasPrefix(r.URL.Path, prefix)
+			}) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			var reqBody []byte
+			if r.Body != nil {
+				// only the recorded part of the body is buffered, the rest is streamed to the handler
+				var err error
+				if reqBody, err = io.ReadAll(io.LimitReader(r.Body, int64(maxBody)+1)); err != nil {
+					s.logger.Warnf(r.Context(), "failed to read body of recorded request: %v", err)
+				}
+				r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(reqBody), r.Body), Closer: r.Body}
+			}
+			capture := newResponseCapture(w, maxBody)
+			startedAt := time.Now()
+			next.ServeHTTP(capture, r)
+
+			exchange := RecordedExchange{
+				ID:         uuid.NewString(),
+				Version:    s.version,
+				RecordedAt: startedAt.UTC(),
+				Duration:   time.Since(startedAt),
+				Request: RecordedRequest{
+					Method:    r.Method,
+					URI:       redactURI(r.URL, redactParams),
+					Headers:   redactHeaders(r.Header, redact),
+					Body:      redactBody(r.Header.Get("Content-Type"), reqBody[:min(len(reqBody), maxBody)], redactFields, redactJSON),
+					Truncated: len(reqBody) > maxBody,
+				},
+				Response: RecordedResponse{
+					StatusCode: capture.status,
+					Headers:    redactHeaders(w.Header(), redact),
+					Body:       redactBody(w.Header().Get("Content-Type"), capture.body.Bytes(), redactFields, redactJSON),
+					Truncated:  capture.size > capture.body.Len(),
+				},
+			}
+			recorder.save(context.WithoutCancel(r.Context()), s.logger, exchange)
+		})
+	}
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func redactHeaders(headers http.Header, redact []string) http.Header {
+	res := headers.Clone()
+	for _, name := range redact {
+		if _, ok := res[http.CanonicalHeaderKey(name)]; ok {
+			res.Set(name, RedactedValue)
+		}
+	}
+	return res
+}
+
+func redactURI(u *url.URL, params []string) string {
+	if u.RawQuery == "" {
+		return u.RequestURI()
+	}
+	redacted := *u
+	redacted.RawQuery = redactValues(u.RawQuery, params)
+	return redacted.RequestURI()
+}
+
+// redactValues replaces values of the names in URL encoded query, names are matched case-insensitively
+func redactValues(query string, names []string) string {
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return RedactedValue
+	}
+	for name, vs := range values {
+		if lo.ContainsBy(names, func(redacted string) bool { return strings.EqualFold(name, redacted) }) {
+			values[name] = lo.Map(vs, func(string, int) string { return RedactedValue })
+		}
+	}
+	return values.Encode()
+}
+
+// jsonFieldsPattern matches string values of the fields in JSON, it is used instead of decoding so that
+// truncated bodies are redacted as well
+func jsonFieldsPattern(fields []string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)("(?:` + strings.Join(lo.Map(fields, func(field string, _ int) string {
+		return regexp.QuoteMeta(field)
+	}), "|") + `)"\s*:\s*)"(?:[^"\\]|\\.)*"?`)
+}
+
+func redactBody(contentType string, body []byte, fields []string, jsonFields *regexp.Regexp) string {
+	switch {
+	case strings.Contains(contentType, "json"):
+		return jsonFields.ReplaceAllString(string(body), `${1}"`+RedactedValue+`"`)
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded") && len(body) > 0:
+		return redactValues(string(body), fields)
+	default:
+		return string(body)
+	}
+}
+
+func newRecordStore(target string, client s3iface.S3API) (RecordStore, error) {
+	if bucketAndPrefix, ok := strings.CutPrefix(target, "s3://"); ok {
+		bucket, prefix, _ := strings.Cut(bucketAndPrefix, "/")
+		if client == nil {
+			sess, err := session.NewSession()
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to init aws session")
+			}
+			client = s3.New(sess)
+		}
+		return &s3RecordStore{client: client, bucket: bucket, prefix: strings.TrimSuffix(prefix, "/")}, nil
+	}
+	if err := os.MkdirAll(target, 0o755); err != nil {
+		return nil, errors.Wrapf(err, "failed to create %s", target)
+	}
+	return &dirRecordStore{dir: target}, nil
+}
+
+func recordName(exchange RecordedExchange) string {
+	return fmt.Sprintf("%s-%s.json", exchange.RecordedAt.Format("20060102T150405.000"), exchange.ID)
+}
+
+type dirRecordStore struct {
+	dir string
+}
+
+func (d *dirRecordStore) Save(_ context.Context, exchange RecordedExchange) error {
+	data, err := json.MarshalIndent(exchange, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(d.dir, recordName(exchange)), data, 0o644)
+}
+
+type s3RecordStore struct {
+	client s3iface.S3API
+	bucket string
+	prefix string
+}
+
+func (s *s3RecordStore) Save(ctx context.Context, exchange RecordedExchange) error {
+	data, err := json.Marshal(exchange)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(strings.TrimPrefix(s.prefix+"/"+recordName(exchange), "/")),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String("application/json"),
+	})
+	return err
+}
+
+// LoadRecordedExchanges reads exchanges previously recorded into a local directory
+func LoadRecordedExchanges(dir string) ([]RecordedExchange, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	res := make([]RecordedExchange, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read %s", file)
+		}
+		var exchange RecordedExchange
+		if err := json.Unmarshal(data, &exchange); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %s", file)
+		}
+		res = append(res, exchange)
+	}
+	return res, nil
+}
@@ -0,0 +1,91 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestRequestRecorder(t *testing.T) {
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		router.POST("/api/echo", func(c service.HttpAdapter) error {
+			c.JSON(http.StatusOK, map[string]string{"body": string(service.ReadBytes(c.RequestBody()))})
+			return nil
+		})
+		return nil
+	})
+	tests := []struct {
+		name          string
+		body          string
+		wantTruncated bool
+	}{
+		{name: "complete body", body: "hello"},
+		{name: "truncated body", body: "hello world", wantTruncated: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			h := servicetest.New(t, routes, service.WithRequestRecorder(dir, service.RequestRecorderConfig{MaxBodyBytes: 8}))
+
+			res := h.Invoke(http.MethodPost, "/api/echo", tt.body, map[string]string{"Authorization": "Bearer secret"})
+			require.Equal(t, http.StatusOK, res.StatusCode)
+			assert.JSONEq(t, `{"body":"`+tt.body+`"}`, string(res.Body), "body is passed to handler beyond the recorded part")
+
+			exchanges := loadExchanges(t, dir, 1)
+			assert.Equal(t, service.RedactedValue, exchanges[0].Request.Headers.Get("Authorization"))
+			assert.Equal(t, tt.wantTruncated, exchanges[0].Request.Truncated)
+			assert.Equal(t, tt.body[:min(len(tt.body), 8)], exchanges[0].Request.Body)
+		})
+	}
+}
+
+func TestRequestRecorderRedaction(t *testing.T) {
+	dir := t.TempDir()
+	h := servicetest.New(t, service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		router.POST("/api/login", func(c service.HttpAdapter) error {
+			c.JSON(http.StatusOK, map[string]string{"user": "joe", "accessToken": "issued"})
+			return nil
+		})
+		return nil
+	}), service.WithRequestRecorder(dir, service.RequestRecorderConfig{RedactQueryParams: []string{"otp"}, RedactBodyFields: []string{"pin"}}))
+
+	res := h.Invoke(http.MethodPost, "/api/login?signature=sig&otp=123&page=1", `{"user":"joe","password":"p\"w","pin":"1234"}`,
+		map[string]string{"Content-Type": "application/json"})
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	exchanges := loadExchanges(t, dir, 1)
+	assert.Equal(t, "/api/login?otp=%3Credacted%3E&page=1&signature=%3Credacted%3E", exchanges[0].Request.URI)
+	assert.JSONEq(t, `{"user":"joe","password":"<redacted>","pin":"<redacted>"}`, exchanges[0].Request.Body)
+	assert.JSONEq(t, `{"user":"joe","accessToken":"<redacted>"}`, exchanges[0].Response.Body)
+}
+
+// loadExchanges waits for n exchanges as they are saved in background
+func loadExchanges(t *testing.T, dir string, n int) []service.RecordedExchange {
+	var exchanges []service.RecordedExchange
+	require.Eventually(t, func() bool {
+		var err error
+		exchanges, err = service.LoadRecordedExchanges(dir)
+		return err == nil && len(exchanges) == n
+	}, time.Second, 10*time.Millisecond)
+	return exchanges
+}
+
+func TestRequestRecorderInvalidTarget(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(file, nil, 0o644))
+
+	_, err := service.New(context.Background(),
+		service.WithRoutingType("function-url"),
+		service.WithRoutes(func(router service.HttpAdapterRouter) error { return nil }),
+		service.WithRequestRecorder(filepath.Join(file, "recordings"), service.RequestRecorderConfig{}))
+	assert.ErrorContains(t, err, "failed to init request recorder")
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+)
+
+type authenticatedKeyType struct{}
+
+var authenticatedKey authenticatedKeyType = struct{}{}
+
+// withAuthenticatedFlag lets handler middlewares learn whether request passed API key authentication
+func withAuthenticatedFlag(r *http.Request) (*http.Request, *atomic.Bool) {
+	flag := &atomic.Bool{}
+	return r.WithContext(context.WithValue(r.Context(), authenticatedKey, flag)), flag
+}
+
+func markAuthenticated(ctx context.Context) {
+	if flag, ok := ctx.Value(authenticatedKey).(*atomic.Bool); ok {
+		flag.Store(true)
+	}
+}
+
+// keyID identifies API key of the request in usage, lockout and logs, key itself is never kept, only its hash
+func keyID(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// RequestUID returns UID the service assigned to the request, it is also logged under RequestUIDKey
+func RequestUID(ctx context.Context) (string, bool) {
+	requestUID, ok := logger.GetValues(ctx)[RequestUIDKey].(string)
+	return requestUID, ok && requestUID != ""
+}
+
+// RequestStartedAt returns time the service started to process the request at, it is also logged under
+// RequestStartedKey
+func RequestStartedAt(ctx context.Context) (time.Time, bool) {
+	startedAt, ok := logger.GetValues(ctx)[RequestStartedKey].(time.Time)
+	return startedAt, ok
+}
+
+// IsAuthorized reports whether the request passed API key or signed URL authentication of the service, it is
+// false for routes skipping auth and before the auth middleware ran
+func IsAuthorized(ctx context.Context) bool {
+	flag, ok := ctx.Value(authenticatedKey).(*atomic.Bool)
+	return ok && flag.Load()
+}
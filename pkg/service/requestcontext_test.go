@@ -0,0 +1,64 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/util/clocktest"
+)
+
+func TestRequestContextAccessors(t *testing.T) {
+	clock := clocktest.New(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	handler := func(c service.HttpAdapter) error {
+		requestUID, hasUID := service.RequestUID(c.Context())
+		startedAt, hasStartedAt := service.RequestStartedAt(c.Context())
+		c.JSON(http.StatusOK, service.M{
+			"requestUID":   requestUID,
+			"hasUID":       hasUID,
+			"startedAt":    startedAt,
+			"hasStartedAt": hasStartedAt,
+			"authorized":   service.IsAuthorized(c.Context()),
+		})
+		return nil
+	}
+	h := servicetest.New(t, service.WithClock(clock), service.WithApiKey("key"), service.WithSkipAuthRoutes("/api/public"),
+		service.WithRoutes(func(router service.HttpAdapterRouter) error {
+			router.GET("/api/private", handler)
+			router.GET("/api/public", handler)
+			return nil
+		}))
+
+	var body struct {
+		RequestUID   string    `json:"requestUID"`
+		HasUID       bool      `json:"hasUID"`
+		StartedAt    time.Time `json:"startedAt"`
+		HasStartedAt bool      `json:"hasStartedAt"`
+		Authorized   bool      `json:"authorized"`
+	}
+	res := h.Invoke(http.MethodGet, "/api/private", nil, map[string]string{"Authorization": "Bearer key"})
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.NoError(t, res.JSON(&body))
+	assert.True(t, body.HasUID)
+	assert.NotEmpty(t, body.RequestUID)
+	assert.True(t, body.HasStartedAt)
+	assert.True(t, clock.Now().Equal(body.StartedAt))
+	assert.True(t, body.Authorized)
+
+	res = h.Invoke(http.MethodGet, "/api/public", nil, nil)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.NoError(t, res.JSON(&body))
+	assert.False(t, body.Authorized, "routes skipping auth are not authorized")
+
+	_, ok := service.RequestUID(context.Background())
+	assert.False(t, ok)
+	_, ok = service.RequestStartedAt(context.Background())
+	assert.False(t, ok)
+	assert.False(t, service.IsAuthorized(context.Background()))
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/samber/lo"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/util/ratelimit"
+)
+
+// ViewerCountryHeader is set by CloudFront to ISO 3166-1 alpha-2 code of the viewer country when the origin
+// request policy forwards it
+const ViewerCountryHeader = "CloudFront-Viewer-Country"
+
+// anyCountry keys rate limit of countries without their own limit
+const anyCountry = "*"
+
+// HeaderRule matches values of the header case-insensitively, requests with a value in Deny are rejected
+// and so are requests without a value in Allow when it is set, including ones missing the header
+type HeaderRule struct {
+	Header string
+	Allow  []string
+	Deny   []string
+}
+
+// RequestPolicy fences requests by viewer country and custom headers
+type RequestPolicy struct {
+	// AllowCountries and DenyCountries are a HeaderRule of ViewerCountryHeader, with AllowCountries set
+	// requests not coming through CloudFront are rejected as they have no country
+	AllowCountries []string
+	DenyCountries  []string
+	// CountryRateLimits limits requests of every client IP per upper-case viewer country, "*" limits countries
+	// without their own limit; requests without country are not limited
+	CountryRateLimits map[string]ratelimit.Limiter
+	Headers           []HeaderRule
+}
+
+// WithRequestPolicy rejects requests breaking the policy with 403, or with 429 when rate limit of the country
+// is exceeded; the policy runs before auth, see MiddlewareRequestPolicy
+func WithRequestPolicy(policy RequestPolicy) Option {
+	return func(s *service) {
+		s.requestPolicy = &policy
+	}
+}
+
+func (p *RequestPolicy) rules() []HeaderRule {
+	return append([]HeaderRule{{Header: ViewerCountryHeader, Allow: p.AllowCountries, Deny: p.DenyCountries}}, p.Headers...)
+}
+
+// violation returns why value of the header breaks the rule, empty when it does not
+func (r HeaderRule) violation(value string) string {
+	equal := func(v string) bool { return strings.EqualFold(v, value) }
+	switch {
+	case value != "" && lo.SomeBy(r.Deny, equal):
+		return "denied"
+	case len(r.Allow) > 0 && (value == "" || !lo.SomeBy(r.Allow, equal)):
+		return "not allowed"
+	default:
+		return ""
+	}
+}
+
+func (s *service) requestPolicyMiddleware() HttpAdapterHandler {
+	return func(c HttpAdapter) error {
+		for _, rule := range s.requestPolicy.rules() {
+			value := strings.TrimSpace(c.Header(rule.Header))
+			if reason := rule.violation(value); reason != "" {
+				logger.LogSecurity(c.Context(), s.logger, SecurityEventPolicyBlocked, securityFields(c, map[string]any{
+					"header": rule.Header,
+					"value":  value,
+					"reason": reason,
+				}))
+				c.JSON(http.StatusForbidden, M{"message": Localize(c, MessageForbidden)})
+				c.AbortWithStatus(http.StatusForbidden)
+				return nil
+			}
+		}
+		return s.checkCountryRateLimit(c)
+	}
+}
+
+func (s *service) checkCountryRateLimit(c HttpAdapter) error {
+	country := strings.ToUpper(strings.TrimSpace(c.Header(ViewerCountryHeader)))
+	if country == "" {
+		return nil
+	}
+	limiter, ok := s.requestPolicy.CountryRateLimits[country]
+	if !ok {
+		limiter, ok = s.requestPolicy.CountryRateLimits[anyCountry]
+	}
+	if !ok {
+		return nil
+	}
+	res, err := limiter.Allow(c.Context(), country+":"+c.RemoteIP())
+	if err != nil {
+		// limiter backend failure must not take the service down
+		s.logger.Errorf(s.logger.WithValue(c.Context(), "country", country), "failed to check rate limit of country: %v", err)
+		return nil
+	}
+	if res.Allowed {
+		return nil
+	}
+	logger.LogSecurity(c.Context(), s.logger, SecurityEventPolicyBlocked, securityFields(c, map[string]any{
+		"header": ViewerCountryHeader,
+		"value":  country,
+		"reason": "rate limited",
+	}))
+	c.SetHeader("Retry-After", strconv.Itoa(int(math.Ceil(res.RetryAfter.Seconds()))))
+	c.JSON(http.StatusTooManyRequests, M{"message": Localize(c, MessageTooManyRequests)})
+	c.AbortWithStatus(http.StatusTooManyRequests)
+	return nil
+}
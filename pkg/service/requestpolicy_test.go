@@ -0,0 +1,84 @@
+package service_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/util/ratelimit"
+)
+
+func TestWithRequestPolicy(t *testing.T) {
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		router.GET("/api/ping", func(c service.HttpAdapter) error {
+			c.JSON(http.StatusOK, service.M{})
+			return nil
+		})
+		return nil
+	})
+	tests := []struct {
+		name       string
+		policy     service.RequestPolicy
+		headers    map[string]string
+		wantStatus int
+	}{
+		{name: "allowed country", policy: service.RequestPolicy{AllowCountries: []string{"DE", "FR"}}, headers: map[string]string{service.ViewerCountryHeader: "fr"}, wantStatus: http.StatusOK},
+		{name: "not allowed country", policy: service.RequestPolicy{AllowCountries: []string{"DE"}}, headers: map[string]string{service.ViewerCountryHeader: "US"}, wantStatus: http.StatusForbidden},
+		{name: "no country with allow list", policy: service.RequestPolicy{AllowCountries: []string{"DE"}}, wantStatus: http.StatusForbidden},
+		{name: "denied country", policy: service.RequestPolicy{DenyCountries: []string{"KP"}}, headers: map[string]string{service.ViewerCountryHeader: "KP"}, wantStatus: http.StatusForbidden},
+		{name: "no country with deny list", policy: service.RequestPolicy{DenyCountries: []string{"KP"}}, wantStatus: http.StatusOK},
+		{
+			name:       "denied header value",
+			policy:     service.RequestPolicy{Headers: []service.HeaderRule{{Header: "X-Tenant", Deny: []string{"banned"}}}},
+			headers:    map[string]string{"X-Tenant": "Banned"},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "allowed header value",
+			policy:     service.RequestPolicy{Headers: []service.HeaderRule{{Header: "X-Tenant", Allow: []string{"acme"}}}},
+			headers:    map[string]string{"X-Tenant": "acme"},
+			wantStatus: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := servicetest.New(t, routes, service.WithRequestPolicy(tt.policy))
+
+			assert.Equal(t, tt.wantStatus, h.Invoke(http.MethodGet, "/api/ping", nil, tt.headers).StatusCode)
+		})
+	}
+}
+
+func TestRequestPolicyCountryRateLimits(t *testing.T) {
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		router.GET("/api/ping", func(c service.HttpAdapter) error {
+			c.JSON(http.StatusOK, service.M{})
+			return nil
+		})
+		return nil
+	})
+	h := servicetest.New(t, routes, service.WithRequestPolicy(service.RequestPolicy{
+		CountryRateLimits: map[string]ratelimit.Limiter{
+			"DE": ratelimit.NewSlidingWindow(1, time.Hour),
+			"*":  ratelimit.NewSlidingWindow(2, time.Hour),
+		},
+	}))
+	invoke := func(country string) *servicetest.Response {
+		return h.Invoke(http.MethodGet, "/api/ping", nil, map[string]string{service.ViewerCountryHeader: country})
+	}
+
+	assert.Equal(t, http.StatusOK, invoke("DE").StatusCode)
+	res := invoke("DE")
+	assert.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+	assert.NotEmpty(t, res.Headers.Get("Retry-After"))
+	assert.JSONEq(t, `{"message":"too many requests"}`, string(res.Body))
+
+	assert.Equal(t, http.StatusOK, invoke("US").StatusCode)
+	assert.Equal(t, http.StatusOK, invoke("US").StatusCode)
+	assert.Equal(t, http.StatusTooManyRequests, invoke("US").StatusCode)
+	assert.Equal(t, http.StatusOK, h.Invoke(http.MethodGet, "/api/ping", nil, nil).StatusCode, "requests without country are not limited")
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+type serviceKeyType struct{}
+
+var serviceKey serviceKeyType = struct{}{}
+
+// Response is the standard envelope of the payload and metadata of the request
+type Response struct {
+	Data any        `json:"data" yaml:"data"`
+	Meta ResultMeta `json:"meta" yaml:"meta"`
+}
+
+// StatusError is implemented by errors which define http status of the response
+type StatusError interface {
+	error
+	StatusCode() int
+}
+
+type statusError struct {
+	error
+	status int
+}
+
+func (e *statusError) StatusCode() int {
+	return e.status
+}
+
+func (e *statusError) Unwrap() error {
+	return e.error
+}
+
+// ErrorWithStatus attaches http status to err which is used by Fail
+func ErrorWithStatus(status int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &statusError{error: err, status: status}
+}
+
+// StatusCode returns http status attached to err, 500 otherwise
+func StatusCode(err error) int {
+	var statusErr StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode()
+	}
+	return http.StatusInternalServerError
+}
+
+// OK responds with data wrapped into the standard envelope
+func OK(c HttpAdapter, data any) {
+	Respond(c, http.StatusOK, data)
+}
+
+// Respond writes data wrapped into the standard envelope with meta of the request
+func Respond(c HttpAdapter, code int, data any) {
+	c.JSON(code, Response{
+		Data: data,
+		Meta: metaOf(c.Context()),
+	})
+}
+
+// Fail responds with error wrapped into the standard envelope, status is taken from StatusError (500 by default)
+func Fail(c HttpAdapter, err error) {
+	if err == nil {
+		err = errors.New("request failed with unknown error")
+	}
+	meta := metaOf(c.Context())
+	meta.Error = lo.ToPtr(err.Error())
+	status := StatusCode(err)
+	if s, ok := c.Context().Value(serviceKey).(Service); ok && status >= http.StatusInternalServerError {
+		s.Logger().Errorf(c.Context(), "request failed: %v", err)
+	}
+	c.JSON(status, Response{
+		Meta: meta,
+	})
+}
+
+func withService(ctx context.Context, s Service) context.Context {
+	return context.WithValue(ctx, serviceKey, s)
+}
+
+// metaOf returns meta of the request, service is stored in context by requestUIDMiddleware
+func metaOf(ctx context.Context) ResultMeta {
+	if s, ok := ctx.Value(serviceKey).(Service); ok {
+		return s.GetMeta(ctx)
+	}
+	return ResultMeta{RequestFinishedAt: time.Now()}
+}
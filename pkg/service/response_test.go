@@ -0,0 +1,67 @@
+package service_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/util/clocktest"
+)
+
+func TestFail(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantError  string
+	}{
+		{name: "status error", err: service.ErrorWithStatus(http.StatusConflict, errors.New("exists")), wantStatus: http.StatusConflict, wantError: "exists"},
+		{name: "plain error", err: errors.New("boom"), wantStatus: http.StatusInternalServerError, wantError: "boom"},
+		{name: "nil error", wantStatus: http.StatusInternalServerError, wantError: "request failed with unknown error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := servicetest.New(t, service.WithRoutes(func(router service.HttpAdapterRouter) error {
+				router.GET("/api/fail", func(c service.HttpAdapter) error {
+					service.Fail(c, tt.err)
+					return nil
+				})
+				return nil
+			}))
+
+			res := h.Invoke(http.MethodGet, "/api/fail", nil, nil)
+			require.Equal(t, tt.wantStatus, res.StatusCode)
+			var body service.Response
+			require.NoError(t, res.JSON(&body))
+			require.NotNil(t, body.Meta.Error)
+			assert.Equal(t, tt.wantError, *body.Meta.Error)
+		})
+	}
+}
+
+func TestResponseMetaClock(t *testing.T) {
+	startedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := clocktest.New(startedAt)
+	h := servicetest.New(t, service.WithClock(clock), service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		router.GET("/api/slow", func(c service.HttpAdapter) error {
+			clock.Advance(1500 * time.Millisecond)
+			service.OK(c, "done")
+			return nil
+		})
+		return nil
+	}))
+
+	res := h.Invoke(http.MethodGet, "/api/slow", nil, nil)
+	require.Equal(t, http.StatusOK, res.StatusCode, string(res.Body))
+	var body service.Response
+	require.NoError(t, res.JSON(&body))
+	assert.Equal(t, startedAt, body.Meta.RequestStartedAt.UTC())
+	assert.Equal(t, startedAt.Add(1500*time.Millisecond), body.Meta.RequestFinishedAt.UTC())
+	assert.Equal(t, 1500*time.Millisecond, body.Meta.RequestTime)
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"mime"
+	"net"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/awsutil"
+)
+
+const (
+	// MaxBufferedResponseSize is the lambda limit of buffered response bodies, binary bodies are counted base64-encoded
+	MaxBufferedResponseSize = 6 * 1024 * 1024
+	// MaxStreamingResponseSize is the lambda limit of streamed response bodies
+	MaxStreamingResponseSize = 20 * 1024 * 1024
+	// OffloadedResponseHeader is set to "true" on envelopes of responses offloaded with WithResponseOffload
+	OffloadedResponseHeader = "X-Offloaded-Response"
+
+	defaultResponseOffloadURLExpiry = 15 * time.Minute
+)
+
+// ErrResponseTooLarge is returned by writes of streamed responses past MaxStreamingResponseSize
+var ErrResponseTooLarge = errors.New("response body exceeds lambda response size limit")
+
+type ResponseOffloadConfig struct {
+	Bucket    string
+	Prefix    string
+	URLExpiry time.Duration // validity of presigned URL, defaults to 15 minutes
+	S3Client  s3iface.S3API
+}
+
+// OffloadedResponse is the envelope returned instead of JSON response exceeding lambda limit, the response
+// is kept as is in S3 and can be downloaded from URL until ExpiresAt
+type OffloadedResponse struct {
+	URL       string    `json:"url" yaml:"url"`
+	ExpiresAt time.Time `json:"expiresAt" yaml:"expiresAt"`
+	Size      int       `json:"size" yaml:"size"`
+}
+
+// WithResponseOffload stores buffered JSON responses exceeding MaxBufferedResponseSize in the bucket and returns
+// OffloadedResponse with presigned URL of the object instead; other oversized responses fail with 500
+func WithResponseOffload(cfg ResponseOffloadConfig) Option {
+	return func(s *service) {
+		s.responseOffload = &cfg
+	}
+}
+
+func (s *service) initResponseOffload() error {
+	if s.responseOffload == nil || s.responseOffload.S3Client != nil {
+		return nil
+	}
+	if s.responseOffload.Bucket == "" {
+		return errors.Errorf("response offload bucket is not set")
+	}
+	sess, err := session.NewSession()
+	if err != nil {
+		return errors.Wrapf(err, "failed to init aws session")
+	}
+	s.responseOffload.S3Client = s3.New(sess)
+	return nil
+}
+
+// responseSizeHandler keeps responses within lambda limits, it is not used in server mode; buffered responses
+// are held back until the handler returns to be replaced when they are too large, streamed ones fail to write
+// past the limit
+func (s *service) responseSizeHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sw := &responseSizeWriter{
+			ResponseWriter: w,
+			s:              s,
+			r:              r,
+			status:         http.StatusOK,
+			streaming:      s.useResponseStreaming,
+			limit:          lo.Ternary(s.useResponseStreaming, MaxStreamingResponseSize, MaxBufferedResponseSize),
+		}
+		next.ServeHTTP(sw, r)
+		sw.finish()
+	})
+}
+
+type responseSizeWriter struct {
+	http.ResponseWriter
+	s           *service
+	r           *http.Request
+	streaming   bool
+	limit       int
+	status      int
+	wroteHeader bool
+	committed   bool // buffered response was flushed and can no longer be replaced
+	exceeded    bool
+	logged      bool
+	size        int
+	body        bytes.Buffer
+}
+
+func (w *responseSizeWriter) WriteHeader(status int) {
+	if w.streaming || w.committed {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.status = status
+	}
+}
+
+func (w *responseSizeWriter) Write(p []byte) (int, error) {
+	if w.streaming {
+		if w.size+len(p) > w.limit {
+			w.exceed(w.size + len(p))
+			return 0, ErrResponseTooLarge
+		}
+		n, err := w.ResponseWriter.Write(p)
+		w.size += n
+		return n, err
+	}
+	w.size += len(p)
+	if w.committed {
+		if w.encodedSize() > w.limit {
+			w.exceed(w.encodedSize())
+		}
+		return w.ResponseWriter.Write(p)
+	}
+	w.WriteHeader(http.StatusOK)
+	if !w.exceeded && w.encodedSize() > w.limit {
+		w.exceeded = true
+		if !w.offloadable() {
+			// the response is replaced with an error, so it is not kept any longer
+			w.body.Reset()
+		}
+	}
+	if !w.exceeded || w.offloadable() {
+		w.body.Write(p)
+	}
+	return len(p), nil
+}
+
+// Flush writes buffered response through unless it is too large already
+func (w *responseSizeWriter) Flush() {
+	if !w.streaming && !w.committed {
+		if w.exceeded {
+			return
+		}
+		w.commit()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *responseSizeWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return hijacker.Hijack()
+	}
+	return nil, nil, errors.Errorf("ResponseWriter does not implement http.Hijacker")
+}
+
+func (w *responseSizeWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// encodedSize is the size of buffered body in lambda response, binary bodies are base64-encoded there
+func (w *responseSizeWriter) encodedSize() int {
+	if awsutil.IsBinaryMediaType(w.Header().Get("Content-Type")) {
+		return base64.StdEncoding.EncodedLen(w.size)
+	}
+	return w.size
+}
+
+func (w *responseSizeWriter) offloadable() bool {
+	mediaType, _, err := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	return w.s.responseOffload != nil && err == nil && mediaTypeMatches("application/json", mediaType)
+}
+
+// exceed logs the response going past the limit once
+func (w *responseSizeWriter) exceed(size int) {
+	w.exceeded = true
+	if w.logged {
+		return
+	}
+	w.logged = true
+	w.s.logger.Errorf(w.r.Context(), "response body of %d bytes exceeds lambda limit of %d bytes: %s %s",
+		size, w.limit, w.r.Method, w.r.URL.Path)
+}
+
+func (w *responseSizeWriter) commit() {
+	w.committed = true
+	if w.wroteHeader {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	if w.body.Len() > 0 {
+		_, _ = w.ResponseWriter.Write(w.body.Bytes())
+	}
+	w.body = bytes.Buffer{}
+}
+
+func (w *responseSizeWriter) finish() {
+	if w.streaming || w.committed {
+		return
+	}
+	if !w.exceeded {
+		w.commit()
+		return
+	}
+	ctx := w.r.Context()
+	w.exceed(w.encodedSize())
+	header := w.Header()
+	header.Del("Content-Length")
+	header.Del("Content-Encoding")
+	if w.offloadable() {
+		offloaded, err := w.offload()
+		if err == nil {
+			header.Set("Content-Type", JSONContentType)
+			header.Set(OffloadedResponseHeader, "true")
+			w.ResponseWriter.WriteHeader(w.status)
+			data, _ := encodeJSON(offloaded)
+			_, _ = w.ResponseWriter.Write(data)
+			return
+		}
+		w.s.logger.Errorf(ctx, "failed to offload response: %v", err)
+	}
+	header.Set("Content-Type", JSONContentType)
+	w.ResponseWriter.WriteHeader(http.StatusInternalServerError)
+	data, _ := encodeJSON(M{"message": localize(ctx, w.r.Header.Get("Accept-Language"), MessageResponseTooLarge, w.limit)})
+	_, _ = w.ResponseWriter.Write(data)
+}
+
+func (w *responseSizeWriter) offload() (OffloadedResponse, error) {
+	cfg := w.s.responseOffload
+	key := path.Join(cfg.Prefix, uuid.NewString()+".json")
+	if _, err := cfg.S3Client.PutObjectWithContext(w.r.Context(), &s3.PutObjectInput{
+		Bucket:        aws.String(cfg.Bucket),
+		Key:           aws.String(key),
+		Body:          bytes.NewReader(w.body.Bytes()),
+		ContentType:   aws.String(w.Header().Get("Content-Type")),
+		ContentLength: aws.Int64(int64(w.body.Len())),
+	}); err != nil {
+		return OffloadedResponse{}, errors.Wrapf(err, "failed to put response to s3://%s/%s", cfg.Bucket, key)
+	}
+	expiry := lo.Ternary(cfg.URLExpiry > 0, cfg.URLExpiry, defaultResponseOffloadURLExpiry)
+	req, _ := cfg.S3Client.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(cfg.Bucket),
+		Key:    aws.String(key),
+	})
+	url, err := req.Presign(expiry)
+	if err != nil {
+		return OffloadedResponse{}, errors.Wrapf(err, "failed to presign s3://%s/%s", cfg.Bucket, key)
+	}
+	return OffloadedResponse{
+		URL:       url,
+		ExpiresAt: w.s.clock.Now().Add(expiry).UTC().Truncate(time.Second),
+		Size:      w.body.Len(),
+	}, nil
+}
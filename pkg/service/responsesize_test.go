@@ -0,0 +1,77 @@
+package service_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestResponseSizeLimit(t *testing.T) {
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		router.GET("/api/items", func(c service.HttpAdapter) error {
+			size := service.MaxBufferedResponseSize
+			if c.Query("small") != "" {
+				size = 16
+			}
+			c.JSON(http.StatusOK, service.M{"items": strings.Repeat("x", size)})
+			return nil
+		})
+		return nil
+	})
+	var uploaded []byte
+	s3Server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uploaded, _ = io.ReadAll(r.Body)
+	}))
+	defer s3Server.Close()
+	sess, err := session.NewSession(&aws.Config{
+		Region:           aws.String("us-east-1"),
+		Endpoint:         aws.String(s3Server.URL),
+		Credentials:      credentials.NewStaticCredentials("id", "secret", ""),
+		S3ForcePathStyle: aws.Bool(true),
+	})
+	require.NoError(t, err)
+
+	t.Run("too large", func(t *testing.T) {
+		h := servicetest.New(t, routes)
+
+		res := h.Invoke(http.MethodGet, "/api/items?small=true", nil, nil)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.JSONEq(t, `{"items":"xxxxxxxxxxxxxxxx"}`, string(res.Body))
+
+		res = h.Invoke(http.MethodGet, "/api/items", nil, nil)
+		assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
+		assert.Contains(t, string(res.Body), "response exceeds 6291456 bytes limit")
+	})
+
+	t.Run("offloaded", func(t *testing.T) {
+		h := servicetest.New(t, routes, service.WithResponseOffload(service.ResponseOffloadConfig{
+			Bucket:   "responses",
+			Prefix:   "offloaded",
+			S3Client: s3.New(sess),
+		}))
+
+		res := h.Invoke(http.MethodGet, "/api/items", nil, nil)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "true", res.Headers.Get(service.OffloadedResponseHeader))
+		var offloaded service.OffloadedResponse
+		require.NoError(t, json.Unmarshal(res.Body, &offloaded))
+		assert.Contains(t, offloaded.URL, "/responses/offloaded/")
+		assert.Contains(t, offloaded.URL, "X-Amz-Signature=")
+		assert.Equal(t, len(uploaded), offloaded.Size)
+		assert.True(t, strings.HasPrefix(string(uploaded), `{"items":"xxx`))
+	})
+}
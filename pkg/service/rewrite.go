@@ -0,0 +1,60 @@
+package service
+
+import (
+	"net/http"
+	"net/textproto"
+)
+
+// RequestRewriter modifies request before routing, e.g. to map legacy paths to the new ones, url changes are
+// visible to routing and handlers while OriginalURL keeps the url sent by the caller
+type RequestRewriter func(r *http.Request)
+
+// WithRequestRewriter adds rewriters applied in order to every request regardless of the engine,
+// they get path with API Gateway stage and base path stripped
+func WithRequestRewriter(rewriters ...RequestRewriter) Option {
+	return func(s *service) {
+		s.requestRewriters = append(s.requestRewriters, rewriters...)
+	}
+}
+
+// PathAliases rewrites requests of the legacy paths (keys) to the new ones (values)
+func PathAliases(aliases map[string]string) RequestRewriter {
+	return func(r *http.Request) {
+		if p, ok := aliases[r.URL.Path]; ok {
+			r.URL.Path = p
+		}
+	}
+}
+
+// CanonicalHeaders makes names of the headers canonical, e.g. for requests constructed from lambda events
+// by hand, so that they are found by http.Header.Get
+func CanonicalHeaders() RequestRewriter {
+	return func(r *http.Request) {
+		for name, values := range r.Header {
+			if canonical := textproto.CanonicalMIMEHeaderKey(name); canonical != name {
+				delete(r.Header, name)
+				r.Header[canonical] = append(r.Header[canonical], values...)
+			}
+		}
+	}
+}
+
+func (s *service) rewriteRequestHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(s.requestRewriters) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		// request is cloned so that rewriters do not modify url kept for OriginalURL
+		r = withOriginalURL(r)
+		path := r.URL.Path
+		for _, rewrite := range s.requestRewriters {
+			rewrite(r)
+		}
+		if r.URL.Path != path {
+			r.URL.RawPath = ""
+		}
+		r.RequestURI = r.URL.RequestURI()
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,73 @@
+package service_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestRequestRewriter(t *testing.T) {
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		router.GET("/api/v2/items", func(c service.HttpAdapter) error {
+			c.JSON(http.StatusOK, map[string]string{
+				"uri":      c.Request().RequestURI,
+				"original": service.OriginalURL(c.Request()).RequestURI(),
+				"tenant":   c.Header("X-Tenant-Id"),
+			})
+			return nil
+		})
+		return nil
+	})
+	lowercase := service.RequestRewriter(func(r *http.Request) {
+		r.URL.Path = strings.ToLower(r.URL.Path)
+	})
+	rewriters := service.WithRequestRewriter(lowercase, service.PathAliases(map[string]string{
+		"/api/items": "/api/v2/items",
+	}))
+
+	tests := []struct {
+		name         string
+		streaming    bool
+		path         string
+		wantURI      string
+		wantOriginal string
+	}{
+		{name: "legacy path alias", path: "/api/items?q=1", wantURI: "/api/v2/items?q=1", wantOriginal: "/api/items?q=1"},
+		{name: "rewriters are applied in order", path: "/API/Items", wantURI: "/api/v2/items", wantOriginal: "/API/Items"},
+		{name: "unchanged path", path: "/api/v2/items", wantURI: "/api/v2/items", wantOriginal: "/api/v2/items"},
+		{name: "echo engine", streaming: true, path: "/shop/API/items", wantURI: "/api/v2/items", wantOriginal: "/shop/API/items"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := servicetest.New(t, routes, rewriters, service.WithBasePath("/shop"), service.UseResponseStreaming(tt.streaming))
+
+			res := h.Invoke(http.MethodGet, tt.path, nil, nil)
+			require.Equal(t, http.StatusOK, res.StatusCode, string(res.Body))
+			var body map[string]string
+			require.NoError(t, res.JSON(&body))
+			assert.Equal(t, tt.wantURI, body["uri"])
+			assert.Equal(t, tt.wantOriginal, body["original"])
+		})
+	}
+
+	t.Run("canonical headers", func(t *testing.T) {
+		h := servicetest.New(t, routes, service.WithRequestRewriter(service.CanonicalHeaders()))
+		req := httptest.NewRequest(http.MethodGet, "/api/v2/items", nil)
+		req.Header["x-tenant-id"] = []string{"acme"}
+		rec := httptest.NewRecorder()
+		h.Service.Handler().ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var body map[string]string
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, "acme", body["tenant"])
+	})
+}
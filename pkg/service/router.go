@@ -2,15 +2,17 @@ package service
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"net/http"
 	"strings"
 	"time"
 
-	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"github.com/samber/lo"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
 )
 
 const (
@@ -25,6 +27,9 @@ type ResultMeta struct {
 	RequestFinishedAt time.Time     `json:"requestFinishedAt" yaml:"requestFinishedAt"`
 	RequestTime       time.Duration `json:"requestTime" yaml:"requestTime"`
 	Cost              float64       `json:"cost" yaml:"cost"`
+	Memory            *MemoryStats  `json:"memory,omitempty" yaml:"memory,omitempty"` // only set when WithMemoryStats is used
+	ColdStart         bool          `json:"coldStart,omitempty" yaml:"coldStart,omitempty"`
+	Init              *InitStats    `json:"init,omitempty" yaml:"init,omitempty"` // only set for the first request of the instance
 }
 
 type Error struct {
@@ -32,8 +37,15 @@ type Error struct {
 	Meta    ResultMeta `json:"meta" yaml:"meta"` // metadata related to processing
 }
 
+const (
+	AuthEnabled     = "enabled"
+	AuthDisabled    = "disabled"
+	AuthUnavailable = "unavailable" // API key secret could not be resolved
+)
+
 type Status struct {
 	Status string   `json:"status" yaml:"status"`
+	Auth   string   `json:"auth" yaml:"auth"`
 	Errors []string `json:"errors,omitempty" yaml:"errors,omitempty"`
 }
 
@@ -44,10 +56,14 @@ func ReadBytes(stream io.Reader) []byte {
 }
 
 func (s *service) reportStatus(c HttpAdapter, status *Status) {
-	c.JSON(http.StatusOK, gin.H{
+	res := M{
 		"version": s.version,
 		"status":  status,
-	})
+	}
+	if s.build != nil {
+		res["build"] = s.build
+	}
+	c.JSON(http.StatusOK, res)
 }
 
 // @Schemes
@@ -64,20 +80,52 @@ func (s *service) statusEndpoint(c HttpAdapter) error {
 func (s *service) Status() *Status {
 	res := Status{
 		Status: "running",
+		Auth:   AuthDisabled,
+	}
+	switch {
+	case s.apiKey != "":
+		res.Auth = AuthEnabled
+	case s.apiKeyErr != nil:
+		res.Auth = AuthUnavailable
+		res.Errors = append(res.Errors, "failed to resolve API_KEY secret, authentication is disabled")
 	}
 	return &res
 }
 
+// checkAuth fails when authentication is required but API key is missing, apiKeyErr is the error of API_KEY resolution
+func (s *service) checkAuth(apiKeyErr error) error {
+	if s.apiKey != "" {
+		return nil
+	}
+	s.apiKeyErr = apiKeyErr
+	if !s.requiredAuth {
+		return nil
+	}
+	if apiKeyErr != nil {
+		return errors.Wrapf(apiKeyErr, "API key is required but its secret can not be resolved")
+	}
+	return errors.Errorf("API key is required but not configured")
+}
+
 func (s *service) requestUIDMiddleware() HttpAdapterHandler {
 	return func(c HttpAdapter) error {
 		ctx := c.Context()
 
-		requestUID, err := uuid.NewUUID()
-		if err != nil {
-			return err
+		// request UID may be assigned by handler middleware (e.g. problem details)
+		if _, ok := RequestUID(ctx); !ok {
+			requestUID, err := uuid.NewUUID()
+			if err != nil {
+				return err
+			}
+			ctx = s.logger.WithValue(ctx, RequestUIDKey, requestUID.String())
+		}
+		ctx = s.logger.WithValue(ctx, RequestStartedKey, s.clock.Now())
+		if s.memoryStats {
+			ctx = withMemStatsSnapshot(ctx)
 		}
-		ctx = s.logger.WithValue(ctx, RequestUIDKey, requestUID.String())
-		ctx = s.logger.WithValue(ctx, RequestStartedKey, time.Now())
+		ctx = s.markColdStart(ctx)
+		ctx = withService(ctx, s)
+		ctx = s.withSigningHost(ctx, c.Request())
 
 		c.SetContext(ctx)
 		return nil
@@ -87,10 +135,9 @@ func (s *service) requestUIDMiddleware() HttpAdapterHandler {
 func (s *service) debugLogMiddleware() HttpAdapterHandler {
 	return func(c HttpAdapter) error {
 		if s.requestDebugMode {
-			requestUIDOrNil := s.logger.GetValue(c.Context(), RequestUIDKey)
-			requestUID := "<nil>"
-			if requestUIDOrNil != nil {
-				requestUID = requestUIDOrNil.(string)
+			requestUID, ok := RequestUID(c.Context())
+			if !ok {
+				requestUID = "<nil>"
 			}
 			ctx := c.Context()
 			ctx = s.logger.WithValue(ctx, "request", map[string]any{
@@ -117,29 +164,53 @@ func (s *service) apiKeyAuthMiddleware() HttpAdapterHandler {
 			return errors.Errorf("API_KEY is not configured")
 		}
 
-		if _, found := lo.Find(s.skipAuthRoutes, func(prefix string) bool {
-			return strings.HasPrefix(c.Request().RequestURI, prefix)
-		}); found {
+		if s.isAuthSkipped(c.Request()) {
 			s.logger.Infof(s.ctx, "skip authorization for "+c.Request().RequestURI+" ... ")
 			return nil
 		}
 
+		if s.isSignedURL(c.Request()) {
+			markAuthenticated(c.Context())
+			return nil
+		} else if OriginalURL(c.Request()).Query().Get(SignedURLSignatureParam) != "" {
+			logger.LogSecurity(c.Context(), s.logger, SecurityEventSignatureMismatch, securityFields(c, nil))
+		}
+
 		authHeader := c.Request().Header["Authorization"]
-		if len(authHeader) == 0 {
-			s.respondUnauthorized(c)
-			return errors.Errorf("Unauthorized")
+		if len(authHeader) == 0 && s.isOptionalAuthRoute(c.Request().URL.Path) {
+			// anonymous request of optional auth route, handler branches on IsAuthorized
+			return nil
+		} else if len(authHeader) == 0 {
+			return s.rejectUnauthorized(c, "missing authorization")
 		} else if providedTokenParts := strings.Split(authHeader[0], " "); len(providedTokenParts) < 2 {
-			s.respondUnauthorized(c)
-			return errors.Errorf("Unauthorized")
+			return s.rejectUnauthorized(c, "malformed authorization")
 		} else if providedTokenParts[1] != s.apiKey {
-			s.respondUnauthorized(c)
-			return errors.Errorf("Unauthorized")
+			return s.rejectUnauthorized(c, "invalid API key")
+		}
+		markAuthenticated(c.Context())
+		if len(s.apiKeyScopes) > 0 {
+			c.SetContext(context.WithValue(c.Context(), apiKeyScopesKey, s.apiKeyScopes))
 		}
 		return nil
 	}
 }
 
+// isAuthSkipped reports whether request is served without authentication, see WithSkipAuthRoutes
+func (s *service) isAuthSkipped(r *http.Request) bool {
+	_, found := lo.Find(s.skipAuthRoutes, func(prefix string) bool {
+		return strings.HasPrefix(r.RequestURI, prefix)
+	})
+	return found || lo.Contains(s.publicRoutes, r.URL.Path)
+}
+
+// rejectUnauthorized logs auth failure security event and responds with 401
+func (s *service) rejectUnauthorized(c HttpAdapter, reason string) error {
+	logger.LogSecurity(c.Context(), s.logger, SecurityEventAuthFailure, securityFields(c, map[string]any{"reason": reason}))
+	s.respondUnauthorized(c)
+	return errors.Errorf("Unauthorized")
+}
+
 func (s *service) respondUnauthorized(c HttpAdapter) {
-	c.JSON(http.StatusUnauthorized, gin.H{"message": "authorization key is not provided"})
+	c.JSON(http.StatusUnauthorized, M{"message": Localize(c, MessageUnauthorized)})
 	c.AbortWithStatus(http.StatusUnauthorized)
 }
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/samber/lo"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+)
+
+type RouteInfo struct {
+	Method       string   `json:"method" yaml:"method"`
+	Path         string   `json:"path" yaml:"path"`
+	Host         string   `json:"host,omitempty" yaml:"host,omitempty"` // only set for routes registered via WithHostRouter
+	AuthRequired bool     `json:"authRequired" yaml:"authRequired"`
+	AuthOptional bool     `json:"authOptional,omitempty" yaml:"authOptional,omitempty"` // anonymous requests are served as well, see WithOptionalAuthRoutes
+	Middlewares  []string `json:"middlewares,omitempty" yaml:"middlewares,omitempty"`
+}
+
+type routeRegistry struct {
+	mu       sync.Mutex
+	routes   []RouteInfo
+	versions []versionPrefix
+}
+
+func (r *routeRegistry) add(method, p string, middlewares []string) {
+	r.addRoute(RouteInfo{
+		Method:      method,
+		Path:        p,
+		Middlewares: middlewares,
+	})
+}
+
+func (r *routeRegistry) addRoute(route RouteInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.routes = append(r.routes, route)
+}
+
+func (r *routeRegistry) list() []RouteInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]RouteInfo{}, r.routes...)
+}
+
+// introspectingRouter records every route registered through the delegate router
+type introspectingRouter struct {
+	delegate    HttpAdapterRouter
+	registry    *routeRegistry
+	logger      logger.Logger
+	flags       FeatureFlags
+	host        string
+	prefix      string
+	middlewares []string
+}
+
+func newIntrospectingRouter(delegate HttpAdapterRouter, registry *routeRegistry, log logger.Logger, flags FeatureFlags) HttpAdapterRouter {
+	return &introspectingRouter{
+		delegate: delegate,
+		registry: registry,
+		logger:   log,
+		flags:    flags,
+	}
+}
+
+func (r *introspectingRouter) add(method, p string) {
+	r.registry.addRoute(RouteInfo{
+		Method:      method,
+		Path:        path.Join("/", r.prefix, p) + lo.If(len(p) > 1 && strings.HasSuffix(p, "/"), "/").Else(""),
+		Host:        r.host,
+		Middlewares: append([]string{}, r.middlewares...),
+	})
+}
+
+func (r *introspectingRouter) Use(mw HttpAdapterHandler) {
+	r.middlewares = append(r.middlewares, middlewareName(mw))
+	r.delegate.Use(mw)
+}
+
+func (r *introspectingRouter) Any(p string, h HttpAdapterHandler) {
+	r.add("ANY", p)
+	r.delegate.Any(p, h)
+}
+
+func (r *introspectingRouter) GET(p string, h HttpAdapterHandler) {
+	r.add(http.MethodGet, p)
+	r.delegate.GET(p, h)
+}
+
+func (r *introspectingRouter) POST(p string, h HttpAdapterHandler) {
+	r.add(http.MethodPost, p)
+	r.delegate.POST(p, h)
+}
+
+func (r *introspectingRouter) DELETE(p string, h HttpAdapterHandler) {
+	r.add(http.MethodDelete, p)
+	r.delegate.DELETE(p, h)
+}
+
+func (r *introspectingRouter) PATCH(p string, h HttpAdapterHandler) {
+	r.add(http.MethodPatch, p)
+	r.delegate.PATCH(p, h)
+}
+
+func (r *introspectingRouter) PUT(p string, h HttpAdapterHandler) {
+	r.add(http.MethodPut, p)
+	r.delegate.PUT(p, h)
+}
+
+func (r *introspectingRouter) OPTIONS(p string, h HttpAdapterHandler) {
+	r.add(http.MethodOptions, p)
+	r.delegate.OPTIONS(p, h)
+}
+
+func (r *introspectingRouter) HEAD(p string, h HttpAdapterHandler) {
+	r.add(http.MethodHead, p)
+	r.delegate.HEAD(p, h)
+}
+
+func (r *introspectingRouter) Group(name string) HttpAdapterRouter {
+	return &introspectingRouter{
+		delegate:    r.delegate.Group(name),
+		registry:    r.registry,
+		logger:      r.logger,
+		flags:       r.flags,
+		host:        r.host,
+		prefix:      path.Join(r.prefix, name),
+		middlewares: append([]string{}, r.middlewares...),
+	}
+}
+
+// Versioned registers version through the introspecting router itself so that its routes are recorded
+// and the version becomes available for Accept-Version negotiation
+func (r *introspectingRouter) Versioned(version string, callback RegisterRoutesCallback, opts ...VersionOption) error {
+	r.registry.addVersion(r.prefix, version)
+	return versioned(r, r.logger, version, callback, opts...)
+}
+
+var funcSuffixRegexp = regexp.MustCompile(`(\.func\d+)+$`)
+
+// middlewareName turns function name like github.com/org/repo/pkg.(*service).authMiddleware.func1
+// into authMiddleware
+func middlewareName(mw HttpAdapterHandler) string {
+	fn := runtime.FuncForPC(reflect.ValueOf(mw).Pointer())
+	if fn == nil {
+		return "unknown"
+	}
+	name := funcSuffixRegexp.ReplaceAllString(fn.Name(), "")
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// Routes returns all routes registered via the service router
+func (s *service) Routes() []RouteInfo {
+	return lo.Map(s.routes.list(), func(route RouteInfo, _ int) RouteInfo {
+		route.AuthRequired = s.apiKey != "" && !s.isSkipAuthRoute(route.Path) && !lo.Contains(s.publicRoutes, route.Path)
+		route.AuthOptional = route.AuthRequired && s.isOptionalAuthRoute(route.Path)
+		route.AuthRequired = route.AuthRequired && !route.AuthOptional
+		return route
+	})
+}
+
+func (s *service) isSkipAuthRoute(p string) bool {
+	_, found := lo.Find(s.skipAuthRoutes, func(prefix string) bool {
+		return strings.HasPrefix(p, prefix)
+	})
+	return found
+}
+
+func (s *service) isOptionalAuthRoute(p string) bool {
+	return lo.SomeBy(s.optionalAuthRoutes, func(prefix string) bool {
+		return strings.HasPrefix(p, prefix)
+	})
+}
+
+func (s *service) logRoutes(ctx context.Context) {
+	for _, route := range s.Routes() {
+		s.logger.Infof(s.logger.WithValue(ctx, "route", route), "registered route %s %s", route.Method, route.Path)
+	}
+}
+
+// @Schemes
+// @Description list registered routes (local debug only)
+// @Tags debug
+// @Produce json
+// @Success 200 {array} RouteInfo
+// @Router /api/_routes [get]
+func (s *service) routesEndpoint(c HttpAdapter) error {
+	c.JSON(http.StatusOK, s.Routes())
+	return nil
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/samber/lo"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+)
+
+type apiKeyScopesKeyType struct{}
+
+var apiKeyScopesKey apiKeyScopesKeyType = struct{}{}
+
+// WithApiKeyScopes grants scopes to requests authenticated with the API key of the service, e.g.
+// WithApiKeyScopes("admin") lets holders of the key call routes guarded by RequireScope("admin")
+func WithApiKeyScopes(scopes ...string) Option {
+	return func(s *service) {
+		s.apiKeyScopes = append(s.apiKeyScopes, scopes...)
+	}
+}
+
+// Scopes returns scopes granted to the principal of the request: "scope" and "scp" claims of JWT/Cognito
+// authorizers, "cognito:groups" as well as "scope" and "scopes" of lambda authorizer context, plus scopes of
+// the API key the request is authenticated with; string claims may list scopes separated by spaces or commas
+func Scopes(ctx context.Context) []string {
+	var res []string
+	if claims, ok := AuthorizerClaims(ctx); ok {
+		res = append(res, claimScopes(claims.JWT(), "scope", "scp", "cognito:groups")...)
+		res = append(res, claimScopes(claims, "scope", "scopes")...)
+	}
+	if scopes, ok := ctx.Value(apiKeyScopesKey).([]string); ok {
+		res = append(res, scopes...)
+	}
+	return lo.Uniq(res)
+}
+
+// HasScope reports whether the principal of the request is granted the scope, see Scopes
+func HasScope(ctx context.Context, scope string) bool {
+	return lo.Contains(Scopes(ctx), scope)
+}
+
+// RequireScope rejects requests whose principal lacks any of the scopes with 403 Forbidden, details of the
+// response list required and missing scopes; use it with router.Use for a group of routes or with Scoped for
+// a single route
+func RequireScope(scopes ...string) HttpAdapterHandler {
+	return func(c HttpAdapter) error {
+		missing, _ := lo.Difference(scopes, Scopes(c.Context()))
+		if len(missing) == 0 {
+			return nil
+		}
+		if s, ok := c.Context().Value(serviceKey).(Service); ok {
+			logger.LogSecurity(c.Context(), s.Logger(), SecurityEventScopeDenied, securityFields(c, map[string]any{
+				"missingScopes": missing,
+			}))
+		}
+		c.JSON(http.StatusForbidden, M{
+			"message":        Localize(c, MessageForbidden),
+			"requiredScopes": scopes,
+			"missingScopes":  missing,
+		})
+		c.AbortWithStatus(http.StatusForbidden)
+		return nil
+	}
+}
+
+// Scoped wraps handler of a single route to serve principals granted all the scopes only, see RequireScope
+func Scoped(h HttpAdapterHandler, scopes ...string) HttpAdapterHandler {
+	check := RequireScope(scopes...)
+	return func(c HttpAdapter) error {
+		if err := check(c); err != nil || c.IsAborted() {
+			return err
+		}
+		return h(c)
+	}
+}
+
+// claimScopes collects scopes of claims of the keys, claims are either lists or strings
+func claimScopes(claims Claims, keys ...string) []string {
+	var res []string
+	for _, key := range keys {
+		switch v := claims[key].(type) {
+		case nil:
+		case []string:
+			res = append(res, v...)
+		case []any:
+			res = append(res, lo.FilterMap(v, func(item any, _ int) (string, bool) {
+				scope, ok := item.(string)
+				return scope, ok && scope != ""
+			})...)
+		default:
+			// cognito groups arrive as "[admin editor]" when API Gateway stringifies claims
+			res = append(res, strings.FieldsFunc(strings.Trim(claims.String(key), "[]"), func(r rune) bool {
+				return r == ' ' || r == ','
+			})...)
+		}
+	}
+	return res
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/awsutil/eventstest"
+)
+
+func TestRequireScope(t *testing.T) {
+	svc, err := New(context.Background(), WithEnv(func(string) string { return "" }), WithRoutingType("api-gateway"),
+		WithApiKey("key"), WithApiKeyScopes("orders:read"), WithSkipAuthRoutes("/api/jwt"),
+		WithRoutes(func(router HttpAdapterRouter) error {
+			ok := func(c HttpAdapter) error {
+				c.JSON(http.StatusOK, M{"scopes": Scopes(c.Context())})
+				return nil
+			}
+			router.GET("/api/orders", Scoped(ok, "orders:read"))
+			admin := router.Group("/api/admin")
+			admin.Use(RequireScope("admin"))
+			admin.GET("/users", ok)
+			router.GET("/api/jwt/orders", Scoped(ok, "orders:read", "orders:write"))
+			return nil
+		}))
+	require.NoError(t, err)
+	s := svc.(*service)
+
+	tests := []struct {
+		name        string
+		path        string
+		opts        []eventstest.RequestOption
+		wantStatus  int
+		wantMissing []string
+	}{
+		{
+			name:       "api key scopes",
+			path:       "/api/orders",
+			opts:       []eventstest.RequestOption{eventstest.WithHeader("Authorization", "Bearer key")},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:        "api key lacks scope",
+			path:        "/api/admin/users",
+			opts:        []eventstest.RequestOption{eventstest.WithHeader("Authorization", "Bearer key")},
+			wantStatus:  http.StatusForbidden,
+			wantMissing: []string{"admin"},
+		},
+		{
+			name: "jwt scope claim",
+			path: "/api/jwt/orders",
+			opts: []eventstest.RequestOption{eventstest.WithAuthorizer(map[string]any{
+				"claims": map[string]any{"sub": "user-1", "scope": "orders:read orders:write"},
+			})},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "cognito groups",
+			path: "/api/jwt/orders",
+			opts: []eventstest.RequestOption{eventstest.WithAuthorizer(map[string]any{
+				"claims": map[string]any{"sub": "user-1", "cognito:groups": "[orders:read]"},
+			})},
+			wantStatus:  http.StatusForbidden,
+			wantMissing: []string{"orders:write"},
+		},
+		{
+			name:        "lambda authorizer context",
+			path:        "/api/jwt/orders",
+			opts:        []eventstest.RequestOption{eventstest.WithAuthorizer(map[string]any{"scopes": "orders:write"})},
+			wantStatus:  http.StatusForbidden,
+			wantMissing: []string{"orders:read"},
+		},
+		{
+			name:        "anonymous",
+			path:        "/api/jwt/orders",
+			wantStatus:  http.StatusForbidden,
+			wantMissing: []string{"orders:read", "orders:write"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := s.ProxyLambdaApiGateway(context.Background(), eventstest.APIGatewayProxyRequest(http.MethodGet, tt.path, tt.opts...))
+			require.NoError(t, err)
+			require.Equal(t, tt.wantStatus, res.StatusCode, res.Body)
+			if tt.wantStatus != http.StatusForbidden {
+				return
+			}
+			var body struct {
+				Message        string   `json:"message"`
+				RequiredScopes []string `json:"requiredScopes"`
+				MissingScopes  []string `json:"missingScopes"`
+			}
+			require.NoError(t, json.Unmarshal([]byte(res.Body), &body))
+			assert.Equal(t, "access is forbidden", body.Message)
+			assert.NotEmpty(t, body.RequiredScopes)
+			assert.Equal(t, tt.wantMissing, body.MissingScopes)
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/awsutil"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+)
+
+const (
+	// securityLogGroupEnv names CloudWatch Logs group security events are written to instead of stdout
+	securityLogGroupEnv = "SIMPLE_CONTAINER_SECURITY_LOG_GROUP"
+	lambdaLogStreamEnv  = "AWS_LAMBDA_LOG_STREAM_NAME"
+)
+
+// security events logged with logger.Security by the service
+const (
+	SecurityEventAuthFailure       = "authFailure"
+	SecurityEventAuthLockout       = "authLockout"
+	SecurityEventIPBlocked         = "ipBlocked"
+	SecurityEventPolicyBlocked     = "policyBlocked"
+	SecurityEventInspectionDenied  = "inspectionDenied"
+	SecurityEventSignatureMismatch = "signatureMismatch"
+	SecurityEventScopeDenied       = "scopeDenied"
+	SecurityEventPolicyDenied      = "policyDenied"
+)
+
+// securityLoggerOptions route security events to the log group of SIMPLE_CONTAINER_SECURITY_LOG_GROUP, in a stream
+// named as the lambda log stream of the instance
+func securityLoggerOptions(getenv func(string) string) []logger.Option {
+	group := getenv(securityLogGroupEnv)
+	if group == "" {
+		return nil
+	}
+	stream := getenv(lambdaLogStreamEnv)
+	if stream == "" {
+		stream = "security"
+	}
+	return []logger.Option{logger.WithSecuritySink(awsutil.CloudWatchSecuritySink(nil, group, stream))}
+}
+
+// securityFields describes request of a security event
+func securityFields(c HttpAdapter, fields map[string]any) map[string]any {
+	res := map[string]any{
+		"clientIP": c.RemoteIP(),
+		"method":   c.Request().Method,
+		"path":     c.Request().URL.Path,
+	}
+	for k, v := range fields {
+		res[k] = v
+	}
+	return res
+}
@@ -0,0 +1,46 @@
+package service_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicefake"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestSecurityEvents(t *testing.T) {
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		router.GET("/api/ping", func(c service.HttpAdapter) error {
+			c.JSON(http.StatusOK, service.M{})
+			return nil
+		})
+		return nil
+	})
+	log := servicefake.NewLogger()
+	h := servicetest.New(t, routes, service.WithLogger(log), service.WithApiKey("key"),
+		service.WithTrustedProxies("192.0.2.1"), service.WithIPFilter(nil, []string{"198.51.100.7"}))
+
+	assert.Equal(t, http.StatusForbidden, h.Invoke(http.MethodGet, "/api/ping", nil, map[string]string{
+		"X-Forwarded-For": "198.51.100.7",
+	}).StatusCode)
+	assert.Equal(t, http.StatusUnauthorized, h.Invoke(http.MethodGet, "/api/ping", nil, map[string]string{
+		"Authorization": "Bearer guess",
+	}).StatusCode)
+	assert.Equal(t, http.StatusOK, h.Invoke(http.MethodGet, "/api/ping", nil, map[string]string{
+		"Authorization": "Bearer key",
+	}).StatusCode)
+
+	events := log.EntriesWithLevel(logger.Security)
+	require.Len(t, events, 2)
+	assert.Equal(t, service.SecurityEventIPBlocked, events[0].Message)
+	assert.Equal(t, "198.51.100.7", events[0].Context["clientIP"])
+	assert.Equal(t, "denied", events[0].Context["reason"])
+	assert.Equal(t, service.SecurityEventAuthFailure, events[1].Message)
+	assert.Equal(t, "invalid API key", events[1].Context["reason"])
+	assert.Equal(t, "/api/ping", events[1].Context["path"])
+}
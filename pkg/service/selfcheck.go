@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+const statusRoute = "/api/status"
+
+// checkAuthConfig detects auth misconfigurations, problems are logged as warnings or returned as error
+// when strict auth is enabled
+func (s *service) checkAuthConfig(ctx context.Context) error {
+	routes := lo.Filter(s.Routes(), func(route RouteInfo, _ int) bool {
+		return !strings.HasPrefix(route.Path, "/api/swagger") && !strings.HasPrefix(route.Path, "/api/_")
+	})
+
+	var problems []string
+	for _, prefix := range s.skipAuthRoutes {
+		if prefix == "" || prefix == "/" {
+			problems = append(problems, "skip auth route \""+prefix+"\" exposes every route without authentication")
+			continue
+		}
+		if prefix == statusRoute {
+			continue
+		}
+		if !lo.SomeBy(routes, func(route RouteInfo) bool { return strings.HasPrefix(route.Path, prefix) }) {
+			problems = append(problems, "skip auth route \""+prefix+"\" does not match any registered route")
+		}
+	}
+	for _, prefix := range s.optionalAuthRoutes {
+		if !lo.SomeBy(routes, func(route RouteInfo) bool { return strings.HasPrefix(route.Path, prefix) }) {
+			problems = append(problems, "optional auth route \""+prefix+"\" does not match any registered route")
+		}
+	}
+	if s.apiKey == "" {
+		unprotected := lo.Filter(routes, func(route RouteInfo, _ int) bool {
+			return !s.isSkipAuthRoute(route.Path)
+		})
+		if len(unprotected) > 0 {
+			problems = append(problems, "API_KEY is not configured, routes are served without authentication: "+
+				strings.Join(lo.Map(unprotected, func(route RouteInfo, _ int) string { return route.Method + " " + route.Path }), ", "))
+		}
+	}
+
+	for _, problem := range problems {
+		s.logger.Warnf(ctx, "auth self-check: %s", problem)
+	}
+	if s.strictAuth && len(problems) > 0 {
+		return errors.Errorf("auth self-check failed: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
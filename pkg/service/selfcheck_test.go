@@ -0,0 +1,59 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+)
+
+func TestAuthSelfCheck(t *testing.T) {
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		router.GET("/api/items", func(c service.HttpAdapter) error {
+			c.JSON(http.StatusOK, nil)
+			return nil
+		})
+		return nil
+	})
+	tests := []struct {
+		name    string
+		opts    []service.Option
+		wantErr string
+	}{
+		{name: "valid config", opts: []service.Option{service.WithApiKey("secret"), service.WithSkipAuthRoutes("/api/items")}},
+		{name: "problems are only logged without strict auth", opts: []service.Option{service.WithSkipAuthRoutes("/")}},
+		{
+			name:    "skip route matches nothing",
+			opts:    []service.Option{service.WithStrictAuth(), service.WithApiKey("secret"), service.WithSkipAuthRoutes("/api/missing")},
+			wantErr: `auth self-check failed: skip auth route "/api/missing" does not match any registered route`,
+		},
+		{
+			name:    "skip every route",
+			opts:    []service.Option{service.WithStrictAuth(), service.WithApiKey("secret"), service.WithSkipAuthRoutes("/")},
+			wantErr: `auth self-check failed: skip auth route "/" exposes every route without authentication`,
+		},
+		{
+			name:    "api key is not configured",
+			opts:    []service.Option{service.WithStrictAuth()},
+			wantErr: "auth self-check failed: API_KEY is not configured, routes are served without authentication: GET /api/items",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]service.Option{
+				service.WithEnv(func(string) string { return "" }),
+				service.WithRoutingType("function-url"),
+				routes,
+			}, tt.opts...)
+			_, err := service.New(context.Background(), opts...)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+const (
+	defaultReadHeaderTimeout = 10 * time.Second
+	defaultIdleTimeout       = 120 * time.Second
+)
+
+// HTTPServerConfig configures http server used in local debug mode, zero values keep defaults
+type HTTPServerConfig struct {
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration // defaults to 10s
+	WriteTimeout      time.Duration // keep zero for streaming endpoints
+	IdleTimeout       time.Duration // defaults to 120s
+	MaxHeaderBytes    int
+	TLSCertFile       string
+	TLSKeyFile        string
+	H2C               bool // serve HTTP/2 without TLS, ignored when TLS is configured
+}
+
+func WithHTTPServerConfig(cfg HTTPServerConfig) Option {
+	return func(s *service) {
+		s.serverConfig = cfg
+	}
+}
+
+func (s *service) newHTTPServer(handler http.Handler) *http.Server {
+	cfg := s.serverConfig
+	if cfg.H2C && handler != nil && !s.serverTLSEnabled() {
+		handler = h2c.NewHandler(handler, &http2.Server{IdleTimeout: cfg.IdleTimeout})
+	}
+	return &http.Server{
+		Addr:              fmt.Sprintf("0.0.0.0:%s", lo.If(s.port != "", s.port).Else("8080")),
+		Handler:           handler,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: lo.If(cfg.ReadHeaderTimeout > 0, cfg.ReadHeaderTimeout).Else(defaultReadHeaderTimeout),
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       lo.If(cfg.IdleTimeout > 0, cfg.IdleTimeout).Else(defaultIdleTimeout),
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
+}
+
+func (s *service) serverTLSEnabled() bool {
+	return s.serverConfig.TLSCertFile != "" && s.serverConfig.TLSKeyFile != ""
+}
+
+func (s *service) listenAndServe() error {
+	if s.unixSocket != "" {
+		return s.serveUnixSocket()
+	}
+	if s.serverTLSEnabled() {
+		return s.server.ListenAndServeTLS(s.serverConfig.TLSCertFile, s.serverConfig.TLSKeyFile)
+	}
+	return s.server.ListenAndServe()
+}
+
+func (s *service) serveUnixSocket() error {
+	// socket file left by the previous process prevents listening
+	if err := os.Remove(s.unixSocket); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "failed to remove stale socket %s", s.unixSocket)
+	}
+	listener, err := net.Listen("unix", s.unixSocket)
+	if err != nil {
+		return errors.Wrapf(err, "failed to listen on %s", s.unixSocket)
+	}
+	if s.serverTLSEnabled() {
+		return s.server.ServeTLS(listener, s.serverConfig.TLSCertFile, s.serverConfig.TLSKeyFile)
+	}
+	return s.server.Serve(listener)
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+const (
+	serverModeEnv          = "SIMPLE_CONTAINER_SERVER_MODE"
+	defaultShutdownTimeout = 30 * time.Second
+	livenessRoute          = "/healthz"
+	readinessRoute         = "/readyz"
+)
+
+// WithServerMode runs service as a long-living http server (e.g. on ECS/Fargate) instead of lambda handler,
+// server shuts down gracefully on SIGTERM and exposes /healthz and /readyz endpoints, swagger is disabled
+// unless enabled explicitly with WithSwagger
+func WithServerMode() Option {
+	return func(s *service) {
+		s.serverMode = true
+	}
+}
+
+// WithShutdownTimeout sets time given to in-flight requests to complete on shutdown in server mode
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return func(s *service) {
+		s.shutdownTimeout = timeout
+	}
+}
+
+func WithSwagger(enabled bool) Option {
+	return func(s *service) {
+		s.swagger = &enabled
+	}
+}
+
+func (s *service) swaggerEnabled() bool {
+	if s.swagger != nil {
+		return *s.swagger
+	}
+	return !s.serverMode
+}
+
+type serverState struct {
+	shuttingDown atomic.Bool
+}
+
+func (s *service) registerHealthEndpoints() {
+	s.skipAuthRoutes = append(s.skipAuthRoutes, livenessRoute, readinessRoute)
+	s.httpRouter.GET(livenessRoute, func(c HttpAdapter) error {
+		c.JSON(http.StatusOK, Status{Status: "alive"})
+		return nil
+	})
+	s.httpRouter.GET(readinessRoute, func(c HttpAdapter) error {
+		if s.serverState.shuttingDown.Load() {
+			c.JSON(http.StatusServiceUnavailable, Status{Status: "shutting down"})
+			return nil
+		}
+		c.JSON(http.StatusOK, Status{Status: "ready"})
+		return nil
+	})
+}
+
+// serve runs http server until SIGTERM/SIGINT and then waits for in-flight requests to complete
+func (s *service) serve() error {
+	ctx, stop := signal.NotifyContext(s.ctx, syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		s.logger.Infof(ctx, "starting http server on %s", lo.If(s.unixSocket != "", "unix:"+s.unixSocket).Else(s.server.Addr))
+		errCh <- s.listenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	s.serverState.shuttingDown.Store(true)
+	s.logger.Infof(context.Background(), "shutting down http server...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+	if err := s.server.Shutdown(shutdownCtx); err != nil {
+		return errors.Wrapf(err, "failed to shutdown http server gracefully")
+	}
+	if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	s.logger.Infof(context.Background(), "http server stopped")
+	s.runShutdownHooks()
+	return nil
+}
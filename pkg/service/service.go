@@ -2,11 +2,13 @@ package service
 
 import (
 	"context"
-	"fmt"
 	"io"
+	"io/fs"
+	"net"
 	"net/http"
-	"os"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -15,16 +17,15 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/pkg/errors"
 	"github.com/samber/lo"
-	echoSwagger "github.com/swaggo/echo-swagger"
-	swaggerfiles "github.com/swaggo/files"
-	ginSwagger "github.com/swaggo/gin-swagger"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
 	ginadapter "github.com/awslabs/aws-lambda-go-api-proxy/gin"
+	"github.com/awslabs/aws-lambda-go-api-proxy/httpadapter"
 
 	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/awsutil"
 	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/util"
 )
 
 const (
@@ -38,6 +39,9 @@ const (
 
 type Service interface {
 	Start() error
+	// Stop cancels context of the service and of in-flight requests, in server mode Start returns once
+	// the server is shut down, otherwise shutdown hooks are run by Stop
+	Stop()
 	Logger() logger.Logger
 	IsLocalDebugMode() bool
 	IsRequestDebugEnabled() bool
@@ -45,13 +49,31 @@ type Service interface {
 	Version() string
 	GetMeta(ctx context.Context) ResultMeta
 	GinAdapter() *ginadapter.GinLambda
+	// GinEngine and EchoEngine return engine of the default route tree, nil when the engine is not in use,
+	// e.g. to attach engine specific binders, validators or middlewares
+	GinEngine() *gin.Engine
+	EchoEngine() *echo.Echo
+	Handler() http.Handler
+	// LambdaHandler returns handler of lambda events, nil when the service does not run in lambda
+	LambdaHandler() lambda.Handler
+	Routes() []RouteInfo
+	InitStats() InitStats
+	RunMigrations(ctx context.Context, fsys fs.FS) error
+	Mode() Mode
+	// Group returns group of concurrent tasks bound to ctx and to the service lifecycle
+	Group(ctx context.Context) *Group
 }
 
 type service struct {
 	ctx                           context.Context
 	apiKey                        string
+	urlSigningKey                 string
 	cancels                       []func()
+	stopOnce                      sync.Once
+	shutdownOnce                  sync.Once
 	lambdaAdapter                 *ginadapter.GinLambda
+	ginEngine                     *gin.Engine
+	echoEngine                    *echo.Echo
 	server                        *http.Server
 	localDebugMode                bool
 	requestDebugMode              bool
@@ -59,43 +81,126 @@ type service struct {
 	port                          string
 	registerRoutesCallback        RegisterRoutesCallback
 	skipAuthRoutes                []string
+	optionalAuthRoutes            []string
+	apiKeyScopes                  []string
+	policyEngine                  PolicyEngine
+	tenantResolver                TenantResolver
 	version                       string
 	routingType                   string
 	registerStatusEndpoint        *bool
 	httpRouter                    HttpAdapterRouter
 	lambdaStartFunc               any
+	lambdaOptions                 []lambda.Option
 	lambdaSize                    float64
 	lambdaCostPerMbPerMillisecond float64
 	useResponseStreaming          bool
+	handlerAdapter                *httpadapter.HandlerAdapter
+	handlerMiddlewares            []HandlerMiddleware
+	recorders                     []*requestRecorder
+	shadows                       []*shadowTraffic
+	requestRewriters              []RequestRewriter
+	responseHeaders               []routeHeaders
+	routeNormalization            *routeNormalization
+	notFoundHandler               HttpAdapterHandler
+	methodNotAllowedHandler       HttpAdapterHandler
+	getenv                        func(string) string
+	handler                       http.Handler
+	routes                        *routeRegistry
+	strictAuth                    bool
+	memoryStats                   bool
+	memoryWarnedAt                atomic.Int64
+	init                          *initTimer
+	firstRequest                  atomic.Bool
+	timeoutWatchdogThreshold      time.Duration
+	timeoutWatchdogCallback       TimeoutWarningCallback
+	serverConfig                  HTTPServerConfig
+	serverMode                    bool
+	serverState                   serverState
+	shutdownTimeout               time.Duration
+	swagger                       *bool
+	unixSocket                    string
+	lambdaWebAdapter              bool
+	publicRoutes                  []string
+	losslessEvents                bool
+	basePath                      string
+	hostRouters                   map[string]RegisterRoutesCallback
+	hostRoutes                    []*hostRoute
+	trustProxyHeaders             bool
+	trustedProxies                []string
+	trustedProxyNets              []*net.IPNet
+	maxBodySize                   int64
+	uploadInspectors              []UploadInspector
+	observatory                   *observatoryConfig
+	secretsProvider               awsutil.SecretsProvider
+	requiredAuth                  bool
+	pprof                         bool
+	buildInfo                     bool
+	featureFlags                  FeatureFlags
+	clock                         util.Clock
+	build                         *BuildInfo
+	apiKeyErr                     error
+	problemDetails                bool
+	messageFS                     fs.FS
+	messages                      *messageBundle
+	usage                         *usageTracker
+	budget                        *costBudget
+	shutdownHooks                 []ShutdownHook
+	migrationsFS                  fs.FS
+	migrationConfig               MigrationConfig
+	mode                          Mode
+	environment                   Environment
+	costReport                    bool
+	middlewareChainEdits          []MiddlewareChainEdit
+	middlewareChain               MiddlewareChain
+	ipFilter                      *ipFilter
+	requestPolicy                 *RequestPolicy
+	requestInspectors             []RequestInspector
+	authLockout                   *AuthLockoutConfig
+	responseOffload               *ResponseOffloadConfig
 }
 
 func New(ctx context.Context, opts ...Option) (Service, error) {
-	log := logger.NewLogger()
+	timer := newInitTimer()
+	probe := probeOf(opts)
+	getenv := probe.getenv
+	profile, profileErr := resolveEnvironmentProfile(probe.environment, getenv)
+	log := logger.NewLogger(append(profile.loggerOptions(), securityLoggerOptions(getenv)...)...)
 
 	// stdout and stderr are sent to AWS CloudWatch Logs
 	log.Infof(ctx, "Server cold start")
+	if profileErr != nil {
+		return nil, errors.Wrapf(profileErr, "invalid service configuration")
+	}
 
-	if apiKey, err := awsutil.GetEnvOrSecret("API_KEY"); err != nil {
-		log.Warnf(ctx, "Failed to get API_KEY secret: %v", err)
+	apiKey, _, apiKeyErr := probe.secretsProvider.Secret(ctx, "API_KEY")
+	if apiKeyErr != nil {
+		log.Warnf(ctx, "Failed to get API_KEY secret: %v", apiKeyErr)
 	} else {
 		opts = append([]Option{WithApiKey(apiKey)}, opts...)
 	}
 
-	opts = append([]Option{WithVersion(os.Getenv(serviceVersionEnv))}, opts...)
-	opts = append([]Option{WithRoutingType(os.Getenv(lambdaRoutingTypeEnv))}, opts...)
+	opts = append([]Option{WithVersion(getenv(serviceVersionEnv))}, opts...)
+	opts = append([]Option{WithRoutingType(getenv(lambdaRoutingTypeEnv))}, opts...)
 
-	if os.Getenv("REQUEST_DEBUG") != "" {
+	if getenv("REQUEST_DEBUG") != "" {
 		opts = append([]Option{WithRequestDebugMode()}, opts...)
 	}
 
-	if os.Getenv("LOCAL_DEBUG") == "true" {
+	if isLambdaWebAdapterEnv(getenv) {
+		opts = append([]Option{WithLambdaWebAdapter()}, opts...)
+	}
+	if getenv(serverModeEnv) == "true" {
+		opts = append([]Option{WithServerMode()}, opts...)
+	}
+
+	if getenv("LOCAL_DEBUG") == "true" {
 		opts = append([]Option{WithLocalDebugMode()}, opts...)
 	}
-	if os.Getenv("PORT") != "" {
-		opts = append([]Option{WithPort(os.Getenv("PORT"))}, opts...)
+	if getenv("PORT") != "" {
+		opts = append([]Option{WithPort(getenv("PORT"))}, opts...)
 	}
-	if os.Getenv(lambdaSizeMbEnv) != "" {
-		sizeFloat, err := strconv.ParseFloat(os.Getenv(lambdaSizeMbEnv), 64)
+	if getenv(lambdaSizeMbEnv) != "" {
+		sizeFloat, err := strconv.ParseFloat(getenv(lambdaSizeMbEnv), 64)
 		if err == nil {
 			opts = append([]Option{WithLambdaSize(sizeFloat)}, opts...)
 		} else {
@@ -104,24 +209,75 @@ func New(ctx context.Context, opts ...Option) (Service, error) {
 		}
 	}
 	opts = append([]Option{WithLambdaCostPerMbPerMs(lambdaCostPerMbMs)}, opts...)
-	invokeMode := os.Getenv("SIMPLE_CONTAINER_AWS_LAMBDA_INVOKE_MODE")
+	invokeMode := getenv("SIMPLE_CONTAINER_AWS_LAMBDA_INVOKE_MODE")
 	if invokeMode != "" {
 		if invokeMode == "RESPONSE_STREAM" {
 			opts = append([]Option{UseResponseStreaming(true)}, opts...)
 		}
 	}
 
+	// environment defaults go first for env variables and explicit options to override them
+	opts = append(profile.options(), opts...)
+
 	gin.SetMode(gin.ReleaseMode)
 	gin.DefaultWriter = io.Discard
 
 	s := &service{
-		ctx: ctx,
+		ctx:             ctx,
+		routes:          &routeRegistry{},
+		init:            timer,
+		shutdownTimeout: defaultShutdownTimeout,
+		getenv:          getenv,
+		secretsProvider: probe.secretsProvider,
 	}
 
 	s.logger = log
 	for _, opt := range opts {
 		opt(s)
 	}
+	timer.stats.Options = timer.phase()
+	if s.featureFlags == nil {
+		s.featureFlags = EnvFeatureFlags(s.getenv)
+	}
+	s.clock = util.ClockOrSystem(s.clock)
+
+	if err := s.validateStreaming(); err != nil {
+		return nil, errors.Wrapf(err, "invalid service configuration")
+	}
+	if err := s.checkAuth(apiKeyErr); err != nil {
+		return nil, errors.Wrapf(err, "invalid service configuration")
+	}
+	trustedProxyNets, err := parseTrustedProxies(s.trustedProxies)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid service configuration")
+	}
+	s.trustedProxyNets = trustedProxyNets
+	if err := s.initIPFilter(); err != nil {
+		return nil, errors.Wrapf(err, "invalid service configuration")
+	}
+	if err := s.initObservatory(); err != nil {
+		return nil, err
+	}
+	s.mode = s.resolveMode(s.httpRouter != nil)
+	log.Infof(ctx, "service mode: %s", s.mode)
+
+	if err := s.initRecorders(); err != nil {
+		return nil, err
+	}
+	if err := s.initShadowTraffic(); err != nil {
+		return nil, errors.Wrapf(err, "invalid service configuration")
+	}
+	if err := s.initResponseOffload(); err != nil {
+		return nil, errors.Wrapf(err, "invalid service configuration")
+	}
+
+	if s.messageFS != nil {
+		bundle, err := loadMessageBundle(s.messageFS)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load message bundle")
+		}
+		s.messages = bundle
+	}
 
 	var router http.Handler
 	if s.httpRouter == nil && s.useResponseStreaming {
@@ -131,54 +287,110 @@ func New(ctx context.Context, opts ...Option) (Service, error) {
 			return nil, errors.Wrapf(err, "failed to init echo router")
 		}
 		router = echoRouter
+		s.echoEngine = echoRouter
 		s.httpRouter = EchoRouter(echoRouter, s.logger, s.localDebugMode)
-		s.lambdaStartFunc = s.newEchoLambdaStartFunc(echoRouter)
-		echoRouter.GET("/api/swagger/*", echoSwagger.WrapHandler)
 	} else if s.httpRouter == nil {
 		log.Infof(ctx, "setting up gin router")
-		ginRouter := gin.New()
+		ginRouter := s.newGinEngine()
 		s.httpRouter = GinRouter(ginRouter, s.logger, s.localDebugMode)
-		ginRouter.Use(gin.Recovery())
 		s.lambdaAdapter = ginadapter.New(ginRouter)
 		router = ginRouter
+		s.ginEngine = ginRouter
 		switch s.routingType {
 		case lambdaRoutingTypeFunctionUrl:
 			s.lambdaStartFunc = s.ProxyLambdaFunctionURL
 		case lambdaRoutingTypeApiGw:
 			s.lambdaStartFunc = s.ProxyLambdaApiGateway
 		default:
-			return nil, errors.Errorf("Unknown routing type: %q \n", s.routingType)
-		}
-		ginRouter.Use(func(c *gin.Context) {
-			if c.Request.RequestURI == "/api/swagger" || c.Request.RequestURI == "/api/swagger/" {
-				c.Request.RequestURI = "/api/swagger/index.html"
+			// routing type is irrelevant when not running in lambda
+			if !s.serverMode {
+				return nil, errors.Errorf("Unknown routing type: %q \n", s.routingType)
 			}
-		})
-		ginRouter.GET("/api/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
+		}
 	}
 
-	s.server = &http.Server{
-		Addr:    fmt.Sprintf("0.0.0.0:%s", lo.If(s.port != "", s.port).Else("8080")),
-		Handler: router,
+	if len(s.hostRouters) > 0 {
+		if router == nil {
+			return nil, errors.Errorf("host router is not supported with custom http adapter router")
+		}
+		s.initHostRoutes()
+		router = s.hostDispatchHandler(router)
+	}
+
+	if router != nil {
+		// all code paths (local server, buffered and streaming lambda) serve requests via the same handler chain
+		router = s.wrapHandler(chainHandler(router, s.handlerChain()))
+		s.handler = router
+		// GinLambda can only proxy events to *gin.Engine, so buffered lambda events are proxied to the
+		// handler chain instead for handler middlewares to apply to lambda requests as well
+		s.handlerAdapter = httpadapter.New(router)
+		if s.useResponseStreaming && s.lambdaStartFunc == nil {
+			s.lambdaStartFunc = s.newStreamingLambdaStartFunc(router)
+		}
 	}
 
-	s.skipAuthRoutes = append(s.skipAuthRoutes, "/api/status")
+	s.server = s.newHTTPServer(router)
+
+	s.skipAuthRoutes = append(s.skipAuthRoutes, statusRoute)
+	s.httpRouter = newIntrospectingRouter(s.httpRouter, s.routes, s.logger, s.featureFlags)
+	timer.stats.RouterBuild = timer.phase()
 
 	if s.registerRoutesCallback == nil {
 		return nil, errors.Errorf("register routes callback is not set")
 	}
-	s.httpRouter.Use(s.requestUIDMiddleware())
-	s.httpRouter.Use(s.debugLogMiddleware())
-	if s.apiKey != "" {
-		s.httpRouter.Use(s.apiKeyAuthMiddleware())
+	middlewareChain, err := s.buildMiddlewareChain()
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid middleware chain")
+	}
+	s.middlewareChain = middlewareChain
+	s.useMiddlewares(s.httpRouter)
+	if s.serverMode {
+		s.registerHealthEndpoints()
+	}
+	if s.buildInfo {
+		s.build = s.readBuildInfo()
 	}
 	if s.registerStatusEndpoint == nil || lo.FromPtr(s.registerStatusEndpoint) {
-		s.httpRouter.GET("/api/status", s.statusEndpoint)
+		s.httpRouter.GET(statusRoute, s.statusEndpoint)
+	}
+	if s.usage != nil {
+		if s.apiKey != "" {
+			s.httpRouter.GET(usageRoute, s.usageEndpoint)
+		} else {
+			s.logger.Warnf(ctx, "usage endpoint is not registered as API_KEY is not configured")
+		}
+	}
+	if s.pprof {
+		if s.apiKey != "" || s.localDebugMode {
+			s.registerPprofEndpoints()
+		} else {
+			s.logger.Warnf(ctx, "pprof endpoints are not registered as API_KEY is not configured")
+		}
+	}
+	if s.localDebugMode {
+		s.httpRouter.POST(benchRoute, s.benchEndpoint)
+		s.httpRouter.GET("/api/_routes", s.routesEndpoint)
+	}
+	if s.swaggerEnabled() {
+		s.registerSwagger(s.ginEngine, s.echoEngine)
 	}
 
 	if err := s.registerRoutesCallback(s.httpRouter); err != nil {
 		return nil, errors.Wrapf(err, "failed to register routes")
 	}
+	if err := s.registerHostRoutes(); err != nil {
+		return nil, err
+	}
+	if s.lambdaWebAdapter {
+		s.registerWebAdapterReadiness()
+	}
+	timer.stats.RouteRegistration = timer.phase()
+	if s.localDebugMode || s.requestDebugMode {
+		s.logRoutes(ctx)
+	}
+	if err := s.checkAuthConfig(ctx); err != nil {
+		return nil, err
+	}
 
 	ctx, cancel := context.WithCancel(ctx)
 	s.cancels = append(s.cancels, cancel)
@@ -187,44 +399,64 @@ func New(ctx context.Context, opts ...Option) (Service, error) {
 	return s, nil
 }
 
-func (s *service) newEchoLambdaStartFunc(echoRouter *echo.Echo) func(context.Context, events.LambdaFunctionURLRequest) (*events.LambdaFunctionURLStreamingResponse, error) {
-	delegate := echohandler.NewFunctionURLStreamingHandler(echoadapter.NewEchoAdapter(echoRouter))
+// useMiddlewares installs middleware chain shared by all route trees of the service
+func (s *service) useMiddlewares(router HttpAdapterRouter) {
+	for _, mw := range s.middlewareChain {
+		if mw.Handler != nil {
+			router.Use(mw.Handler)
+		}
+	}
+}
+
+func (s *service) newStreamingLambdaStartFunc(handler http.Handler) func(context.Context, events.LambdaFunctionURLRequest) (*events.LambdaFunctionURLStreamingResponse, error) {
+	delegate := echohandler.NewFunctionURLStreamingHandler(echoadapter.NewVanillaAdapter(handler))
 	return func(ctx context.Context, request events.LambdaFunctionURLRequest) (*events.LambdaFunctionURLStreamingResponse, error) {
 		if s.requestDebugMode {
 			s.Logger().Infof(s.Logger().WithValue(ctx, "lambdaEvent", request), "got lambda event")
 		}
+		if s.losslessEvents {
+			ctx = awsutil.WithOriginalEvent(ctx, request)
+		}
+		ctx = s.withAuthorizerClaims(ctx, awsutil.ToAuthorizerMap(request.RequestContext.Authorizer))
+		ctx = withSourceIP(ctx, request.RequestContext.HTTP.SourceIP)
 		return delegate(ctx, request)
 	}
 }
 
 func (s *service) initEchoAdapter() (*echo.Echo, error) {
 	e := echo.New()
+	s.handleEchoNoRoute(e)
 	return e, nil
 }
 
 func (s *service) GetMeta(ctx context.Context) ResultMeta {
-	requestStartedAt := s.logger.GetValue(ctx, RequestStartedKey).(time.Time)
-	requestFinishedAt := time.Now()
-	requestTime := time.Since(requestStartedAt)
-	cost := s.lambdaSize * float64(requestTime.Milliseconds()) * s.lambdaCostPerMbPerMillisecond
+	requestStartedAt, _ := RequestStartedAt(ctx)
+	requestUID, _ := RequestUID(ctx)
+	requestFinishedAt := s.clock.Now()
+	requestTime := s.clock.Since(requestStartedAt)
+	cost := s.costOf(requestTime)
+	initStats := s.initStatsOf(ctx)
 	return ResultMeta{
-		RequestUID:        s.logger.GetValue(ctx, RequestUIDKey).(string),
+		RequestUID:        requestUID,
 		RequestStartedAt:  requestStartedAt,
 		RequestTime:       requestTime,
 		RequestFinishedAt: requestFinishedAt,
 		Cost:              cost,
+		Memory:            s.memoryStatsOf(ctx),
+		ColdStart:         initStats != nil,
+		Init:              initStats,
 	}
 }
 
 func (s *service) Start() error {
-	if s.localDebugMode {
-		return s.server.ListenAndServe()
-	} else {
-		s.Logger().Infof(context.Background(), "starting lambda handler...")
-		lambda.Start(s.lambdaStartFunc)
-		s.Logger().Infof(context.Background(), "finished lambda handler...")
-		return nil
+	if s.migrationsFS != nil && s.isMigrateEnv() {
+		return s.migrate()
+	} else if s.serverMode {
+		return s.serve()
+	} else if s.localDebugMode {
+		return s.listenAndServe()
 	}
+	return s.startLambda()
 }
 
 func (s *service) Logger() logger.Logger {
@@ -251,21 +483,46 @@ func (s *service) GinAdapter() *ginadapter.GinLambda {
 	return s.lambdaAdapter
 }
 
+func (s *service) GinEngine() *gin.Engine {
+	return s.ginEngine
+}
+
+func (s *service) EchoEngine() *echo.Echo {
+	return s.echoEngine
+}
+
+// Handler returns http handler serving all registered routes regardless of the engine in use
+func (s *service) Handler() http.Handler {
+	return s.handler
+}
+
 func (s *service) ProxyLambdaApiGateway(ctx context.Context, request events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
-	if s.lambdaAdapter == nil {
+	if s.losslessEvents {
+		ctx = awsutil.WithOriginalEvent(ctx, request)
+	}
+	ctx = s.withAuthorizerClaims(ctx, request.RequestContext.Authorizer)
+	ctx = withSourceIP(ctx, request.RequestContext.Identity.SourceIP)
+	ctx = withStage(ctx, request)
+	if s.handlerAdapter == nil {
 		return events.APIGatewayProxyResponse{}, errors.Errorf("lambda adapter is not configure, are you using gin adapter?")
 	}
-	return s.lambdaAdapter.ProxyWithContext(ctx, request)
+	res, err := s.handlerAdapter.ProxyWithContext(ctx, request)
+	return awsutil.EncodeBinaryBody(res), err
 }
 
 func (s *service) ProxyLambdaFunctionURL(ctx context.Context, request events.LambdaFunctionURLRequest) (any, error) {
+	if s.losslessEvents {
+		ctx = awsutil.WithOriginalEvent(ctx, request)
+	}
 	apiGwReq := awsutil.ToAPIGatewayRequest(request)
-	if s.lambdaAdapter == nil {
+	ctx = s.withAuthorizerClaims(ctx, apiGwReq.RequestContext.Authorizer)
+	ctx = withSourceIP(ctx, request.RequestContext.HTTP.SourceIP)
+	if s.handlerAdapter == nil {
 		return events.APIGatewayProxyResponse{}, errors.Errorf("lambda adapter is not configure, are you using gin adapter?")
 	}
-	res, err := s.lambdaAdapter.ProxyWithContext(ctx, apiGwReq)
+	res, err := s.handlerAdapter.ProxyWithContext(ctx, apiGwReq)
 	if err != nil {
 		return events.LambdaFunctionURLResponse{}, errors.Wrapf(err, "failed to process request")
 	}
-	return awsutil.ToLambdaFunctionURLResponse(res), nil
+	return awsutil.ToLambdaFunctionURLResponse(awsutil.EncodeBinaryBody(res)), nil
 }
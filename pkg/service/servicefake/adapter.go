@@ -0,0 +1,190 @@
+package servicefake
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+)
+
+// HttpAdapter serves provided request and records response in Recorder
+type HttpAdapter struct {
+	Recorder *httptest.ResponseRecorder
+	Params   map[string]string
+	Aborted  bool
+
+	request *http.Request
+}
+
+var _ service.HttpAdapter = &HttpAdapter{}
+
+func NewHttpAdapter(request *http.Request) *HttpAdapter {
+	return &HttpAdapter{
+		Recorder: httptest.NewRecorder(),
+		Params:   map[string]string{},
+		request:  request,
+	}
+}
+
+func (h *HttpAdapter) Context() context.Context {
+	return h.request.Context()
+}
+
+func (h *HttpAdapter) SetContext(ctx context.Context) {
+	h.request = h.request.WithContext(ctx)
+}
+
+func (h *HttpAdapter) SetHeader(name, value string) {
+	h.Recorder.Header().Set(name, value)
+}
+
+func (h *HttpAdapter) Writer() service.HttpWriterFlusher {
+	return &recorderWriter{ResponseRecorder: h.Recorder}
+}
+
+func (h *HttpAdapter) JSON(code int, obj any) {
+	h.Recorder.Header().Set("Content-Type", "application/json; charset=utf-8")
+	h.Recorder.WriteHeader(code)
+	_ = json.NewEncoder(h.Recorder).Encode(obj)
+}
+
+func (h *HttpAdapter) Proto(code int, message proto.Message) {
+	contentType, data, err := service.EncodeProto(h.request.Header.Get("Accept"), message)
+	if err != nil {
+		h.Recorder.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	h.Recorder.Header().Set("Content-Type", contentType)
+	h.Recorder.WriteHeader(code)
+	_, _ = h.Recorder.Write(data)
+}
+
+func (h *HttpAdapter) File(path, name string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open file %s", path)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return errors.Wrapf(err, "failed to stat file %s", path)
+	}
+	service.ServeFile(h.Recorder, h.request, file, lo.If(name != "", name).Else(filepath.Base(path)), info.ModTime())
+	return nil
+}
+
+func (h *HttpAdapter) FileContent(content io.ReadSeeker, name string, modTime time.Time) {
+	service.ServeFile(h.Recorder, h.request, content, name, modTime)
+}
+
+func (h *HttpAdapter) RequestBody() io.Reader {
+	return h.request.Body
+}
+
+func (h *HttpAdapter) Request() *http.Request {
+	return h.request
+}
+
+func (h *HttpAdapter) AbortWithStatus(status int) {
+	h.Aborted = true
+	h.Recorder.WriteHeader(status)
+}
+
+func (h *HttpAdapter) IsAborted() bool {
+	return h.Aborted
+}
+
+func (h *HttpAdapter) RemoteIP() string {
+	if ip := service.ClientIP(h.Context()); ip != "" {
+		return ip
+	}
+	ip, _, err := net.SplitHostPort(h.request.RemoteAddr)
+	if err != nil {
+		return ""
+	}
+	return ip
+}
+
+// ClientGone is closed when the request context is done
+func (h *HttpAdapter) ClientGone() <-chan struct{} {
+	return h.Context().Done()
+}
+
+func (h *HttpAdapter) Query(name string) string {
+	return h.request.URL.Query().Get(name)
+}
+
+func (h *HttpAdapter) QueryArray(name string) []string {
+	return h.request.URL.Query()[name]
+}
+
+func (h *HttpAdapter) Header(name string) string {
+	return h.request.Header.Get(name)
+}
+
+func (h *HttpAdapter) Headers() http.Header {
+	return h.request.Header
+}
+
+func (h *HttpAdapter) Param(name string) string {
+	return h.Params[name]
+}
+
+func (h *HttpAdapter) FormFile(name string) (*multipart.FileHeader, error) {
+	_, header, err := h.request.FormFile(name)
+	return header, err
+}
+
+func (h *HttpAdapter) MultipartForm() (*multipart.Form, error) {
+	if err := h.request.ParseMultipartForm(32 << 20); err != nil {
+		return nil, err
+	}
+	return h.request.MultipartForm, nil
+}
+
+func (h *HttpAdapter) MultipartStream(callback func(part *multipart.Part) error) error {
+	reader, err := h.request.MultipartReader()
+	if err != nil {
+		return err
+	}
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		err = callback(part)
+		_ = part.Close()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (h *HttpAdapter) Redirect(code int, location string) error {
+	http.Redirect(h.Recorder, h.request, location, code)
+	return nil
+}
+
+type recorderWriter struct {
+	*httptest.ResponseRecorder
+}
+
+func (r *recorderWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, errors.Errorf("ResponseRecorder does not implement http.Hijacker")
+}
@@ -0,0 +1,89 @@
+package servicefake
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/samber/lo"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+)
+
+type LogEntry struct {
+	Level   string
+	Message string
+	Context logger.ContextValue
+}
+
+// Logger keeps all messages in memory, context values are handled by the real logger
+type Logger struct {
+	logger.Logger
+
+	mu      sync.Mutex
+	entries []LogEntry
+}
+
+var _ logger.Logger = &Logger{}
+
+func NewLogger() *Logger {
+	return &Logger{
+		Logger: logger.NewLogger(),
+	}
+}
+
+func (l *Logger) Infof(ctx context.Context, format string, args ...any) {
+	l.add(ctx, logger.Info, format, args)
+}
+
+func (l *Logger) Errorf(ctx context.Context, format string, args ...any) {
+	l.add(ctx, logger.Error, format, args)
+}
+
+func (l *Logger) Warnf(ctx context.Context, format string, args ...any) {
+	l.add(ctx, logger.Warn, format, args)
+}
+
+func (l *Logger) Security(ctx context.Context, event string, fields map[string]any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, LogEntry{
+		Level:   logger.Security,
+		Message: event,
+		Context: lo.Assign(logger.GetValues(ctx), fields),
+	})
+}
+
+func (l *Logger) add(ctx context.Context, level, format string, args []any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, LogEntry{
+		Level:   level,
+		Message: fmt.Sprintf(format, args...),
+		Context: logger.GetValues(ctx),
+	})
+}
+
+// Entries returns copy of all logged messages
+func (l *Logger) Entries() []LogEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]LogEntry{}, l.entries...)
+}
+
+// EntriesWithLevel returns logged messages of the provided level
+func (l *Logger) EntriesWithLevel(level string) []LogEntry {
+	var res []LogEntry
+	for _, entry := range l.Entries() {
+		if entry.Level == level {
+			res = append(res, entry)
+		}
+	}
+	return res
+}
+
+func (l *Logger) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = nil
+}
@@ -0,0 +1,117 @@
+package servicefake
+
+import (
+	"net/http"
+	"path"
+	"sync"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+)
+
+type Route struct {
+	Method  string
+	Path    string
+	Handler service.HttpAdapterHandler
+}
+
+// HttpAdapterRouter records registered routes and middlewares, groups share the records of the root router
+type HttpAdapterRouter struct {
+	prefix string
+	state  *routerState
+}
+
+type routerState struct {
+	mu          sync.Mutex
+	routes      []Route
+	middlewares []service.HttpAdapterHandler
+}
+
+var _ service.HttpAdapterRouter = &HttpAdapterRouter{}
+
+func NewHttpAdapterRouter() *HttpAdapterRouter {
+	return &HttpAdapterRouter{
+		state: &routerState{},
+	}
+}
+
+func (r *HttpAdapterRouter) Routes() []Route {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+	return append([]Route{}, r.state.routes...)
+}
+
+func (r *HttpAdapterRouter) Middlewares() []service.HttpAdapterHandler {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+	return append([]service.HttpAdapterHandler{}, r.state.middlewares...)
+}
+
+// Route returns handler registered for exact method and path
+func (r *HttpAdapterRouter) Route(method, p string) (service.HttpAdapterHandler, bool) {
+	for _, route := range r.Routes() {
+		if (route.Method == method || route.Method == "ANY") && route.Path == p {
+			return route.Handler, true
+		}
+	}
+	return nil, false
+}
+
+func (r *HttpAdapterRouter) Use(mw service.HttpAdapterHandler) {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+	r.state.middlewares = append(r.state.middlewares, mw)
+}
+
+func (r *HttpAdapterRouter) add(method, p string, h service.HttpAdapterHandler) {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+	r.state.routes = append(r.state.routes, Route{
+		Method:  method,
+		Path:    path.Join("/", r.prefix, p),
+		Handler: h,
+	})
+}
+
+func (r *HttpAdapterRouter) Any(p string, h service.HttpAdapterHandler) {
+	r.add("ANY", p, h)
+}
+
+func (r *HttpAdapterRouter) GET(p string, h service.HttpAdapterHandler) {
+	r.add(http.MethodGet, p, h)
+}
+
+func (r *HttpAdapterRouter) POST(p string, h service.HttpAdapterHandler) {
+	r.add(http.MethodPost, p, h)
+}
+
+func (r *HttpAdapterRouter) DELETE(p string, h service.HttpAdapterHandler) {
+	r.add(http.MethodDelete, p, h)
+}
+
+func (r *HttpAdapterRouter) PATCH(p string, h service.HttpAdapterHandler) {
+	r.add(http.MethodPatch, p, h)
+}
+
+func (r *HttpAdapterRouter) PUT(p string, h service.HttpAdapterHandler) {
+	r.add(http.MethodPut, p, h)
+}
+
+func (r *HttpAdapterRouter) OPTIONS(p string, h service.HttpAdapterHandler) {
+	r.add(http.MethodOptions, p, h)
+}
+
+func (r *HttpAdapterRouter) HEAD(p string, h service.HttpAdapterHandler) {
+	r.add(http.MethodHead, p, h)
+}
+
+func (r *HttpAdapterRouter) Group(name string) service.HttpAdapterRouter {
+	return &HttpAdapterRouter{
+		prefix: path.Join(r.prefix, name),
+		state:  r.state,
+	}
+}
+
+// Versioned registers routes under /{version} prefix, deprecation options are ignored
+func (r *HttpAdapterRouter) Versioned(version string, callback service.RegisterRoutesCallback, _ ...service.VersionOption) error {
+	return callback(r.Group("/" + version))
+}
@@ -0,0 +1,151 @@
+package servicefake
+
+import (
+	"context"
+	"io/fs"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/labstack/echo/v4"
+	"github.com/samber/lo"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	ginadapter "github.com/awslabs/aws-lambda-go-api-proxy/gin"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+)
+
+// Service is a configurable in-memory implementation of service.Service
+type Service struct {
+	FakeLogger       *Logger
+	Router           *HttpAdapterRouter
+	LocalDebugMode   bool
+	RequestDebugMode bool
+	FakePort         string
+	FakeVersion      string
+	Meta             *service.ResultMeta // returned from GetMeta when set
+	Started          bool
+	Stopped          bool
+	StartErr         error
+	FakeHandler      http.Handler
+	FakeLambda       lambda.Handler
+	FakeGinLambda    *ginadapter.GinLambda
+	FakeGinEngine    *gin.Engine
+	FakeEchoEngine   *echo.Echo
+	FakeInitStats    service.InitStats
+	Migrations       []fs.FS // migrations passed to RunMigrations
+	MigrateErr       error
+	FakeMode         service.Mode
+}
+
+var _ service.Service = &Service{}
+
+func NewService() *Service {
+	return &Service{
+		FakeLogger:  NewLogger(),
+		Router:      NewHttpAdapterRouter(),
+		FakePort:    "8080",
+		FakeHandler: http.NotFoundHandler(),
+	}
+}
+
+// RegisterRoutes invokes callback against the fake router so that registered routes can be inspected
+func (s *Service) RegisterRoutes(callback service.RegisterRoutesCallback) error {
+	return callback(s.Router)
+}
+
+func (s *Service) Start() error {
+	s.Started = true
+	return s.StartErr
+}
+
+func (s *Service) Stop() {
+	s.Stopped = true
+}
+
+func (s *Service) Logger() logger.Logger {
+	return s.FakeLogger
+}
+
+func (s *Service) IsLocalDebugMode() bool {
+	return s.LocalDebugMode
+}
+
+func (s *Service) IsRequestDebugEnabled() bool {
+	return s.RequestDebugMode
+}
+
+func (s *Service) Port() string {
+	return s.FakePort
+}
+
+func (s *Service) Version() string {
+	return s.FakeVersion
+}
+
+// GetMeta returns Meta when set, otherwise builds meta from values stored in context
+func (s *Service) GetMeta(ctx context.Context) service.ResultMeta {
+	if s.Meta != nil {
+		return *s.Meta
+	}
+	res := service.ResultMeta{
+		RequestFinishedAt: time.Now(),
+	}
+	if requestUID, ok := service.RequestUID(ctx); ok {
+		res.RequestUID = requestUID
+	}
+	if startedAt, ok := service.RequestStartedAt(ctx); ok {
+		res.RequestStartedAt = startedAt
+		res.RequestTime = res.RequestFinishedAt.Sub(startedAt)
+	}
+	return res
+}
+
+func (s *Service) GinAdapter() *ginadapter.GinLambda {
+	return s.FakeGinLambda
+}
+
+func (s *Service) GinEngine() *gin.Engine {
+	return s.FakeGinEngine
+}
+
+func (s *Service) EchoEngine() *echo.Echo {
+	return s.FakeEchoEngine
+}
+
+func (s *Service) Handler() http.Handler {
+	return s.FakeHandler
+}
+
+func (s *Service) LambdaHandler() lambda.Handler {
+	return s.FakeLambda
+}
+
+// Routes lists routes registered in the fake router, middleware names are not tracked
+func (s *Service) Routes() []service.RouteInfo {
+	return lo.Map(s.Router.Routes(), func(route Route, _ int) service.RouteInfo {
+		return service.RouteInfo{
+			Method: route.Method,
+			Path:   route.Path,
+		}
+	})
+}
+
+func (s *Service) InitStats() service.InitStats {
+	return s.FakeInitStats
+}
+
+func (s *Service) RunMigrations(_ context.Context, fsys fs.FS) error {
+	s.Migrations = append(s.Migrations, fsys)
+	return s.MigrateErr
+}
+
+func (s *Service) Mode() service.Mode {
+	return s.FakeMode
+}
+
+func (s *Service) Group(ctx context.Context) *service.Group {
+	return service.NewGroup(ctx, s.FakeLogger)
+}
@@ -0,0 +1,34 @@
+package servicefake
+
+import (
+	"context"
+	"sync"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/audit"
+)
+
+// Sink keeps audit records in memory, Err is returned from Write when set
+type Sink struct {
+	Err error
+
+	mu      sync.Mutex
+	records []audit.Record
+}
+
+var _ audit.Sink = &Sink{}
+
+func (s *Sink) Write(_ context.Context, record audit.Record) error {
+	if s.Err != nil {
+		return s.Err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, record)
+	return nil
+}
+
+func (s *Sink) Records() []audit.Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]audit.Record{}, s.records...)
+}
@@ -0,0 +1,212 @@
+package servicetest
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/awsutil/eventstest"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+)
+
+// Harness invokes routes of the service in-process without AWS or a listening server
+type Harness struct {
+	t       testing.TB
+	Service service.Service
+}
+
+type Response struct {
+	StatusCode int
+	Headers    http.Header
+	Body       []byte
+}
+
+// New builds the service with provided options, routing type defaults to function-url
+// so that options only need to provide routes. Process environment is ignored, variables
+// can be provided with service.WithEnv
+func New(t testing.TB, opts ...service.Option) *Harness {
+	t.Helper()
+	opts = append([]service.Option{
+		service.WithEnv(func(string) string { return "" }),
+		service.WithRoutingType("function-url"),
+	}, opts...)
+	svc, err := service.New(context.Background(), opts...)
+	require.NoError(t, err, "failed to init service")
+	require.NotNil(t, svc.Handler(), "service has no http handler, custom http adapter routers cannot be invoked")
+	return &Harness{
+		t:       t,
+		Service: svc,
+	}
+}
+
+// Invoke performs request against the service, body can be nil, string, []byte, io.Reader
+// or any other value which is then encoded as JSON
+func (h *Harness) Invoke(method, path string, body any, headers map[string]string) *Response {
+	h.t.Helper()
+	var reader io.Reader
+	switch b := body.(type) {
+	case nil:
+	case io.Reader:
+		reader = b
+	case []byte:
+		reader = bytes.NewReader(b)
+	case string:
+		reader = bytes.NewBufferString(b)
+	default:
+		data, err := json.Marshal(b)
+		require.NoError(h.t, err, "failed to marshal request body")
+		reader = bytes.NewReader(data)
+		if _, ok := headers["Content-Type"]; !ok {
+			headers = withHeader(headers, "Content-Type", "application/json")
+		}
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	rec := httptest.NewRecorder()
+	handler := h.Service.Handler()
+	if handler == nil {
+		h.t.Fatalf("service has no http handler, custom http adapter routers cannot be invoked")
+	}
+	handler.ServeHTTP(rec, req)
+
+	res := rec.Result()
+	defer res.Body.Close()
+	resBody, err := io.ReadAll(res.Body)
+	require.NoError(h.t, err, "failed to read response body")
+	return &Response{
+		StatusCode: res.StatusCode,
+		Headers:    res.Header,
+		Body:       resBody,
+	}
+}
+
+// InvokeLambda performs request against the lambda handler of the service the way the runtime does: the request
+// is sent as API Gateway or Function URL event payload depending on the routing type and the response payload
+// (including streamed one) is decoded back, body is passed the same way as to Invoke
+func (h *Harness) InvokeLambda(method, path string, body any, headers map[string]string) *Response {
+	h.t.Helper()
+	handler := h.Service.LambdaHandler()
+	require.NotNil(h.t, handler, "service has no lambda handler")
+
+	// binary and JSON bodies set content type along with the body
+	var opts []eventstest.RequestOption
+	contentType, hasContentType := headers["Content-Type"]
+	withContentType := func(defaultType string) string {
+		return lo.Ternary(hasContentType, contentType, defaultType)
+	}
+	bodyContentType := false
+	switch b := body.(type) {
+	case nil:
+	case string:
+		opts = append(opts, eventstest.WithBody(b))
+	case io.Reader:
+		data, err := io.ReadAll(b)
+		require.NoError(h.t, err, "failed to read request body")
+		opts, bodyContentType = append(opts, eventstest.WithBinaryBody(data, withContentType("application/octet-stream"))), true
+	case []byte:
+		opts, bodyContentType = append(opts, eventstest.WithBinaryBody(b, withContentType("application/octet-stream"))), true
+	default:
+		data, err := json.Marshal(b)
+		require.NoError(h.t, err, "failed to marshal request body")
+		opts = append(opts, eventstest.WithBody(string(data)), eventstest.WithHeader("Content-Type", withContentType("application/json")))
+		bodyContentType = true
+	}
+	for name, value := range headers {
+		if name != "Content-Type" || !bodyContentType {
+			opts = append(opts, eventstest.WithHeader(name, value))
+		}
+	}
+
+	var event any
+	if h.Service.Mode().RoutingType == "api-gateway" {
+		event = eventstest.APIGatewayProxyRequest(method, path, opts...)
+	} else {
+		event = eventstest.LambdaFunctionURLRequest(method, path, opts...)
+	}
+	payload, err := json.Marshal(event)
+	require.NoError(h.t, err, "failed to marshal lambda event")
+	out, err := handler.Invoke(context.Background(), payload)
+	require.NoError(h.t, err, "failed to invoke lambda handler")
+
+	if h.Service.Mode().ResponseStreaming {
+		return h.streamedResponse(out)
+	}
+	var res lambdaResponse
+	require.NoError(h.t, json.Unmarshal(out, &res), "failed to unmarshal lambda response")
+	resBody := []byte(res.Body)
+	if res.IsBase64Encoded {
+		resBody, err = base64.StdEncoding.DecodeString(res.Body)
+		require.NoError(h.t, err, "failed to decode lambda response body")
+	}
+	return &Response{
+		StatusCode: res.StatusCode,
+		Headers:    res.header(),
+		Body:       resBody,
+	}
+}
+
+// lambdaResponse covers API Gateway proxy and Function URL responses, and the prelude of streamed responses
+type lambdaResponse struct {
+	StatusCode        int                 `json:"statusCode"`
+	Headers           map[string]string   `json:"headers"`
+	MultiValueHeaders map[string][]string `json:"multiValueHeaders"`
+	Cookies           []string            `json:"cookies"`
+	Body              string              `json:"body"`
+	IsBase64Encoded   bool                `json:"isBase64Encoded"`
+}
+
+func (r *lambdaResponse) header() http.Header {
+	res := http.Header{}
+	for name, values := range r.MultiValueHeaders {
+		for _, value := range values {
+			res.Add(name, value)
+		}
+	}
+	for name, value := range r.Headers {
+		if res.Get(name) == "" {
+			res.Set(name, value)
+		}
+	}
+	for _, cookie := range r.Cookies {
+		res.Add("Set-Cookie", cookie)
+	}
+	return res
+}
+
+// streamedResponse splits streamed response into JSON prelude and body separated with 8 null bytes
+func (h *Harness) streamedResponse(out []byte) *Response {
+	prelude, body, found := bytes.Cut(out, make([]byte, 8))
+	require.True(h.t, found, "streamed response has no prelude")
+	var res lambdaResponse
+	require.NoError(h.t, json.Unmarshal(prelude, &res), "failed to unmarshal streamed response prelude")
+	return &Response{
+		StatusCode: res.StatusCode,
+		Headers:    res.header(),
+		Body:       body,
+	}
+}
+
+// JSON decodes response body into the provided value
+func (r *Response) JSON(v any) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+func withHeader(headers map[string]string, name, value string) map[string]string {
+	res := make(map[string]string, len(headers)+1)
+	for k, v := range headers {
+		res[k] = v
+	}
+	res[name] = value
+	return res
+}
@@ -0,0 +1,125 @@
+package servicetest
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+)
+
+type echoBody struct {
+	Message string `json:"message"`
+}
+
+func TestInvoke(t *testing.T) {
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		router.POST("/api/echo", func(c service.HttpAdapter) error {
+			var body echoBody
+			if err := json.Unmarshal(service.ReadBytes(c.RequestBody()), &body); err != nil {
+				return err
+			}
+			c.JSON(http.StatusOK, body)
+			return nil
+		})
+		return nil
+	})
+	tests := []struct {
+		name string
+		opts []service.Option
+	}{
+		{
+			name: "gin",
+			opts: []service.Option{routes},
+		},
+		{
+			name: "echo streaming",
+			opts: []service.Option{routes, service.UseResponseStreaming(true)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := New(t, tt.opts...)
+
+			res := h.Invoke(http.MethodGet, "/api/status", nil, nil)
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+
+			res = h.Invoke(http.MethodPost, "/api/echo", echoBody{Message: "hello"}, nil)
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+			var body echoBody
+			assert.NoError(t, res.JSON(&body))
+			assert.Equal(t, "hello", body.Message)
+
+			res = h.Invoke(http.MethodGet, "/api/unknown", nil, nil)
+			assert.Equal(t, http.StatusNotFound, res.StatusCode)
+		})
+	}
+}
+
+func TestProcessEnvIsIgnored(t *testing.T) {
+	t.Setenv("API_KEY", "secret")
+	t.Setenv("SIMPLE_CONTAINER_AWS_LAMBDA_ROUTING_TYPE", "unknown")
+	h := New(t, service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		router.GET("/api/ping", func(c service.HttpAdapter) error {
+			c.JSON(http.StatusOK, echoBody{Message: "pong"})
+			return nil
+		})
+		return nil
+	}))
+
+	res := h.Invoke(http.MethodGet, "/api/ping", nil, nil)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestInvokeLambda(t *testing.T) {
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		router.POST("/api/echo", func(c service.HttpAdapter) error {
+			var body echoBody
+			if err := json.Unmarshal(service.ReadBytes(c.RequestBody()), &body); err != nil {
+				return err
+			}
+			c.Writer().Header().Set("X-Echo", c.Request().Header.Get("X-Echo")+c.Request().URL.Query().Get("suffix"))
+			c.JSON(http.StatusOK, body)
+			return nil
+		})
+		return nil
+	})
+	tests := []struct {
+		name string
+		opts []service.Option
+	}{
+		{
+			name: "function url",
+			opts: []service.Option{routes},
+		},
+		{
+			name: "api gateway",
+			opts: []service.Option{routes, service.WithRoutingType("api-gateway")},
+		},
+		{
+			name: "function url streaming",
+			opts: []service.Option{routes, service.UseResponseStreaming(true)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := New(t, tt.opts...)
+
+			res := h.InvokeLambda(http.MethodGet, "/api/status", nil, nil)
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+
+			res = h.InvokeLambda(http.MethodPost, "/api/echo?suffix=!", echoBody{Message: "hello"}, map[string]string{"X-Echo": "hi"})
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+			assert.Equal(t, "hi!", res.Headers.Get("X-Echo"))
+			assert.Contains(t, res.Headers.Get("Content-Type"), "application/json")
+			var body echoBody
+			assert.NoError(t, res.JSON(&body))
+			assert.Equal(t, "hello", body.Message)
+
+			res = h.InvokeLambda(http.MethodGet, "/api/unknown", nil, nil)
+			assert.Equal(t, http.StatusNotFound, res.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// ShadowRequestHeader marks mirrored requests, requests having it are never mirrored again
+	ShadowRequestHeader = "X-Shadow-Request"
+
+	defaultShadowTimeout = 5 * time.Second
+	defaultShadowMaxBody = 64 * 1024
+)
+
+var hopByHopHeaders = []string{"Connection", "Keep-Alive", "Proxy-Connection", "Te", "Trailer", "Transfer-Encoding", "Upgrade"}
+
+// WithShadowTraffic mirrors percent (in range [0, 100]) of requests to the same path of targetURL in background
+// and logs differences between its responses and responses of the service, callers only get the primary response.
+// In lambda mode shadow requests may be frozen together with the execution environment once the invocation completes,
+// they are resumed with the next invocation and are cut by timeout otherwise; pending comparisons are awaited on shutdown
+func WithShadowTraffic(targetURL string, percent float64) Option {
+	return func(s *service) {
+		shadow := &shadowTraffic{target: targetURL, percent: percent}
+		s.shadows = append(s.shadows, shadow)
+		s.handlerMiddlewares = append(s.handlerMiddlewares, s.shadowTrafficMiddleware(shadow))
+		s.shutdownHooks = append(s.shutdownHooks, shadow.wait)
+	}
+}
+
+// shadowTraffic keeps its position among handler middlewares while the target is validated in New
+type shadowTraffic struct {
+	target  string
+	percent float64
+	url     *url.URL
+	client  *http.Client
+	pending sync.WaitGroup
+}
+
+func (s *service) initShadowTraffic() error {
+	for _, shadow := range s.shadows {
+		target, err := url.Parse(shadow.target)
+		if err != nil {
+			return errors.Wrapf(err, "invalid shadow traffic target %q", shadow.target)
+		}
+		if target.Scheme != "http" && target.Scheme != "https" || target.Host == "" {
+			return errors.Errorf("invalid shadow traffic target %q: absolute http(s) URL is expected", shadow.target)
+		}
+		shadow.url = target
+		shadow.client = &http.Client{Timeout: defaultShadowTimeout}
+	}
+	return nil
+}
+
+func (s *service) shadowTrafficMiddleware(shadow *shadowTraffic) HandlerMiddleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get(ShadowRequestHeader) != "" || shadow.percent <= 0 || rand.Float64()*100 >= shadow.percent {
+				next.ServeHTTP(w, r)
+				return
+			}
+			var reqBody []byte
+			if r.Body != nil {
+				var err error
+				if reqBody, err = io.ReadAll(io.LimitReader(r.Body, defaultShadowMaxBody+1)); err != nil {
+					s.logger.Warnf(r.Context(), "failed to read body of shadowed request: %v", err)
+				}
+				r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(reqBody), r.Body))
+			}
+			if len(reqBody) > defaultShadowMaxBody {
+				// large bodies are not buffered to keep memory of the primary request bounded
+				next.ServeHTTP(w, r)
+				return
+			}
+			shadowReq, err := shadow.newRequest(context.WithoutCancel(r.Context()), r, reqBody)
+			if err != nil {
+				s.logger.Warnf(r.Context(), "failed to create shadow request: %v", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+			shadowRes := make(chan *shadowResponse, 1)
+			go func() {
+				shadowRes <- shadow.do(shadowReq)
+			}()
+
+			capture := newResponseCapture(w, defaultShadowMaxBody)
+			next.ServeHTTP(capture, r)
+
+			primary := &shadowResponse{
+				status:    capture.status,
+				body:      bytes.Clone(capture.body.Bytes()),
+				truncated: capture.size > capture.body.Len(),
+			}
+			ctx, route := context.WithoutCancel(r.Context()), r.Method+" "+r.URL.Path
+			shadow.pending.Add(1)
+			go func() {
+				defer shadow.pending.Done()
+				s.compareShadowResponse(ctx, route, primary, <-shadowRes)
+			}()
+		})
+	}
+}
+
+// wait awaits shadow requests which are in-flight and comparisons of their responses
+func (t *shadowTraffic) wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		t.pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrapf(ctx.Err(), "failed to await shadow requests")
+	}
+}
+
+func (t *shadowTraffic) newRequest(ctx context.Context, r *http.Request, body []byte) (*http.Request, error) {
+	target := *t.url
+	target.Path = strings.TrimSuffix(t.url.Path, "/") + r.URL.Path
+	target.RawPath = ""
+	target.RawQuery = r.URL.RawQuery
+	req, err := http.NewRequestWithContext(ctx, r.Method, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header = r.Header.Clone()
+	for _, name := range hopByHopHeaders {
+		req.Header.Del(name)
+	}
+	req.Header.Set(ShadowRequestHeader, "true")
+	return req, nil
+}
+
+type shadowResponse struct {
+	status    int
+	body      []byte
+	truncated bool
+	err       error
+}
+
+func (t *shadowTraffic) do(req *http.Request) *shadowResponse {
+	res, err := t.client.Do(req)
+	if err != nil {
+		return &shadowResponse{err: err}
+	}
+	defer func() { _ = res.Body.Close() }()
+	body, err := io.ReadAll(io.LimitReader(res.Body, defaultShadowMaxBody+1))
+	if err != nil {
+		return &shadowResponse{err: err}
+	}
+	return &shadowResponse{
+		status:    res.StatusCode,
+		body:      body[:min(len(body), defaultShadowMaxBody)],
+		truncated: len(body) > defaultShadowMaxBody,
+	}
+}
+
+// compareShadowResponse logs difference of status codes and bodies, truncated bodies are not compared
+func (s *service) compareShadowResponse(ctx context.Context, route string, primary, shadow *shadowResponse) {
+	ctx = s.logger.WithValue(ctx, "shadowRoute", route)
+	if shadow.err != nil {
+		s.logger.Warnf(ctx, "shadow request %s failed: %v", route, shadow.err)
+		return
+	}
+	var diffs []string
+	if primary.status != shadow.status {
+		diffs = append(diffs, "status")
+		ctx = s.logger.WithValue(ctx, "primaryStatus", primary.status)
+		ctx = s.logger.WithValue(ctx, "shadowStatus", shadow.status)
+	}
+	if !primary.truncated && !shadow.truncated && !sameBody(primary, shadow) {
+		diffs = append(diffs, "body")
+		ctx = s.logger.WithValue(ctx, "primaryBody", string(primary.body))
+		ctx = s.logger.WithValue(ctx, "shadowBody", string(shadow.body))
+	}
+	if len(diffs) > 0 {
+		s.logger.Warnf(ctx, "shadow response of %s differs: %s", route, strings.Join(diffs, ", "))
+	}
+}
+
+// sameBody compares JSON bodies ignoring formatting, order of keys and ResultMeta which differs for every request
+func sameBody(primary, shadow *shadowResponse) bool {
+	if bytes.Equal(primary.body, shadow.body) {
+		return true
+	}
+	var primaryJSON, shadowJSON any
+	if json.Unmarshal(primary.body, &primaryJSON) != nil || json.Unmarshal(shadow.body, &shadowJSON) != nil {
+		return false
+	}
+	for _, v := range []any{primaryJSON, shadowJSON} {
+		if obj, ok := v.(map[string]any); ok {
+			delete(obj, "meta")
+		}
+	}
+	return reflect.DeepEqual(primaryJSON, shadowJSON)
+}
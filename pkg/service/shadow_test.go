@@ -0,0 +1,127 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+type warningsLogger struct {
+	logger.Logger
+	mu       sync.Mutex
+	warnings []string
+}
+
+func (l *warningsLogger) Warnf(_ context.Context, format string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.warnings = append(l.warnings, fmt.Sprintf(format, args...))
+}
+
+// list returns warnings starting with prefix
+func (l *warningsLogger) list(prefix string) []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return lo.Filter(l.warnings, func(warning string, _ int) bool {
+		return strings.HasPrefix(warning, prefix)
+	})
+}
+
+func TestShadowTraffic(t *testing.T) {
+	shadowed := make(chan *http.Request, 10)
+	// slow shadow responds once the primary response is sent
+	release := make(chan struct{})
+	shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		shadowed <- r
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/shadow/same":
+			_, _ = w.Write([]byte(`{ "b": 2, "a": 1 }`))
+		case "/shadow/slow":
+			<-release
+			_, _ = w.Write([]byte(`{"a":1,"b":2}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"a":1}`))
+		}
+	}))
+	defer shadow.Close()
+
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		router.Any("/shadow/*path", func(c service.HttpAdapter) error {
+			c.JSON(http.StatusOK, service.M{"a": 1, "b": 2})
+			return nil
+		})
+		return nil
+	})
+
+	tests := []struct {
+		name string
+		path string
+		slow bool
+		want string
+	}{
+		{name: "same response", path: "/shadow/same"},
+		{name: "different response", path: "/shadow/changed?q=1", want: "shadow response of POST /shadow/changed differs: status, body"},
+		{name: "slow shadow does not delay response", path: "/shadow/slow", slow: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := &warningsLogger{Logger: logger.NewLogger()}
+			h := servicetest.New(t, routes, service.WithLogger(log), service.WithShadowTraffic(shadow.URL, 100))
+
+			res := h.Invoke(http.MethodPost, tt.path, map[string]string{"name": "test"}, nil)
+			require.Equal(t, http.StatusOK, res.StatusCode, string(res.Body))
+			if tt.slow {
+				close(release)
+			}
+
+			select {
+			case r := <-shadowed:
+				assert.Equal(t, "true", r.Header.Get(service.ShadowRequestHeader))
+				assert.Equal(t, tt.path, r.URL.RequestURI())
+			case <-time.After(time.Second):
+				t.Fatal("request is not shadowed")
+			}
+			// shutdown awaits pending comparisons
+			h.Service.Stop()
+			if tt.want == "" {
+				assert.Empty(t, log.list("shadow"))
+				return
+			}
+			assert.Equal(t, []string{tt.want}, log.list("shadow"))
+		})
+	}
+
+	t.Run("shadow requests are not mirrored", func(t *testing.T) {
+		h := servicetest.New(t, routes, service.WithShadowTraffic(shadow.URL, 100))
+		res := h.Invoke(http.MethodGet, "/shadow/same", nil, map[string]string{service.ShadowRequestHeader: "true"})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		h.Service.Stop()
+		select {
+		case <-shadowed:
+			t.Fatal("shadow request is mirrored")
+		default:
+		}
+	})
+
+	t.Run("invalid target", func(t *testing.T) {
+		_, err := service.New(context.Background(), service.WithEnv(func(string) string { return "" }),
+			service.WithRoutingType("function-url"), routes, service.WithShadowTraffic("/relative", 10))
+		require.Error(t, err)
+		assert.True(t, strings.Contains(err.Error(), "invalid shadow traffic target"), err.Error())
+	})
+}
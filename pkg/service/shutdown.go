@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+// ShutdownHook releases resources held across invocations, e.g. database connections
+type ShutdownHook func(ctx context.Context) error
+
+// WithShutdownHook adds hooks run in reverse order once the service stops: on SIGTERM in lambda mode
+// (lambda sends it only shortly before the execution environment is shut down) and after the http server
+// is stopped in server mode
+func WithShutdownHook(hooks ...ShutdownHook) Option {
+	return func(s *service) {
+		s.shutdownHooks = append(s.shutdownHooks, hooks...)
+	}
+}
+
+// runShutdownHooks runs hooks once, whichever of SIGTERM, server shutdown or Stop comes first
+func (s *service) runShutdownHooks() {
+	s.shutdownOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+		defer cancel()
+		for i := len(s.shutdownHooks) - 1; i >= 0; i-- {
+			if err := s.shutdownHooks[i](ctx); err != nil {
+				s.logger.Errorf(ctx, "shutdown hook failed: %v", err)
+			}
+		}
+	})
+}
+
+func (s *service) Stop() {
+	s.stopOnce.Do(func() {
+		for _, cancel := range s.cancels {
+			cancel()
+		}
+		// server mode runs hooks once in-flight requests are complete
+		if !s.serverMode {
+			s.runShutdownHooks()
+		}
+	})
+}
+
+// serviceContextHandler cancels request context once the service is stopped, request values are kept; it also
+// provides the flag of IsAuthorized unless a handler middleware did
+func (s *service) serviceContextHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Value(authenticatedKey).(*atomic.Bool); !ok {
+			r, _ = withAuthenticatedFlag(r)
+		}
+		ctx, cancel := context.WithCancelCause(r.Context())
+		defer cancel(nil)
+		stop := context.AfterFunc(s.ctx, func() {
+			cancel(errors.Wrapf(context.Canceled, "service is stopped"))
+		})
+		defer stop()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
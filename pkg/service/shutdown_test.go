@@ -0,0 +1,51 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestStop(t *testing.T) {
+	var hooks atomic.Int32
+	started := make(chan struct{})
+	h := servicetest.New(t,
+		service.WithShutdownHook(func(context.Context) error {
+			hooks.Add(1)
+			return nil
+		}),
+		service.WithRoutes(func(router service.HttpAdapterRouter) error {
+			router.GET("/api/wait", func(c service.HttpAdapter) error {
+				close(started)
+				select {
+				case <-c.Context().Done():
+					c.JSON(http.StatusServiceUnavailable, service.M{"message": context.Cause(c.Context()).Error()})
+				case <-time.After(time.Second):
+					c.JSON(http.StatusOK, service.M{})
+				}
+				return nil
+			})
+			return nil
+		}))
+
+	done := make(chan *servicetest.Response)
+	go func() {
+		done <- h.Invoke(http.MethodGet, "/api/wait", nil, nil)
+	}()
+	<-started
+	h.Service.Stop()
+	h.Service.Stop()
+
+	res := <-done
+	require.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	assert.JSONEq(t, `{"message":"service is stopped: context canceled"}`, string(res.Body))
+	assert.Equal(t, int32(1), hooks.Load(), "shutdown hooks must run once")
+}
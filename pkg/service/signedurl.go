@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type signingHostKeyType struct{}
+
+var signingHostKey signingHostKeyType = struct{}{}
+
+const (
+	SignedURLExpiresParam   = "expires"
+	SignedURLSignatureParam = "signature"
+)
+
+// WithURLSigningKey sets the key of URLs minted by SignURL, it is required to sign URLs and should differ from
+// the API key; GET and HEAD requests to such URLs on the same host pass API key authentication until the URL
+// expires
+func WithURLSigningKey(key string) Option {
+	return func(s *service) {
+		s.urlSigningKey = key
+	}
+}
+
+// SignURL mints URL granting temporary access to target, e.g. a download link, which is a path or URL as it is
+// requested by clients (including base path); ctx is the context of a request served by the service, URL is
+// signed for its host unless target is an absolute URL
+func SignURL(ctx context.Context, target string, ttl time.Duration) (string, error) {
+	s, ok := ctx.Value(serviceKey).(*service)
+	if !ok {
+		return "", errors.Errorf("service is not found in context")
+	}
+	key := s.urlSigningKey
+	if key == "" {
+		return "", errors.Errorf("URL signing key is not configured, see WithURLSigningKey")
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid URL %q", target)
+	}
+	host, _ := ctx.Value(signingHostKey).(string)
+	if u.Host != "" {
+		host = strings.ToLower(u.Hostname())
+	}
+	expires := strconv.FormatInt(s.clock.Now().Add(ttl).Unix(), 10)
+	query := u.Query()
+	query.Set(SignedURLExpiresParam, expires)
+	query.Set(SignedURLSignatureParam, urlSignature(key, host, u.Path, expires))
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// isSignedURL reports whether request URL carries valid signature which has not expired yet, signed URLs are
+// meant for downloads so that only GET and HEAD requests are accepted as the signature does not cover the method;
+// signature covers the host so that URL signed for one domain of host router is not valid on the others
+func (s *service) isSignedURL(r *http.Request) bool {
+	key := s.urlSigningKey
+	u := OriginalURL(r)
+	signature, expires := u.Query().Get(SignedURLSignatureParam), u.Query().Get(SignedURLExpiresParam)
+	if key == "" || signature == "" || expires == "" || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+		return false
+	}
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || s.clock.Now().Unix() > expiresAt {
+		return false
+	}
+	host := requestHost(r, s.trustProxyHeaders)
+	return hmac.Equal([]byte(signature), []byte(urlSignature(key, host, u.Path, expires)))
+}
+
+// withSigningHost keeps host of the request for URLs signed while serving it
+func (s *service) withSigningHost(ctx context.Context, r *http.Request) context.Context {
+	if s.urlSigningKey == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, signingHostKey, requestHost(r, s.trustProxyHeaders))
+}
+
+func urlSignature(key, host, path, expires string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(host + "\n" + path + "\n" + expires))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
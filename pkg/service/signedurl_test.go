@@ -0,0 +1,120 @@
+package service_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestSignedURL(t *testing.T) {
+	auth := map[string]string{"Authorization": "Bearer key"}
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		router.GET("/links/:name", func(c service.HttpAdapter) error {
+			ttl, err := time.ParseDuration(c.Query("ttl"))
+			if err != nil {
+				return err
+			}
+			link, err := service.SignURL(c.Context(), "/files/"+c.Param("name")+"?inline=true", ttl)
+			if err != nil {
+				return err
+			}
+			c.JSON(http.StatusOK, service.M{"url": link})
+			return nil
+		})
+		router.GET("/files/:name", func(c service.HttpAdapter) error {
+			c.JSON(http.StatusOK, service.M{"name": c.Param("name"), "inline": c.Query("inline")})
+			return nil
+		})
+		router.DELETE("/files/:name", func(c service.HttpAdapter) error {
+			c.JSON(http.StatusOK, service.M{"deleted": c.Param("name")})
+			return nil
+		})
+		return nil
+	})
+
+	tests := []struct {
+		name       string
+		method     string
+		ttl        string
+		tamper     func(string) string
+		wantStatus int
+	}{
+		{name: "signed", ttl: "1m", wantStatus: http.StatusOK},
+		{name: "expired", ttl: "-1m", wantStatus: http.StatusUnauthorized},
+		{name: "other method", method: http.MethodDelete, ttl: "1m", wantStatus: http.StatusUnauthorized},
+		{
+			name:       "other path",
+			ttl:        "1m",
+			tamper:     func(u string) string { return strings.Replace(u, "/files/report.csv", "/files/secret.csv", 1) },
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "other host",
+			ttl:        "1m",
+			tamper:     func(u string) string { return "https://other.example.com" + u },
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "extended expiry",
+			ttl:        "1m",
+			tamper:     func(u string) string { return strings.Replace(u, "expires=", "expires=9", 1) },
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "no signature",
+			ttl:        "1m",
+			tamper:     func(u string) string { return strings.Split(u, "&")[0] },
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		for _, streaming := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s, streaming: %t", tt.name, streaming), func(t *testing.T) {
+				h := servicetest.New(t, routes, service.WithApiKey("key"), service.WithURLSigningKey("signing"), service.UseResponseStreaming(streaming))
+
+				res := h.Invoke(http.MethodGet, "/links/report.csv?ttl="+tt.ttl, nil, auth)
+				require.Equal(t, http.StatusOK, res.StatusCode, string(res.Body))
+				var link struct {
+					URL string `json:"url"`
+				}
+				require.NoError(t, res.JSON(&link))
+				if tt.tamper != nil {
+					link.URL = tt.tamper(link.URL)
+				}
+
+				method := http.MethodGet
+				if tt.method != "" {
+					method = tt.method
+				}
+				res = h.Invoke(method, link.URL, nil, nil)
+				require.Equal(t, tt.wantStatus, res.StatusCode, string(res.Body))
+				if tt.wantStatus == http.StatusOK {
+					assert.JSONEq(t, `{"name":"report.csv","inline":"true"}`, string(res.Body))
+				}
+			})
+		}
+	}
+}
+
+func TestSignedURLRequiresSigningKey(t *testing.T) {
+	var signErr error
+	h := servicetest.New(t, service.WithApiKey("key"), service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		router.GET("/link", func(c service.HttpAdapter) error {
+			_, signErr = service.SignURL(c.Context(), "/files/report.csv", time.Minute)
+			return signErr
+		})
+		return nil
+	}))
+
+	res := h.Invoke(http.MethodGet, "/link", nil, map[string]string{"Authorization": "Bearer key"})
+	assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
+	assert.ErrorContains(t, signErr, "URL signing key is not configured")
+}
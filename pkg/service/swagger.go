@@ -0,0 +1,39 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/labstack/echo/v4"
+	echoSwagger "github.com/swaggo/echo-swagger"
+	swaggerfiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+)
+
+const (
+	swaggerRoute      = "/api/swagger"
+	swaggerIndexRoute = swaggerRoute + "/index.html"
+)
+
+// registerSwagger registers swagger UI on the engine in use, it is called after shared middlewares
+// and service endpoints are installed so that registration order is the same for gin and echo
+func (s *service) registerSwagger(ginEngine *gin.Engine, echoEngine *echo.Echo) {
+	switch {
+	case ginEngine != nil:
+		ginEngine.GET(swaggerRoute+"/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
+		s.routes.add(http.MethodGet, swaggerRoute+"/*any", nil)
+	case echoEngine != nil:
+		echoEngine.GET(swaggerRoute+"/*", echoSwagger.WrapHandler)
+		s.routes.add(http.MethodGet, swaggerRoute+"/*", nil)
+	default:
+		return
+	}
+	s.requestRewriters = append(s.requestRewriters, rewriteSwaggerIndex)
+}
+
+// rewriteSwaggerIndex serves index page for the swagger root, swagger handlers match files by RequestURI
+func rewriteSwaggerIndex(r *http.Request) {
+	if r.URL.Path == swaggerRoute || r.URL.Path == swaggerRoute+"/" {
+		r.URL.Path = swaggerIndexRoute
+	}
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+// UploadInfo describes uploaded file part being inspected
+type UploadInfo struct {
+	FieldName    string
+	FileName     string
+	ContentType  string // declared by the client
+	DetectedType string // sniffed from the content
+	Size         int64
+}
+
+// UploadInspector inspects each uploaded file before handlers see the request, returned error rejects the request
+// with 422 unless it carries another status via ErrorWithStatus
+type UploadInspector interface {
+	Inspect(ctx context.Context, upload UploadInfo, content io.Reader) error
+}
+
+// UploadInspectorFunc is a function implementing UploadInspector, e.g. a call to a scanning lambda
+type UploadInspectorFunc func(ctx context.Context, upload UploadInfo, content io.Reader) error
+
+func (f UploadInspectorFunc) Inspect(ctx context.Context, upload UploadInfo, content io.Reader) error {
+	return f(ctx, upload, content)
+}
+
+// WithUploadInspector inspects file parts of multipart requests, so that FormFile, MultipartForm and
+// MultipartStream only ever see uploads accepted by every inspector
+func WithUploadInspector(inspectors ...UploadInspector) Option {
+	return func(s *service) {
+		s.uploadInspectors = append(s.uploadInspectors, inspectors...)
+	}
+}
+
+// MaxUploadSize rejects files larger than size bytes with 413
+func MaxUploadSize(size int64) UploadInspector {
+	return UploadInspectorFunc(func(_ context.Context, upload UploadInfo, _ io.Reader) error {
+		if upload.Size > size {
+			return ErrorWithStatus(http.StatusRequestEntityTooLarge, errors.Errorf("file %s exceeds %d bytes", upload.FileName, size))
+		}
+		return nil
+	})
+}
+
+// AllowedUploadTypes rejects files whose sniffed content type is none of mediaTypes with 415, see RequireContentType
+// for supported patterns
+func AllowedUploadTypes(mediaTypes ...string) UploadInspector {
+	return UploadInspectorFunc(func(_ context.Context, upload UploadInfo, _ io.Reader) error {
+		if !lo.SomeBy(mediaTypes, func(allowed string) bool {
+			return mediaTypeMatches(strings.ToLower(allowed), upload.DetectedType)
+		}) {
+			return ErrorWithStatus(http.StatusUnsupportedMediaType,
+				errors.Errorf("file %s of type %s is not allowed", upload.FileName, upload.DetectedType))
+		}
+		return nil
+	})
+}
+
+// uploadInspectionHandler buffers multipart bodies to inspect file parts, malformed bodies are passed through
+// for handlers to report
+func (s *service) uploadInspectionHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || !strings.HasPrefix(mediaType, "multipart/") || params["boundary"] == "" || r.Body == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if tooLarge := (*http.MaxBytesError)(nil); errors.As(err, &tooLarge) {
+			http.Error(w, "request body is too large", http.StatusRequestEntityTooLarge)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.inspectUploads(r.Context(), multipart.NewReader(bytes.NewReader(body), params["boundary"])); err != nil {
+			s.logger.Warnf(r.Context(), "upload is rejected: %v", err)
+			status := http.StatusUnprocessableEntity
+			if statusErr := StatusError(nil); errors.As(err, &statusErr) {
+				status = statusErr.StatusCode()
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *service) inspectUploads(ctx context.Context, reader *multipart.Reader) error {
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			// either all parts are inspected or the body is malformed, which is reported by handlers
+			return nil
+		}
+		if part.FileName() == "" {
+			continue
+		}
+		content, err := io.ReadAll(part)
+		if err != nil {
+			return nil
+		}
+		upload := UploadInfo{
+			FieldName:    part.FormName(),
+			FileName:     part.FileName(),
+			ContentType:  part.Header.Get("Content-Type"),
+			DetectedType: detectContentType(content),
+			Size:         int64(len(content)),
+		}
+		for _, inspector := range s.uploadInspectors {
+			if err := inspector.Inspect(ctx, upload, bytes.NewReader(content)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// detectContentType sniffs media type of content without parameters
+func detectContentType(content []byte) string {
+	mediaType, _, _ := mime.ParseMediaType(http.DetectContentType(content))
+	return mediaType
+}
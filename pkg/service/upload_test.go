@@ -0,0 +1,112 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestUploadInspector(t *testing.T) {
+	var persisted []string
+	scanner := service.UploadInspectorFunc(func(_ context.Context, upload service.UploadInfo, content io.Reader) error {
+		data, err := io.ReadAll(content)
+		if err != nil {
+			return err
+		}
+		if bytes.Contains(data, []byte("EICAR")) {
+			return errors.Errorf("file %s is infected", upload.FileName)
+		}
+		return nil
+	})
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		router.POST("/uploads", func(c service.HttpAdapter) error {
+			file, err := c.FormFile("file")
+			if err != nil {
+				service.Fail(c, service.ErrorWithStatus(http.StatusBadRequest, err))
+				return nil
+			}
+			persisted = append(persisted, file.Filename)
+			c.JSON(http.StatusOK, service.M{"size": file.Size})
+			return nil
+		})
+		return nil
+	})
+	multipartBody := func(name string, content []byte) (string, []byte) {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		require.NoError(t, writer.WriteField("title", strings.Repeat("t", 200)))
+		file, err := writer.CreateFormFile("file", name)
+		require.NoError(t, err)
+		_, err = file.Write(content)
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+		return writer.FormDataContentType(), body.Bytes()
+	}
+
+	tests := []struct {
+		name          string
+		fileName      string
+		content       []byte
+		contentType   string
+		wantStatus    int
+		wantError     string
+		wantPersisted bool
+	}{
+		{name: "text", fileName: "notes.txt", content: []byte("hello"), wantStatus: http.StatusOK, wantPersisted: true},
+		{name: "png", fileName: "image.png", content: []byte("\x89PNG\r\n\x1a\n"), wantStatus: http.StatusOK, wantPersisted: true},
+		{
+			name:       "too large",
+			fileName:   "notes.txt",
+			content:    bytes.Repeat([]byte("a"), 101),
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantError:  "file notes.txt exceeds 100 bytes",
+		},
+		{
+			name:       "type is sniffed",
+			fileName:   "image.png",
+			content:    []byte("%PDF-1.4"),
+			wantStatus: http.StatusUnsupportedMediaType,
+			wantError:  "file image.png of type application/pdf is not allowed",
+		},
+		{
+			name:       "scanner",
+			fileName:   "notes.txt",
+			content:    []byte("X5O!P%@AP EICAR"),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantError:  "file notes.txt is infected",
+		},
+		{name: "not multipart", contentType: "application/json", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		for _, streaming := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s, streaming: %t", tt.name, streaming), func(t *testing.T) {
+				persisted = nil
+				h := servicetest.New(t, routes, service.UseResponseStreaming(streaming),
+					service.WithUploadInspector(service.MaxUploadSize(100), service.AllowedUploadTypes("text/*", "image/png"), scanner))
+
+				contentType, body := multipartBody(tt.fileName, tt.content)
+				if tt.contentType != "" {
+					contentType, body = tt.contentType, []byte(`{}`)
+				}
+				res := h.Invoke(http.MethodPost, "/uploads", body, map[string]string{"Content-Type": contentType})
+				require.Equal(t, tt.wantStatus, res.StatusCode, string(res.Body))
+				if tt.wantError != "" {
+					assert.Equal(t, tt.wantError, strings.TrimSpace(string(res.Body)))
+				}
+				assert.Equal(t, tt.wantPersisted, len(persisted) == 1)
+			})
+		}
+	}
+}
@@ -0,0 +1,313 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+)
+
+const usageRoute = "/api/_usage"
+
+type usageRequestKeyType struct{}
+
+var usageRequestKey usageRequestKeyType = struct{}{}
+
+// usageRequest is the API key usage of the request is accounted to unless it exceeded the quota
+type usageRequest struct {
+	id, month string
+	overQuota atomic.Bool
+}
+
+// UsageConfig configures per API key usage tracking
+type UsageConfig struct {
+	// MonthlyQuota is a limit of requests per key per calendar month (UTC), 0 means unlimited. Quota is checked
+	// against usage known to the instance: its own requests with totals of the Store refreshed once they are
+	// flushed, so requests served by other instances in between may exceed the quota
+	MonthlyQuota int64
+	// Store is an optional store aggregating usage of all instances, usage is flushed to it in background after
+	// requests with increments made meanwhile batched into a single update per key, and on shutdown
+	Store UsageStore
+}
+
+// KeyUsage is usage of the API key within a month, key itself is never stored, only its hash
+type KeyUsage struct {
+	KeyID    string  `json:"keyId" yaml:"keyId"`
+	Month    string  `json:"month" yaml:"month"`
+	Requests int64   `json:"requests" yaml:"requests"`
+	Cost     float64 `json:"cost" yaml:"cost"`
+}
+
+// UsageStore aggregates usage across instances, Add returns totals after the increment
+type UsageStore interface {
+	Add(ctx context.Context, keyID, month string, requests int64, cost float64) (KeyUsage, error)
+}
+
+// WithUsageTracking counts requests and estimated cost per API key, exposes them on /api/_usage
+// (requires API key) and rejects requests with 429 once monthly quota of the key is exceeded
+func WithUsageTracking(cfg UsageConfig) Option {
+	return func(s *service) {
+		s.usage = &usageTracker{cfg: cfg, usage: map[string]*KeyUsage{}, pending: map[string]*KeyUsage{}}
+		s.handlerMiddlewares = append(s.handlerMiddlewares, s.usageMiddleware())
+		s.shutdownHooks = append(s.shutdownHooks, s.usage.shutdown)
+	}
+}
+
+type usageTracker struct {
+	cfg   UsageConfig
+	mu    sync.Mutex
+	usage map[string]*KeyUsage
+	// pending are increments not flushed to the store yet keyed by usageKey
+	pending  map[string]*KeyUsage
+	flushing bool
+	flushes  sync.WaitGroup
+}
+
+func usageKey(id, month string) string {
+	return id + "/" + month
+}
+
+func usageMonth(t time.Time) string {
+	return t.UTC().Format("2006-01")
+}
+
+func (u *usageTracker) get(id, month string) KeyUsage {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if usage, ok := u.usage[id]; ok && usage.Month == month {
+		return *usage
+	}
+	return KeyUsage{KeyID: id, Month: month}
+}
+
+// add increments local usage and pending increments of the store (if any)
+func (u *usageTracker) add(id, month string, cost float64) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	usage, ok := u.usage[id]
+	if !ok || usage.Month != month {
+		usage = &KeyUsage{KeyID: id, Month: month}
+		u.usage[id] = usage
+	}
+	usage.Requests++
+	usage.Cost += cost
+	if u.cfg.Store == nil {
+		return
+	}
+	pending, ok := u.pending[usageKey(id, month)]
+	if !ok {
+		pending = &KeyUsage{KeyID: id, Month: month}
+		u.pending[usageKey(id, month)] = pending
+	}
+	pending.Requests++
+	pending.Cost += cost
+}
+
+// flushInBackground flushes pending increments unless a flush is running already, the running one flushes
+// increments made meanwhile once it is done
+func (u *usageTracker) flushInBackground(ctx context.Context, log logger.Logger) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.flushing || len(u.pending) == 0 {
+		return
+	}
+	u.flushing = true
+	u.flushes.Add(1)
+	go func() {
+		defer u.flushes.Done()
+		for {
+			err := u.flush(ctx)
+			if err != nil {
+				log.Errorf(ctx, "failed to store usage: %v", err)
+			}
+			u.mu.Lock()
+			// failed increments are kept for the next flush instead of retrying them right away
+			if err != nil || len(u.pending) == 0 {
+				u.flushing = false
+				u.mu.Unlock()
+				return
+			}
+			u.mu.Unlock()
+		}
+	}()
+}
+
+// flush adds pending increments to the store, its totals replace local view keeping increments made meanwhile
+func (u *usageTracker) flush(ctx context.Context) error {
+	u.mu.Lock()
+	pending := u.pending
+	u.pending = map[string]*KeyUsage{}
+	u.mu.Unlock()
+
+	var firstErr error
+	failed := 0
+	for key, increment := range pending {
+		total, err := u.cfg.Store.Add(ctx, increment.KeyID, increment.Month, increment.Requests, increment.Cost)
+		u.mu.Lock()
+		unflushed, ok := u.pending[key]
+		if err != nil {
+			firstErr, failed = lo.Ternary(firstErr == nil, err, firstErr), failed+1
+			if ok {
+				unflushed.Requests += increment.Requests
+				unflushed.Cost += increment.Cost
+			} else {
+				u.pending[key] = increment
+			}
+		} else if current, found := u.usage[increment.KeyID]; found && current.Month == increment.Month {
+			*current = total
+			if ok {
+				current.Requests += unflushed.Requests
+				current.Cost += unflushed.Cost
+			}
+		}
+		u.mu.Unlock()
+	}
+	if firstErr != nil {
+		return errors.Wrapf(firstErr, "failed to flush usage of %d keys", failed)
+	}
+	return nil
+}
+
+// shutdown waits for the running flush and flushes the rest
+func (u *usageTracker) shutdown(ctx context.Context) error {
+	if u.cfg.Store == nil {
+		return nil
+	}
+	done := make(chan struct{})
+	go func() {
+		u.flushes.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return u.flush(ctx)
+	case <-ctx.Done():
+		return errors.Wrapf(ctx.Err(), "failed to store usage")
+	}
+}
+
+func (u *usageTracker) list() []KeyUsage {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	res := lo.Map(lo.Values(u.usage), func(usage *KeyUsage, _ int) KeyUsage {
+		return *usage
+	})
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].KeyID < res[j].KeyID
+	})
+	return res
+}
+
+func (s *service) usageMiddleware() HandlerMiddleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			parts := strings.Split(r.Header.Get("Authorization"), " ")
+			if len(parts) < 2 || parts[1] == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			req := &usageRequest{id: keyID(parts[1]), month: usageMonth(s.clock.Now())}
+			startedAt := s.clock.Now()
+			r, authenticated := withAuthenticatedFlag(r.WithContext(context.WithValue(r.Context(), usageRequestKey, req)))
+			next.ServeHTTP(w, r)
+			if !authenticated.Load() || req.overQuota.Load() {
+				// key is not valid, route does not require it or the request was rejected, nothing to account
+				return
+			}
+			s.usage.add(req.id, req.month, s.costOf(s.clock.Since(startedAt)))
+			s.usage.flushInBackground(context.WithoutCancel(r.Context()), s.logger)
+		})
+	}
+}
+
+// usageQuotaMiddleware rejects requests of API keys exceeding monthly quota, it runs after auth so that only
+// authenticated keys are checked
+func (s *service) usageQuotaMiddleware() HttpAdapterHandler {
+	return func(c HttpAdapter) error {
+		ctx := c.Context()
+		req, ok := ctx.Value(usageRequestKey).(*usageRequest)
+		if !ok || !IsAuthorized(ctx) {
+			return nil
+		}
+		quota := s.usage.cfg.MonthlyQuota
+		if s.usage.get(req.id, req.month).Requests < quota {
+			return nil
+		}
+		req.overQuota.Store(true)
+		s.logger.Warnf(s.logger.WithValue(ctx, "keyId", req.id), "monthly quota of %d requests is exceeded", quota)
+		now := s.clock.Now()
+		c.SetHeader("Retry-After", strconv.Itoa(int(nextMonth(now).Sub(now).Seconds())))
+		c.JSON(http.StatusTooManyRequests, M{"message": "monthly quota is exceeded"})
+		c.AbortWithStatus(http.StatusTooManyRequests)
+		return nil
+	}
+}
+
+func nextMonth(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// @Schemes
+// @Description usage of API keys within current instance
+// @Tags debug
+// @Produce json
+// @Success 200 {array} KeyUsage
+// @Router /api/_usage [get]
+func (s *service) usageEndpoint(c HttpAdapter) error {
+	c.JSON(http.StatusOK, s.usage.list())
+	return nil
+}
+
+type dynamoDBUsageStore struct {
+	client dynamodbiface.DynamoDBAPI
+	table  string
+}
+
+// DynamoDBUsageStore keeps usage in the table with "keyId" hash key and "month" range key
+func DynamoDBUsageStore(client dynamodbiface.DynamoDBAPI, table string) UsageStore {
+	return &dynamoDBUsageStore{
+		client: client,
+		table:  table,
+	}
+}
+
+func (d *dynamoDBUsageStore) Add(ctx context.Context, keyID, month string, requests int64, cost float64) (KeyUsage, error) {
+	out, err := d.client.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(d.table),
+		Key: map[string]*dynamodb.AttributeValue{
+			"keyId": {S: aws.String(keyID)},
+			"month": {S: aws.String(month)},
+		},
+		UpdateExpression: aws.String("ADD requests :requests, cost :cost"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":requests": {N: aws.String(strconv.FormatInt(requests, 10))},
+			":cost":     {N: aws.String(strconv.FormatFloat(cost, 'f', -1, 64))},
+		},
+		ReturnValues: aws.String(dynamodb.ReturnValueAllNew),
+	})
+	if err != nil {
+		return KeyUsage{}, errors.Wrapf(err, "failed to update usage of key %s", keyID)
+	}
+	res := KeyUsage{KeyID: keyID, Month: month}
+	if v, ok := out.Attributes["requests"]; ok && v.N != nil {
+		res.Requests, _ = strconv.ParseInt(*v.N, 10, 64)
+	}
+	if v, ok := out.Attributes["cost"]; ok && v.N != nil {
+		res.Cost, _ = strconv.ParseFloat(*v.N, 64)
+	}
+	return res, nil
+}
@@ -0,0 +1,70 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+type countingUsageStore struct {
+	mu       sync.Mutex
+	requests int64
+}
+
+func (c *countingUsageStore) Add(_ context.Context, keyID, month string, requests int64, cost float64) (service.KeyUsage, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests += requests
+	return service.KeyUsage{KeyID: keyID, Month: month, Requests: c.requests, Cost: cost}, nil
+}
+
+func (c *countingUsageStore) stored() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.requests
+}
+
+func TestUsageTracking(t *testing.T) {
+	store := &countingUsageStore{}
+	h := servicetest.New(t,
+		service.WithApiKey("secret"),
+		service.WithSkipAuthRoutes("/api/public"),
+		service.WithUsageTracking(service.UsageConfig{MonthlyQuota: 2, Store: store}),
+		service.WithRoutes(func(router service.HttpAdapterRouter) error {
+			for _, p := range []string{"/api/private", "/api/public"} {
+				router.GET(p, func(c service.HttpAdapter) error {
+					c.JSON(http.StatusOK, map[string]string{"status": "ok"})
+					return nil
+				})
+			}
+			return nil
+		}))
+	steps := []struct {
+		name       string
+		path       string
+		key        string
+		wantStatus int
+		wantStored int64
+	}{
+		{name: "invalid key is not accounted", path: "/api/private", key: "wrong", wantStatus: http.StatusUnauthorized, wantStored: 0},
+		{name: "public route is not accounted", path: "/api/public", key: "wrong", wantStatus: http.StatusOK, wantStored: 0},
+		{name: "first request", path: "/api/private", key: "secret", wantStatus: http.StatusOK, wantStored: 1},
+		{name: "second request", path: "/api/private", key: "secret", wantStatus: http.StatusOK, wantStored: 2},
+		{name: "quota exceeded", path: "/api/private", key: "secret", wantStatus: http.StatusTooManyRequests, wantStored: 2},
+		{name: "quota is checked after auth", path: "/api/public", key: "secret", wantStatus: http.StatusOK, wantStored: 2},
+	}
+	for _, step := range steps {
+		res := h.Invoke(http.MethodGet, step.path, nil, map[string]string{"Authorization": "Bearer " + step.key})
+		require.Equal(t, step.wantStatus, res.StatusCode, step.name)
+		// usage is flushed to the store in background
+		assert.Eventually(t, func() bool { return store.stored() == step.wantStored }, time.Second, time.Millisecond, step.name)
+	}
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+)
+
+const (
+	AcceptVersionHeader = "Accept-Version"
+	DeprecationHeader   = "Deprecation"
+	SunsetHeader        = "Sunset"
+)
+
+type (
+	VersionOption func(*apiVersion)
+	apiVersion    struct {
+		version string
+		sunset  *time.Time
+	}
+)
+
+// Deprecated marks API version as deprecated: responses get Deprecation and Sunset headers
+// and every call is logged as warning so that remaining clients can be tracked down
+func Deprecated(sunset time.Time) VersionOption {
+	return func(v *apiVersion) {
+		v.sunset = lo.ToPtr(sunset)
+	}
+}
+
+// VersionedRouter is optionally implemented by HttpAdapterRouter to customize registration of API versions
+type VersionedRouter interface {
+	Versioned(version string, callback RegisterRoutesCallback, opts ...VersionOption) error
+}
+
+// Versioned registers routes of API version under /{version} prefix of the router, routers of the service
+// also make the version available for Accept-Version negotiation
+func Versioned(router HttpAdapterRouter, version string, callback RegisterRoutesCallback, opts ...VersionOption) error {
+	if v, ok := router.(VersionedRouter); ok {
+		return v.Versioned(version, callback, opts...)
+	}
+	return versioned(router, nil, version, callback, opts...)
+}
+
+// versioned registers routes of API version under /{version} prefix of the router
+func versioned(router HttpAdapterRouter, log logger.Logger, version string, callback RegisterRoutesCallback, opts ...VersionOption) error {
+	v := &apiVersion{version: strings.Trim(version, "/")}
+	for _, opt := range opts {
+		opt(v)
+	}
+	group := router.Group("/" + v.version)
+	if v.sunset != nil {
+		group.Use(v.deprecationMiddleware(log))
+	}
+	if err := callback(group); err != nil {
+		return errors.Wrapf(err, "failed to register routes of API version %s", v.version)
+	}
+	return nil
+}
+
+func (v *apiVersion) deprecationMiddleware(log logger.Logger) HttpAdapterHandler {
+	sunset := v.sunset.UTC().Format(http.TimeFormat)
+	return func(c HttpAdapter) error {
+		c.SetHeader(DeprecationHeader, "true")
+		c.SetHeader(SunsetHeader, sunset)
+		if log != nil {
+			log.Warnf(c.Context(), "deprecated API version %s is called: %s %s (sunset at %s)",
+				v.version, c.Request().Method, c.Request().URL.Path, sunset)
+		}
+		return nil
+	}
+}
+
+type versionPrefix struct {
+	prefix  string
+	version string
+}
+
+func (r *routeRegistry) addVersion(prefix, version string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.versions = append(r.versions, versionPrefix{
+		prefix:  path.Join("/", prefix),
+		version: strings.Trim(version, "/"),
+	})
+}
+
+// versionedPath inserts version into unversioned path using the longest matching prefix the version is registered under
+func (r *routeRegistry) versionedPath(version, p string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var matched *versionPrefix
+	for i, v := range r.versions {
+		underPrefix := v.prefix == "/" || stripPathPrefix(p, v.prefix) != p
+		if v.version != version || !underPrefix {
+			continue
+		}
+		if matched == nil || len(v.prefix) > len(matched.prefix) {
+			matched = &r.versions[i]
+		}
+	}
+	if matched == nil {
+		return p, false
+	}
+	rest := p
+	if matched.prefix != "/" {
+		rest = stripPathPrefix(p, matched.prefix)
+	}
+	if lo.SomeBy(r.versions, func(v versionPrefix) bool {
+		return v.prefix == matched.prefix && stripPathPrefix(rest, "/"+v.version) != rest
+	}) {
+		// path is already versioned explicitly
+		return p, false
+	}
+	return path.Join(matched.prefix, matched.version, rest), true
+}
+
+// versionNegotiationHandler routes requests with Accept-Version header to the routes of the requested version
+func (s *service) versionNegotiationHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if version := r.Header.Get(AcceptVersionHeader); version != "" {
+			if p, ok := s.routes.versionedPath(strings.Trim(version, "/"), r.URL.Path); ok {
+				r = withPath(r, p)
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,83 @@
+package service_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+)
+
+func TestVersioned(t *testing.T) {
+	versionRoutes := func(version string) service.RegisterRoutesCallback {
+		return func(router service.HttpAdapterRouter) error {
+			router.GET("/items", func(c service.HttpAdapter) error {
+				c.JSON(http.StatusOK, map[string]string{"version": version})
+				return nil
+			})
+			return nil
+		}
+	}
+	sunset := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+	routes := service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		api := router.Group("/api")
+		if err := service.Versioned(api, "v1", versionRoutes("v1"), service.Deprecated(sunset)); err != nil {
+			return err
+		}
+		return service.Versioned(api, "v2", versionRoutes("v2"))
+	})
+	tests := []struct {
+		name           string
+		path           string
+		headers        map[string]string
+		wantStatus     int
+		wantVersion    string
+		wantDeprecated bool
+	}{
+		{name: "explicit version", path: "/api/v2/items", wantStatus: http.StatusOK, wantVersion: "v2"},
+		{name: "deprecated version", path: "/api/v1/items", wantStatus: http.StatusOK, wantVersion: "v1", wantDeprecated: true},
+		{
+			name:        "negotiated version",
+			path:        "/api/items",
+			headers:     map[string]string{service.AcceptVersionHeader: "v2"},
+			wantStatus:  http.StatusOK,
+			wantVersion: "v2",
+		},
+		{
+			name:        "explicit version wins over header",
+			path:        "/api/v2/items",
+			headers:     map[string]string{service.AcceptVersionHeader: "v1"},
+			wantStatus:  http.StatusOK,
+			wantVersion: "v2",
+		},
+		{
+			name:       "unknown version",
+			path:       "/api/items",
+			headers:    map[string]string{service.AcceptVersionHeader: "v3"},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := servicetest.New(t, routes)
+
+			res := h.Invoke(http.MethodGet, tt.path, nil, tt.headers)
+			require.Equal(t, tt.wantStatus, res.StatusCode)
+			if tt.wantVersion != "" {
+				var body map[string]string
+				require.NoError(t, res.JSON(&body))
+				assert.Equal(t, tt.wantVersion, body["version"])
+			}
+			if tt.wantDeprecated {
+				assert.Equal(t, "true", res.Headers.Get(service.DeprecationHeader))
+				assert.Equal(t, sunset.Format(http.TimeFormat), res.Headers.Get(service.SunsetHeader))
+			} else {
+				assert.Empty(t, res.Headers.Get(service.DeprecationHeader))
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// TimeoutWarning describes request which is still in-flight when lambda deadline is imminent
+type TimeoutWarning struct {
+	RequestUID string        `json:"requestUID" yaml:"requestUID"`
+	Method     string        `json:"method" yaml:"method"`
+	Path       string        `json:"path" yaml:"path"`
+	Elapsed    time.Duration `json:"elapsed" yaml:"elapsed"`
+	Remaining  time.Duration `json:"remaining" yaml:"remaining"`
+}
+
+type TimeoutWarningCallback func(ctx context.Context, warning TimeoutWarning)
+
+// WithTimeoutWatchdog logs an error and invokes callback (if not nil) when request is still being
+// processed threshold before the lambda deadline
+func WithTimeoutWatchdog(threshold time.Duration, callback TimeoutWarningCallback) Option {
+	return func(s *service) {
+		s.timeoutWatchdogThreshold = threshold
+		s.timeoutWatchdogCallback = callback
+	}
+}
+
+func (s *service) timeoutWatchdogMiddleware() HttpAdapterHandler {
+	return func(c HttpAdapter) error {
+		ctx := c.Context()
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			return nil
+		}
+		startedAt := s.clock.Now()
+		method, path := c.Request().Method, c.Request().URL.Path
+		timer := s.clock.NewTimer(max(deadline.Sub(startedAt)-s.timeoutWatchdogThreshold, 0))
+		go func() {
+			select {
+			// lambda runtime cancels context once invocation is complete
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C():
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			requestUID, _ := RequestUID(ctx)
+			warning := TimeoutWarning{
+				RequestUID: requestUID,
+				Method:     method,
+				Path:       path,
+				Elapsed:    s.clock.Since(startedAt),
+				Remaining:  deadline.Sub(s.clock.Now()),
+			}
+			s.logger.Errorf(s.logger.WithValue(ctx, "timeoutWarning", warning),
+				"request %s %s is about to time out in %s", method, path, warning.Remaining)
+			if s.timeoutWatchdogCallback != nil {
+				s.timeoutWatchdogCallback(ctx, warning)
+			}
+		}()
+		return nil
+	}
+}
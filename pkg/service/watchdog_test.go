@@ -0,0 +1,88 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/util/clocktest"
+)
+
+// deadlineContext reports deadline of the fake clock, it is cancelled explicitly once the invocation is complete
+type deadlineContext struct {
+	context.Context
+	deadline time.Time
+}
+
+func (c deadlineContext) Deadline() (time.Time, bool) {
+	return c.deadline, true
+}
+
+func TestTimeoutWatchdog(t *testing.T) {
+	tests := []struct {
+		name        string
+		elapsed     time.Duration
+		wantWarning bool
+	}{
+		{name: "slow request", elapsed: 60 * time.Millisecond, wantWarning: true},
+		{name: "fast request", elapsed: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := clocktest.New(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+			warnings := make(chan service.TimeoutWarning, 1)
+			h := servicetest.New(t,
+				service.WithClock(clock),
+				// lambda deadline is emulated by the deadline of request context
+				service.WithHandlerMiddleware(func(next http.Handler) http.Handler {
+					return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						ctx, cancel := context.WithCancel(deadlineContext{Context: r.Context(), deadline: clock.Now().Add(100 * time.Millisecond)})
+						defer cancel()
+						next.ServeHTTP(w, r.WithContext(ctx))
+					})
+				}),
+				service.WithTimeoutWatchdog(70*time.Millisecond, func(ctx context.Context, warning service.TimeoutWarning) {
+					warnings <- warning
+				}),
+				service.WithRoutes(func(router service.HttpAdapterRouter) error {
+					router.GET("/api/work", func(c service.HttpAdapter) error {
+						clock.Advance(tt.elapsed)
+						if tt.wantWarning {
+							// the warning is only sent while the request is in-flight
+							select {
+							case warning := <-warnings:
+								warnings <- warning
+							case <-time.After(time.Second):
+							}
+						}
+						c.JSON(http.StatusOK, map[string]string{"status": "ok"})
+						return nil
+					})
+					return nil
+				}))
+
+			assert.Equal(t, http.StatusOK, h.Invoke(http.MethodGet, "/api/work", nil, nil).StatusCode)
+			if !tt.wantWarning {
+				// watchdog stops its timer once the request is complete, so the deadline passes silently
+				assert.Eventually(t, func() bool { return clock.Timers() == 0 }, time.Second, time.Millisecond)
+				clock.Advance(time.Second)
+				assert.Empty(t, warnings)
+				return
+			}
+			select {
+			case warning := <-warnings:
+				assert.Equal(t, "/api/work", warning.Path)
+				assert.NotEmpty(t, warning.RequestUID)
+				assert.Equal(t, 60*time.Millisecond, warning.Elapsed)
+				assert.Equal(t, 40*time.Millisecond, warning.Remaining)
+			default:
+				t.Fatal("no timeout warning")
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/samber/lo"
+)
+
+const (
+	lwaExecWrapperEnv       = "AWS_LAMBDA_EXEC_WRAPPER"
+	lwaExecWrapper          = "/opt/bootstrap"
+	lwaPortEnv              = "AWS_LWA_PORT"
+	lwaReadinessCheckEnv    = "AWS_LWA_READINESS_CHECK_PATH"
+	lwaDefaultReadinessPath = "/"
+)
+
+// WithUnixSocket makes http server listen on unix domain socket instead of tcp port
+func WithUnixSocket(path string) Option {
+	return func(s *service) {
+		s.unixSocket = path
+	}
+}
+
+// WithLambdaWebAdapter runs service as plain http server behind AWS Lambda Web Adapter layer:
+// port is taken from AWS_LWA_PORT (falling back to PORT) and readiness endpoint is served
+// on AWS_LWA_READINESS_CHECK_PATH unless the application registers that route itself
+func WithLambdaWebAdapter() Option {
+	return func(s *service) {
+		s.serverMode = true
+		s.lambdaWebAdapter = true
+		if port := s.getenv(lwaPortEnv); port != "" {
+			s.port = port
+		}
+	}
+}
+
+func isLambdaWebAdapterEnv(getenv func(string) string) bool {
+	return getenv(lwaExecWrapperEnv) == lwaExecWrapper || getenv(lwaPortEnv) != ""
+}
+
+func (s *service) registerWebAdapterReadiness() {
+	readinessPath := lo.If(s.getenv(lwaReadinessCheckEnv) != "", s.getenv(lwaReadinessCheckEnv)).Else(lwaDefaultReadinessPath)
+	if lo.SomeBy(s.routes.list(), func(route RouteInfo) bool {
+		return route.Path == readinessPath && (route.Method == http.MethodGet || route.Method == "ANY")
+	}) {
+		return
+	}
+	// readiness path is often "/", so it is exempted from auth by exact match rather than by prefix
+	s.publicRoutes = append(s.publicRoutes, readinessPath)
+	s.httpRouter.GET(readinessPath, func(c HttpAdapter) error {
+		c.JSON(http.StatusOK, Status{Status: "ready"})
+		return nil
+	})
+}
@@ -0,0 +1,339 @@
+package session
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/util"
+)
+
+const (
+	DefaultCookieName = "session"
+	DefaultTTL        = 24 * time.Hour
+	// maxCookieSize is the size of a cookie browsers are guaranteed to keep
+	maxCookieSize = 4096
+)
+
+// ErrCookieTooLarge is returned by Save when values of a cookie session do not fit into a cookie, a Store
+// should be used for such sessions
+var ErrCookieTooLarge = errors.New("session cookie exceeds 4096 bytes")
+
+// Session is the state of a browser session, values are JSON encoded so numbers are float64 once loaded
+type Session struct {
+	ID        string         `json:"id"`
+	Values    map[string]any `json:"values,omitempty"`
+	CreatedAt time.Time      `json:"createdAt"`
+	RotatedAt time.Time      `json:"rotatedAt"`
+	ExpiresAt time.Time      `json:"expiresAt"`
+
+	isNew      bool
+	previousID string
+}
+
+// IsNew reports whether the request did not present a valid session
+func (s *Session) IsNew() bool {
+	return s.isNew
+}
+
+func (s *Session) Get(key string) (any, bool) {
+	v, ok := s.Values[key]
+	return v, ok
+}
+
+func (s *Session) Set(key string, value any) {
+	if s.Values == nil {
+		s.Values = map[string]any{}
+	}
+	s.Values[key] = value
+}
+
+func (s *Session) Delete(key string) {
+	delete(s.Values, key)
+}
+
+// Rotate assigns new ID to the session once it is saved keeping its values, call it when privileges of the
+// session change (e.g. on login) so that a session ID learnt before can not be used afterwards
+func (s *Session) Rotate() {
+	if s.previousID == "" && !s.isNew {
+		s.previousID = s.ID
+	}
+	s.ID = ""
+}
+
+// Manager keeps sessions of browser clients in cookies, values are kept in the cookie itself unless a Store is
+// used and the cookie holds the session ID only; cookies are encrypted and authenticated with AES-GCM so that
+// clients can neither read nor forge them
+type Manager interface {
+	// Load returns session of the request, new session when the request has no cookie or the cookie is invalid
+	// or expired
+	Load(c service.HttpAdapter) (*Session, error)
+	// Save persists session and sets its cookie extending expiration by TTL, sessions expire TTL after they
+	// are saved last; it should be called before the response is written
+	Save(c service.HttpAdapter, s *Session) error
+	// Destroy deletes session and expires its cookie
+	Destroy(c service.HttpAdapter, s *Session) error
+}
+
+type (
+	Option func(*manager)
+)
+
+type manager struct {
+	aeads            []cipher.AEAD
+	store            Store
+	ttl              time.Duration
+	rotationInterval time.Duration
+	clock            util.Clock
+	cookie           http.Cookie
+	previousSecrets  []string
+}
+
+// WithStore keeps session values in store instead of the cookie, e.g. DynamoDBStore
+func WithStore(store Store) Option {
+	return func(m *manager) {
+		m.store = store
+	}
+}
+
+// WithTTL sets how long sessions live after they are saved last, 24 hours by default
+func WithTTL(ttl time.Duration) Option {
+	return func(m *manager) {
+		m.ttl = ttl
+	}
+}
+
+// WithRotationInterval rotates IDs of sessions older than interval when they are saved, see Session.Rotate
+func WithRotationInterval(interval time.Duration) Option {
+	return func(m *manager) {
+		m.rotationInterval = interval
+	}
+}
+
+// WithPreviousSecrets keeps cookies encrypted with previous secrets valid while secrets are rotated, such
+// cookies are re-encrypted with the current secret once saved
+func WithPreviousSecrets(secrets ...string) Option {
+	return func(m *manager) {
+		m.previousSecrets = append(m.previousSecrets, secrets...)
+	}
+}
+
+// WithCookieName sets name of the session cookie, "session" by default
+func WithCookieName(name string) Option {
+	return func(m *manager) {
+		m.cookie.Name = name
+	}
+}
+
+// WithCookieDomain shares session cookie with subdomains of domain
+func WithCookieDomain(domain string) Option {
+	return func(m *manager) {
+		m.cookie.Domain = domain
+	}
+}
+
+// WithCookiePath limits session cookie to path, "/" by default
+func WithCookiePath(path string) Option {
+	return func(m *manager) {
+		m.cookie.Path = path
+	}
+}
+
+// WithSameSite sets SameSite attribute of the session cookie, Lax by default
+func WithSameSite(sameSite http.SameSite) Option {
+	return func(m *manager) {
+		m.cookie.SameSite = sameSite
+	}
+}
+
+// WithInsecureCookie lets session cookie be sent over plain HTTP, meant for local runs only
+func WithInsecureCookie() Option {
+	return func(m *manager) {
+		m.cookie.Secure = false
+	}
+}
+
+func WithClock(clock util.Clock) Option {
+	return func(m *manager) {
+		m.clock = clock
+	}
+}
+
+// New returns manager encrypting cookies with key derived from secret, secret should have at least 32 bytes
+// of entropy (e.g. a random value kept in Secrets Manager)
+func New(secret string, opts ...Option) (Manager, error) {
+	if secret == "" {
+		return nil, errors.Errorf("session secret is not set")
+	}
+	m := &manager{
+		ttl:   DefaultTTL,
+		clock: util.SystemClock(),
+		cookie: http.Cookie{
+			Name:     DefaultCookieName,
+			Path:     "/",
+			Secure:   true,
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		},
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	for _, secret := range append([]string{secret}, m.previousSecrets...) {
+		key := sha256.Sum256([]byte(secret))
+		block, err := aes.NewCipher(key[:])
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create session cipher")
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create session cipher")
+		}
+		m.aeads = append(m.aeads, aead)
+	}
+	return m, nil
+}
+
+func (m *manager) Load(c service.HttpAdapter) (*Session, error) {
+	cookie, err := c.Request().Cookie(m.cookie.Name)
+	if err != nil {
+		return m.newSession(), nil
+	}
+	var s Session
+	if !m.decrypt(cookie.Value, &s) || s.ID == "" || m.expired(&s) {
+		return m.newSession(), nil
+	}
+	if m.store == nil {
+		return &s, nil
+	}
+	stored, err := m.store.Load(c.Context(), s.ID)
+	if err != nil {
+		return nil, err
+	}
+	// DynamoDB TTL removes expired items with a delay
+	if stored == nil || m.expired(stored) {
+		return m.newSession(), nil
+	}
+	return stored, nil
+}
+
+func (m *manager) Save(c service.HttpAdapter, s *Session) error {
+	now := m.clock.Now()
+	if s.ID != "" && m.rotationInterval > 0 && now.Sub(s.RotatedAt) >= m.rotationInterval {
+		s.Rotate()
+	}
+	if s.ID == "" {
+		id, err := newID()
+		if err != nil {
+			return err
+		}
+		s.ID, s.RotatedAt = id, now
+	}
+	s.ExpiresAt = now.Add(m.ttl)
+
+	payload := *s
+	if m.store != nil {
+		if err := m.store.Save(c.Context(), *s); err != nil {
+			return err
+		}
+		if s.previousID != "" {
+			if err := m.store.Delete(c.Context(), s.previousID); err != nil {
+				return err
+			}
+		}
+		// the cookie references the stored session only
+		payload.Values = nil
+	}
+	value, err := m.encrypt(payload)
+	if err != nil {
+		return err
+	}
+	cookie := m.cookie
+	cookie.Value = value
+	cookie.Expires = s.ExpiresAt
+	cookie.MaxAge = int(m.ttl.Seconds())
+	if len(cookie.String()) > maxCookieSize {
+		return ErrCookieTooLarge
+	}
+	c.Writer().Header().Add("Set-Cookie", cookie.String())
+	s.isNew, s.previousID = false, ""
+	return nil
+}
+
+func (m *manager) Destroy(c service.HttpAdapter, s *Session) error {
+	if m.store != nil {
+		for _, id := range []string{s.ID, s.previousID} {
+			if id == "" {
+				continue
+			}
+			if err := m.store.Delete(c.Context(), id); err != nil {
+				return err
+			}
+		}
+	}
+	cookie := m.cookie
+	cookie.MaxAge = -1
+	c.Writer().Header().Add("Set-Cookie", cookie.String())
+	*s = *m.newSession()
+	return nil
+}
+
+func (m *manager) expired(s *Session) bool {
+	return !m.clock.Now().Before(s.ExpiresAt)
+}
+
+func (m *manager) newSession() *Session {
+	return &Session{
+		Values:    map[string]any{},
+		CreatedAt: m.clock.Now(),
+		isNew:     true,
+	}
+}
+
+// encrypt seals JSON of the session with the current secret, cookie name is authenticated as well so that
+// the value of one cookie can not be used as another one
+func (m *manager) encrypt(s Session) (string, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to marshal session")
+	}
+	aead := m.aeads[0]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(data)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", errors.Wrapf(err, "failed to generate session nonce")
+	}
+	return base64.RawURLEncoding.EncodeToString(aead.Seal(nonce, nonce, data, []byte(m.cookie.Name))), nil
+}
+
+func (m *manager) decrypt(value string, s *Session) bool {
+	sealed, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return false
+	}
+	for _, aead := range m.aeads {
+		if len(sealed) < aead.NonceSize() {
+			return false
+		}
+		data, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(m.cookie.Name))
+		if err == nil {
+			return json.Unmarshal(data, s) == nil
+		}
+	}
+	return false
+}
+
+func newID() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrapf(err, "failed to generate session ID")
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,149 @@
+package session_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/service/servicetest"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/session"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/util/clocktest"
+)
+
+func routes(t *testing.T, m session.Manager) service.Option {
+	return service.WithRoutes(func(router service.HttpAdapterRouter) error {
+		router.POST("/api/login", func(c service.HttpAdapter) error {
+			s, err := m.Load(c)
+			require.NoError(t, err)
+			s.Set("user", c.Query("user"))
+			s.Rotate()
+			require.NoError(t, m.Save(c, s))
+			c.JSON(http.StatusOK, service.M{"id": s.ID})
+			return nil
+		})
+		router.GET("/api/me", func(c service.HttpAdapter) error {
+			s, err := m.Load(c)
+			require.NoError(t, err)
+			if s.IsNew() {
+				c.JSON(http.StatusUnauthorized, service.M{"message": "no session"})
+				return nil
+			}
+			require.NoError(t, m.Save(c, s))
+			user, _ := s.Get("user")
+			c.JSON(http.StatusOK, service.M{"id": s.ID, "user": user})
+			return nil
+		})
+		router.POST("/api/logout", func(c service.HttpAdapter) error {
+			s, err := m.Load(c)
+			require.NoError(t, err)
+			require.NoError(t, m.Destroy(c, s))
+			c.JSON(http.StatusOK, service.M{})
+			return nil
+		})
+		return nil
+	})
+}
+
+// sessionCookie returns session cookie set by the response, empty when it is expired
+func sessionCookie(t *testing.T, res *servicetest.Response) string {
+	cookies := (&http.Response{Header: res.Headers}).Cookies()
+	require.Len(t, cookies, 1)
+	if cookies[0].MaxAge < 0 {
+		return ""
+	}
+	return cookies[0].Name + "=" + cookies[0].Value
+}
+
+func TestManager(t *testing.T) {
+	for _, withStore := range []bool{false, true} {
+		t.Run(fmt.Sprintf("store: %t", withStore), func(t *testing.T) {
+			clock := clocktest.New(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+			store := session.MemoryStore()
+			opts := []session.Option{session.WithClock(clock), session.WithTTL(time.Hour)}
+			if withStore {
+				opts = append(opts, session.WithStore(store))
+			}
+			m, err := session.New("secret", opts...)
+			require.NoError(t, err)
+			h := servicetest.New(t, routes(t, m))
+
+			res := h.Invoke(http.MethodGet, "/api/me", nil, nil)
+			assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+			res = h.Invoke(http.MethodPost, "/api/login?user=alice", nil, nil)
+			require.Equal(t, http.StatusOK, res.StatusCode)
+			cookie := sessionCookie(t, res)
+			assert.NotContains(t, cookie, "alice", "session cookie is encrypted")
+			assert.Contains(t, res.Headers.Get("Set-Cookie"), "HttpOnly")
+			assert.Contains(t, res.Headers.Get("Set-Cookie"), "Secure")
+
+			res = h.Invoke(http.MethodGet, "/api/me", nil, map[string]string{"Cookie": cookie})
+			require.Equal(t, http.StatusOK, res.StatusCode)
+			assert.Contains(t, string(res.Body), `"user":"alice"`)
+
+			res = h.Invoke(http.MethodGet, "/api/me", nil, map[string]string{"Cookie": "session=" + strings.Repeat("A", 64)})
+			assert.Equal(t, http.StatusUnauthorized, res.StatusCode, "forged cookie is not accepted")
+
+			clock.Advance(2 * time.Hour)
+			res = h.Invoke(http.MethodGet, "/api/me", nil, map[string]string{"Cookie": cookie})
+			assert.Equal(t, http.StatusUnauthorized, res.StatusCode, "session expired")
+		})
+	}
+}
+
+func TestRotation(t *testing.T) {
+	clock := clocktest.New(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	store := session.MemoryStore()
+	m, err := session.New("secret", session.WithClock(clock), session.WithStore(store), session.WithRotationInterval(10*time.Minute))
+	require.NoError(t, err)
+	h := servicetest.New(t, routes(t, m))
+
+	res := h.Invoke(http.MethodPost, "/api/login?user=alice", nil, nil)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	loginCookie := sessionCookie(t, res)
+
+	clock.Advance(15 * time.Minute)
+	res = h.Invoke(http.MethodGet, "/api/me", nil, map[string]string{"Cookie": loginCookie})
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	rotatedCookie := sessionCookie(t, res)
+	assert.NotEqual(t, loginCookie, rotatedCookie)
+	assert.Contains(t, string(res.Body), `"user":"alice"`, "values are kept across rotation")
+
+	res = h.Invoke(http.MethodGet, "/api/me", nil, map[string]string{"Cookie": loginCookie})
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode, "rotated session ID is not valid anymore")
+
+	res = h.Invoke(http.MethodPost, "/api/logout", nil, map[string]string{"Cookie": rotatedCookie})
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Empty(t, sessionCookie(t, res))
+	res = h.Invoke(http.MethodGet, "/api/me", nil, map[string]string{"Cookie": rotatedCookie})
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode, "destroyed session is deleted from the store")
+}
+
+func TestPreviousSecrets(t *testing.T) {
+	previous, err := session.New("previous-secret")
+	require.NoError(t, err)
+	res := servicetest.New(t, routes(t, previous)).Invoke(http.MethodPost, "/api/login?user=alice", nil, nil)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	cookie := sessionCookie(t, res)
+
+	current, err := session.New("current-secret")
+	require.NoError(t, err)
+	res = servicetest.New(t, routes(t, current)).Invoke(http.MethodGet, "/api/me", nil, map[string]string{"Cookie": cookie})
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	rotated, err := session.New("current-secret", session.WithPreviousSecrets("previous-secret"))
+	require.NoError(t, err)
+	res = servicetest.New(t, routes(t, rotated)).Invoke(http.MethodGet, "/api/me", nil, map[string]string{"Cookie": cookie})
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	res = servicetest.New(t, routes(t, current)).Invoke(http.MethodGet, "/api/me", nil, map[string]string{"Cookie": sessionCookie(t, res)})
+	assert.Equal(t, http.StatusOK, res.StatusCode, "cookie is re-encrypted with the current secret")
+
+	_, err = session.New("")
+	assert.EqualError(t, err, "session secret is not set")
+}
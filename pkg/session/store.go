@@ -0,0 +1,130 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+// Store keeps sessions on the server side so that cookies only hold session IDs
+type Store interface {
+	// Load returns nil when session does not exist
+	Load(ctx context.Context, id string) (*Session, error)
+	Save(ctx context.Context, s Session) error
+	Delete(ctx context.Context, id string) error
+}
+
+type memoryStore struct {
+	mu       sync.Mutex
+	sessions map[string][]byte
+}
+
+// MemoryStore keeps sessions within the instance, meant for tests and local runs
+func MemoryStore() Store {
+	return &memoryStore{sessions: map[string][]byte{}}
+}
+
+func (m *memoryStore) Load(_ context.Context, id string) (*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal session")
+	}
+	return &s, nil
+}
+
+func (m *memoryStore) Save(_ context.Context, s Session) error {
+	// sessions are kept serialized so that they are loaded the way other stores load them
+	data, err := json.Marshal(s)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal session")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[s.ID] = data
+	return nil
+}
+
+func (m *memoryStore) Delete(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, id)
+	return nil
+}
+
+type dynamoDBStore struct {
+	client dynamodbiface.DynamoDBAPI
+	table  string
+}
+
+// DynamoDBStore keeps sessions in the table with "id" hash key, "ttl" attribute holds expiration in epoch
+// seconds so that DynamoDB TTL configured on it removes expired sessions
+func DynamoDBStore(client dynamodbiface.DynamoDBAPI, table string) Store {
+	return &dynamoDBStore{
+		client: client,
+		table:  table,
+	}
+}
+
+func (d *dynamoDBStore) Load(ctx context.Context, id string) (*Session, error) {
+	out, err := d.client.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(d.table),
+		Key:            map[string]*dynamodb.AttributeValue{"id": {S: aws.String(id)}},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load session")
+	}
+	if len(out.Item) == 0 {
+		return nil, nil
+	}
+	var s Session
+	if v, ok := out.Item["data"]; ok {
+		if err := json.Unmarshal([]byte(aws.StringValue(v.S)), &s); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal session")
+		}
+	}
+	return &s, nil
+}
+
+func (d *dynamoDBStore) Save(ctx context.Context, s Session) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal session")
+	}
+	_, err = d.client.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(d.table),
+		Item: map[string]*dynamodb.AttributeValue{
+			"id":   {S: aws.String(s.ID)},
+			"data": {S: aws.String(string(data))},
+			"ttl":  {N: aws.String(strconv.FormatInt(s.ExpiresAt.Unix(), 10))},
+		},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to save session")
+	}
+	return nil
+}
+
+func (d *dynamoDBStore) Delete(ctx context.Context, id string) error {
+	_, err := d.client.DeleteItemWithContext(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(d.table),
+		Key:       map[string]*dynamodb.AttributeValue{"id": {S: aws.String(id)}},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete session")
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package util
+
+import (
+	"time"
+)
+
+// Clock abstracts time so that time dependent behaviour can be tested with clocktest.Clock
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	NewTimer(d time.Duration) ClockTimer
+}
+
+type ClockTimer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+// SystemClock returns clock of the time package
+func SystemClock() Clock {
+	return systemClock{}
+}
+
+// ClockOrSystem returns clock, SystemClock when it is nil
+func ClockOrSystem(clock Clock) Clock {
+	if clock == nil {
+		return SystemClock()
+	}
+	return clock
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+func (systemClock) NewTimer(d time.Duration) ClockTimer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+type systemTimer struct {
+	*time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
@@ -0,0 +1,31 @@
+package clocktest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := New(start)
+
+	first, second, stopped := c.NewTimer(time.Second), c.NewTimer(time.Minute), c.NewTimer(time.Second)
+	assert.True(t, stopped.Stop())
+	assert.Equal(t, 2, c.Timers())
+
+	c.Advance(time.Second)
+	assert.Equal(t, start.Add(time.Second), <-first.C())
+	assert.Equal(t, time.Second, c.Since(start))
+	assert.Len(t, second.C(), 0)
+	assert.Len(t, stopped.C(), 0)
+	assert.False(t, first.Stop())
+
+	c.Set(start.Add(time.Hour))
+	assert.Equal(t, start.Add(time.Hour), <-second.C())
+	assert.Equal(t, 0, c.Timers())
+
+	// timers which are due right away fire immediately
+	assert.Equal(t, start.Add(time.Hour), <-c.NewTimer(0).C())
+}
@@ -0,0 +1,26 @@
+package util
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+)
+
+// traceIDKey is the key of X-Ray trace ID set by lambda runtime
+const traceIDKey = "x-amzn-trace-id"
+
+// DetachContext returns context which is never canceled, e.g. for work continuing after response is sent;
+// only logger values, trace ID and lambda context of ctx are kept, so that request scoped values do not
+// outlive the request
+func DetachContext(ctx context.Context) context.Context {
+	detached := logger.CopyLoggerValues(ctx, context.Background())
+	if traceID, ok := ctx.Value(traceIDKey).(string); ok {
+		detached = context.WithValue(detached, traceIDKey, traceID) //nolint:staticcheck // string key is defined by lambda runtime
+	}
+	if lc, ok := lambdacontext.FromContext(ctx); ok {
+		detached = lambdacontext.NewContext(detached, lc)
+	}
+	return detached
+}
@@ -0,0 +1,38 @@
+package util
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+)
+
+func TestDetachContext(t *testing.T) {
+	type requestKey struct{}
+	log := logger.NewLogger()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	ctx = log.WithValue(ctx, "requestUID", "uid")
+	ctx = context.WithValue(ctx, traceIDKey, "Root=1-abc") //nolint:staticcheck // string key is defined by lambda runtime
+	ctx = lambdacontext.NewContext(ctx, &lambdacontext.LambdaContext{AwsRequestID: "request"})
+	ctx = context.WithValue(ctx, requestKey{}, "request scoped")
+
+	detached := DetachContext(ctx)
+	cancel()
+	require.Error(t, ctx.Err())
+	assert.NoError(t, detached.Err())
+	_, hasDeadline := detached.Deadline()
+	assert.False(t, hasDeadline)
+
+	assert.Equal(t, "uid", log.GetValue(detached, "requestUID"))
+	assert.Equal(t, "Root=1-abc", detached.Value(traceIDKey))
+	lc, ok := lambdacontext.FromContext(detached)
+	require.True(t, ok)
+	assert.Equal(t, "request", lc.AwsRequestID)
+	assert.Nil(t, detached.Value(requestKey{}))
+}
@@ -0,0 +1,82 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+)
+
+// BoundedGroup runs concurrent tasks sharing context which is canceled once a task fails, panics of tasks
+// are recovered as their errors
+type BoundedGroup struct {
+	group  *errgroup.Group
+	ctx    context.Context
+	cancel context.CancelFunc
+	logger logger.Logger
+}
+
+// NewBoundedGroup returns group of tasks bound to ctx running at most limit tasks at once, Go blocks until
+// a task can be started; number of tasks is not limited when limit is not positive
+func NewBoundedGroup(ctx context.Context, limit int) *BoundedGroup {
+	ctx, cancel := context.WithCancel(ctx)
+	group, ctx := errgroup.WithContext(ctx)
+	if limit > 0 {
+		group.SetLimit(limit)
+	}
+	return &BoundedGroup{group: group, ctx: ctx, cancel: cancel}
+}
+
+// WithLogger logs failures of tasks with log, task name is attached to context of the task as "task" value;
+// failures are not logged by default
+func (g *BoundedGroup) WithLogger(log logger.Logger) *BoundedGroup {
+	g.logger = log
+	return g
+}
+
+// Context returns context of the tasks, it is canceled once a task fails or the group is waited for
+func (g *BoundedGroup) Context() context.Context {
+	return g.ctx
+}
+
+// SetLimit changes limit of tasks running at once, it must not be called while tasks are running
+func (g *BoundedGroup) SetLimit(n int) {
+	g.group.SetLimit(n)
+}
+
+// Cancel cancels context of the tasks, e.g. when work they are doing is no longer needed
+func (g *BoundedGroup) Cancel() {
+	g.cancel()
+}
+
+// Go starts task named name
+func (g *BoundedGroup) Go(name string, task func(ctx context.Context) error) {
+	g.group.Go(func() (err error) {
+		ctx := g.ctx
+		if g.logger != nil {
+			ctx = g.logger.WithValue(ctx, "task", name)
+		}
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("task %s panicked: %v", name, r)
+				if g.logger != nil {
+					g.logger.Errorf(g.logger.WithValue(ctx, "stack", string(debug.Stack())), "%v", err)
+				}
+			}
+		}()
+		// failures caused by the first one are not logged
+		if err = task(ctx); err != nil && g.logger != nil && g.ctx.Err() == nil {
+			g.logger.Errorf(g.logger.WithValue(ctx, "error", err.Error()), "task %s failed: %v", name, err)
+		}
+		return err
+	})
+}
+
+// Wait waits for all tasks and returns the first error
+func (g *BoundedGroup) Wait() error {
+	defer g.cancel()
+	return g.group.Wait()
+}
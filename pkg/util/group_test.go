@@ -0,0 +1,79 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+)
+
+type errorsLogger struct {
+	logger.Logger
+	entries []string
+	tasks   []any
+}
+
+func (l *errorsLogger) Errorf(ctx context.Context, format string, args ...any) {
+	l.entries = append(l.entries, fmt.Sprintf(format, args...))
+	l.tasks = append(l.tasks, l.GetValue(ctx, "task"))
+}
+
+func TestBoundedGroupLimit(t *testing.T) {
+	g := NewBoundedGroup(context.Background(), 2)
+	var running, maxRunning atomic.Int32
+	for i := 0; i < 6; i++ {
+		g.Go(fmt.Sprintf("task %d", i), func(context.Context) error {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				current := maxRunning.Load()
+				if n <= current || maxRunning.CompareAndSwap(current, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			return nil
+		})
+	}
+	require.NoError(t, g.Wait())
+	assert.Equal(t, int32(2), maxRunning.Load())
+	assert.Error(t, g.Context().Err())
+}
+
+func TestBoundedGroupFailures(t *testing.T) {
+	log := &errorsLogger{Logger: logger.NewLogger()}
+	g := NewBoundedGroup(context.Background(), 0).WithLogger(log)
+	failure := errors.New("downstream is unavailable")
+	g.Go("fetch", func(ctx context.Context) error {
+		return failure
+	})
+	g.Go("wait", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	assert.ErrorIs(t, g.Wait(), failure)
+	assert.Equal(t, []string{"task fetch failed: downstream is unavailable"}, log.entries)
+	assert.Equal(t, []any{"fetch"}, log.tasks)
+
+	log.entries, log.tasks = nil, nil
+	g = NewBoundedGroup(context.Background(), 0).WithLogger(log)
+	g.Go("parse", func(context.Context) error {
+		var values map[string]int
+		values["key"]++
+		return nil
+	})
+	assert.EqualError(t, g.Wait(), "task parse panicked: assignment to entry in nil map")
+	assert.Equal(t, []string{"task parse panicked: assignment to entry in nil map"}, log.entries)
+
+	// failures are not logged without logger
+	g = NewBoundedGroup(context.Background(), 0)
+	g.Go("panic", func(context.Context) error { panic("boom") })
+	assert.EqualError(t, g.Wait(), "task panic panicked: boom")
+}
@@ -0,0 +1,256 @@
+package lock
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/util"
+)
+
+const defaultRetryInterval = time.Second
+
+var (
+	ErrLocked    = errors.New("lock is held by another owner")
+	ErrLeaseLost = errors.New("lease is lost")
+)
+
+// Locker hands out leases stored in DynamoDB table with "name" hash key, items are never deleted
+// so that fencing tokens keep growing across owners
+type Locker interface {
+	// TryAcquire returns ErrLocked when lock is held by another owner
+	TryAcquire(ctx context.Context, name string, ttl time.Duration) (*Lease, error)
+	// Acquire waits until lock is acquired or ctx is done
+	Acquire(ctx context.Context, name string, ttl time.Duration) (*Lease, error)
+}
+
+type (
+	Option func(*locker)
+)
+
+type locker struct {
+	client        dynamodbiface.DynamoDBAPI
+	table         string
+	owner         string
+	retryInterval time.Duration
+	logger        logger.Logger
+	clock         util.Clock
+}
+
+// WithOwner sets owner id of the leases, random id prefixed with host name is used by default
+func WithOwner(owner string) Option {
+	return func(l *locker) {
+		l.owner = owner
+	}
+}
+
+// WithRetryInterval sets how often Acquire retries to get the lock
+func WithRetryInterval(interval time.Duration) Option {
+	return func(l *locker) {
+		l.retryInterval = interval
+	}
+}
+
+func WithLogger(logger logger.Logger) Option {
+	return func(l *locker) {
+		l.logger = logger
+	}
+}
+
+// WithClock sets clock of lease expirations, retries and heartbeats
+func WithClock(clock util.Clock) Option {
+	return func(l *locker) {
+		l.clock = clock
+	}
+}
+
+func New(client dynamodbiface.DynamoDBAPI, table string, opts ...Option) Locker {
+	hostname, _ := os.Hostname()
+	l := &locker{
+		client:        client,
+		table:         table,
+		owner:         fmt.Sprintf("%s-%s", hostname, uuid.NewString()),
+		retryInterval: defaultRetryInterval,
+		logger:        logger.NewLogger(),
+		clock:         util.SystemClock(),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Lease is an acquired lock, FencingToken grows with every acquisition so that
+// downstream systems can reject writes of the owners whose lease has expired
+type Lease struct {
+	Name         string
+	Owner        string // owner of the locker suffixed with unique id of the lease
+	FencingToken int64
+	ExpiresAt    time.Time
+
+	mu     sync.Mutex
+	ttl    time.Duration
+	locker *locker
+}
+
+func (l *locker) TryAcquire(ctx context.Context, name string, ttl time.Duration) (*Lease, error) {
+	now := l.clock.Now()
+	expiresAt := now.Add(ttl)
+	// every lease has its own owner so that the lock is not re-entrant, even for the same locker
+	owner := fmt.Sprintf("%s/%s", l.owner, uuid.NewString())
+	out, err := l.client.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(l.table),
+		Key:                 map[string]*dynamodb.AttributeValue{"name": {S: aws.String(name)}},
+		UpdateExpression:    aws.String("SET #owner = :owner, expiresAt = :expiresAt ADD fencingToken :one"),
+		ConditionExpression: aws.String("attribute_not_exists(#owner) OR expiresAt < :now"),
+		ExpressionAttributeNames: map[string]*string{
+			"#owner": aws.String("owner"),
+		},
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":owner":     {S: aws.String(owner)},
+			":expiresAt": {N: aws.String(millis(expiresAt))},
+			":now":       {N: aws.String(millis(now))},
+			":one":       {N: aws.String("1")},
+		},
+		ReturnValues: aws.String(dynamodb.ReturnValueAllNew),
+	})
+	if isConditionFailed(err) {
+		return nil, ErrLocked
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "failed to acquire lock %s", name)
+	}
+	token, err := strconv.ParseInt(aws.StringValue(out.Attributes["fencingToken"].N), 10, 64)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid fencing token of lock %s", name)
+	}
+	return &Lease{
+		Name:         name,
+		Owner:        owner,
+		FencingToken: token,
+		ExpiresAt:    expiresAt,
+		ttl:          ttl,
+		locker:       l,
+	}, nil
+}
+
+func (l *locker) Acquire(ctx context.Context, name string, ttl time.Duration) (*Lease, error) {
+	for {
+		lease, err := l.TryAcquire(ctx, name, ttl)
+		if !errors.Is(err, ErrLocked) {
+			return lease, err
+		}
+		timer := l.clock.NewTimer(l.retryInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, errors.Wrapf(ctx.Err(), "failed to acquire lock %s", name)
+		case <-timer.C():
+		}
+	}
+}
+
+// Renew extends lease by its ttl, ErrLeaseLost is returned when lock was taken over after expiration
+func (le *Lease) Renew(ctx context.Context) error {
+	le.mu.Lock()
+	defer le.mu.Unlock()
+	expiresAt := le.locker.clock.Now().Add(le.ttl)
+	err := le.update(ctx, "SET expiresAt = :expiresAt", map[string]*dynamodb.AttributeValue{
+		":expiresAt": {N: aws.String(millis(expiresAt))},
+	})
+	if err != nil {
+		return err
+	}
+	le.ExpiresAt = expiresAt
+	return nil
+}
+
+// Release frees the lock keeping its fencing token
+func (le *Lease) Release(ctx context.Context) error {
+	le.mu.Lock()
+	defer le.mu.Unlock()
+	return le.update(ctx, "REMOVE #owner SET expiresAt = :zero", map[string]*dynamodb.AttributeValue{
+		":zero": {N: aws.String("0")},
+	})
+}
+
+func (le *Lease) update(ctx context.Context, expression string, values map[string]*dynamodb.AttributeValue) error {
+	values[":owner"] = &dynamodb.AttributeValue{S: aws.String(le.Owner)}
+	values[":token"] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(le.FencingToken, 10))}
+	_, err := le.locker.client.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(le.locker.table),
+		Key:                       map[string]*dynamodb.AttributeValue{"name": {S: aws.String(le.Name)}},
+		UpdateExpression:          aws.String(expression),
+		ConditionExpression:       aws.String("#owner = :owner AND fencingToken = :token"),
+		ExpressionAttributeNames:  map[string]*string{"#owner": aws.String("owner")},
+		ExpressionAttributeValues: values,
+	})
+	if isConditionFailed(err) {
+		return ErrLeaseLost
+	} else if err != nil {
+		return errors.Wrapf(err, "failed to update lease of lock %s", le.Name)
+	}
+	return nil
+}
+
+// Heartbeat renews the lease every interval until ctx is done or stop is called, returned context is cancelled
+// once the lease is lost so that the work guarded by the lock can be stopped. Stop waits until renewals are over,
+// so the lease can be released right after it
+func (le *Lease) Heartbeat(ctx context.Context, interval time.Duration) (context.Context, func()) {
+	leaseCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer cancel()
+		for {
+			timer := le.locker.clock.NewTimer(interval)
+			select {
+			case <-leaseCtx.Done():
+				timer.Stop()
+				return
+			case <-timer.C():
+				err := le.Renew(leaseCtx)
+				if errors.Is(err, ErrLeaseLost) {
+					le.locker.logger.Errorf(ctx, "lease of lock %s is lost", le.Name)
+					return
+				} else if err != nil && leaseCtx.Err() == nil {
+					le.locker.logger.Warnf(ctx, "failed to renew lease of lock %s: %v", le.Name, err)
+				}
+				if le.locker.clock.Now().After(le.expiresAt()) {
+					le.locker.logger.Errorf(ctx, "lease of lock %s has expired", le.Name)
+					return
+				}
+			}
+		}
+	}()
+	return leaseCtx, func() {
+		cancel()
+		<-done
+	}
+}
+
+func (le *Lease) expiresAt() time.Time {
+	le.mu.Lock()
+	defer le.mu.Unlock()
+	return le.ExpiresAt
+}
+
+func millis(t time.Time) string {
+	return strconv.FormatInt(t.UnixMilli(), 10)
+}
+
+func isConditionFailed(err error) bool {
+	var awsErr awserr.Error
+	return errors.As(err, &awsErr) && awsErr.Code() == dynamodb.ErrCodeConditionalCheckFailedException
+}
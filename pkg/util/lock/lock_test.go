@@ -0,0 +1,156 @@
+package lock
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/util/clocktest"
+)
+
+type lockItem struct {
+	owner     string
+	expiresAt int64
+	token     int64
+}
+
+// fakeDynamoDB evaluates update expressions used by the locker
+type fakeDynamoDB struct {
+	dynamodbiface.DynamoDBAPI
+	mu     sync.Mutex
+	items  map[string]*lockItem
+	renews int
+}
+
+func (f *fakeDynamoDB) UpdateItemWithContext(_ aws.Context, in *dynamodb.UpdateItemInput, _ ...request.Option) (*dynamodb.UpdateItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	num := func(name string) int64 {
+		n, _ := strconv.ParseInt(aws.StringValue(in.ExpressionAttributeValues[name].N), 10, 64)
+		return n
+	}
+	conditionFailed := awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", nil)
+	name := aws.StringValue(in.Key["name"].S)
+	item, ok := f.items[name]
+	if !ok {
+		item = &lockItem{}
+		f.items[name] = item
+	}
+	owner := aws.StringValue(in.ExpressionAttributeValues[":owner"].S)
+	expression := aws.StringValue(in.UpdateExpression)
+	switch {
+	case strings.Contains(expression, "ADD fencingToken"):
+		if item.owner != "" && item.expiresAt >= num(":now") {
+			return nil, conditionFailed
+		}
+		item.owner, item.expiresAt = owner, num(":expiresAt")
+		item.token++
+		return &dynamodb.UpdateItemOutput{Attributes: map[string]*dynamodb.AttributeValue{
+			"fencingToken": {N: aws.String(strconv.FormatInt(item.token, 10))},
+		}}, nil
+	case item.owner != owner || item.token != num(":token"):
+		return nil, conditionFailed
+	case strings.HasPrefix(expression, "REMOVE #owner"):
+		item.owner, item.expiresAt = "", 0
+	default:
+		f.renews++
+		item.expiresAt = num(":expiresAt")
+	}
+	return &dynamodb.UpdateItemOutput{}, nil
+}
+
+func (f *fakeDynamoDB) expire(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.items[name].expiresAt = 0
+}
+
+func TestLease(t *testing.T) {
+	ctx := context.Background()
+	db := &fakeDynamoDB{items: map[string]*lockItem{}}
+	locker := New(db, "locks", WithOwner("worker"))
+
+	first, err := locker.TryAcquire(ctx, "job", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), first.FencingToken)
+	assert.True(t, strings.HasPrefix(first.Owner, "worker/"))
+
+	_, err = locker.TryAcquire(ctx, "job", time.Minute)
+	assert.ErrorIs(t, err, ErrLocked, "lock must not be re-entrant for the same owner")
+
+	require.NoError(t, first.Renew(ctx))
+	require.NoError(t, first.Release(ctx))
+
+	second, err := locker.TryAcquire(ctx, "job", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), second.FencingToken, "fencing token must keep growing")
+
+	db.expire("job")
+	third, err := New(db, "locks", WithOwner("other")).TryAcquire(ctx, "job", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), third.FencingToken)
+
+	assert.ErrorIs(t, second.Renew(ctx), ErrLeaseLost, "expired lease taken over by another owner must be fenced")
+	assert.ErrorIs(t, second.Release(ctx), ErrLeaseLost)
+	assert.NoError(t, third.Renew(ctx))
+}
+
+func TestHeartbeat(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("renews until stopped", func(t *testing.T) {
+		db := &fakeDynamoDB{items: map[string]*lockItem{}}
+		clock := clocktest.New(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+		lease, err := New(db, "locks", WithClock(clock)).TryAcquire(ctx, "job", time.Minute)
+		require.NoError(t, err)
+		renews := func() int {
+			db.mu.Lock()
+			defer db.mu.Unlock()
+			return db.renews
+		}
+
+		leaseCtx, stop := lease.Heartbeat(ctx, 5*time.Millisecond)
+		for i := 1; i <= 3; i++ {
+			require.Eventually(t, func() bool { return clock.Timers() == 1 }, time.Second, time.Millisecond)
+			clock.Advance(5 * time.Millisecond)
+			require.Eventually(t, func() bool { return renews() == i }, time.Second, time.Millisecond)
+		}
+		stop()
+		require.Error(t, leaseCtx.Err())
+		assert.Equal(t, clock.Now().Add(time.Minute), lease.expiresAt())
+
+		assert.Zero(t, clock.Timers(), "lease must not be renewed after stop")
+		clock.Advance(time.Minute)
+		assert.Equal(t, 3, renews())
+		assert.NoError(t, lease.Release(ctx))
+	})
+
+	t.Run("cancels context when lease is lost", func(t *testing.T) {
+		db := &fakeDynamoDB{items: map[string]*lockItem{}}
+		lease, err := New(db, "locks").TryAcquire(ctx, "job", time.Minute)
+		require.NoError(t, err)
+		db.expire("job")
+		_, err = New(db, "locks").TryAcquire(ctx, "job", time.Minute)
+		require.NoError(t, err)
+
+		leaseCtx, stop := lease.Heartbeat(ctx, 5*time.Millisecond)
+		defer stop()
+		select {
+		case <-leaseCtx.Done():
+		case <-time.After(time.Second):
+			t.Fatal("lease context is not cancelled")
+		}
+	})
+}
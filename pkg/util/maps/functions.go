@@ -1,9 +1,10 @@
 package maps
 
 import (
-	"sync"
+	"context"
+	"fmt"
 
-	"golang.org/x/sync/errgroup"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/util"
 )
 
 // MapErr is similar to lo.Map, but handles error in iteratee function
@@ -22,30 +23,20 @@ func MapErr[T any, R any](collection []T, iteratee func(T, int) (R, error)) ([]R
 }
 
 func MapParallelErr[T any, R any](collection []T, iteratee func(T, int) (R, error)) ([]R, error) {
-	syncStore := sync.Map{}
-	errG := errgroup.Group{}
-
+	result := make([]R, len(collection))
+	group := util.NewBoundedGroup(context.Background(), 0)
 	for i, item := range collection {
-		errG.Go(func() error {
+		group.Go(fmt.Sprintf("item %d", i), func(context.Context) error {
 			res, err := iteratee(item, i)
 			if err != nil {
 				return err
 			}
-			syncStore.Store(i, res)
+			result[i] = res
 			return nil
 		})
 	}
-
-	err := errG.Wait()
-	if err != nil {
+	if err := group.Wait(); err != nil {
 		return nil, err
 	}
-
-	result := make([]R, 0)
-	syncStore.Range(func(key, value any) bool {
-		result = append(result, value.(R))
-		return true
-	})
-
 	return result, nil
 }
@@ -0,0 +1,27 @@
+package maps
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapParallelErr(t *testing.T) {
+	res, err := MapParallelErr([]int{3, 2, 1}, func(item int, _ int) (int, error) {
+		time.Sleep(time.Duration(item) * time.Millisecond)
+		return item * 10, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int{30, 20, 10}, res)
+
+	_, err = MapParallelErr([]int{1, 2}, func(item int, _ int) (int, error) {
+		if item == 2 {
+			return 0, errors.New("item is invalid")
+		}
+		return item, nil
+	})
+	assert.EqualError(t, err, "item is invalid")
+}
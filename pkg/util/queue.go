@@ -0,0 +1,62 @@
+package util
+
+import (
+	"container/heap"
+)
+
+// PriorityQueue pops items in order defined by less, it is not safe for concurrent use
+type PriorityQueue[T any] struct {
+	items *heapItems[T]
+}
+
+func NewPriorityQueue[T any](less func(a, b T) bool) *PriorityQueue[T] {
+	return &PriorityQueue[T]{items: &heapItems[T]{less: less}}
+}
+
+func (q *PriorityQueue[T]) Push(items ...T) {
+	for _, item := range items {
+		heap.Push(q.items, item)
+	}
+}
+
+// Pop removes and returns the least item, false when queue is empty
+func (q *PriorityQueue[T]) Pop() (T, bool) {
+	if q.Len() == 0 {
+		var zero T
+		return zero, false
+	}
+	return heap.Pop(q.items).(T), true
+}
+
+// Peek returns the least item without removing it, false when queue is empty
+func (q *PriorityQueue[T]) Peek() (T, bool) {
+	if q.Len() == 0 {
+		var zero T
+		return zero, false
+	}
+	return q.items.values[0], true
+}
+
+func (q *PriorityQueue[T]) Len() int {
+	return len(q.items.values)
+}
+
+// heapItems implements heap.Interface
+type heapItems[T any] struct {
+	values []T
+	less   func(a, b T) bool
+}
+
+func (h *heapItems[T]) Len() int           { return len(h.values) }
+func (h *heapItems[T]) Less(i, j int) bool { return h.less(h.values[i], h.values[j]) }
+func (h *heapItems[T]) Swap(i, j int)      { h.values[i], h.values[j] = h.values[j], h.values[i] }
+func (h *heapItems[T]) Push(x any)         { h.values = append(h.values, x.(T)) }
+
+func (h *heapItems[T]) Pop() any {
+	last := len(h.values) - 1
+	item := h.values[last]
+	var zero T
+	h.values[last] = zero
+	h.values = h.values[:last]
+	return item
+}
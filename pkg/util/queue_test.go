@@ -0,0 +1,27 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriorityQueue(t *testing.T) {
+	q := NewPriorityQueue(func(a, b int) bool { return a < b })
+	_, ok := q.Pop()
+	assert.False(t, ok)
+
+	q.Push(5, 1, 4)
+	q.Push(2, 3)
+	head, ok := q.Peek()
+	assert.True(t, ok)
+	assert.Equal(t, 1, head)
+	assert.Equal(t, 5, q.Len())
+
+	var res []int
+	for item, ok := q.Pop(); ok; item, ok = q.Pop() {
+		res = append(res, item)
+	}
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, res)
+	assert.Equal(t, 0, q.Len())
+}
@@ -0,0 +1,192 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+const pruneInterval = time.Minute
+
+// Result of taking a single request from the limit of the key
+type Result struct {
+	Allowed    bool
+	Remaining  int           // requests left right after this one
+	RetryAfter time.Duration // when request is not allowed, time until it would be
+}
+
+// Limiter limits requests per key, e.g. client IP or API key; distributed implementations (DynamoDB, Redis)
+// satisfy it to share limits across lambda instances, while in-memory ones limit a single instance
+type Limiter interface {
+	Allow(ctx context.Context, key string) (Result, error)
+}
+
+// Wait blocks until limiter allows request of key or ctx is done, e.g. to pace outbound calls
+func Wait(ctx context.Context, limiter Limiter, key string) error {
+	for {
+		res, err := limiter.Allow(ctx, key)
+		if err != nil || res.Allowed {
+			return err
+		}
+		timer := time.NewTimer(res.RetryAfter)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// TokenBucket allows bursts of up to burst requests refilled at rate requests per second
+type TokenBucket struct {
+	rate     float64
+	burst    int
+	now      func() time.Time
+	mu       sync.Mutex
+	buckets  map[string]*bucket
+	prunedAt time.Time
+}
+
+var _ Limiter = &TokenBucket{}
+
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	return &TokenBucket{
+		rate:    rate,
+		burst:   burst,
+		now:     time.Now,
+		buckets: map[string]*bucket{},
+	}
+}
+
+func (l *TokenBucket) Allow(_ context.Context, key string) (Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.prune(now)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.burst), updatedAt: now}
+		l.buckets[key] = b
+	}
+	b.tokens = l.refill(b, now)
+	b.updatedAt = now
+	if b.tokens < 1 {
+		if l.rate <= 0 {
+			return Result{RetryAfter: math.MaxInt64}, nil
+		}
+		return Result{RetryAfter: time.Duration((1 - b.tokens) / l.rate * float64(time.Second))}, nil
+	}
+	b.tokens--
+	return Result{Allowed: true, Remaining: int(b.tokens)}, nil
+}
+
+func (l *TokenBucket) refill(b *bucket, now time.Time) float64 {
+	return min(float64(l.burst), b.tokens+now.Sub(b.updatedAt).Seconds()*l.rate)
+}
+
+// prune drops buckets which are full again, as they are the same as missing ones
+func (l *TokenBucket) prune(now time.Time) {
+	if now.Sub(l.prunedAt) < pruneInterval {
+		return
+	}
+	l.prunedAt = now
+	for key, b := range l.buckets {
+		if l.refill(b, now) >= float64(l.burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+type window struct {
+	start    time.Time
+	count    int
+	previous int
+}
+
+// SlidingWindow allows limit requests per window, requests of the previous window are weighted by its overlap
+// with the sliding window, so that bursts at window boundaries do not double the limit
+type SlidingWindow struct {
+	limit    int
+	size     time.Duration
+	now      func() time.Time
+	mu       sync.Mutex
+	windows  map[string]*window
+	prunedAt time.Time
+}
+
+var _ Limiter = &SlidingWindow{}
+
+func NewSlidingWindow(limit int, size time.Duration) *SlidingWindow {
+	return &SlidingWindow{
+		limit:   limit,
+		size:    size,
+		now:     time.Now,
+		windows: map[string]*window{},
+	}
+}
+
+func (l *SlidingWindow) Allow(_ context.Context, key string) (Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.prune(now)
+	start := now.Truncate(l.size)
+	w, ok := l.windows[key]
+	if !ok {
+		w = &window{start: start}
+		l.windows[key] = w
+	}
+	l.advance(w, start)
+	overlap := 1 - float64(now.Sub(start))/float64(l.size)
+	used := float64(w.previous)*overlap + float64(w.count)
+	if used+1 > float64(l.limit) {
+		return Result{RetryAfter: l.retryAfter(w, now, start)}, nil
+	}
+	w.count++
+	return Result{Allowed: true, Remaining: int(float64(l.limit) - used - 1)}, nil
+}
+
+func (l *SlidingWindow) advance(w *window, start time.Time) {
+	switch {
+	case w.start.Equal(start):
+	case w.start.Add(l.size).Equal(start):
+		w.previous, w.count, w.start = w.count, 0, start
+	default:
+		w.previous, w.count, w.start = 0, 0, start
+	}
+}
+
+// retryAfter returns time until weight of the previous window decays enough for one more request, which is
+// in the next window when the current one is exhausted by itself
+func (l *SlidingWindow) retryAfter(w *window, now, start time.Time) time.Duration {
+	if l.limit <= 0 {
+		return math.MaxInt64
+	}
+	previous, count := w.previous, w.count
+	if count+1 > l.limit {
+		start, previous, count = start.Add(l.size), count, 0
+	}
+	// previous*(1-elapsed/size) + count + 1 <= limit
+	elapsed := (1 - float64(l.limit-count-1)/float64(previous)) * float64(l.size)
+	return max(start.Add(time.Duration(math.Ceil(elapsed))).Sub(now), time.Millisecond)
+}
+
+// prune drops windows which do not affect the current one
+func (l *SlidingWindow) prune(now time.Time) {
+	if now.Sub(l.prunedAt) < pruneInterval {
+		return
+	}
+	l.prunedAt = now
+	for key, w := range l.windows {
+		if now.Sub(w.start) >= 2*l.size {
+			delete(l.windows, key)
+		}
+	}
+}
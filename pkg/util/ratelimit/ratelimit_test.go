@@ -0,0 +1,94 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func (c *clock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func allow(t *testing.T, limiter Limiter, key string) Result {
+	t.Helper()
+	res, err := limiter.Allow(context.Background(), key)
+	require.NoError(t, err)
+	return res
+}
+
+func TestTokenBucket(t *testing.T) {
+	c := &clock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	limiter := NewTokenBucket(2, 3)
+	limiter.now = c.Now
+
+	for i := 2; i >= 0; i-- {
+		assert.Equal(t, Result{Allowed: true, Remaining: i}, allow(t, limiter, "a"))
+	}
+	assert.Equal(t, Result{RetryAfter: 500 * time.Millisecond}, allow(t, limiter, "a"))
+	// keys have their own buckets
+	assert.True(t, allow(t, limiter, "b").Allowed)
+
+	c.advance(250 * time.Millisecond)
+	assert.Equal(t, Result{RetryAfter: 250 * time.Millisecond}, allow(t, limiter, "a"))
+	c.advance(250 * time.Millisecond)
+	assert.Equal(t, Result{Allowed: true}, allow(t, limiter, "a"))
+
+	// refill does not exceed burst and full buckets are pruned
+	c.advance(time.Hour)
+	assert.Equal(t, Result{Allowed: true, Remaining: 2}, allow(t, limiter, "a"))
+	assert.Len(t, limiter.buckets, 1)
+}
+
+func TestSlidingWindow(t *testing.T) {
+	c := &clock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	limiter := NewSlidingWindow(4, time.Minute)
+	limiter.now = c.Now
+
+	for i := 3; i >= 0; i-- {
+		assert.Equal(t, Result{Allowed: true, Remaining: i}, allow(t, limiter, "a"))
+	}
+	c.advance(30 * time.Second)
+	// the current window is exhausted by itself, requests of it weigh 3 of 4 after 15s of the next window
+	assert.Equal(t, Result{RetryAfter: 45 * time.Second}, allow(t, limiter, "a"))
+
+	c.advance(30 * time.Second)
+	// burst at window boundary does not double the limit
+	assert.Equal(t, Result{RetryAfter: 15 * time.Second}, allow(t, limiter, "a"))
+	c.advance(15 * time.Second)
+	assert.Equal(t, Result{Allowed: true}, allow(t, limiter, "a"))
+	c.advance(15 * time.Second)
+	assert.Equal(t, Result{Allowed: true}, allow(t, limiter, "a"))
+
+	// windows which do not affect the current one are reset and pruned
+	c.advance(2 * time.Minute)
+	assert.Equal(t, Result{Allowed: true, Remaining: 3}, allow(t, limiter, "a"))
+	assert.True(t, allow(t, limiter, "b").Allowed)
+	c.advance(3 * time.Minute)
+	allow(t, limiter, "c")
+	assert.Len(t, limiter.windows, 1)
+}
+
+func TestWait(t *testing.T) {
+	limiter := NewTokenBucket(20, 1)
+	startedAt := time.Now()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, Wait(context.Background(), limiter, "outbound"))
+	}
+	assert.GreaterOrEqual(t, time.Since(startedAt), 100*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, Wait(ctx, NewTokenBucket(0.1, 0), "outbound"), context.DeadlineExceeded)
+}
@@ -0,0 +1,38 @@
+package util
+
+import (
+	"errors"
+)
+
+// Must returns value or panics with err, meant for values which can only fail due to programming errors,
+// e.g. compiling constant templates at init
+func Must[T any](value T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// Try calls all fns and joins their errors, e.g. to close several resources or validate options
+func Try(fns ...func() error) error {
+	var errs []error
+	for _, fn := range fns {
+		if fn == nil {
+			continue
+		}
+		if err := fn(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// FirstNonNil returns the first of values which is not nil, nil when there are none
+func FirstNonNil[T any](values ...*T) *T {
+	for _, value := range values {
+		if value != nil {
+			return value
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package util
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMust(t *testing.T) {
+	assert.Equal(t, 42, Must(strconv.Atoi("42")))
+	assert.PanicsWithError(t, `strconv.Atoi: parsing "x": invalid syntax`, func() {
+		Must(strconv.Atoi("x"))
+	})
+}
+
+func TestTry(t *testing.T) {
+	var called []int
+	call := func(i int, err error) func() error {
+		return func() error {
+			called = append(called, i)
+			return err
+		}
+	}
+	first, second := errors.New("first"), errors.New("second")
+
+	err := Try(call(1, first), nil, call(2, nil), call(3, second))
+	assert.Equal(t, []int{1, 2, 3}, called)
+	assert.ErrorIs(t, err, first)
+	assert.ErrorIs(t, err, second)
+	assert.Equal(t, "first\nsecond", err.Error())
+
+	assert.NoError(t, Try(call(4, nil)))
+	assert.NoError(t, Try())
+}
+
+func TestFirstNonNil(t *testing.T) {
+	assert.Equal(t, lo.ToPtr("b"), FirstNonNil(nil, lo.ToPtr("b"), lo.ToPtr("c")))
+	assert.Nil(t, FirstNonNil[string](nil, nil))
+	assert.Nil(t, FirstNonNil[string]())
+}
@@ -0,0 +1,97 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+
+	"github.com/samber/lo"
+)
+
+// Class of error deciding whether the action is retried
+type Class string
+
+const (
+	ClassNone      Class = ""          // no error
+	ClassPermanent Class = "permanent" // retry would fail the same way, e.g. validation or access errors
+	ClassRetryable Class = "retryable" // transient failure, e.g. 5xx or connection reset
+	ClassThrottled Class = "throttled" // request is throttled, retry with backoff
+	ClassTimeout   Class = "timeout"   // network or per-call timeout
+	ClassCanceled  Class = "canceled"  // context is canceled, retries are pointless
+)
+
+// throttlingCodes are error codes of throttled AWS requests
+var throttlingCodes = []string{
+	"Throttling", "ThrottlingException", "ThrottledException", "RequestThrottledException", "TooManyRequestsException",
+	"ProvisionedThroughputExceededException", "TransactionInProgressException", "RequestLimitExceeded",
+	"BandwidthLimitExceeded", "LimitExceededException", "RequestThrottled", "SlowDown", "PriorRequestNotComplete",
+	"EC2ThrottledException",
+}
+
+// transientCodes are error codes of AWS requests failed for reasons unrelated to the request
+var transientCodes = []string{
+	"RequestTimeout", "RequestTimeoutException", "InternalError", "InternalFailure", "InternalServerError",
+	"ServiceUnavailable", "ServiceUnavailableException", "RequestError", "ECONNRESET",
+}
+
+// Classify returns class of err, AWS SDK errors of both v1 (Code/StatusCode) and v2 (ErrorCode/HTTPStatusCode)
+// are recognized by their methods so that neither SDK is required
+func Classify(err error) Class {
+	if err == nil {
+		return ClassNone
+	}
+	code := errorCode(err)
+	status := statusCode(err)
+	// SDK v1 reports canceled context with its own error code
+	if errors.Is(err, context.Canceled) || code == "RequestCanceled" {
+		return ClassCanceled
+	}
+	switch {
+	case lo.Contains(throttlingCodes, code) || status == http.StatusTooManyRequests:
+		return ClassThrottled
+	case errors.Is(err, context.DeadlineExceeded) || isNetTimeout(err):
+		return ClassTimeout
+	case lo.Contains(transientCodes, code) || status >= http.StatusInternalServerError:
+		return ClassRetryable
+	case errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, io.ErrUnexpectedEOF):
+		return ClassRetryable
+	}
+	return ClassPermanent
+}
+
+// IsRetryable reports whether err is worth retrying, to be used as Config.RetryIf
+func IsRetryable(err error) bool {
+	return lo.Contains([]Class{ClassRetryable, ClassThrottled, ClassTimeout}, Classify(err))
+}
+
+func errorCode(err error) string {
+	var v2 interface{ ErrorCode() string }
+	if errors.As(err, &v2) {
+		return v2.ErrorCode()
+	}
+	var v1 interface{ Code() string }
+	if errors.As(err, &v1) {
+		return v1.Code()
+	}
+	return ""
+}
+
+func statusCode(err error) int {
+	var v2 interface{ HTTPStatusCode() int }
+	if errors.As(err, &v2) {
+		return v2.HTTPStatusCode()
+	}
+	var v1 interface{ StatusCode() int }
+	if errors.As(err, &v1) {
+		return v1.StatusCode()
+	}
+	return 0
+}
+
+func isNetTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,71 @@
+package retry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+)
+
+// apiError mimics errors of AWS SDK v2 (smithy.APIError and HTTP response error)
+type apiError struct {
+	code   string
+	status int
+}
+
+func (e *apiError) Error() string        { return e.code }
+func (e *apiError) ErrorCode() string    { return e.code }
+func (e *apiError) HTTPStatusCode() int  { return e.status }
+func (e *apiError) ErrorMessage() string { return "" }
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Class
+	}{
+		{name: "nil", err: nil, want: ClassNone},
+		{name: "plain", err: fmt.Errorf("invalid input"), want: ClassPermanent},
+		{name: "canceled", err: fmt.Errorf("call: %w", context.Canceled), want: ClassCanceled},
+		{name: "deadline exceeded", err: fmt.Errorf("call: %w", context.DeadlineExceeded), want: ClassTimeout},
+		{name: "v1 throttling", err: awserr.NewRequestFailure(awserr.New("ThrottlingException", "rate exceeded", nil), 400, "id"), want: ClassThrottled},
+		{name: "v1 server error", err: awserr.NewRequestFailure(awserr.New("InternalFailure", "oops", nil), 500, "id"), want: ClassRetryable},
+		{name: "v1 not found", err: awserr.NewRequestFailure(awserr.New("ResourceNotFoundException", "no table", nil), 400, "id"), want: ClassPermanent},
+		{name: "v1 canceled", err: awserr.New("RequestCanceled", "request context canceled", context.Canceled), want: ClassCanceled},
+		{name: "v2 throttling", err: fmt.Errorf("operation error: %w", &apiError{code: "TooManyRequestsException", status: 400}), want: ClassThrottled},
+		{name: "v2 too many requests", err: &apiError{code: "Unknown", status: 429}, want: ClassThrottled},
+		{name: "v2 service unavailable", err: &apiError{code: "Unknown", status: 503}, want: ClassRetryable},
+		{name: "v2 validation", err: &apiError{code: "ValidationException", status: 400}, want: ClassPermanent},
+		{name: "net timeout", err: &net.DNSError{Err: "i/o timeout", IsTimeout: true}, want: ClassTimeout},
+		{name: "connection reset", err: &net.OpError{Op: "read", Err: syscall.ECONNRESET}, want: ClassRetryable},
+		{name: "unexpected eof", err: fmt.Errorf("read body: %w", io.ErrUnexpectedEOF), want: ClassRetryable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Classify(tt.err))
+		})
+	}
+}
+
+func TestRetryIf(t *testing.T) {
+	errs := []error{&apiError{code: "Throttling", status: 400}, context.Canceled, fmt.Errorf("never reached")}
+	attempted := 0
+	_, err := With[string](context.Background(), Config[string]{
+		Action: func(context.Context) (string, error) {
+			attempted++
+			return "", errs[attempted-1]
+		},
+		MaxRetries: 3,
+		RetryIf:    IsRetryable,
+		Backoff:    func(int) time.Duration { return time.Millisecond },
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 2, attempted)
+}
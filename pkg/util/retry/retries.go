@@ -1,36 +1,135 @@
 package retry
 
 import (
+	"context"
 	"fmt"
+	"math"
+	"time"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/util"
 )
 
+// Unlimited is MaxRetries of actions retried until they succeed or the budget (MaxElapsed or deadline of the
+// context) is spent, With fails right away without a budget
+const Unlimited = -1
+
 type Config[T any] struct {
-	Action                 func() (T, error)
-	MaxRetries             int
-	AttemptErrorCallback   func(int, error)
-	NoMoreAttemptsCallback func(error)
+	Action                 func(ctx context.Context) (T, error)
+	MaxRetries             int // attempts including the first one, it is made once when not positive, see Unlimited
+	AttemptErrorCallback   func(ctx context.Context, attempt int, err error)
+	NoMoreAttemptsCallback func(ctx context.Context, err error)
+	// RetryIf decides whether failed attempt is retried (e.g. IsRetryable), all errors are retried when nil
+	RetryIf func(err error) bool
+	// Backoff returns delay before the attempt following the failed one, attempts are not delayed when nil
+	Backoff func(attempt int) time.Duration
+	// MaxElapsed is the budget of all attempts including backoff delays, attempts which would start past
+	// the budget are not made (running attempt is not interrupted); deadline of the context (e.g. of lambda
+	// invocation) is the budget when not set
+	MaxElapsed time.Duration
+	// Logger logs failed attempts with their delay and error class, Operation names the action in the logs
+	Logger    logger.Logger
+	Operation string
+	// Clock measures the budget and backoff delays, system clock by default
+	Clock util.Clock
+}
+
+// ExponentialBackoff doubles delay starting with base up to maxDelay
+func ExponentialBackoff(base, maxDelay time.Duration) func(attempt int) time.Duration {
+	return func(attempt int) time.Duration {
+		delay := base
+		for i := 1; i < attempt && delay < maxDelay; i++ {
+			delay *= 2
+		}
+		return min(delay, maxDelay)
+	}
 }
 
-func With[T any](in Config[T]) (*T, error) {
+// With runs action until it succeeds or attempts are exhausted, delays are stopped once ctx is done
+func With[T any](ctx context.Context, in Config[T]) (*T, error) {
 	if in.Action == nil {
 		return nil, fmt.Errorf("action is nil")
 	}
+	clock := util.ClockOrSystem(in.Clock)
+	var deadline time.Time
+	if in.MaxElapsed > 0 {
+		deadline = clock.Now().Add(in.MaxElapsed)
+	} else if ctxDeadline, ok := ctx.Deadline(); ok {
+		deadline = ctxDeadline
+	}
+	maxRetries := max(in.MaxRetries, 1)
+	if in.MaxRetries == Unlimited {
+		if deadline.IsZero() {
+			return nil, fmt.Errorf("unlimited retries require MaxElapsed or deadline of the context")
+		}
+		maxRetries = math.MaxInt
+	}
 	var res T
 	var err error
-	for attempt := 1; attempt <= in.MaxRetries; attempt++ {
-		res, err = in.Action()
+	for attempt := 1; attempt <= maxRetries; attempt++ {
+		res, err = in.Action(ctx)
 		if err == nil {
 			return &res, nil
 		}
 		if in.AttemptErrorCallback != nil {
-			in.AttemptErrorCallback(attempt, err)
+			in.AttemptErrorCallback(ctx, attempt, err)
 		}
-		if attempt >= in.MaxRetries {
+		retry := attempt < maxRetries && (in.RetryIf == nil || in.RetryIf(err))
+		delay := backoffDelay(in.Backoff, attempt)
+		if retry && (ctx.Err() != nil || (!deadline.IsZero() && !clock.Now().Add(delay).Before(deadline))) {
+			retry = false
+		}
+		in.logAttempt(ctx, attempt, err, retry, delay)
+		if !retry || !wait(ctx, clock, delay) {
 			if in.NoMoreAttemptsCallback != nil {
-				in.NoMoreAttemptsCallback(err)
+				in.NoMoreAttemptsCallback(ctx, err)
 			}
 			return nil, err
 		}
 	}
 	return &res, nil
 }
+
+func (in Config[T]) logAttempt(ctx context.Context, attempt int, err error, retry bool, delay time.Duration) {
+	if in.Logger == nil {
+		return
+	}
+	ctx = in.Logger.WithValues(ctx, map[string]any{
+		"operation": in.operation(), "attempt": attempt, "errorClass": Classify(err), "retry": retry,
+	})
+	if retry {
+		in.Logger.Warnf(in.Logger.WithValue(ctx, "delay", delay.String()), "attempt %d of %s failed, retrying in %s: %v",
+			attempt, in.operation(), delay, err)
+		return
+	}
+	in.Logger.Errorf(ctx, "attempt %d of %s failed, giving up: %v", attempt, in.operation(), err)
+}
+
+func (in Config[T]) operation() string {
+	if in.Operation == "" {
+		return "action"
+	}
+	return in.Operation
+}
+
+func backoffDelay(backoff func(int) time.Duration, attempt int) time.Duration {
+	if backoff == nil {
+		return 0
+	}
+	return backoff(attempt)
+}
+
+// wait sleeps for delay unless ctx is done first
+func wait(ctx context.Context, clock util.Clock, delay time.Duration) bool {
+	if delay <= 0 {
+		return true
+	}
+	timer := clock.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C():
+		return true
+	}
+}
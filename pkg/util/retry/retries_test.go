@@ -1,11 +1,17 @@
 package retry
 
 import (
+	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/samber/lo"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/util/clocktest"
 )
 
 func TestWithRetries(t *testing.T) {
@@ -60,16 +66,16 @@ func TestWithRetries(t *testing.T) {
 			attempted := 0
 			failedAttempted := 0
 			reported := 0
-			res, err := With[string](Config[string]{
-				Action: func() (string, error) {
+			res, err := With[string](context.Background(), Config[string]{
+				Action: func(context.Context) (string, error) {
 					attempted++
 					return tt.action()
 				},
 				MaxRetries: tt.maxRetries,
-				AttemptErrorCallback: func(attempt int, err error) {
+				AttemptErrorCallback: func(_ context.Context, attempt int, err error) {
 					failedAttempted++
 				},
-				NoMoreAttemptsCallback: func(err error) {
+				NoMoreAttemptsCallback: func(_ context.Context, err error) {
 					reported++
 				},
 			})
@@ -83,3 +89,161 @@ func TestWithRetries(t *testing.T) {
 		})
 	}
 }
+
+func TestWithRetriesBudget(t *testing.T) {
+	failing := func() (string, error) { return "", fmt.Errorf("some error") }
+	tests := []struct {
+		name         string
+		config       Config[string]
+		ctxTimeout   time.Duration
+		wantAttempts int
+		wantMaxTime  time.Duration
+	}{
+		{
+			name:         "backoff within retries",
+			config:       Config[string]{MaxRetries: 3, Backoff: ExponentialBackoff(10*time.Millisecond, time.Second)},
+			wantAttempts: 3,
+			wantMaxTime:  100 * time.Millisecond,
+		},
+		{
+			name:         "max elapsed stops retries",
+			config:       Config[string]{MaxRetries: 10, Backoff: ExponentialBackoff(20*time.Millisecond, time.Second), MaxElapsed: 100 * time.Millisecond},
+			wantAttempts: 3, // delays of 20ms and 40ms fit the budget, 80ms would exceed it
+			wantMaxTime:  100 * time.Millisecond,
+		},
+		{
+			name:         "attempts limited by budget only",
+			config:       Config[string]{MaxRetries: Unlimited, Backoff: func(int) time.Duration { return 30 * time.Millisecond }, MaxElapsed: 100 * time.Millisecond},
+			wantAttempts: 4,
+			wantMaxTime:  100 * time.Millisecond,
+		},
+		{
+			name:         "single attempt when retries are not set",
+			config:       Config[string]{Backoff: func(int) time.Duration { return 30 * time.Millisecond }},
+			ctxTimeout:   time.Second,
+			wantAttempts: 1,
+			wantMaxTime:  30 * time.Millisecond,
+		},
+		{
+			name:         "context deadline is the default budget",
+			config:       Config[string]{MaxRetries: 10, Backoff: func(int) time.Duration { return 30 * time.Millisecond }},
+			ctxTimeout:   50 * time.Millisecond,
+			wantAttempts: 2,
+			wantMaxTime:  50 * time.Millisecond,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.ctxTimeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.ctxTimeout)
+				defer cancel()
+			}
+			attempted := 0
+			tt.config.Action = func(context.Context) (string, error) {
+				attempted++
+				return failing()
+			}
+			startedAt := time.Now()
+			res, err := With[string](ctx, tt.config)
+			assert.Error(t, err)
+			assert.Nil(t, res)
+			assert.Equal(t, tt.wantAttempts, attempted)
+			assert.Less(t, time.Since(startedAt), tt.wantMaxTime)
+		})
+	}
+}
+
+func TestWithUnlimitedRetriesRequireBudget(t *testing.T) {
+	attempted := 0
+	_, err := With[string](context.Background(), Config[string]{
+		Action: func(context.Context) (string, error) {
+			attempted++
+			return "", fmt.Errorf("some error")
+		},
+		MaxRetries: Unlimited,
+	})
+	assert.EqualError(t, err, "unlimited retries require MaxElapsed or deadline of the context")
+	assert.Zero(t, attempted)
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(100*time.Millisecond, time.Second)
+	assert.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second},
+		lo.Map([]int{1, 2, 3, 4, 5}, func(attempt int, _ int) time.Duration { return backoff(attempt) }))
+}
+
+type recordingLogger struct {
+	logger.Logger
+	entries []string
+	values  []logger.ContextValue
+}
+
+func (l *recordingLogger) Warnf(ctx context.Context, format string, args ...any) {
+	l.add(ctx, "WARN "+format, args)
+}
+
+func (l *recordingLogger) Errorf(ctx context.Context, format string, args ...any) {
+	l.add(ctx, "ERROR "+format, args)
+}
+
+func (l *recordingLogger) add(ctx context.Context, format string, args []any) {
+	l.entries = append(l.entries, fmt.Sprintf(format, args...))
+	l.values = append(l.values, logger.GetValues(ctx))
+}
+
+func TestWithRetriesLogger(t *testing.T) {
+	log := &recordingLogger{Logger: logger.NewLogger()}
+	type requestKey struct{}
+	ctx := context.WithValue(context.Background(), requestKey{}, "request")
+	_, err := With[string](ctx, Config[string]{
+		Action: func(ctx context.Context) (string, error) {
+			assert.Equal(t, "request", ctx.Value(requestKey{}))
+			return "", context.DeadlineExceeded
+		},
+		MaxRetries: 2,
+		Backoff:    func(int) time.Duration { return time.Millisecond },
+		Logger:     log,
+		Operation:  "fetch profile",
+	})
+	require.Error(t, err)
+	assert.Equal(t, []string{
+		"WARN attempt 1 of fetch profile failed, retrying in 1ms: context deadline exceeded",
+		"ERROR attempt 2 of fetch profile failed, giving up: context deadline exceeded",
+	}, log.entries)
+	require.Len(t, log.values, 2)
+	assert.Equal(t, ClassTimeout, log.values[0]["errorClass"])
+	assert.Equal(t, "1ms", log.values[0]["delay"])
+	assert.Equal(t, false, log.values[1]["retry"])
+}
+
+func TestWithRetriesClock(t *testing.T) {
+	clock := clocktest.New(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	attempted := make(chan int, 10)
+	done := make(chan error)
+	go func() {
+		n := 0
+		_, err := With[string](context.Background(), Config[string]{
+			Action: func(context.Context) (string, error) {
+				n++
+				attempted <- n
+				return "", fmt.Errorf("some error")
+			},
+			MaxRetries: Unlimited,
+			Backoff:    ExponentialBackoff(time.Minute, time.Hour),
+			MaxElapsed: 5 * time.Minute,
+			Clock:      clock,
+		})
+		done <- err
+	}()
+
+	// delays of 1m and 2m fit the budget, 4m would exceed it
+	for _, delay := range []time.Duration{time.Minute, 2 * time.Minute} {
+		<-attempted
+		require.Eventually(t, func() bool { return clock.Timers() == 1 }, time.Second, time.Millisecond)
+		clock.Advance(delay)
+	}
+	assert.Equal(t, 3, <-attempted)
+	assert.EqualError(t, <-done, "some error")
+}
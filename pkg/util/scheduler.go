@@ -0,0 +1,108 @@
+package util
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Scheduler executes funcs at or after their time within the invocation, e.g. retrying after Retry-After
+// or flushing buffers periodically during long streaming responses; funcs are executed one by one by Run
+type Scheduler struct {
+	clock Clock
+	wake  chan struct{}
+
+	mu    sync.Mutex
+	seq   uint64
+	queue *PriorityQueue[scheduledTask]
+}
+
+type scheduledTask struct {
+	at  time.Time
+	seq uint64
+	fn  func(ctx context.Context)
+}
+
+// NewScheduler creates scheduler measuring time with clock, SystemClock when it is nil
+func NewScheduler(clock Clock) *Scheduler {
+	return &Scheduler{
+		clock: ClockOrSystem(clock),
+		wake:  make(chan struct{}, 1),
+		queue: NewPriorityQueue(func(a, b scheduledTask) bool {
+			// funcs due at the same time are executed in order of scheduling
+			return a.at.Before(b.at) || a.at.Equal(b.at) && a.seq < b.seq
+		}),
+	}
+}
+
+// At schedules fn to be executed at time at, immediately when it is in the past
+func (s *Scheduler) At(at time.Time, fn func(ctx context.Context)) {
+	s.mu.Lock()
+	s.seq++
+	s.queue.Push(scheduledTask{at: at, seq: s.seq, fn: fn})
+	s.mu.Unlock()
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// After schedules fn to be executed after delay d
+func (s *Scheduler) After(d time.Duration, fn func(ctx context.Context)) {
+	s.At(s.clock.Now().Add(d), fn)
+}
+
+// Len returns number of funcs which are not executed yet
+func (s *Scheduler) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.queue.Len()
+}
+
+// Run executes funcs when they are due until ctx is done, funcs left are not executed unless Flush is called
+func (s *Scheduler) Run(ctx context.Context) error {
+	for {
+		s.mu.Lock()
+		next, ok := s.queue.Peek()
+		if ok && !next.at.After(s.clock.Now()) {
+			s.queue.Pop()
+			s.mu.Unlock()
+			next.fn(ctx)
+			continue
+		}
+		s.mu.Unlock()
+
+		var (
+			due   <-chan time.Time
+			timer ClockTimer
+		)
+		if ok {
+			timer = s.clock.NewTimer(next.at.Sub(s.clock.Now()))
+			due = timer.C()
+		}
+		select {
+		case <-ctx.Done():
+		case <-s.wake:
+		case <-due:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+// Flush executes all funcs left in order of their time without waiting for it, e.g. before invocation ends
+func (s *Scheduler) Flush(ctx context.Context) {
+	for {
+		s.mu.Lock()
+		next, ok := s.queue.Pop()
+		s.mu.Unlock()
+		if !ok {
+			return
+		}
+		next.fn(ctx)
+	}
+}
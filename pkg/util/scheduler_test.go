@@ -0,0 +1,64 @@
+package util_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/util"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/util/clocktest"
+)
+
+func TestScheduler(t *testing.T) {
+	clock := clocktest.New(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	s := util.NewScheduler(clock)
+
+	var (
+		mu       sync.Mutex
+		executed []string
+	)
+	record := func(name string) func(context.Context) {
+		return func(context.Context) {
+			mu.Lock()
+			defer mu.Unlock()
+			executed = append(executed, name)
+		}
+	}
+	executedSoFar := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), executed...)
+	}
+
+	s.After(2*time.Second, record("flush"))
+	s.After(time.Second, record("retry"))
+	s.After(time.Second, record("retry again"))
+	s.After(-time.Second, record("overdue"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.Run(ctx) }()
+
+	require.Eventually(t, func() bool { return clock.Timers() == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"overdue"}, executedSoFar())
+
+	clock.Advance(time.Second)
+	require.Eventually(t, func() bool { return len(executedSoFar()) == 3 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"overdue", "retry", "retry again"}, executedSoFar())
+
+	s.After(time.Hour, record("later"))
+	require.Eventually(t, func() bool { return s.Len() == 2 && clock.Timers() == 1 }, time.Second, time.Millisecond)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.Equal(t, 0, clock.Timers())
+	assert.Equal(t, 2, s.Len())
+
+	s.Flush(context.Background())
+	assert.Equal(t, []string{"overdue", "retry", "retry again", "flush", "later"}, executedSoFar())
+	assert.Equal(t, 0, s.Len())
+}
@@ -0,0 +1,73 @@
+package util
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+)
+
+type timingsKeyType struct{}
+
+var timingsKey = timingsKeyType{}
+
+type timings struct {
+	mu        sync.Mutex
+	clock     Clock
+	durations map[string]time.Duration
+}
+
+// WithTimings returns context collecting durations measured with Timer, e.g. for the request being served
+func WithTimings(ctx context.Context) context.Context {
+	return WithTimingsClock(ctx, SystemClock())
+}
+
+// WithTimingsClock is WithTimings measuring durations with clock, e.g. clocktest.Clock for deterministic tests
+func WithTimingsClock(ctx context.Context, clock Clock) context.Context {
+	return context.WithValue(ctx, timingsKey, &timings{clock: clock, durations: map[string]time.Duration{}})
+}
+
+// Timings returns durations collected in context by operation, repeated operations are summed up
+func Timings(ctx context.Context) map[string]time.Duration {
+	t, ok := ctx.Value(timingsKey).(*timings)
+	if !ok {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	res := make(map[string]time.Duration, len(t.durations))
+	for operation, duration := range t.durations {
+		res[operation] = duration
+	}
+	return res
+}
+
+// Timer starts measuring operation, returned func logs the duration, adds it to timings of the context
+// (see WithTimings) and returns it
+func Timer(ctx context.Context, log logger.Logger, operation string) func() time.Duration {
+	t, ok := ctx.Value(timingsKey).(*timings)
+	clock := SystemClock()
+	if ok {
+		clock = t.clock
+	}
+	startedAt := clock.Now()
+	return func() time.Duration {
+		duration := clock.Since(startedAt)
+		if ok {
+			t.mu.Lock()
+			t.durations[operation] += duration
+			t.mu.Unlock()
+		}
+		log.Infof(log.WithValues(ctx, map[string]any{"operation": operation, "durationMs": duration.Milliseconds()}),
+			"%s took %s", operation, duration)
+		return duration
+	}
+}
+
+// TimeIt measures fn with Timer
+func TimeIt[T any](ctx context.Context, log logger.Logger, operation string, fn func(ctx context.Context) (T, error)) (T, error) {
+	stop := Timer(ctx, log, operation)
+	defer stop()
+	return fn(ctx)
+}
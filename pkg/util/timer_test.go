@@ -0,0 +1,59 @@
+package util_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/logger"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/util"
+	"github.com/simple-container-com/go-aws-lambda-sdk/pkg/util/clocktest"
+)
+
+type recordingLogger struct {
+	logger.Logger
+	entries []string
+	values  []logger.ContextValue
+}
+
+func (l *recordingLogger) Infof(ctx context.Context, format string, args ...any) {
+	l.entries = append(l.entries, fmt.Sprintf(format, args...))
+	l.values = append(l.values, logger.GetValues(ctx))
+}
+
+func TestTimer(t *testing.T) {
+	log := &recordingLogger{Logger: logger.NewLogger()}
+	clock := clocktest.New(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	ctx := util.WithTimingsClock(context.Background(), clock)
+
+	stop := util.Timer(ctx, log, "load")
+	clock.Advance(20 * time.Millisecond)
+	duration := stop()
+	assert.Equal(t, 20*time.Millisecond, duration)
+
+	res, err := util.TimeIt(ctx, log, "load", func(context.Context) (string, error) {
+		clock.Advance(10 * time.Millisecond)
+		return "ok", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", res)
+
+	_, err = util.TimeIt(ctx, log, "save", func(context.Context) (int, error) {
+		return 0, fmt.Errorf("failed")
+	})
+	assert.EqualError(t, err, "failed")
+
+	timings := util.Timings(ctx)
+	assert.Equal(t, 30*time.Millisecond, timings["load"])
+	assert.Contains(t, timings, "save")
+	require.Len(t, log.entries, 3)
+	assert.Equal(t, "load took 20ms", log.entries[0])
+	assert.Equal(t, "load", log.values[0]["operation"])
+	assert.Equal(t, duration.Milliseconds(), log.values[0]["durationMs"])
+
+	assert.Nil(t, util.Timings(context.Background()))
+}